@@ -26,6 +26,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/agents"
 	"github.com/Peripli/service-manager/pkg/env"
 	"sync"
+	"time"
 
 	"github.com/Peripli/service-manager/pkg/query"
 
@@ -38,6 +39,7 @@ import (
 
 	"github.com/Peripli/service-manager/api/filters"
 	"github.com/Peripli/service-manager/api/info"
+	"github.com/Peripli/service-manager/api/jobs"
 	"github.com/Peripli/service-manager/api/osb"
 	"github.com/Peripli/service-manager/pkg/health"
 	"github.com/Peripli/service-manager/pkg/web"
@@ -48,19 +50,21 @@ const osbVersion = "2.13"
 
 // Settings type to be loaded from the environment
 type Settings struct {
-	TokenIssuerURL             string   `mapstructure:"token_issuer_url" description:"url of the token issuer which to use for validating tokens"`
-	ClientID                   string   `mapstructure:"client_id" description:"id of the client from which the token must be issued"`
-	TokenBasicAuth             bool     `mapstructure:"token_basic_auth" description:"specifies if client credentials to the authorization server should be sent in the header as basic auth (true) or in the body (false)"`
-	ProtectedLabels            []string `mapstructure:"protected_labels" description:"defines labels which cannot be modified/added by REST API requests"`
-	OSBVersion                 string   `mapstructure:"-"`
-	MaxPageSize                int      `mapstructure:"max_page_size" description:"maximum number of items that could be returned in a single page"`
-	DefaultPageSize            int      `mapstructure:"default_page_size" description:"default number of items returned in a single page if not specified in request"`
-	EnableInstanceTransfer     bool     `mapstructure:"enable_instance_transfer" description:"whether service instance transfer is enabled or not"`
-	RateLimit                  string   `mapstructure:"rate_limit" description:"rate limiter configuration defined in format: rate<:path><,rate<:path>,...>"`
-	RateLimitingEnabled        bool     `mapstructure:"rate_limiting_enabled" description:"enable rate limiting"`
-	RateLimitExcludeClients    []string `mapstructure:"rate_limit_exclude_clients" description:"define client users that should be excluded from the rate limiter processing"`
-	RateLimitExcludePaths      []string `mapstructure:"rate_limit_exclude_paths" description:"define paths that should be excluded from the rate limiter processing"`
-	RateLimitUsageLogThreshold int64    `mapstructure:"rate_limiting_usage_log_threshold" description:"defines a threshold for log notification trigger about requests limit usage. Accepts value in range from 0 to 100 (percents)"`
+	TokenIssuerURL             string        `mapstructure:"token_issuer_url" description:"url of the token issuer which to use for validating tokens"`
+	ClientID                   string        `mapstructure:"client_id" description:"id of the client from which the token must be issued"`
+	TokenBasicAuth             bool          `mapstructure:"token_basic_auth" description:"specifies if client credentials to the authorization server should be sent in the header as basic auth (true) or in the body (false)"`
+	ProtectedLabels            []string      `mapstructure:"protected_labels" description:"defines labels which cannot be modified/added by REST API requests"`
+	OSBVersion                 string        `mapstructure:"-"`
+	MaxPageSize                int           `mapstructure:"max_page_size" description:"maximum number of items that could be returned in a single page"`
+	DefaultPageSize            int           `mapstructure:"default_page_size" description:"default number of items returned in a single page if not specified in request"`
+	MaxBulkItems               int           `mapstructure:"max_bulk_items" description:"maximum number of items that could be created, updated or deleted in a single bulk request"`
+	EnableInstanceTransfer     bool          `mapstructure:"enable_instance_transfer" description:"whether service instance transfer is enabled or not"`
+	RateLimit                  string        `mapstructure:"rate_limit" description:"rate limiter configuration defined in format: rate<:path><,rate<:path>,...>"`
+	RateLimitingEnabled        bool          `mapstructure:"rate_limiting_enabled" description:"enable rate limiting"`
+	RateLimitExcludeClients    []string      `mapstructure:"rate_limit_exclude_clients" description:"define client users that should be excluded from the rate limiter processing"`
+	RateLimitExcludePaths      []string      `mapstructure:"rate_limit_exclude_paths" description:"define paths that should be excluded from the rate limiter processing"`
+	RateLimitUsageLogThreshold int64         `mapstructure:"rate_limiting_usage_log_threshold" description:"defines a threshold for log notification trigger about requests limit usage. Accepts value in range from 0 to 100 (percents)"`
+	LastModifiedSafetyWindow   time.Duration `mapstructure:"last_modified_safety_window" description:"time subtracted from the start of a list request for its Last-Modified header, so that changes of transactions committed later are still reported. Should be longer than the longest transaction"`
 }
 
 // DefaultSettings returns default values for API settings
@@ -73,11 +77,13 @@ func DefaultSettings() *Settings {
 		OSBVersion:                 osbVersion,
 		MaxPageSize:                200,
 		DefaultPageSize:            50,
+		MaxBulkItems:               500,
 		EnableInstanceTransfer:     false,
 		RateLimit:                  "10000-H,1000-M",
 		RateLimitingEnabled:        false,
 		RateLimitExcludeClients:    []string{},
 		RateLimitUsageLogThreshold: 10,
+		LastModifiedSafetyWindow:   time.Minute,
 	}
 }
 
@@ -86,6 +92,9 @@ func (s *Settings) Validate() error {
 	if (len(s.TokenIssuerURL)) == 0 {
 		return fmt.Errorf("validate Settings: APITokenIssuerURL missing")
 	}
+	if s.LastModifiedSafetyWindow < 0 {
+		return fmt.Errorf("validate Settings: LastModifiedSafetyWindow must not be negative")
+	}
 	return validateRateLimiterConfiguration(s.RateLimit)
 }
 
@@ -95,9 +104,28 @@ type Options struct {
 	OperationSettings *operations.Settings
 	WSSettings        *ws.Settings
 	Notificator       storage.Notificator
-	WaitGroup         *sync.WaitGroup
-	TenantLabelKey    string
-	Agents            *agents.Settings
+	// NotificationAcks stores the revisions of the notifications acknowledged by the platforms
+	NotificationAcks storage.NotificationAckStore
+	WaitGroup        *sync.WaitGroup
+	TenantLabelKey   string
+	Agents           *agents.Settings
+	// JobWorker executes the queued asynchronous operations. If it is not provided, asynchronous operations are
+	// executed by in-process workers only.
+	JobWorker *operations.JobWorker
+	// CancellationNotifier propagates the cancellation of operations to the instance executing them. If it is not
+	// provided, only the operations executed by this instance are aborted when they are canceled.
+	CancellationNotifier storage.CancellationNotifier
+	// LeaseStore provides the leaders and the last runs of the background jobs. If it is not provided, the background
+	// jobs monitoring endpoint is not registered.
+	LeaseStore storage.LeaseStore
+	// RevisionHorizon provides the revisions up to which the resource events are committed
+	RevisionHorizon storage.RevisionHorizon
+	// ColumnMapper provides the columns by which the objects can be ordered and grouped. If it is not provided, the
+	// objects can be ordered and grouped only by labels.
+	ColumnMapper storage.ColumnMapper
+	// OperationHooks are invoked before and after the asynchronous operations. If they are not provided, operations
+	// are started without approval.
+	OperationHooks *operations.Hooks
 }
 
 // New returns the minimum set of REST APIs needed for the Service Manager
@@ -107,26 +135,34 @@ func New(ctx context.Context, e env.Environment, options *Options) (*web.API, er
 	if err != nil {
 		return nil, err
 	}
+	brokerController := NewAsyncController(ctx, options, web.ServiceBrokersURL, types.ServiceBrokerType, false, func() types.Object {
+		return &types.ServiceBroker{}
+	}, false)
+	platformController := NewController(ctx, options, web.PlatformsURL, types.PlatformType, func() types.Object {
+		return &types.Platform{}
+	}, true)
+	visibilityController := NewController(ctx, options, web.VisibilitiesURL, types.VisibilityType, func() types.Object {
+		return &types.Visibility{}
+	}, false)
+
 	api := &web.API{
 		// Default controllers - more filters can be registered using the relevant API methods
 		Controllers: []web.Controller{
-			NewAsyncController(ctx, options, web.ServiceBrokersURL, types.ServiceBrokerType, false, func() types.Object {
-				return &types.ServiceBroker{}
-			}, false),
-			NewController(ctx, options, web.PlatformsURL, types.PlatformType, func() types.Object {
-				return &types.Platform{}
-			}, true),
-			NewController(ctx, options, web.VisibilitiesURL, types.VisibilityType, func() types.Object {
-				return &types.Visibility{}
-			}, false),
+			brokerController,
+			platformController,
+			visibilityController,
 			NewTenantController(options.Repository),
 			NewServiceInstanceController(ctx, options),
 			NewServiceBindingController(ctx, options),
-			apiNotifications.NewController(ctx, options.Repository, options.WSSettings, options.Notificator),
+			apiNotifications.NewController(ctx, options.Repository, options.WSSettings, options.Notificator, options.NotificationAcks),
 
 			NewServiceOfferingController(ctx, options),
 			NewServicePlanController(ctx, options),
 			NewOperationsController(ctx, options),
+			NewUpgradeCampaignController(ctx, options),
+			NewSearchController(options),
+			NewEventsController(options),
+			NewWebhookController(ctx, options),
 			NewAgentsController(options.Agents),
 
 			&credentialsController{
@@ -174,6 +210,15 @@ func New(ctx context.Context, e env.Environment, options *Options) (*web.API, er
 		Registry: health.NewDefaultRegistry(),
 	}
 
+	// the controllers serving bulk requests pass their items through the filters of the API
+	for _, bulkController := range []*BaseController{brokerController, platformController, visibilityController} {
+		bulkController.api = api
+	}
+
+	if options.LeaseStore != nil {
+		api.RegisterControllers(jobs.NewController(options.LeaseStore))
+	}
+
 	if rateLimiters != nil {
 		api.RegisterFiltersAfter(
 			filters.LoggingFilterName,
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Peripli/service-manager/operations"
@@ -46,20 +47,36 @@ const pagingLimitOffset = 1
 
 // BaseController provides common CRUD handlers for all object types in the service manager
 type BaseController struct {
-	scheduler *operations.Scheduler
+	scheduler            *operations.Scheduler
+	cancellationNotifier storage.CancellationNotifier
 
 	resourceBaseURL string
 	objectType      types.ObjectType
 	repository      storage.Repository
 	objectBlueprint func() types.Object
+	// columnMapper provides the columns by which the objects can be ordered and grouped
+	columnMapper storage.ColumnMapper
 
 	DefaultPageSize int
 	MaxPageSize     int
+	MaxBulkItems    int
+
+	// lastModifiedSafetyWindow is subtracted from the start of list requests for their Last-Modified header
+	lastModifiedSafetyWindow time.Duration
+
+	// api provides the filters through which the items of bulk requests are passed
+	api *web.API
 
 	supportsAsync  bool
 	isAsyncDefault bool
 
 	supportsCascadeDelete bool
+
+	// supportsScheduling allows operations to be scheduled for a later time with the scheduled_at query parameter
+	supportsScheduling bool
+	// updateDeferral returns the time until which the update of the object with the given request body is deferred,
+	// if any
+	updateDeferral func(ctx context.Context, body []byte, object types.Object) time.Time
 }
 
 // NewController returns a new base controller
@@ -72,14 +89,18 @@ func NewController(ctx context.Context, options *Options, resourceBaseURL string
 		}
 	}
 	controller := &BaseController{
-		repository:            options.Repository,
-		resourceBaseURL:       resourceBaseURL,
-		objectBlueprint:       objectBlueprint,
-		objectType:            objectType,
-		DefaultPageSize:       options.APISettings.DefaultPageSize,
-		MaxPageSize:           options.APISettings.MaxPageSize,
-		scheduler:             operations.NewScheduler(ctx, options.Repository, options.OperationSettings, poolSize, options.WaitGroup),
-		supportsCascadeDelete: supportsCascadeDelete,
+		repository:               options.Repository,
+		columnMapper:             options.ColumnMapper,
+		resourceBaseURL:          resourceBaseURL,
+		objectBlueprint:          objectBlueprint,
+		objectType:               objectType,
+		DefaultPageSize:          options.APISettings.DefaultPageSize,
+		MaxPageSize:              options.APISettings.MaxPageSize,
+		MaxBulkItems:             options.APISettings.MaxBulkItems,
+		lastModifiedSafetyWindow: options.APISettings.LastModifiedSafetyWindow,
+		scheduler:                operations.NewScheduler(ctx, options.Repository, options.OperationSettings, poolSize, options.WaitGroup),
+		cancellationNotifier:     options.CancellationNotifier,
+		supportsCascadeDelete:    supportsCascadeDelete,
 	}
 
 	return controller
@@ -90,6 +111,12 @@ func NewAsyncController(ctx context.Context, options *Options, resourceBaseURL s
 	controller := NewController(ctx, options, resourceBaseURL, objectType, objectBlueprint, supportsCascadeDelete)
 	controller.supportsAsync = true
 	controller.isAsyncDefault = isAsyncDefault
+	if options.JobWorker != nil {
+		options.JobWorker.Register(controller.scheduler, objectType, objectBlueprint)
+	}
+	if options.OperationHooks != nil {
+		options.OperationHooks.Attach(controller.scheduler)
+	}
 
 	return controller
 }
@@ -104,6 +131,20 @@ func (c *BaseController) Routes() []web.Route {
 			},
 			Handler: c.CreateObject,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   c.resourceBaseURL + web.ResourceBulkURL,
+			},
+			Handler: c.BulkObjects,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   c.resourceBaseURL + web.ResourceAggregationsURL,
+			},
+			Handler: c.AggregateObjects,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -118,6 +159,20 @@ func (c *BaseController) Routes() []web.Route {
 			},
 			Handler: c.GetOperation,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.ResourceOperationsURL, web.PathParamID, web.OperationCancelURL),
+			},
+			Handler: c.CancelOperation,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.ResourceHistoryURL),
+			},
+			Handler: c.GetHistory,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -158,28 +213,14 @@ func (c *BaseController) CreateObject(r *web.Request) (*web.Response, error) {
 	ctx := r.Context()
 	log.C(ctx).Debugf("Creating new %s", c.objectType)
 
-	result := c.objectBlueprint()
-	if err := util.BytesToObject(r.Body, result); err != nil {
+	result, err := c.newObject(r.Body)
+	if err != nil {
 		return nil, err
 	}
-
-	if result.GetID() == "" {
-		UUID, err := uuid.NewV4()
-		if err != nil {
-			return nil, fmt.Errorf("could not generate GUID for %s: %s", c.objectType, err)
-		}
-		result.SetID(UUID.String())
-	}
-	currentTime := time.Now().UTC()
 	// override ready provide from the request body
-	result.SetCreatedAt(currentTime)
-	result.SetUpdatedAt(currentTime)
 	result.SetReady(false)
 
-	action := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
-		object, err := repository.Create(ctx, result)
-		return object, util.HandleStorageError(err, c.objectType.String())
-	}
+	item := &storage.BatchItem{Type: types.CREATE, ObjectType: c.objectType, Object: result}
 
 	UUID, err := uuid.NewV4()
 	if err != nil {
@@ -203,7 +244,7 @@ func (c *BaseController) CreateObject(r *web.Request) (*web.Response, error) {
 		Context:       c.prepareOperationContextByRequest(r),
 	}
 
-	createdObj, isAsync, err := c.scheduler.ScheduleStorageAction(ctx, operation, action, c.supportsAsync)
+	createdObj, isAsync, err := c.scheduler.ScheduleStorageItem(ctx, operation, item, c.supportsAsync)
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +258,7 @@ func (c *BaseController) CreateObject(r *web.Request) (*web.Response, error) {
 	}
 
 	cleanObject(ctx, createdObj.GetLastOperation())
-	return util.NewJSONResponse(http.StatusCreated, createdObj)
+	return util.NewJSONResponseWithHeaders(http.StatusCreated, createdObj, etagHeaders(createdObj))
 }
 
 // DeleteObjects handles the deletion of the objects specified in the request
@@ -258,16 +299,31 @@ func (c *BaseController) DeleteSingleObject(r *web.Request) (*web.Response, erro
 	r.Request = r.WithContext(ctx)
 	criteria := query.CriteriaForContext(ctx)
 	opCtx := c.prepareOperationContextByRequest(r)
+	scheduledAt, err := c.scheduledAt(r)
+	if err != nil {
+		return nil, err
+	}
+	if !scheduledAt.IsZero() && opCtx.Cascade {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: "Cascade delete can not be scheduled for a later time",
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
 
-	action := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
-		// At this point, the resource will be already deleted if cascade operation requested.
-		if c.supportsCascadeDelete && opCtx.Cascade {
-			return nil, nil
+	var preconditions []query.Criterion
+	if r.Header.Get(headerIfMatch) != "" {
+		object, err := c.repository.Get(ctx, c.objectType, criteria...)
+		if err != nil {
+			return nil, util.HandleStorageError(err, c.objectType.String())
+		}
+		if preconditions, err = ifMatchCriteria(r, object); err != nil {
+			return nil, err
 		}
-		err := repository.Delete(ctx, c.objectType, criteria...)
-		return nil, util.HandleStorageError(err, c.objectType.String())
 	}
 
+	item := &storage.BatchItem{Type: types.DELETE, ObjectType: c.objectType, Criteria: criteria, Preconditions: preconditions}
+
 	UUID, err := uuid.NewV4()
 	if err != nil {
 		return nil, fmt.Errorf("could not generate GUID for %s: %s", c.objectType, err)
@@ -330,13 +386,23 @@ func (c *BaseController) DeleteSingleObject(r *web.Request) (*web.Response, erro
 		CascadeRootID: cascadeRootId,
 	}
 	if c.supportsCascadeDelete && opCtx.Cascade {
+		// At this point, the resource will be already deleted if cascade operation requested.
+		action := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
+			return nil, nil
+		}
 		_, err = c.scheduler.ScheduleSyncStorageAction(ctx, operation, action)
 		if err != nil {
 			return nil, err
 		}
 		return util.NewLocationResponse(operation.GetID(), operation.ResourceID, c.resourceBaseURL)
 	}
-	_, isAsync, err := c.scheduler.ScheduleStorageAction(ctx, operation, action, c.supportsAsync)
+	if !scheduledAt.IsZero() {
+		if err := c.scheduler.ScheduleStorageItemAt(ctx, operation, item, scheduledAt); err != nil {
+			return nil, err
+		}
+		return util.NewLocationResponse(operation.GetID(), operation.ResourceID, c.resourceBaseURL)
+	}
+	_, isAsync, err := c.scheduler.ScheduleStorageItem(ctx, operation, item, c.supportsAsync)
 	if err != nil {
 		return nil, err
 	}
@@ -354,21 +420,43 @@ func (c *BaseController) GetSingleObject(r *web.Request) (*web.Response, error)
 	ctx := r.Context()
 	log.C(ctx).Debugf("Getting %s with id %s", c.objectType, objectID)
 
+	fields, err := parseFields(r.URL.Query().Get(web.QueryParamFields), c.objectBlueprint())
+	if err != nil {
+		return nil, err
+	}
+
 	byID := query.ByField(query.EqualsOperator, "id", objectID)
-	criteria := query.CriteriaForContext(ctx)
-	object, err := c.repository.Get(ctx, c.objectType, append(criteria, byID)...)
+	criteria := append(query.CriteriaForContext(ctx), byID)
+	if len(fields) != 0 {
+		criteria = append(criteria, query.SelectResultFields(fields...))
+	}
+	object, err := c.repository.Get(ctx, c.objectType, criteria...)
 	if err != nil {
 		return nil, util.HandleStorageError(err, c.objectType.String())
 	}
 
 	cleanObject(ctx, object)
 
-	if err := attachLastOperation(ctx, objectID, object, c.repository); err != nil {
-		return nil, err
+	if fieldsContain(fields, lastOperationField) {
+		if err := attachLastOperation(ctx, objectID, object, c.repository); err != nil {
+			return nil, err
+		}
+		cleanObject(ctx, object.GetLastOperation())
 	}
 
-	cleanObject(ctx, object.GetLastOperation())
-	return util.NewJSONResponse(http.StatusOK, object)
+	headers := etagHeaders(object, fields...)
+	if headers != nil && notModified(r, headers[headerETag], lastModified(object)) {
+		return notModifiedResponse(headers), nil
+	}
+
+	if len(fields) != 0 {
+		projectedObject, err := projectObject(object, fields)
+		if err != nil {
+			return nil, err
+		}
+		return util.NewJSONResponseWithHeaders(http.StatusOK, projectedObject, headers)
+	}
+	return util.NewJSONResponseWithHeaders(http.StatusOK, object, headers)
 }
 
 // GetOperation handles the fetching of a single operation with the id specified for the specified resource
@@ -400,11 +488,131 @@ func GetResourceOperation(r *web.Request, repository storage.Repository, objectT
 	return util.NewJSONResponse(http.StatusOK, operation)
 }
 
+// GetHistory handles the fetching of a page of the operation events of the resource with the id specified in the
+// order in which they were recorded. The history is available after the resource is deleted until the events are cleaned up.
+func (c *BaseController) GetHistory(r *web.Request) (*web.Response, error) {
+	objectID := r.PathParams[web.PathParamResourceID]
+
+	ctx := r.Context()
+	log.C(ctx).Debugf("Getting history of object of type %s with id %s", c.objectType, objectID)
+
+	byObjectID := query.ByField(query.EqualsOperator, "resource_id", objectID)
+	byObjectType := query.ByField(query.EqualsOperator, "resource_type", c.objectType.String())
+	var err error
+	ctx, err = query.AddCriteria(ctx, byObjectID, byObjectType)
+	if err != nil {
+		return nil, err
+	}
+	criteria := query.CriteriaForContext(ctx)
+
+	count, err := c.repository.Count(ctx, types.OperationEventType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.OperationEventType.String())
+	}
+
+	limit, err := c.parseMaxItemsQuery(r.URL.Query().Get("max_items"))
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		page := struct {
+			ItemsCount int `json:"num_items"`
+		}{
+			ItemsCount: count,
+		}
+		return util.NewJSONResponse(http.StatusOK, page)
+	}
+
+	pagingSequence, err := c.parsePageToken(ctx, r.URL.Query().Get("token"))
+	if err != nil {
+		return nil, err
+	}
+	criteria = append(criteria,
+		query.OrderResultBy("paging_sequence", query.AscOrder),
+		query.ByField(query.GreaterThanOperator, "paging_sequence", pagingSequence),
+		query.LimitResultBy(limit+pagingLimitOffset))
+	events, err := c.repository.List(ctx, types.OperationEventType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.OperationEventType.String())
+	}
+
+	page, err := pageFromObjectList(ctx, events, count, limit, generateTokenForItem)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewJSONResponse(http.StatusOK, page)
+}
+
+// CancelOperation handles the cancellation of a single operation with the id specified for the specified resource
+func (c *BaseController) CancelOperation(r *web.Request) (*web.Response, error) {
+	return CancelResourceOperation(r, c.repository, c.scheduler, c.cancellationNotifier, c.objectType)
+}
+
+// CancelResourceOperation cancels the in progress operation with the id specified for the specified resource and
+// notifies the post hooks of the scheduler of the cancellation
+func CancelResourceOperation(r *web.Request, repository storage.Repository, scheduler *operations.Scheduler, notifier storage.CancellationNotifier, objectType types.ObjectType) (*web.Response, error) {
+	objectID := r.PathParams[web.PathParamResourceID]
+	operationID := r.PathParams[web.PathParamID]
+
+	ctx := r.Context()
+	log.C(ctx).Debugf("Canceling operation with id %s for object of type %s with id %s", operationID, objectType, objectID)
+
+	byOperationID := query.ByField(query.EqualsOperator, "id", operationID)
+	byObjectID := query.ByField(query.EqualsOperator, "resource_id", objectID)
+	var err error
+	ctx, err = query.AddCriteria(ctx, byObjectID, byOperationID)
+	if err != nil {
+		return nil, err
+	}
+	return cancelOperation(ctx, repository, scheduler, notifier, query.CriteriaForContext(ctx)...)
+}
+
+func cancelOperation(ctx context.Context, repository storage.Repository, scheduler *operations.Scheduler, notifier storage.CancellationNotifier, criteria ...query.Criterion) (*web.Response, error) {
+	operation, err := repository.Get(ctx, types.OperationType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.OperationType.String())
+	}
+
+	canceledOperation, err := scheduler.CancelOperation(ctx, notifier, operation.(*types.Operation))
+	if err != nil {
+		return nil, err
+	}
+	cleanObject(ctx, canceledOperation)
+	return util.NewJSONResponse(http.StatusOK, canceledOperation)
+}
+
 // ListObjects handles the fetching of all objects
 func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 	ctx := r.Context()
+	// updated_at is set when the row is written, which may be long before the transaction is committed, so a change
+	// committed after the start of the request may carry an earlier time. Last-Modified is therefore set to the start
+	// of the request minus a safety window, and changes of transactions which took less than the window are reported
+	// by the next request with this time in If-Modified-Since. The next responses may repeat some of the entities.
+	lastModified := time.Now().UTC().Add(-c.lastModifiedSafetyWindow)
 
 	criteria := query.CriteriaForContext(ctx)
+	if modifiedSince, ok := ifModifiedSince(r); ok {
+		modified, err := c.modifiedSince(ctx, criteria, modifiedSince)
+		if err != nil {
+			return nil, err
+		}
+		if !modified {
+			return notModifiedResponse(map[string]string{headerLastModified: r.Header.Get(headerIfModifiedSince)}), nil
+		}
+	}
+
+	updatedSince, err := parseUpdatedSince(r.URL.Query().Get(web.QueryParamUpdatedSince))
+	if err != nil {
+		return nil, err
+	}
+	deletedCriteria := criteria
+	if !updatedSince.IsZero() {
+		// as with Last-Modified, changes committed after the given time may carry an earlier time,
+		// so the changes made in the safety window before it are listed as well
+		updatedSince = updatedSince.Add(-c.lastModifiedSafetyWindow)
+		criteria = append(criteria, query.ByField(query.GreaterThanOperator, "updated_at", updatedSince.Format(time.RFC3339Nano)))
+	}
+
 	count, err := c.repository.Count(ctx, c.objectType, criteria...)
 	if err != nil {
 		return nil, util.HandleStorageError(err, c.objectType.String())
@@ -426,15 +634,23 @@ func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 		return util.NewJSONResponse(http.StatusOK, page)
 	}
 
+	fields, err := parseFields(r.URL.Query().Get(web.QueryParamFields), c.objectBlueprint())
+	if err != nil {
+		return nil, err
+	}
+
 	rawToken := r.URL.Query().Get("token")
-	pagingSequence, err := c.parsePageToken(ctx, rawToken)
+	orderBy := r.URL.Query().Get(web.QueryParamOrderBy)
+	pagingCriteria, tokenForItem, err := c.pagingCriteria(ctx, orderBy, rawToken)
 	if err != nil {
 		return nil, err
 	}
 
-	criteria = append(criteria, query.LimitResultBy(limit+pagingLimitOffset),
-		query.OrderResultBy("paging_sequence", query.AscOrder),
-		query.ByField(query.GreaterThanOperator, "paging_sequence", pagingSequence))
+	criteria = append(criteria, query.LimitResultBy(limit+pagingLimitOffset))
+	criteria = append(criteria, pagingCriteria...)
+	if len(fields) != 0 {
+		criteria = append(criteria, query.SelectResultFields(append(fields, labelOrderFields(pagingCriteria)...)...))
+	}
 
 	log.C(ctx).Debugf("Getting a page of %ss", c.objectType)
 	objectList, err := c.repository.List(ctx, c.objectType, criteria...)
@@ -443,17 +659,40 @@ func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 	}
 
 	attachLastOps := r.URL.Query().Get("attach_last_operations")
-	if attachLastOps == "true" {
+	if attachLastOps == "true" && fieldsContain(fields, lastOperationField) {
 		if err := attachLastOperations(ctx, objectList, c.repository); err != nil {
 			return nil, err
 		}
 	}
 
-	page := pageFromObjectList(ctx, objectList, count, limit)
-	resp, err := util.NewJSONResponse(http.StatusOK, page)
+	page, err := pageFromObjectList(ctx, objectList, count, limit, tokenForItem)
 	if err != nil {
 		return nil, err
 	}
+	if !updatedSince.IsZero() && rawToken == "" {
+		// the deletions are reported only with the first page
+		if page.Deleted, err = c.repository.ListDeleted(ctx, c.objectType, updatedSince, deletedCriteria...); err != nil {
+			return nil, util.HandleStorageError(err, c.objectType.String())
+		}
+	}
+	var resp *web.Response
+	if len(fields) != 0 {
+		projected, err := projectPage(page, fields)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = util.NewJSONResponse(http.StatusOK, projected)
+	} else {
+		resp, err = util.NewJSONResponse(http.StatusOK, page)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Set(headerLastModified, lastModified.Format(http.TimeFormat))
+	resp.Header.Set(headerETag, weakETag(resp.Body))
+	if notModified(r, resp.Header.Get(headerETag), time.Time{}) {
+		return notModifiedResponse(map[string]string{headerETag: resp.Header.Get(headerETag)}), nil
+	}
 
 	if page.Token != "" {
 		nextPageUrl := r.URL
@@ -466,6 +705,46 @@ func (c *BaseController) ListObjects(r *web.Request) (*web.Response, error) {
 	return resp, nil
 }
 
+// AggregateObjects handles the counting of the objects grouped by the field or label specified in the request
+func (c *BaseController) AggregateObjects(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+
+	groupBy := strings.TrimSpace(r.URL.Query().Get(web.QueryParamGroupBy))
+	if groupBy == "" {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s query parameter is required", web.QueryParamGroupBy),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	groupableColumns, err := c.orderableColumnValues(c.objectBlueprint())
+	if err != nil {
+		return nil, err
+	}
+	isLabel := strings.HasPrefix(groupBy, query.LabelFieldPrefix) && len(groupBy) > len(query.LabelFieldPrefix)
+	if _, ok := groupableColumns[groupBy]; !ok && !isLabel {
+		return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported group by field: %s", groupBy)}
+	}
+
+	criteria := query.CriteriaForContext(ctx)
+	count, err := c.repository.Count(ctx, c.objectType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, c.objectType.String())
+	}
+
+	log.C(ctx).Debugf("Counting %ss grouped by %s", c.objectType, groupBy)
+	groups, err := c.repository.CountGroupedBy(ctx, c.objectType, groupBy, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, c.objectType.String())
+	}
+
+	return util.NewJSONResponse(http.StatusOK, &types.Aggregation{
+		GroupBy:    groupBy,
+		ItemsCount: count,
+		Groups:     groups,
+	})
+}
+
 // PatchObject handles the update of the object with the id specified in the request
 func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 	if err := util.ValidateJSONContentType(r.Header.Get("Content-Type")); err != nil {
@@ -476,7 +755,7 @@ func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 	ctx := r.Context()
 	log.C(ctx).Debugf("Updating %s with id %s", c.objectType, objectID)
 
-	labelChanges, err := query.LabelChangesFromJSON(r.Body)
+	scheduledAt, err := c.scheduledAt(r)
 	if err != nil {
 		return nil, err
 	}
@@ -487,32 +766,31 @@ func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 		return nil, err
 	}
 	criteria := query.CriteriaForContext(ctx)
-	objFromDB, err := c.repository.Get(ctx, c.objectType, criteria...)
+	objFromDB, labelChanges, err := c.patchedObject(ctx, c.repository, objectID, r.Body, criteria)
 	if err != nil {
-		return nil, util.HandleStorageError(err, c.objectType.String())
-	}
-
-	if r.Body, err = sjson.DeleteBytes(r.Body, "labels"); err != nil {
 		return nil, err
 	}
-	createdAt := objFromDB.GetCreatedAt()
-	updatedAt := objFromDB.GetUpdatedAt()
-
-	if err := util.BytesToObject(r.Body, objFromDB); err != nil {
+	preconditions, err := ifMatchCriteria(r, objFromDB)
+	if err != nil {
 		return nil, err
 	}
+	if scheduledAt.IsZero() && c.updateDeferral != nil {
+		scheduledAt = c.updateDeferral(ctx, r.Body, objFromDB)
+	}
+	if !scheduledAt.IsZero() && len(preconditions) == 0 {
+		// the update must not overwrite changes of the object made until it is executed
+		preconditions = []query.Criterion{
+			query.ByField(query.EqualsOperator, "updated_at", objFromDB.GetUpdatedAt().UTC().Format(time.RFC3339Nano)),
+		}
+	}
 
-	objFromDB.SetID(objectID)
-	objFromDB.SetCreatedAt(createdAt)
-	objFromDB.SetUpdatedAt(updatedAt)
-	objFromDB.SetReady(true)
-
-	labels, _, _ := query.ApplyLabelChangesToLabels(labelChanges, objFromDB.GetLabels())
-	objFromDB.SetLabels(labels)
-
-	action := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
-		object, err := repository.Update(ctx, objFromDB, labelChanges, criteria...)
-		return object, util.HandleStorageError(err, c.objectType.String())
+	item := &storage.BatchItem{
+		Type:          types.UPDATE,
+		ObjectType:    c.objectType,
+		Object:        objFromDB,
+		LabelChanges:  labelChanges,
+		Criteria:      criteria,
+		Preconditions: preconditions,
 	}
 
 	UUID, err := uuid.NewV4()
@@ -536,7 +814,14 @@ func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 		Context:       c.prepareOperationContextByRequest(r),
 	}
 
-	object, isAsync, err := c.scheduler.ScheduleStorageAction(ctx, operation, action, c.supportsAsync)
+	if !scheduledAt.IsZero() {
+		if err := c.scheduler.ScheduleStorageItemAt(ctx, operation, item, scheduledAt); err != nil {
+			return nil, err
+		}
+		return util.NewLocationResponse(operation.GetID(), operation.ResourceID, c.resourceBaseURL)
+	}
+
+	object, isAsync, err := c.scheduler.ScheduleStorageItem(ctx, operation, item, c.supportsAsync)
 	if err != nil {
 		return nil, err
 	}
@@ -551,7 +836,61 @@ func (c *BaseController) PatchObject(r *web.Request) (*web.Response, error) {
 
 	cleanObject(ctx, object.GetLastOperation())
 	cleanObject(ctx, object)
-	return util.NewJSONResponse(http.StatusOK, object)
+	return util.NewJSONResponseWithHeaders(http.StatusOK, object, etagHeaders(object))
+}
+
+// newObject creates an object of the controller type from the given request body. The object gets a generated id
+// if none is provided.
+func (c *BaseController) newObject(body []byte) (types.Object, error) {
+	result := c.objectBlueprint()
+	if err := util.BytesToObject(body, result); err != nil {
+		return nil, err
+	}
+
+	if result.GetID() == "" {
+		UUID, err := uuid.NewV4()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate GUID for %s: %s", c.objectType, err)
+		}
+		result.SetID(UUID.String())
+	}
+	currentTime := time.Now().UTC()
+	result.SetCreatedAt(currentTime)
+	result.SetUpdatedAt(currentTime)
+	return result, nil
+}
+
+// patchedObject fetches the object matching the criteria and applies the changes from the given request body on it.
+// It returns the patched object and the label changes which have to be stored along with it.
+func (c *BaseController) patchedObject(ctx context.Context, repository storage.Repository, objectID string, body []byte, criteria []query.Criterion) (types.Object, types.LabelChanges, error) {
+	labelChanges, err := query.LabelChangesFromJSON(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objFromDB, err := repository.Get(ctx, c.objectType, criteria...)
+	if err != nil {
+		return nil, nil, util.HandleStorageError(err, c.objectType.String())
+	}
+
+	if body, err = sjson.DeleteBytes(body, "labels"); err != nil {
+		return nil, nil, err
+	}
+	createdAt := objFromDB.GetCreatedAt()
+	updatedAt := objFromDB.GetUpdatedAt()
+
+	if err := util.BytesToObject(body, objFromDB); err != nil {
+		return nil, nil, err
+	}
+
+	objFromDB.SetID(objectID)
+	objFromDB.SetCreatedAt(createdAt)
+	objFromDB.SetUpdatedAt(updatedAt)
+	objFromDB.SetReady(true)
+
+	labels, _, _ := query.ApplyLabelChangesToLabels(labelChanges, objFromDB.GetLabels())
+	objFromDB.SetLabels(labels)
+	return objFromDB, labelChanges, nil
 }
 
 func cleanObject(ctx context.Context, object types.Object) {
@@ -631,7 +970,11 @@ func attachLastOperation(ctx context.Context, objectID string, object types.Obje
 }
 
 func (c *BaseController) parseMaxItemsQuery(maxItems string) (int, error) {
-	limit := c.DefaultPageSize
+	return parseMaxItems(maxItems, c.DefaultPageSize, c.MaxPageSize)
+}
+
+func parseMaxItems(maxItems string, defaultPageSize, maxPageSize int) (int, error) {
+	limit := defaultPageSize
 	var err error
 	if maxItems != "" {
 		limit, err = strconv.Atoi(maxItems)
@@ -649,13 +992,45 @@ func (c *BaseController) parseMaxItemsQuery(maxItems string) (int, error) {
 				StatusCode:  http.StatusBadRequest,
 			}
 		}
-		if limit > c.MaxPageSize {
-			limit = c.MaxPageSize
+		if limit > maxPageSize {
+			limit = maxPageSize
 		}
 	}
 	return limit, nil
 }
 
+func parseUpdatedSince(updatedSince string) (time.Time, error) {
+	if updatedSince == "" {
+		return time.Time{}, nil
+	}
+	since, err := time.Parse(time.RFC3339Nano, updatedSince)
+	if err != nil {
+		return time.Time{}, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s should be a time in RFC3339 format: %v", web.QueryParamUpdatedSince, err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return since.UTC(), nil
+}
+
+// modifiedSince checks whether any of the objects matching the criteria has been updated, created or deleted after the given time
+func (c *BaseController) modifiedSince(ctx context.Context, criteria []query.Criterion, since time.Time) (bool, error) {
+	byUpdatedAt := query.ByField(query.GreaterThanOperator, "updated_at", since.UTC().Format(time.RFC3339Nano))
+	updatedCount, err := c.repository.Count(ctx, c.objectType, append(criteria, byUpdatedAt)...)
+	if err != nil {
+		return false, util.HandleStorageError(err, c.objectType.String())
+	}
+	if updatedCount != 0 {
+		return true, nil
+	}
+	deletions, err := c.repository.ListDeleted(ctx, c.objectType, since, criteria...)
+	if err != nil {
+		return false, util.HandleStorageError(err, c.objectType.String())
+	}
+	return len(deletions) != 0, nil
+}
+
 func (c *BaseController) parsePageToken(ctx context.Context, token string) (string, error) {
 	targetPageSequence := "0"
 	if token != "" {
@@ -718,12 +1093,43 @@ func (c *BaseController) prepareOperationContextByRequest(r *web.Request) *types
 	return operationContext
 }
 
-func generateTokenForItem(obj types.Object) string {
+// scheduledAt returns the time for which the operation of the request is scheduled, if any
+func (c *BaseController) scheduledAt(r *web.Request) (time.Time, error) {
+	value := r.URL.Query().Get(web.QueryParamScheduledAt)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if !c.supportsScheduling {
+		return time.Time{}, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("Scheduling operations for a later time is not supported for %s", c.objectType),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	scheduledAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("Invalid %s query parameter: %s must be an RFC3339 timestamp", web.QueryParamScheduledAt, value),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	if !scheduledAt.After(time.Now()) {
+		return time.Time{}, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("Invalid %s query parameter: %s is not in the future", web.QueryParamScheduledAt, value),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return scheduledAt, nil
+}
+
+func generateTokenForItem(obj types.Object) (string, error) {
 	nextPageToken := obj.GetPagingSequence()
-	return base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(nextPageToken, 10)))
+	return base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(nextPageToken, 10))), nil
 }
 
-func pageFromObjectList(ctx context.Context, objectList types.ObjectList, count, limit int) *types.ObjectPage {
+func pageFromObjectList(ctx context.Context, objectList types.ObjectList, count, limit int, tokenForItem func(types.Object) (string, error)) (*types.ObjectPage, error) {
 	page := &types.ObjectPage{
 		ItemsCount: count,
 		Items:      make([]types.Object, 0, objectList.Len()),
@@ -737,7 +1143,11 @@ func pageFromObjectList(ctx context.Context, objectList types.ObjectList, count,
 
 	if len(page.Items) > limit {
 		page.Items = page.Items[:len(page.Items)-1]
-		page.Token = generateTokenForItem(page.Items[len(page.Items)-1])
+		token, err := tokenForItem(page.Items[len(page.Items)-1])
+		if err != nil {
+			return nil, err
+		}
+		page.Token = token
 	}
-	return page
+	return page, nil
 }
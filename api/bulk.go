@@ -0,0 +1,346 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	bulkOpCreate = "create"
+	bulkOpPatch  = "patch"
+	bulkOpDelete = "delete"
+)
+
+// bulkRequest is the body of a bulk request
+type bulkRequest struct {
+	// Atomic specifies whether all items are executed in a single transaction
+	Atomic bool       `json:"atomic"`
+	Items  []bulkItem `json:"items"`
+}
+
+// bulkItem is a single create, patch or delete in a bulk request. The resource holds the same body as the
+// corresponding single resource request and if_match holds the value of its If-Match header.
+type bulkItem struct {
+	Op       string          `json:"op"`
+	ID       string          `json:"id,omitempty"`
+	IfMatch  string          `json:"if_match,omitempty"`
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// bulkItemResult is the outcome of a single item of a bulk request
+type bulkItemResult struct {
+	Op         string          `json:"op"`
+	ID         string          `json:"id,omitempty"`
+	StatusCode int             `json:"status"`
+	Resource   types.Object    `json:"resource,omitempty"`
+	Error      *util.HTTPError `json:"error,omitempty"`
+}
+
+type bulkResponse struct {
+	Items []*bulkItemResult `json:"items"`
+}
+
+// BulkObjects handles the creation, update and deletion of multiple objects in a single request. Each item passes
+// through the filters of the corresponding single object endpoint before it is executed. In atomic mode all items are
+// executed in a single transaction and the first failure is returned. Otherwise each item is executed on its own and
+// the result of every item is returned.
+func (c *BaseController) BulkObjects(r *web.Request) (*web.Response, error) {
+	if err := util.ValidateJSONContentType(r.Header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
+
+	ctx := r.Context()
+	if r.URL.Query().Get(web.QueryParamAsync) == "true" {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: "Bulk requests cannot be executed asynchronously",
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	request := &bulkRequest{}
+	if err := util.BytesToObject(r.Body, request); err != nil {
+		return nil, err
+	}
+	if len(request.Items) == 0 || len(request.Items) > c.MaxBulkItems {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("bulk request should contain between 1 and %d items", c.MaxBulkItems),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	log.C(ctx).Debugf("Executing bulk request with %d %s items (atomic: %t)", len(request.Items), c.objectType, request.Atomic)
+
+	results := make([]*bulkItemResult, len(request.Items))
+	batchItems := make([]*storage.BatchItem, len(request.Items))
+	for i, item := range request.Items {
+		results[i] = &bulkItemResult{Op: item.Op, ID: item.ID}
+		batchItems[i] = c.bulkBatchItem(r, item)
+	}
+
+	if request.Atomic {
+		if err := storage.ExecuteBatch(ctx, c.repository, batchItems...); err != nil {
+			if batchErr, ok := err.(*storage.BatchError); ok {
+				return nil, bulkItemError(ctx, batchErr.Index, util.HandleStorageError(batchErr.Err, c.objectType.String()))
+			}
+			return nil, util.HandleStorageError(err, c.objectType.String())
+		}
+	}
+
+	for i, batchItem := range batchItems {
+		if !request.Atomic {
+			if err := batchItem.Prepare(ctx, c.repository); err != nil {
+				setBulkItemError(ctx, results[i], err)
+				continue
+			}
+			if err := batchItem.Execute(ctx, c.repository); err != nil {
+				setBulkItemError(ctx, results[i], util.HandleStorageError(err, c.objectType.String()))
+				continue
+			}
+		}
+		if batchItem.Type == types.CREATE {
+			if err := c.markBulkItemCreated(ctx, batchItem); err != nil {
+				setBulkItemError(ctx, results[i], util.HandleStorageError(err, c.objectType.String()))
+				continue
+			}
+		}
+		setBulkItemResult(ctx, results[i], batchItem)
+	}
+
+	return util.NewJSONResponse(http.StatusOK, &bulkResponse{Items: results})
+}
+
+// bulkBatchItem returns the storage batch item of the bulk item. The item is prepared right before it is executed, so
+// that it can refer to the items executed before it in the same batch.
+func (c *BaseController) bulkBatchItem(r *web.Request, item bulkItem) *storage.BatchItem {
+	batchItem := &storage.BatchItem{}
+	batchItem.Prepare = func(ctx context.Context, repository storage.Repository) error {
+		prepared, err := c.prepareBulkItem(r, item, repository)
+		if err != nil {
+			return err
+		}
+		*batchItem = *prepared
+		return nil
+	}
+	return batchItem
+}
+
+// markBulkItemCreated marks the object created by the item and the resources created along with it as ready, the same
+// way a create operation does once the object has been stored
+func (c *BaseController) markBulkItemCreated(ctx context.Context, batchItem *storage.BatchItem) error {
+	transactionalRepository, ok := c.repository.(storage.TransactionalRepository)
+	if !ok {
+		return fmt.Errorf("bulk created %s can only be marked as ready by transactional repositories", c.objectType)
+	}
+	return transactionalRepository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
+		object, err := operations.MarkCreated(ctx, storage, batchItem.Object, batchItem.Operation.TransitiveResources)
+		if err != nil {
+			return err
+		}
+		batchItem.Object = object
+		return nil
+	})
+}
+
+// prepareBulkItem passes the item through the filters of the corresponding single object endpoint and converts the
+// filtered request to a storage batch item. The objects to patch and delete are read through the given repository.
+func (c *BaseController) prepareBulkItem(r *web.Request, item bulkItem, repository storage.Repository) (*storage.BatchItem, error) {
+	endpoint := web.Endpoint{Path: fmt.Sprintf("%s/{%s}", c.resourceBaseURL, web.PathParamResourceID)}
+	var handler web.HandlerFunc
+	var batchItem *storage.BatchItem
+	switch item.Op {
+	case bulkOpCreate:
+		endpoint = web.Endpoint{Method: http.MethodPost, Path: c.resourceBaseURL}
+		handler = func(req *web.Request) (*web.Response, error) {
+			object, err := c.newObject(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			// override ready provided from the request body, the object is marked as ready once it is created
+			object.SetReady(false)
+			// the operation is not stored, it only records the resources created along with the object
+			UUID, err := uuid.NewV4()
+			if err != nil {
+				return nil, fmt.Errorf("could not generate GUID for %s: %s", c.objectType, err)
+			}
+			operation := &types.Operation{
+				Base: types.Base{
+					ID:        UUID.String(),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+					Labels:    make(map[string][]string),
+					Ready:     true,
+				},
+				Type:          types.CREATE,
+				State:         types.IN_PROGRESS,
+				ResourceID:    object.GetID(),
+				ResourceType:  c.objectType,
+				PlatformID:    types.SMPlatform,
+				CorrelationID: log.CorrelationIDFromContext(req.Context()),
+			}
+			batchItem = &storage.BatchItem{Type: types.CREATE, ObjectType: c.objectType, Object: object, Operation: operation}
+			return &web.Response{StatusCode: http.StatusCreated}, nil
+		}
+	case bulkOpPatch:
+		endpoint.Method = http.MethodPatch
+		handler = func(req *web.Request) (*web.Response, error) {
+			criteria := append(query.CriteriaForContext(req.Context()), query.ByField(query.EqualsOperator, "id", item.ID))
+			object, labelChanges, err := c.patchedObject(req.Context(), repository, item.ID, req.Body, criteria)
+			if err != nil {
+				return nil, err
+			}
+			preconditions, err := ifMatchCriteria(req, object)
+			if err != nil {
+				return nil, err
+			}
+			batchItem = &storage.BatchItem{Type: types.UPDATE, ObjectType: c.objectType, Object: object, LabelChanges: labelChanges, Criteria: criteria, Preconditions: preconditions}
+			return &web.Response{StatusCode: http.StatusOK}, nil
+		}
+	case bulkOpDelete:
+		endpoint.Method = http.MethodDelete
+		handler = func(req *web.Request) (*web.Response, error) {
+			criteria := append(query.CriteriaForContext(req.Context()), query.ByField(query.EqualsOperator, "id", item.ID))
+			var preconditions []query.Criterion
+			if item.IfMatch != "" {
+				object, err := repository.Get(req.Context(), c.objectType, criteria...)
+				if err != nil {
+					return nil, util.HandleStorageError(err, c.objectType.String())
+				}
+				if preconditions, err = ifMatchCriteria(req, object); err != nil {
+					return nil, err
+				}
+			}
+			batchItem = &storage.BatchItem{Type: types.DELETE, ObjectType: c.objectType, Criteria: criteria, Preconditions: preconditions}
+			return &web.Response{StatusCode: http.StatusOK}, nil
+		}
+	default:
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("unsupported bulk operation %q: expected one of %s, %s or %s", item.Op, bulkOpCreate, bulkOpPatch, bulkOpDelete),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	if item.Op != bulkOpCreate && item.ID == "" {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s bulk operation requires id", item.Op),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	itemRequest, err := c.bulkItemRequest(r, endpoint, item)
+	if err != nil {
+		return nil, err
+	}
+	var filters web.Filters
+	if c.api != nil {
+		filters = web.Filters(c.api.Filters).Matching(endpoint)
+	}
+	resp, err := filters.Chain(handler).Handle(itemRequest)
+	if err != nil {
+		return nil, err
+	}
+	if batchItem == nil {
+		// one of the filters has handled the item without passing it to the controller
+		statusCode := http.StatusBadRequest
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s bulk operation was rejected with status %d", item.Op, statusCode),
+			StatusCode:  statusCode,
+		}
+	}
+	return batchItem, nil
+}
+
+// bulkItemRequest builds the request which the single object endpoint would receive for the bulk item
+func (c *BaseController) bulkItemRequest(r *web.Request, endpoint web.Endpoint, item bulkItem) (*web.Request, error) {
+	// the criteria added by the filters of the bulk request are dropped as the filters are applied again for the item
+	ctx, err := query.ContextWithCriteria(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	httpRequest := r.Request.Clone(ctx)
+	httpRequest.Method = endpoint.Method
+	httpRequest.URL.RawQuery = ""
+	httpRequest.URL.Path = c.resourceBaseURL
+	httpRequest.Header.Del(headerIfMatch)
+	if item.IfMatch != "" {
+		httpRequest.Header.Set(headerIfMatch, item.IfMatch)
+	}
+	pathParams := map[string]string{}
+	if item.Op != bulkOpCreate {
+		httpRequest.URL.Path = fmt.Sprintf("%s/%s", c.resourceBaseURL, item.ID)
+		pathParams[web.PathParamResourceID] = item.ID
+	}
+	body := []byte(item.Resource)
+	if len(body) == 0 {
+		body = []byte("{}")
+	}
+
+	return &web.Request{
+		Request:    httpRequest,
+		PathParams: pathParams,
+		Body:       body,
+	}, nil
+}
+
+func setBulkItemError(ctx context.Context, result *bulkItemResult, err error) {
+	httpErr := util.ToHTTPError(ctx, err)
+	result.StatusCode = httpErr.StatusCode
+	result.Error = httpErr
+}
+
+func setBulkItemResult(ctx context.Context, result *bulkItemResult, batchItem *storage.BatchItem) {
+	result.StatusCode = http.StatusOK
+	switch batchItem.Type {
+	case types.CREATE:
+		result.StatusCode = http.StatusCreated
+		result.ID = batchItem.Object.GetID()
+		result.Resource = batchItem.Object
+	case types.UPDATE:
+		result.Resource = batchItem.Object
+	}
+	if result.Resource != nil {
+		cleanObject(ctx, result.Resource)
+	}
+}
+
+func bulkItemError(ctx context.Context, index int, err error) error {
+	httpErr := util.ToHTTPError(ctx, err)
+	return &util.HTTPError{
+		ErrorType:   httpErr.ErrorType,
+		Description: fmt.Sprintf("bulk item %d failed: %s", index, httpErr.Description),
+		StatusCode:  httpErr.StatusCode,
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const (
+	headerETag            = "ETag"
+	headerIfMatch         = "If-Match"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+	headerLastModified    = "Last-Modified"
+)
+
+// etagDigestSeparator separates the updated_at of the object from the digest of the rest of its representation in its
+// entity tag
+const etagDigestSeparator = ";"
+
+// etag returns the entity tag of the object. The tag is the quoted updated_at of the object so that it can also be
+// derived from the updated_at of list items. The last operation of the object and the projection to some of its fields
+// are not reflected in the updated_at, so the tags of such representations also contain a digest of them.
+func etag(object types.Object, fields ...string) string {
+	version := object.GetUpdatedAt().UTC().Format(time.RFC3339Nano)
+	representation := make([]string, 0)
+	if lastOperation := object.GetLastOperation(); lastOperation != nil {
+		representation = append(representation, lastOperation.ID, string(lastOperation.State), lastOperation.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	}
+	if len(fields) != 0 {
+		representation = append(representation, "fields="+strings.Join(normalizeFields(fields), ","))
+	}
+	if len(representation) == 0 {
+		return fmt.Sprintf("%q", version)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(representation, "\n")))
+	return fmt.Sprintf("%q", version+etagDigestSeparator+hex.EncodeToString(sum[:8]))
+}
+
+// etagVersion returns the updated_at of the object from which the given strong entity tag was built
+func etagVersion(tag string) string {
+	version := strings.Trim(tag, `"`)
+	if i := strings.Index(version, etagDigestSeparator); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+// weakETag returns a weak entity tag of the given response body
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf("W/%q", hex.EncodeToString(sum[:16]))
+}
+
+// lastModified returns the time of the last modification of the object or of its last operation
+func lastModified(object types.Object) time.Time {
+	modified := object.GetUpdatedAt()
+	if lastOperation := object.GetLastOperation(); lastOperation != nil && lastOperation.UpdatedAt.After(modified) {
+		modified = lastOperation.UpdatedAt
+	}
+	return modified
+}
+
+// etagHeaders returns the ETag and Last-Modified headers of the given representation of the object if the updated_at
+// of the object is known. The fields are the projection of the representation, if any.
+func etagHeaders(object types.Object, fields ...string) map[string]string {
+	if object == nil || object.GetUpdatedAt().IsZero() {
+		return nil
+	}
+	return map[string]string{
+		headerETag:         etag(object, fields...),
+		headerLastModified: lastModified(object).UTC().Format(http.TimeFormat),
+	}
+}
+
+// etagMatches checks whether any of the comma separated tags of the header matches the given tag. Weak tags match
+// only if weak comparison is requested.
+func etagMatches(header, tag string, weak bool) bool {
+	if weak {
+		tag = strings.TrimPrefix(tag, "W/")
+	}
+	for _, headerTag := range strings.Split(header, ",") {
+		headerTag = strings.TrimSpace(headerTag)
+		if weak {
+			headerTag = strings.TrimPrefix(headerTag, "W/")
+		}
+		if headerTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagVersionMatches checks whether any of the comma separated strong tags of the header was built from the given
+// updated_at of the object
+func etagVersionMatches(header, version string) bool {
+	for _, headerTag := range strings.Split(header, ",") {
+		headerTag = strings.TrimSpace(headerTag)
+		if strings.HasPrefix(headerTag, `"`) && etagVersion(headerTag) == version {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified checks the If-None-Match header of the request against the current tag of the resource or, if the
+// request has no such header, its If-Modified-Since header against the time of the last modification of the resource
+func notModified(r *web.Request, tag string, lastModified time.Time) bool {
+	if ifNoneMatch := strings.TrimSpace(r.Header.Get(headerIfNoneMatch)); ifNoneMatch != "" {
+		return ifNoneMatch == "*" || etagMatches(ifNoneMatch, tag, true)
+	}
+	modifiedSince, ok := ifModifiedSince(r)
+	// the header has a precision of seconds
+	return ok && !lastModified.IsZero() && !lastModified.Truncate(time.Second).After(modifiedSince)
+}
+
+// ifModifiedSince returns the time in the If-Modified-Since header of the request if it is valid and the request has
+// no If-None-Match header, which takes precedence
+func ifModifiedSince(r *web.Request) (time.Time, bool) {
+	if r.Header.Get(headerIfNoneMatch) != "" {
+		return time.Time{}, false
+	}
+	modifiedSince, err := http.ParseTime(r.Header.Get(headerIfModifiedSince))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return modifiedSince, true
+}
+
+// notModifiedResponse returns 304 with the given headers
+func notModifiedResponse(headers map[string]string) *web.Response {
+	resp := &web.Response{
+		StatusCode: http.StatusNotModified,
+		Header:     http.Header{},
+	}
+	for header, value := range headers {
+		resp.Header.Set(header, value)
+	}
+	return resp
+}
+
+// ifMatchCriteria checks the If-Match header of the request against the current state of the object. It returns
+// 412 if none of the provided tags matches and otherwise the criteria which guarantee that the object is not
+// modified until it is stored. No criteria are returned if the request has no preconditions.
+func ifMatchCriteria(r *web.Request, object types.Object) ([]query.Criterion, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get(headerIfMatch))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, nil
+	}
+
+	// weak tags never match as If-Match requires strong comparison. Only the updated_at of the tag is compared as the
+	// precondition concerns the object and not its last operation or the projection in which the client got the tag
+	currentTag := etag(object)
+	if etagVersionMatches(ifMatch, etagVersion(currentTag)) {
+		return []query.Criterion{
+			query.ByField(query.EqualsOperator, "updated_at", object.GetUpdatedAt().UTC().Format(time.RFC3339Nano)),
+		}, nil
+	}
+
+	return nil, &util.HTTPError{
+		ErrorType:   "PreconditionFailed",
+		Description: fmt.Sprintf("%s with id %s has been modified: current ETag is %s", object.GetType(), object.GetID(), currentTag),
+		StatusCode:  http.StatusPreconditionFailed,
+	}
+}
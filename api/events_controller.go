@@ -0,0 +1,195 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// EventsPage is a page of the resource events after the revision known to the client
+type EventsPage struct {
+	Events []*types.ResourceEvent `json:"events"`
+	// LastRevision is the revision to be sent with the next request
+	LastRevision int64 `json:"last_revision"`
+	// HasMore reports if there are more events after the page which can be fetched right away
+	HasMore bool `json:"has_more"`
+}
+
+// EventsController implements api.Controller by providing the resource events to clients following the changes of the resources
+type EventsController struct {
+	repository      storage.Repository
+	horizon         storage.RevisionHorizon
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// NewEventsController returns a new controller for the resource events api
+func NewEventsController(options *Options) *EventsController {
+	return &EventsController{
+		repository:      options.Repository,
+		horizon:         options.RevisionHorizon,
+		defaultPageSize: options.APISettings.DefaultPageSize,
+		maxPageSize:     options.APISettings.MaxPageSize,
+	}
+}
+
+func (c *EventsController) Routes() []web.Route {
+	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.EventsURL,
+			},
+			Handler: c.listEvents,
+		},
+	}
+}
+
+// listEvents returns the resource events after the last revision known to the client in the order of their revisions.
+// Only the events up to the committed revision are returned, so that events committed later with lower revisions are
+// not skipped by the client. The page reports more events while visible events are held back by the horizon.
+// The field query of the request restricts the returned events. If the event with the last revision known to the
+// client has already been cleaned up, the client may have missed events and has to resync.
+func (c *EventsController) listEvents(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+
+	lastRevision, err := parseLastRevision(r.URL.Query().Get(web.QueryParamLastRevision))
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseMaxItems(r.URL.Query().Get("max_items"), c.defaultPageSize, c.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := strconv.FormatInt(lastRevision, 10)
+	if lastRevision > 0 {
+		// the last revision of a client is one of the events visible to it, but not necessarily one selected by the query
+		visibleCriteria, err := visibilityCriteria(r)
+		if err != nil {
+			return nil, err
+		}
+		criteria := append(visibleCriteria, query.ByField(query.EqualsOperator, "revision", revision))
+		count, err := c.repository.Count(ctx, types.ResourceEventType, criteria...)
+		if err != nil {
+			return nil, util.HandleStorageError(err, types.ResourceEventType.String())
+		}
+		if count == 0 {
+			return nil, &util.HTTPError{
+				ErrorType:   "Gone",
+				Description: fmt.Sprintf("resource event with revision %d is no longer available", lastRevision),
+				StatusCode:  http.StatusGone,
+			}
+		}
+	}
+
+	page := &EventsPage{
+		Events:       make([]*types.ResourceEvent, 0),
+		LastRevision: lastRevision,
+	}
+	if limit == 0 {
+		return util.NewJSONResponse(http.StatusOK, page)
+	}
+
+	committedRevision, err := c.horizon.CommittedRevision(ctx, types.ResourceEventType)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.ResourceEventType.String())
+	}
+
+	log.C(ctx).Debugf("Listing resource events after revision %d up to revision %d", lastRevision, committedRevision)
+	criteria := append(query.CriteriaForContext(ctx),
+		query.ByField(query.GreaterThanOperator, "revision", revision),
+		query.OrderResultBy("revision", query.AscOrder),
+		query.LimitResultBy(limit+1))
+	events, err := c.repository.ListNoLabels(ctx, types.ResourceEventType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.ResourceEventType.String())
+	}
+	for i := 0; i < events.Len() && i < limit; i++ {
+		event := events.ItemAt(i).(*types.ResourceEvent)
+		if event.Revision > committedRevision {
+			break
+		}
+		page.Events = append(page.Events, event)
+	}
+	// the events after the committed revision are returned once the transactions which may still commit events
+	// with lower revisions have finished, until then the client polls again later instead of right away
+	page.HasMore = len(page.Events) == limit && events.Len() > limit
+	if count := len(page.Events); count > 0 {
+		page.LastRevision = page.Events[count-1].Revision
+	}
+
+	return util.NewJSONResponse(http.StatusOK, page)
+}
+
+// visibilityCriteria returns the criteria of the request context which restrict the events visible to the caller,
+// e.g. to its tenant, without the criteria of the field and label queries of the request
+func visibilityCriteria(r *web.Request) ([]query.Criterion, error) {
+	requestCriteria := make([]query.Criterion, 0)
+	for _, queryType := range query.CriteriaTypes {
+		criteria, err := query.Parse(queryType, r.URL.Query().Get(string(queryType)))
+		if err != nil {
+			return nil, err
+		}
+		requestCriteria = append(requestCriteria, criteria...)
+	}
+
+	result := make([]query.Criterion, 0)
+	for _, criterion := range query.CriteriaForContext(r.Context()) {
+		if i := indexOfCriterion(requestCriteria, criterion); i >= 0 {
+			// each criterion of the request is skipped once, so that the same criterion of the caller still applies
+			requestCriteria = append(requestCriteria[:i], requestCriteria[i+1:]...)
+			continue
+		}
+		result = append(result, criterion)
+	}
+	return result, nil
+}
+
+func indexOfCriterion(criteria []query.Criterion, criterion query.Criterion) int {
+	for i := range criteria {
+		if reflect.DeepEqual(criteria[i], criterion) {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseLastRevision(lastRevision string) (int64, error) {
+	if lastRevision == "" {
+		return 0, nil
+	}
+	revision, err := strconv.ParseInt(lastRevision, 10, 64)
+	if err != nil || revision < 0 {
+		return 0, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("invalid %s query parameter: %s", web.QueryParamLastRevision, lastRevision),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return revision, nil
+}
@@ -28,7 +28,8 @@ func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerB
 		web.VisibilitiesURL+"/*",
 		web.ServiceInstancesURL+"/*",
 		web.ServiceBindingsURL+"/*",
-		web.NotificationsURL+"/*").
+		web.NotificationsURL+"/*",
+		web.SearchURL).
 		Method(http.MethodGet).
 		WithAuthentication(basicPlatformAuthenticator).Required()
 
@@ -67,6 +68,12 @@ func Register(ctx context.Context, cfg *config.Settings, smb *sm.ServiceManagerB
 		web.ConfigURL+"/**",
 		web.ProfileURL+"/**",
 		web.OperationsURL+"/**",
+		web.UpgradeCampaignsURL+"/**",
+		web.WebhooksURL+"/**",
+		web.MonitorJobsURL,
+		web.MonitorNotificationsURL,
+		web.SearchURL,
+		web.EventsURL,
 	).
 		Method(http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete).
 		WithAuthentication(bearerAuthenticator).Required()
@@ -17,6 +17,7 @@ const BrokersVisibilityFilterName = "BrokersFilterByVisibility"
 func NewBrokersFilterByVisibility(repository storage.Repository) *BrokersFilterByVisibility {
 	return &BrokersFilterByVisibility{
 		visibilityFilteringMiddleware: &visibilityFilteringMiddleware{
+			ObjectType:            types.ServiceBrokerType,
 			ListResourcesCriteria: brokersCriteriaFunc(repository),
 			IsResourceVisible:     isBrokerVisible(repository),
 		},
@@ -79,7 +80,7 @@ func (vf *BrokersFilterByVisibility) FilterMatchers() []web.FilterMatcher {
 	return []web.FilterMatcher{
 		{
 			Matchers: []web.Matcher{
-				web.Path(web.ServiceBrokersURL + "/*"),
+				web.Path(web.ServiceBrokersURL+"/*", web.SearchURL),
 				web.Methods(http.MethodGet),
 			},
 		},
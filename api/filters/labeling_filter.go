@@ -12,11 +12,13 @@ import (
 	"github.com/tidwall/gjson"
 
 	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
 
 	"github.com/Peripli/service-manager/pkg/web"
 )
 
 const LabelCriteriaFilterNameSuffix = "CriteriaFilter"
+const SearchLabelCriteriaFilterNameSuffix = "SearchCriteriaFilter"
 const ResourceLabelingFilterNameSuffix = "LabelingFilter"
 
 // NewLabelingFilters returns set of filters which applies resource labeling rules
@@ -24,6 +26,7 @@ func NewLabelingFilters(labelName, labelKey string, basePaths []string, extractV
 	return []web.Filter{
 		newLabelCriteriaFilter(labelName, labelKey, basePaths, extractValueFunc),
 		newResourceLabelingFilter(labelName, labelKey, basePaths, extractValueFunc),
+		newSearchLabelCriteriaFilter(labelName, labelKey, basePaths, extractValueFunc),
 	}
 }
 
@@ -52,6 +55,34 @@ func newLabelCriteriaFilter(labelName, labelKey string, bastPaths []string, extr
 	}
 }
 
+// newSearchLabelCriteriaFilter creates a new LabelingFilter from the specified settings that filters the search results of the resources
+// managed under the base paths based on a filtering label
+func newSearchLabelCriteriaFilter(labelName, labelKey string, basePaths []string, extractValueFunc func(request *web.Request) (string, error)) *LabelingFilter {
+	return &LabelingFilter{
+		LabelKey:     labelKey,
+		FilterName:   labelName + SearchLabelCriteriaFilterNameSuffix,
+		BasePaths:    []string{web.SearchURL},
+		Methods:      []string{http.MethodGet},
+		ExtractValue: extractValueFunc,
+		LabelingFunc: func(request *web.Request, labelKey, labelValue string) error {
+			ctx := request.Context()
+			criterion := query.ByLabel(query.EqualsOperator, labelKey, labelValue)
+			for _, basePath := range basePaths {
+				// the types of the resources are the base paths under which they are managed
+				var err error
+				if ctx, err = query.AddCriteriaForType(ctx, types.ObjectType(basePath), criterion); err != nil {
+					return fmt.Errorf("could not add label criteria with key %s and value %s for %s: %s", labelKey, labelValue, basePath, err)
+				}
+			}
+
+			log.C(ctx).Infof("Successfully added label criteria with key %s and value %s for %v to context", labelKey, labelValue, basePaths)
+			request.Request = request.WithContext(ctx)
+
+			return nil
+		},
+	}
+}
+
 // newResourceLabelingFilter creates a new LabelingFilter from the specified settings that adds a filtering label when creating resources
 func newResourceLabelingFilter(labelName, labelKey string, bastPaths []string, extractValueFunc func(request *web.Request) (string, error)) *LabelingFilter {
 	return &LabelingFilter{
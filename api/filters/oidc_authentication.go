@@ -57,6 +57,11 @@ func oidcAuthnMatchers() []web.FilterMatcher {
 					web.ConfigURL+"/**",
 					web.ProfileURL+"/**",
 					web.OperationsURL+"/**",
+					web.UpgradeCampaignsURL+"/**",
+					web.WebhooksURL+"/**",
+					web.MonitorJobsURL,
+					web.MonitorNotificationsURL,
+					web.EventsURL,
 				),
 			},
 		},
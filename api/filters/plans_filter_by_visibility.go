@@ -18,6 +18,7 @@ const PlanVisibilityFilterName = "PlanFilterByVisibility"
 func NewPlansFilterByVisibility(repository storage.Repository) *PlanFilterByVisibility {
 	return &PlanFilterByVisibility{
 		visibilityFilteringMiddleware: &visibilityFilteringMiddleware{
+			ObjectType:            types.ServicePlanType,
 			ListResourcesCriteria: plansCriteriaFunc(repository),
 			IsResourceVisible:     isPlanVisibile(repository),
 		},
@@ -50,7 +51,7 @@ func (vf *PlanFilterByVisibility) FilterMatchers() []web.FilterMatcher {
 	return []web.FilterMatcher{
 		{
 			Matchers: []web.Matcher{
-				web.Path(web.ServicePlansURL + "/*"),
+				web.Path(web.ServicePlansURL+"/*", web.SearchURL),
 				web.Methods(http.MethodGet),
 			},
 		},
@@ -17,6 +17,7 @@ const ServicesVisibilityFilterName = "ServicesFilterByVisibility"
 func NewServicesFilterByVisibility(repository storage.Repository) *ServicesFilterByVisibility {
 	return &ServicesFilterByVisibility{
 		visibilityFilteringMiddleware: &visibilityFilteringMiddleware{
+			ObjectType:            types.ServiceOfferingType,
 			ListResourcesCriteria: servicesCriteriaFunc(repository),
 			IsResourceVisible:     isServiceVisible(repository),
 		},
@@ -61,7 +62,7 @@ func (vf *ServicesFilterByVisibility) FilterMatchers() []web.FilterMatcher {
 	return []web.FilterMatcher{
 		{
 			Matchers: []web.Matcher{
-				web.Path(web.ServiceOfferingsURL + "/*"),
+				web.Path(web.ServiceOfferingsURL+"/*", web.SearchURL),
 				web.Methods(http.MethodGet),
 			},
 		},
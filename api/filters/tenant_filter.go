@@ -16,7 +16,7 @@ func NewMultitenancyFilters(labelKey string, extractTenantFunc func(request *web
 		return nil, errors.New("extractTenantFunc should be provided")
 	}
 
-	return NewLabelingFilters(LabelName, labelKey, []string{web.PlatformsURL, web.ServiceBrokersURL, web.ServiceInstancesURL, web.ServiceBindingsURL}, func(request *web.Request) (string, error) {
+	return NewLabelingFilters(LabelName, labelKey, []string{web.PlatformsURL, web.ServiceBrokersURL, web.ServiceInstancesURL, web.ServiceBindingsURL, web.OperationsURL, web.MonitorNotificationsURL, web.EventsURL, web.WebhooksURL}, func(request *web.Request) (string, error) {
 		ctx := request.Context()
 
 		userContext, found := web.UserFromContext(ctx)
@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 
 	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
 
 	"github.com/Peripli/service-manager/pkg/web"
 
@@ -127,6 +128,27 @@ var _ = Describe("TenantFilters", func() {
 				}
 			})
 
+			Describe("Search criteria filter", func() {
+				It("should add the tenant criteria only for the tenant-scoped types", func() {
+					newReq, err := http.NewRequest(http.MethodGet, "http://example.com"+web.SearchURL, nil)
+					Expect(err).ShouldNot(HaveOccurred())
+					fakeRequest.Request = newReq
+					fakeRequest.Request = fakeRequest.WithContext(web.ContextWithUser(context.Background(), &web.UserContext{
+						AuthenticationType: web.Bearer,
+						Name:               "test",
+						AccessLevel:        web.TenantAccess,
+					}))
+					_, err = multitenancyFilters[2].Run(fakeRequest, fakeHandler)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fakeHandler.HandleCallCount()).To(Equal(1))
+					ctx := fakeHandler.HandleArgsForCall(0).Context()
+					Expect(query.CriteriaForContext(ctx)).To(HaveLen(0))
+					Expect(query.CriteriaForType(ctx, types.ServiceInstanceType)).To(ConsistOf(query.ByLabel(query.EqualsOperator, labelKey, tenant)))
+					Expect(query.CriteriaForType(ctx, types.ServiceBrokerType)).To(ConsistOf(query.ByLabel(query.EqualsOperator, labelKey, tenant)))
+					Expect(query.CriteriaForType(ctx, types.ServicePlanType)).To(HaveLen(0))
+				})
+			})
+
 			Describe("Labeling filter", func() {
 
 				Describe("Tenant access", func() {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Peripli/service-manager/pkg/util"
 
@@ -15,6 +16,9 @@ import (
 )
 
 type visibilityFilteringMiddleware struct {
+	// ObjectType is the type of the resources whose visibility is checked. It allows the criteria to apply only to them
+	// when the request spans several resource types, such as the search
+	ObjectType            types.ObjectType
 	IsResourceVisible     func(ctx context.Context, resourceID, platformID string) (bool, error)
 	ListResourcesCriteria func(ctx context.Context, platformID string) (*query.Criterion, error)
 }
@@ -59,7 +63,22 @@ func (m visibilityFilteringMiddleware) Run(req *web.Request, next web.Handler) (
 	if err != nil {
 		return nil, err
 	}
+	if req.URL.Path == web.SearchURL {
+		if finalQuery == nil {
+			ctx = query.ExcludeType(ctx, m.ObjectType)
+		} else if ctx, err = query.AddCriteriaForType(ctx, m.ObjectType, *finalQuery); err != nil {
+			return nil, err
+		}
+		req.Request = req.WithContext(ctx)
+		return next.Handle(req)
+	}
 	if finalQuery == nil {
+		if strings.HasSuffix(req.URL.Path, web.ResourceAggregationsURL) {
+			return util.NewJSONResponse(http.StatusOK, types.Aggregation{
+				GroupBy: strings.TrimSpace(req.URL.Query().Get(web.QueryParamGroupBy)),
+				Groups:  make([]types.AggregationGroup, 0),
+			})
+		}
 		return util.NewJSONResponse(http.StatusOK, types.ObjectPage{Items: make([]types.Object, 0)})
 	}
 
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jobs
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// Job is the status of a background job in the monitoring API
+type Job struct {
+	Name            string     `json:"name"`
+	Leader          string     `json:"leader"`
+	Term            int64      `json:"term"`
+	LeaderSince     time.Time  `json:"leader_since"`
+	LeaseExpiresAt  time.Time  `json:"lease_expires_at"`
+	LeaseExpired    bool       `json:"lease_expired"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty"`
+	LastRunDuration string     `json:"last_run_duration,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+// Jobs is the list of background jobs returned by the monitoring API
+type Jobs struct {
+	Jobs []*Job `json:"jobs"`
+}
+
+// controller background jobs monitoring controller
+type controller struct {
+	leases storage.LeaseStore
+}
+
+// NewController returns a new controller which lists the background jobs with their leaders from the given lease store
+func NewController(leases storage.LeaseStore) web.Controller {
+	return &controller{
+		leases: leases,
+	}
+}
+
+// listJobs handler for GET /v1/monitor/jobs
+func (c *controller) listJobs(r *web.Request) (*web.Response, error) {
+	leases, err := c.leases.ListLeases(r.Context())
+	if err != nil {
+		return nil, util.HandleStorageError(err, "job")
+	}
+
+	now := time.Now()
+	jobs := &Jobs{Jobs: make([]*Job, 0, len(leases))}
+	for _, lease := range leases {
+		job := &Job{
+			Name:           lease.Job,
+			Leader:         lease.Holder,
+			Term:           lease.Term,
+			LeaderSince:    lease.AcquiredAt,
+			LeaseExpiresAt: lease.ExpiresAt,
+			LeaseExpired:   !now.Before(lease.ExpiresAt),
+			LastError:      lease.LastError,
+		}
+		if !lease.LastRunAt.IsZero() {
+			lastRunAt := lease.LastRunAt
+			job.LastRunAt = &lastRunAt
+			job.LastRunDuration = lease.LastRunDuration.String()
+		}
+		jobs.Jobs = append(jobs.Jobs, job)
+	}
+
+	return util.NewJSONResponse(http.StatusOK, jobs)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// AckMessageType is the type of the messages with which the proxies acknowledge notifications
+const AckMessageType = "ack"
+
+// Message is a message sent by a proxy over the notifications websocket
+type Message struct {
+	Type string `json:"type"`
+	// Revision acknowledges all notifications up to and including this revision
+	Revision int64 `json:"revision"`
+}
+
+// acknowledger persists the revisions acknowledged over a websocket connection
+type acknowledger struct {
+	store      storage.NotificationAckStore
+	platformID string
+
+	lastSent  int64
+	lastAcked int64
+}
+
+func newAcknowledger(store storage.NotificationAckStore, platform *types.Platform, startRevision int64) *acknowledger {
+	if startRevision < 0 {
+		startRevision = 0
+	}
+	return &acknowledger{
+		store:      store,
+		platformID: platform.ID,
+		lastSent:   startRevision,
+		lastAcked:  platform.LastAckedRevision,
+	}
+}
+
+// sent records that the notification with the given revision has been written to the websocket
+func (a *acknowledger) sent(revision int64) {
+	atomic.StoreInt64(&a.lastSent, revision)
+}
+
+// handle processes a message received over the websocket. Acknowledgements of revisions which have not been sent
+// over the connection are rejected, acknowledgements of revisions which are already acknowledged are ignored.
+func (a *acknowledger) handle(ctx context.Context, data []byte) error {
+	message := &Message{}
+	if err := json.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("could not parse message: %s", err)
+	}
+	if message.Type != AckMessageType {
+		return fmt.Errorf("unknown message type %s", message.Type)
+	}
+	return a.ack(ctx, message.Revision)
+}
+
+// ack persists the given revision as the last revision acknowledged by the platform
+func (a *acknowledger) ack(ctx context.Context, revision int64) error {
+	if lastSent := atomic.LoadInt64(&a.lastSent); revision > lastSent {
+		return fmt.Errorf("acknowledged revision %d has not been sent yet (last sent revision %d)", revision, lastSent)
+	}
+	if revision <= a.lastAcked {
+		return nil
+	}
+
+	if err := a.store.AckNotifications(ctx, a.platformID, revision, time.Now()); err != nil {
+		return fmt.Errorf("could not persist acknowledged revision %d: %s", revision, err)
+	}
+
+	log.C(ctx).Debugf("Platform %s acknowledged notifications up to revision %d", a.platformID, revision)
+	a.lastAcked = revision
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package notifications
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	// ResourceTypesQueryParam restricts the notifications to the comma separated resource types, e.g. visibilities
+	ResourceTypesQueryParam = "resource_types"
+	// OperationTypesQueryParam restricts the notifications to the comma separated operation types, e.g. CREATED
+	OperationTypesQueryParam = "operation_types"
+)
+
+// subscriptionFilter compiles the resource types, the operation types and the label query of the request into the
+// filter of the notification queue of the platform. It returns nil if the request does not restrict the notifications.
+func subscriptionFilter(req *web.Request) (storage.NotificationFilter, error) {
+	resourceTypes := splitQueryParam(req, ResourceTypesQueryParam)
+	for _, resourceType := range resourceTypes {
+		if !types.IsNotifiedResourceType(resourceType) {
+			return nil, &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("invalid %s query parameter: unknown resource type %s", ResourceTypesQueryParam, resourceType),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+	}
+
+	operationTypes := splitQueryParam(req, OperationTypesQueryParam)
+	for i, operationType := range operationTypes {
+		operationTypes[i] = strings.ToUpper(operationType)
+		switch types.NotificationOperation(operationTypes[i]) {
+		case types.CREATED, types.MODIFIED, types.DELETED:
+		default:
+			return nil, &util.HTTPError{
+				ErrorType:   "BadRequest",
+				Description: fmt.Sprintf("invalid %s query parameter: unknown operation type %s", OperationTypesQueryParam, operationType),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+	}
+
+	// the label query has already been parsed into the request criteria
+	var labelCriteria []query.Criterion
+	for _, criterion := range query.CriteriaForContext(req.Context()) {
+		if criterion.Type == query.LabelQuery {
+			labelCriteria = append(labelCriteria, criterion)
+		}
+	}
+
+	// both the name of the resource and the path of its API are accepted as resource types
+	return storage.NewNotificationFilter(req.Context(), resourceTypes, operationTypes, labelCriteria), nil
+}
+
+func splitQueryParam(req *web.Request, name string) []string {
+	var values []string
+	for _, value := range strings.Split(req.URL.Query().Get(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
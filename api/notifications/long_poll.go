@@ -0,0 +1,193 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// LongPollResponse is the response to a long-polling notifications request
+type LongPollResponse struct {
+	Notifications []*types.Notification `json:"notifications"`
+	// LastNotificationRevision is the revision to be sent with the next request
+	LastNotificationRevision int64 `json:"last_notification_revision"`
+}
+
+// longPollInactivityTimeouts is the number of long-poll timeouts without requests after which a long-polling
+// platform is considered inactive
+const longPollInactivityTimeouts = 3
+
+// handleLongPoll returns the notifications after the revision known to the proxy. If there are none, it waits for new
+// notifications until the long-poll timeout elapses. The revision in the request acknowledges all notifications up to it.
+func (c *Controller) handleLongPoll(req *web.Request) (*web.Response, error) {
+	ctx := req.Context()
+	platform, err := c.platformFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	revisionKnownToProxy, err := parseRevision(ctx, req.URL.Query().Get(LastKnownRevisionQueryParam), LastKnownRevisionQueryParam+" query parameter")
+	if err != nil {
+		return nil, err
+	}
+	filter, err := subscriptionFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationQueue, lastKnownToSMRevision, err := c.registerConsumer(ctx, platform, revisionKnownToProxy, filter)
+	if err != nil {
+		if err == util.ErrInvalidNotificationRevision {
+			return util.NewJSONResponse(http.StatusGone, nil)
+		}
+		return nil, err
+	}
+	defer c.unregisterConsumer(ctx, notificationQueue)
+
+	c.markLongPollActive(ctx, platform)
+	defer c.scheduleLongPollInactivity(platform.ID)
+
+	if revisionKnownToProxy > 0 {
+		if err := newAcknowledger(c.acks, platform, revisionKnownToProxy).ack(ctx, revisionKnownToProxy); err != nil {
+			log.C(ctx).WithError(err).Errorf("Could not acknowledge notifications of platform %s", platform.ID)
+		}
+	}
+
+	result := &LongPollResponse{
+		Notifications:            c.pollNotifications(ctx, notificationQueue),
+		LastNotificationRevision: revisionKnownToProxy,
+	}
+	if count := len(result.Notifications); count > 0 {
+		result.LastNotificationRevision = result.Notifications[count-1].Revision
+	} else if lastKnownToSMRevision > revisionKnownToProxy {
+		// none of the notifications up to the revision known to SM is for the platform
+		result.LastNotificationRevision = lastKnownToSMRevision
+	}
+
+	return util.NewJSONResponse(http.StatusOK, result)
+}
+
+// pollNotifications waits for the first notification in the queue and returns it together with all notifications
+// which are already queued after it
+func (c *Controller) pollNotifications(ctx context.Context, q storage.NotificationQueue) []*types.Notification {
+	timer := time.NewTimer(c.wsSettings.LongPollTimeout)
+	defer timer.Stop()
+
+	result := make([]*types.Notification, 0)
+	notificationChannel := q.Channel()
+	select {
+	case <-ctx.Done():
+		return result
+	case <-c.baseCtx.Done():
+		return result
+	case <-timer.C:
+		return result
+	case notification, ok := <-notificationChannel:
+		if !ok {
+			return result
+		}
+		result = append(result, notification)
+	}
+
+	for {
+		select {
+		case notification, ok := <-notificationChannel:
+			if !ok {
+				return result
+			}
+			result = append(result, notification)
+		default:
+			return result
+		}
+	}
+}
+
+// markLongPollActive marks the long-polling platform as active. Long-polling platforms keep no connection open, so
+// their requests are recorded as last activity, which is refreshed at most once per long-poll timeout.
+func (c *Controller) markLongPollActive(ctx context.Context, platform *types.Platform) {
+	c.longPollMutex.Lock()
+	if timer, found := c.longPollTimers[platform.ID]; found {
+		timer.Stop()
+		delete(c.longPollTimers, platform.ID)
+	}
+	c.longPollMutex.Unlock()
+
+	if platform.Active && time.Since(platform.LastActive) < c.wsSettings.LongPollTimeout {
+		return
+	}
+	if err := c.repository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
+		platform, err := getPlatform(ctx, storage, platform.ID)
+		if err != nil {
+			return err
+		}
+		platform.Active = true
+		platform.LastActive = time.Now()
+		_, err = storage.Update(ctx, platform, nil)
+		return err
+	}); err != nil {
+		log.C(ctx).WithError(err).Errorf("Could not mark long-polling platform %s as active", platform.ID)
+	}
+}
+
+// scheduleLongPollInactivity marks the platform as inactive unless it long-polls again within the inactivity timeout.
+// The platform might long-poll another instance in the meantime, so it is only marked as inactive if no instance
+// has recorded activity of the platform within the inactivity timeout.
+func (c *Controller) scheduleLongPollInactivity(platformID string) {
+	inactivityTimeout := longPollInactivityTimeouts * c.wsSettings.LongPollTimeout
+
+	c.longPollMutex.Lock()
+	defer c.longPollMutex.Unlock()
+	if timer, found := c.longPollTimers[platformID]; found {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(inactivityTimeout, func() {
+		c.longPollMutex.Lock()
+		if c.longPollTimers[platformID] != timer {
+			c.longPollMutex.Unlock()
+			return
+		}
+		delete(c.longPollTimers, platformID)
+		c.longPollMutex.Unlock()
+
+		if c.baseCtx.Err() != nil {
+			return
+		}
+		if err := c.repository.InTransaction(c.baseCtx, func(ctx context.Context, storage storage.Repository) error {
+			platform, err := getPlatform(ctx, storage, platformID)
+			if err != nil {
+				return err
+			}
+			if !platform.Active || time.Since(platform.LastActive) < inactivityTimeout {
+				return nil
+			}
+			platform.Active = false
+			_, err = storage.Update(ctx, platform, nil)
+			return err
+		}); err != nil {
+			log.C(c.baseCtx).WithError(err).Errorf("Could not mark long-polling platform %s as inactive", platformID)
+		}
+	})
+	c.longPollTimers[platformID] = timer
+}
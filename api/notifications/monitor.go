@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// PlatformLag is the delivery state of the notifications of a platform in the monitoring API
+type PlatformLag struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Connected bool   `json:"connected"`
+	// Acknowledging is false for platforms whose proxies have never acknowledged a notification
+	Acknowledging        bool       `json:"acknowledging"`
+	LastAckedRevision    int64      `json:"last_acked_revision,omitempty"`
+	LastAckedAt          *time.Time `json:"last_acked_at,omitempty"`
+	UnackedNotifications int        `json:"unacked_notifications"`
+	// Lag is the age of the oldest notification the platform has not acknowledged
+	Lag string `json:"lag,omitempty"`
+}
+
+// PlatformsLag is the list of platforms returned by the notifications monitoring API
+type PlatformsLag struct {
+	Platforms []*PlatformLag `json:"platforms"`
+}
+
+// listLag handler for GET /v1/monitor/notifications lists the platforms visible to the caller, e.g. those of its tenant
+func (c *Controller) listLag(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	criteria := append(query.CriteriaForContext(ctx), query.OrderResultBy("name", query.AscOrder))
+	objectList, err := c.repository.List(ctx, types.PlatformType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.PlatformType.String())
+	}
+	platforms := objectList.(*types.Platforms).Platforms
+
+	now := time.Now()
+	result := &PlatformsLag{Platforms: make([]*PlatformLag, 0, len(platforms))}
+	for _, platform := range platforms {
+		if platform.Technical {
+			continue
+		}
+		lag := &PlatformLag{
+			ID:        platform.ID,
+			Name:      platform.Name,
+			Type:      platform.Type,
+			Connected: platform.Active,
+		}
+		if platform.LastAckedRevision > 0 {
+			if err := c.unackedNotifications(ctx, platform, lag, now); err != nil {
+				return nil, util.HandleStorageError(err, types.NotificationType.String())
+			}
+		}
+		result.Platforms = append(result.Platforms, lag)
+	}
+
+	return util.NewJSONResponse(http.StatusOK, result)
+}
+
+func (c *Controller) unackedNotifications(ctx context.Context, platform *types.Platform, lag *PlatformLag, now time.Time) error {
+	lastAckedAt := platform.LastAckedAt
+	lag.Acknowledging = true
+	lag.LastAckedRevision = platform.LastAckedRevision
+	lag.LastAckedAt = &lastAckedAt
+
+	criteria := []query.Criterion{
+		query.ByField(query.GreaterThanOperator, "revision", strconv.FormatInt(platform.LastAckedRevision, 10)),
+		query.ByField(query.EqualsOrNilOperator, "platform_id", platform.ID),
+	}
+	count, err := c.repository.Count(ctx, types.NotificationType, criteria...)
+	if err != nil {
+		return err
+	}
+	lag.UnackedNotifications = count
+	if count == 0 {
+		return nil
+	}
+
+	criteria = append(criteria, query.OrderResultBy("revision", query.AscOrder), query.LimitResultBy(1))
+	oldest, err := c.repository.List(ctx, types.NotificationType, criteria...)
+	if err != nil {
+		return err
+	}
+	if oldest.Len() > 0 {
+		lag.Lag = now.Sub(oldest.ItemAt(0).GetCreatedAt()).Round(time.Second).String()
+	}
+	return nil
+}
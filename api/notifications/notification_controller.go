@@ -19,6 +19,8 @@ package notifications
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/Peripli/service-manager/storage"
 
@@ -34,6 +36,11 @@ type Controller struct {
 
 	wsSettings  *ws.Settings
 	notificator storage.Notificator
+	acks        storage.NotificationAckStore
+
+	// longPollMutex guards the timers which mark the long-polling platforms as inactive
+	longPollMutex  sync.Mutex
+	longPollTimers map[string]*time.Timer
 }
 
 // Routes returns the routes for notifications
@@ -44,18 +51,28 @@ func (c *Controller) Routes() []web.Route {
 				Method: http.MethodGet,
 				Path:   web.NotificationsURL,
 			},
-			Handler:             c.handleWS,
+			Handler:             c.handleNotifications,
 			DisableHTTPTimeouts: true,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.MonitorNotificationsURL,
+			},
+			Handler: c.listLag,
+		},
 	}
 }
 
 // NewController creates new notifications controller
-func NewController(baseCtx context.Context, repository storage.TransactionalRepository, wsSettings *ws.Settings, notificator storage.Notificator) *Controller {
+func NewController(baseCtx context.Context, repository storage.TransactionalRepository, wsSettings *ws.Settings, notificator storage.Notificator, acks storage.NotificationAckStore) *Controller {
 	return &Controller{
 		baseCtx:     baseCtx,
 		repository:  repository,
 		wsSettings:  wsSettings,
 		notificator: notificator,
+		acks:        acks,
+
+		longPollTimers: make(map[string]*time.Timer),
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/query"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/types"
@@ -25,54 +26,40 @@ const (
 	AgentVersionHeader          = "X-Peripli-Agent-Version"
 )
 
+// handleNotifications serves the notifications over a websocket, as server-sent events or as long-polling JSON
+// responses depending on the request
+func (c *Controller) handleNotifications(req *web.Request) (*web.Response, error) {
+	if !websocket.IsWebSocketUpgrade(req.Request) {
+		accept := req.Header.Get("Accept")
+		if strings.Contains(accept, EventStreamContentType) {
+			return c.handleSSE(req)
+		}
+		if strings.Contains(accept, "application/json") {
+			return c.handleLongPoll(req)
+		}
+	}
+	return c.handleWS(req)
+}
+
 func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 	ctx := req.Context()
 	logger := log.C(ctx)
-	user, ok := web.UserFromContext(req.Context())
-	if !ok {
-		return nil, errors.New("user details not found in request context")
+	platform, err := c.platformFromRequest(req)
+	if err != nil {
+		return nil, err
 	}
-
-	platform, err := extractPlatformFromContext(user)
+	revisionKnownToProxy, err := parseRevision(ctx, req.URL.Query().Get(LastKnownRevisionQueryParam), LastKnownRevisionQueryParam+" query parameter")
 	if err != nil {
 		return nil, err
 	}
-	version := req.Header.Get(AgentVersionHeader)
-	if platform.Version != version {
-		platform.Version = version
-		_, err = c.repository.Update(ctx, platform, nil)
-		if err != nil {
-			logger.Errorf("Could not update platform version for platform %s: %v", platform.ID, err)
-			return nil, err
-		}
+	filter, err := subscriptionFilter(req)
+	if err != nil {
+		return nil, err
 	}
 
-	if user.Name == platform.Credentials.Basic.Username && !platform.CredentialsActive {
-		logger.Debugf("Activating credentials for platform %s", platform.ID)
-		platform.CredentialsActive = true
-		platform.OldCredentials = nil
-		_, err = c.repository.Update(ctx, platform, nil)
-		if err != nil {
-			logger.Errorf("Could not activate credentials for platform %s: %v", platform.ID, err)
-			return nil, err
-		}
-	}
-	revisionKnownToProxy := types.InvalidRevision
-	revisionKnownToProxyStr := req.URL.Query().Get(LastKnownRevisionQueryParam)
-	if revisionKnownToProxyStr != "" {
-		var err error
-		revisionKnownToProxy, err = strconv.ParseInt(revisionKnownToProxyStr, 10, 64)
-		if err != nil {
-			logger.Errorf("could not convert string %s to number: %v", revisionKnownToProxyStr, err)
-			return nil, &util.HTTPError{
-				StatusCode:  http.StatusBadRequest,
-				Description: fmt.Sprintf("invalid %s query parameter", LastKnownRevisionQueryParam),
-				ErrorType:   "BadRequest",
-			}
-		}
-	}
+	revisionKnownToProxy = redeliveryRevision(ctx, platform, revisionKnownToProxy)
 
-	notificationQueue, lastKnownToSMRevision, err := c.notificator.RegisterConsumer(platform, revisionKnownToProxy)
+	notificationQueue, lastKnownToSMRevision, err := c.registerConsumer(ctx, platform, revisionKnownToProxy, filter)
 	if err != nil {
 		if err == util.ErrInvalidNotificationRevision {
 			return util.NewJSONResponse(http.StatusGone, nil)
@@ -102,15 +89,101 @@ func (c *Controller) handleWS(req *web.Request) (*web.Response, error) {
 	}
 
 	done := make(chan struct{}, 2)
+	acks := newAcknowledger(c.acks, platform, revisionKnownToProxy)
 
 	go c.closeConn(childCtx, childCtxCancel, conn, done)
-	go c.writeLoop(childCtx, conn, notificationQueue, done)
-	go c.readLoop(childCtx, c.repository, platform, conn, done)
+	go c.writeLoop(childCtx, conn, notificationQueue, acks, done)
+	go c.readLoop(childCtx, c.repository, platform, conn, acks, done)
 
 	return &web.Response{}, nil
 }
 
-func (c *Controller) writeLoop(ctx context.Context, conn *websocket.Conn, q storage.NotificationQueue, done chan<- struct{}) {
+// redeliveryRevision returns the revision after which the notifications are delivered to the platform. The
+// notifications which the platform has not acknowledged are redelivered, even if the proxy knows about them.
+func redeliveryRevision(ctx context.Context, platform *types.Platform, revisionKnownToProxy int64) int64 {
+	if platform.LastAckedRevision <= 0 {
+		return revisionKnownToProxy
+	}
+	if revisionKnownToProxy != platform.LastAckedRevision {
+		log.C(ctx).Infof("Redelivering notifications after revision %d last acknowledged by platform %s (proxy known revision %d)",
+			platform.LastAckedRevision, platform.ID, revisionKnownToProxy)
+	}
+	return platform.LastAckedRevision
+}
+
+// registerConsumer registers the platform as consumer of the notifications after the given revision. If the revision
+// is no longer available, the platform resyncs after the 410 response, so its acknowledgements are reset.
+func (c *Controller) registerConsumer(ctx context.Context, platform *types.Platform, revision int64, filter storage.NotificationFilter) (storage.NotificationQueue, int64, error) {
+	notificationQueue, lastKnownToSMRevision, err := c.notificator.RegisterConsumer(platform, revision, filter)
+	if err == util.ErrInvalidNotificationRevision && platform.LastAckedRevision > 0 {
+		if resetErr := c.acks.ResetNotificationAcks(ctx, platform.ID); resetErr != nil {
+			log.C(ctx).WithError(resetErr).Errorf("Could not reset acknowledged notification revision of platform %s", platform.ID)
+			return nil, types.InvalidRevision, resetErr
+		}
+	}
+	return notificationQueue, lastKnownToSMRevision, err
+}
+
+// platformFromRequest returns the platform which has opened the notifications request and records the version of its
+// agent and the activation of its credentials
+func (c *Controller) platformFromRequest(req *web.Request) (*types.Platform, error) {
+	ctx := req.Context()
+	logger := log.C(ctx)
+	user, ok := web.UserFromContext(req.Context())
+	if !ok {
+		return nil, errors.New("user details not found in request context")
+	}
+
+	platform, err := extractPlatformFromContext(user)
+	if err != nil {
+		return nil, err
+	}
+	// the platform in the user context lacks the state which is not exposed in the API, such as the acknowledged revision
+	platform, err = getPlatform(ctx, c.repository, platform.ID)
+	if err != nil {
+		return nil, err
+	}
+	version := req.Header.Get(AgentVersionHeader)
+	if platform.Version != version {
+		platform.Version = version
+		_, err = c.repository.Update(ctx, platform, nil)
+		if err != nil {
+			logger.Errorf("Could not update platform version for platform %s: %v", platform.ID, err)
+			return nil, err
+		}
+	}
+
+	if user.Name == platform.Credentials.Basic.Username && !platform.CredentialsActive {
+		logger.Debugf("Activating credentials for platform %s", platform.ID)
+		platform.CredentialsActive = true
+		platform.OldCredentials = nil
+		_, err = c.repository.Update(ctx, platform, nil)
+		if err != nil {
+			logger.Errorf("Could not activate credentials for platform %s: %v", platform.ID, err)
+			return nil, err
+		}
+	}
+	return platform, nil
+}
+
+// parseRevision parses the revision known to the proxy, which is invalid if it is not specified
+func parseRevision(ctx context.Context, value, source string) (int64, error) {
+	if value == "" {
+		return types.InvalidRevision, nil
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.C(ctx).Errorf("could not convert string %s to number: %v", value, err)
+		return types.InvalidRevision, &util.HTTPError{
+			StatusCode:  http.StatusBadRequest,
+			Description: fmt.Sprintf("invalid %s", source),
+			ErrorType:   "BadRequest",
+		}
+	}
+	return revision, nil
+}
+
+func (c *Controller) writeLoop(ctx context.Context, conn *websocket.Conn, q storage.NotificationQueue, acks *acknowledger, done chan<- struct{}) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.C(ctx).Errorf("recovered from panic while writing to websocket connection: %s", err)
@@ -138,11 +211,12 @@ func (c *Controller) writeLoop(ctx context.Context, conn *websocket.Conn, q stor
 			if !c.sendWsMessage(ctx, conn, notification) {
 				return
 			}
+			acks.sent(notification.Revision)
 		}
 	}
 }
 
-func (c *Controller) readLoop(ctx context.Context, repository storage.TransactionalRepository, platform *types.Platform, conn *websocket.Conn, done chan<- struct{}) {
+func (c *Controller) readLoop(ctx context.Context, repository storage.TransactionalRepository, platform *types.Platform, conn *websocket.Conn, acks *acknowledger, done chan<- struct{}) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.C(ctx).Errorf("recovered from panic while reading from websocket connection: %s", err)
@@ -154,9 +228,8 @@ func (c *Controller) readLoop(ctx context.Context, repository storage.Transactio
 	}()
 
 	for {
-		// ReadMessage is needed only to receive ping/pong/close control messages
-		// currently we don't expect to receive something else from the proxies
-		_, _, err := conn.ReadMessage()
+		// besides the ping/pong/close control messages, the proxies send acknowledgements of the notifications they have applied
+		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			log.C(ctx).WithError(err).Error("ws: could not read")
 			if err = updatePlatformStatus(ctx, repository, platform.ID, false); err != nil {
@@ -164,6 +237,12 @@ func (c *Controller) readLoop(ctx context.Context, repository storage.Transactio
 			}
 			return
 		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+		if err := acks.handle(ctx, message); err != nil {
+			log.C(ctx).WithError(err).Errorf("Could not process message from platform %s", platform.ID)
+		}
 	}
 }
 
@@ -203,6 +282,14 @@ func newContextWithCorrelationID(baseCtx context.Context, correlationID string)
 	return context.WithCancel(newCtx)
 }
 
+func getPlatform(ctx context.Context, repository storage.Repository, platformID string) (*types.Platform, error) {
+	obj, err := repository.Get(ctx, types.PlatformType, query.ByField(query.EqualsOperator, "id", platformID))
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.PlatformType.String())
+	}
+	return obj.(*types.Platform), nil
+}
+
 func updatePlatformStatus(ctx context.Context, repository storage.TransactionalRepository, platformID string, desiredStatus bool) error {
 	if err := repository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
 		idCriteria := query.Criterion{
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	// EventStreamContentType is the content type with which the platforms request notifications as server-sent events
+	EventStreamContentType = "text/event-stream"
+	// LastEventIDHeader is sent by the platforms reconnecting to the event stream with the last revision they received
+	LastEventIDHeader = "Last-Event-ID"
+	// NotificationEventType is the type of the server-sent events carrying notifications
+	NotificationEventType = "notification"
+)
+
+// handleSSE streams the notifications as server-sent events with the revision of each notification as event id.
+// The Last-Event-ID of a reconnecting platform acknowledges all notifications up to it, and the notifications which
+// the platform has not acknowledged are redelivered like over the websocket.
+func (c *Controller) handleSSE(req *web.Request) (*web.Response, error) {
+	ctx := req.Context()
+	logger := log.C(ctx)
+	platform, err := c.platformFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lastEventID := req.Header.Get(LastEventIDHeader)
+	revisionSource := LastEventIDHeader + " header"
+	revisionKnownToProxyStr := lastEventID
+	if revisionKnownToProxyStr == "" {
+		revisionSource = LastKnownRevisionQueryParam + " query parameter"
+		revisionKnownToProxyStr = req.URL.Query().Get(LastKnownRevisionQueryParam)
+	}
+	revisionKnownToProxy, err := parseRevision(ctx, revisionKnownToProxyStr, revisionSource)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := subscriptionFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastEventID != "" && revisionKnownToProxy > 0 {
+		// event streams have no way back to the server, so the platform acknowledges the events it received on reconnect
+		if err := newAcknowledger(c.acks, platform, revisionKnownToProxy).ack(ctx, revisionKnownToProxy); err != nil {
+			logger.WithError(err).Errorf("Could not acknowledge notifications of platform %s", platform.ID)
+		} else if revisionKnownToProxy > platform.LastAckedRevision {
+			platform.LastAckedRevision = revisionKnownToProxy
+		}
+	}
+	revisionKnownToProxy = redeliveryRevision(ctx, platform, revisionKnownToProxy)
+
+	notificationQueue, lastKnownToSMRevision, err := c.registerConsumer(ctx, platform, revisionKnownToProxy, filter)
+	if err != nil {
+		if err == util.ErrInvalidNotificationRevision {
+			return util.NewJSONResponse(http.StatusGone, nil)
+		}
+		return nil, err
+	}
+
+	correlationID := logger.Data[log.FieldCorrelationID].(string)
+	streamCtx, streamCtxCancel := newContextWithCorrelationID(c.baseCtx, correlationID)
+	defer streamCtxCancel()
+	defer c.unregisterConsumer(streamCtx, notificationQueue)
+
+	rw := req.HijackResponseWriter()
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		util.WriteError(ctx, errors.New("streaming is not supported by the response writer"), rw)
+		return &web.Response{}, nil
+	}
+
+	rw.Header().Set("Content-Type", EventStreamContentType)
+	rw.Header().Set("Cache-Control", "no-cache")
+	// disables the response buffering of reverse proxies such as nginx
+	rw.Header().Set("X-Accel-Buffering", "no")
+	if lastKnownToSMRevision != types.InvalidRevision {
+		rw.Header().Set(LastKnownRevisionHeader, strconv.FormatInt(lastKnownToSMRevision, 10))
+	}
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if err := updatePlatformStatus(streamCtx, c.repository, platform.ID, true); err != nil {
+		log.C(streamCtx).WithError(err).Error("could not update platform status")
+	}
+	c.streamEvents(ctx, streamCtx, rw, flusher, notificationQueue)
+	if err := updatePlatformStatus(streamCtx, c.repository, platform.ID, false); err != nil {
+		log.C(streamCtx).WithError(err).Error("could not update platform status")
+	}
+
+	return &web.Response{}, nil
+}
+
+// streamEvents writes the notifications from the queue as events until the platform disconnects or the queue is closed
+func (c *Controller) streamEvents(reqCtx, ctx context.Context, rw http.ResponseWriter, flusher http.Flusher, q storage.NotificationQueue) {
+	keepAlive := time.NewTicker(c.wsSettings.SSEKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	notificationChannel := q.Channel()
+	for {
+		var event string
+		select {
+		case <-reqCtx.Done():
+			log.C(ctx).Infof("Event stream closed by the platform")
+			return
+		case <-ctx.Done():
+			log.C(ctx).Infof("Event stream shutting down")
+			return
+		case <-keepAlive.C:
+			event = ": keep-alive\n\n"
+		case notification, ok := <-notificationChannel:
+			if !ok {
+				log.C(ctx).Infof("Notifications channel is closed. Closing event stream...")
+				return
+			}
+			data, err := json.Marshal(notification)
+			if err != nil {
+				log.C(ctx).WithError(err).Error("Could not marshal notification")
+				return
+			}
+			event = fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", notification.Revision, NotificationEventType, data)
+		}
+
+		if _, err := rw.Write([]byte(event)); err != nil {
+			log.C(ctx).WithError(err).Error("sse: could not write")
+			return
+		}
+		flusher.Flush()
+	}
+}
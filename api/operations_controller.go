@@ -3,7 +3,10 @@ package api
 import (
 	"context"
 	"fmt"
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/web"
 	"net/http"
 )
@@ -15,11 +18,16 @@ type OperationsController struct {
 
 // NewOperationsController returns a new controller for operations api
 func NewOperationsController(ctx context.Context, options *Options) *OperationsController {
-	return &OperationsController{
+	controller := &OperationsController{
 		BaseController: NewController(ctx, options, web.OperationsURL, types.OperationType, func() types.Object {
 			return &types.Operation{}
 		}, false),
 	}
+	// the post hooks are notified of the operations canceled or retried through this controller
+	if options.OperationHooks != nil {
+		options.OperationHooks.Attach(controller.scheduler)
+	}
+	return controller
 }
 
 func (c *OperationsController) Routes() []web.Route {
@@ -31,6 +39,13 @@ func (c *OperationsController) Routes() []web.Route {
 			},
 			Handler: c.ListObjects,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}", c.resourceBaseURL, web.PathParamResourceID),
+			},
+			Handler: c.GetSingleObject,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodDelete,
@@ -38,5 +53,56 @@ func (c *OperationsController) Routes() []web.Route {
 			},
 			Handler: c.DeleteSingleObject,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.OperationCancelURL),
+			},
+			Handler: c.CancelOperation,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.OperationRetryURL),
+			},
+			Handler: c.RetryOperation,
+		},
+	}
+}
+
+// CancelOperation cancels the in progress operation with the id specified
+func (c *OperationsController) CancelOperation(r *web.Request) (*web.Response, error) {
+	operationID := r.PathParams[web.PathParamResourceID]
+
+	ctx := r.Context()
+	log.C(ctx).Debugf("Canceling operation with id %s", operationID)
+
+	ctx, err := query.AddCriteria(ctx, query.ByField(query.EqualsOperator, "id", operationID))
+	if err != nil {
+		return nil, err
+	}
+	return cancelOperation(ctx, c.repository, c.scheduler, c.cancellationNotifier, query.CriteriaForContext(ctx)...)
+}
+
+// RetryOperation executes the action of the failed or canceled operation with the id specified again
+func (c *OperationsController) RetryOperation(r *web.Request) (*web.Response, error) {
+	operationID := r.PathParams[web.PathParamResourceID]
+
+	ctx := r.Context()
+	log.C(ctx).Debugf("Retrying operation with id %s", operationID)
+
+	ctx, err := query.AddCriteria(ctx, query.ByField(query.EqualsOperator, "id", operationID))
+	if err != nil {
+		return nil, err
+	}
+	object, err := c.repository.Get(ctx, types.OperationType, query.CriteriaForContext(ctx)...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.OperationType.String())
+	}
+
+	operation := object.(*types.Operation)
+	if err := c.scheduler.ScheduleRetry(ctx, operation); err != nil {
+		return nil, err
 	}
+	return util.NewLocationResponse(operation.ID, operation.ResourceID, operation.ResourceType.String())
 }
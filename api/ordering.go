@@ -0,0 +1,184 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// orderedPageToken is the content of the page tokens when the list is ordered by the orderBy query parameter.
+// It holds the order by expression the token was generated for and the sort keys of the last item of the previous page
+// followed by its paging sequence, which is always used as the last sort key in order to make the order stable
+type orderedPageToken struct {
+	OrderBy string   `json:"order_by"`
+	Values  []string `json:"values"`
+}
+
+// pagingCriteria returns the criteria which select the page after the given token and a function generating the token
+// of the next page. Without order by expression the objects are paged by their paging sequence.
+func (c *BaseController) pagingCriteria(ctx context.Context, orderBy, token string) ([]query.Criterion, func(types.Object) (string, error), error) {
+	if orderBy == "" {
+		pagingSequence, err := c.parsePageToken(ctx, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []query.Criterion{
+			query.OrderResultBy("paging_sequence", query.AscOrder),
+			query.ByField(query.GreaterThanOperator, "paging_sequence", pagingSequence),
+		}, generateTokenForItem, nil
+	}
+
+	orderCriteria, err := query.ParseOrderBy(orderBy)
+	if err != nil {
+		return nil, nil, err
+	}
+	orderableColumns, err := c.orderableColumnValues(c.objectBlueprint())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, criterion := range orderCriteria {
+		if _, ok := orderableColumns[criterion.RightOp[0]]; criterion.LeftOp == query.OrderBy && !ok {
+			return nil, nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported order by field: %s", criterion.RightOp[0])}
+		}
+	}
+	orderBy = orderByExpression(orderCriteria)
+
+	criteria := append(orderCriteria, query.OrderResultBy("paging_sequence", query.AscOrder))
+	if token != "" {
+		values, err := parseOrderedPageToken(ctx, token, orderBy, len(criteria))
+		if err != nil {
+			return nil, nil, err
+		}
+		criteria = append(criteria, query.ResultAfter(values...))
+	}
+
+	return criteria, func(obj types.Object) (string, error) {
+		columnValues, err := c.orderableColumnValues(obj)
+		if err != nil {
+			return "", err
+		}
+		return generateOrderedTokenForItem(obj, columnValues, orderBy, orderCriteria)
+	}, nil
+}
+
+// orderableColumnValues returns the values of the columns by which the objects can be ordered and grouped. The criteria
+// refer to the columns of the objects, whose names differ from the JSON field names for some fields.
+func (c *BaseController) orderableColumnValues(obj types.Object) (map[string]string, error) {
+	if c.columnMapper == nil {
+		return map[string]string{}, nil
+	}
+	values, err := c.columnMapper.OrderableColumnValues(obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the column values of %s: %s", obj.GetType(), err)
+	}
+	return values, nil
+}
+
+// labelOrderFields returns the label fields needed to generate the page tokens when the objects are ordered by labels
+func labelOrderFields(orderCriteria []query.Criterion) []string {
+	fields := make([]string, 0)
+	for _, criterion := range orderCriteria {
+		if criterion.LeftOp == query.OrderByLabel {
+			fields = append(fields, query.LabelFieldPrefix+criterion.RightOp[0])
+		}
+	}
+	return fields
+}
+
+func parseOrderedPageToken(ctx context.Context, token, orderBy string, sortKeysCount int) ([]string, error) {
+	invalidTokenErr := &util.HTTPError{
+		ErrorType:   "TokenInvalid",
+		Description: "Invalid token provided.",
+		StatusCode:  http.StatusBadRequest,
+	}
+	tokenBytes, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		log.C(ctx).Infof("Invalid token provided: %v", err)
+		return nil, invalidTokenErr
+	}
+	pageToken := &orderedPageToken{}
+	if err := json.Unmarshal(tokenBytes, pageToken); err != nil {
+		log.C(ctx).Infof("Invalid token provided: %v", err)
+		return nil, invalidTokenErr
+	}
+	if pageToken.OrderBy != orderBy {
+		log.C(ctx).Infof("Invalid token provided: token is generated for order by %s but %s is requested", pageToken.OrderBy, orderBy)
+		return nil, invalidTokenErr
+	}
+	if len(pageToken.Values) != sortKeysCount {
+		log.C(ctx).Infof("Invalid token provided: expected %d sort keys but found %d", sortKeysCount, len(pageToken.Values))
+		return nil, invalidTokenErr
+	}
+	if pagingSequence, err := strconv.ParseInt(pageToken.Values[sortKeysCount-1], 10, 0); err != nil || pagingSequence < 0 {
+		log.C(ctx).Infof("Invalid token provided: invalid paging sequence %s", pageToken.Values[sortKeysCount-1])
+		return nil, invalidTokenErr
+	}
+	return pageToken.Values, nil
+}
+
+func generateOrderedTokenForItem(obj types.Object, columnValues map[string]string, orderBy string, orderCriteria []query.Criterion) (string, error) {
+	values := make([]string, 0, len(orderCriteria)+1)
+	for _, criterion := range orderCriteria {
+		if criterion.LeftOp == query.OrderByLabel {
+			values = append(values, lowestLabelValue(obj.GetLabels()[criterion.RightOp[0]]))
+		} else {
+			values = append(values, columnValues[criterion.RightOp[0]])
+		}
+	}
+	values = append(values, strconv.FormatInt(obj.GetPagingSequence(), 10))
+
+	tokenBytes, err := json.Marshal(&orderedPageToken{OrderBy: orderBy, Values: values})
+	if err != nil {
+		return "", fmt.Errorf("could not generate page token: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(tokenBytes), nil
+}
+
+// orderByExpression returns the normalized order by expression of the given order criteria
+func orderByExpression(orderCriteria []query.Criterion) string {
+	rules := make([]string, 0, len(orderCriteria))
+	for _, criterion := range orderCriteria {
+		field := criterion.RightOp[0]
+		if criterion.LeftOp == query.OrderByLabel {
+			field = query.LabelFieldPrefix + field
+		}
+		rules = append(rules, fmt.Sprintf("%s %s", field, criterion.RightOp[1]))
+	}
+	return strings.Join(rules, ",")
+}
+
+// lowestLabelValue returns the value by which objects with multiple values of a label are ordered
+func lowestLabelValue(values []string) string {
+	lowest := ""
+	for i, value := range values {
+		if i == 0 || value < lowest {
+			lowest = value
+		}
+	}
+	return lowest
+}
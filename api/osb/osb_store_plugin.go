@@ -1053,15 +1053,16 @@ func (sp *storePlugin) handlePollUpdateResponse(ctx context.Context, storage sto
 	return entOp, nil
 }
 
+// removeOsbEntity stores the operation before the entity is deleted, so that the operation gets the tenant label of the entity
 func (sp *storePlugin) removeOsbEntity(resStatus int, deleteEntity func() error, storeOperation func(state types.OperationState, category types.OperationCategory) error) error {
 	switch resStatus {
 	case http.StatusOK:
 		fallthrough
 	case http.StatusGone:
-		if err := deleteEntity(); err != nil {
+		if err := storeOperation(types.SUCCEEDED, types.DELETE); err != nil {
 			return err
 		}
-		if err := storeOperation(types.SUCCEEDED, types.DELETE); err != nil {
+		if err := deleteEntity(); err != nil {
 			return err
 		}
 	case http.StatusAccepted:
@@ -1072,13 +1073,14 @@ func (sp *storePlugin) removeOsbEntity(resStatus int, deleteEntity func() error,
 	return nil
 }
 
+// createOsbEntity stores the entity before its operation, so that the operation gets the tenant label of the entity
 func (sp *storePlugin) createOsbEntity(resStatus int, storeOperation func(state types.OperationState, category types.OperationCategory) error, storeEntity func(ready bool) error) error {
 	switch resStatus {
 	case http.StatusCreated:
-		if err := storeOperation(types.SUCCEEDED, types.CREATE); err != nil {
+		if err := storeEntity(true); err != nil {
 			return err
 		}
-		if err := storeEntity(true); err != nil {
+		if err := storeOperation(types.SUCCEEDED, types.CREATE); err != nil {
 			return err
 		}
 	case http.StatusOK:
@@ -1092,10 +1094,10 @@ func (sp *storePlugin) createOsbEntity(resStatus int, storeOperation func(state
 			}
 		}
 	case http.StatusAccepted:
-		if err := storeOperation(types.IN_PROGRESS, types.CREATE); err != nil {
+		if err := storeEntity(false); err != nil {
 			return err
 		}
-		if err := storeEntity(false); err != nil {
+		if err := storeOperation(types.IN_PROGRESS, types.CREATE); err != nil {
 			return err
 		}
 	}
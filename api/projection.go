@@ -0,0 +1,159 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+const lastOperationField = "last_operation"
+
+// projectedPage is the DTO for a page of resources projected to some of their fields
+type projectedPage struct {
+	Token      string                       `json:"token,omitempty"`
+	ItemsCount int                          `json:"num_items"`
+	Items      []map[string]json.RawMessage `json:"items"`
+}
+
+// parseFields parses the comma separated fields query parameter and validates that the fields exist in the given object.
+// Single labels can be selected using labels.<key>.
+func parseFields(fieldsParam string, blueprint types.Object) ([]string, error) {
+	if strings.TrimSpace(fieldsParam) == "" {
+		return nil, nil
+	}
+	fieldNames := jsonFieldNames(reflect.TypeOf(blueprint))
+	fields := make([]string, 0)
+	for _, field := range strings.Split(fieldsParam, ",") {
+		field = strings.TrimSpace(field)
+		if strings.HasPrefix(field, query.LabelFieldPrefix) && len(field) > len(query.LabelFieldPrefix) {
+			fields = append(fields, field)
+			continue
+		}
+		if !fieldNames[field] {
+			return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field: %s", field)}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// normalizeFields returns the sorted distinct fields of the projection
+func normalizeFields(fields []string) []string {
+	seen := make(map[string]bool, len(fields))
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !seen[field] {
+			seen[field] = true
+			result = append(result, field)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// fieldsContain returns true if there is no projection or the given field is part of it
+func fieldsContain(fields []string, field string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func projectPage(page *types.ObjectPage, fields []string) (*projectedPage, error) {
+	result := &projectedPage{
+		Token:      page.Token,
+		ItemsCount: page.ItemsCount,
+		Items:      make([]map[string]json.RawMessage, 0, len(page.Items)),
+	}
+	for _, item := range page.Items {
+		projectedItem, err := projectObject(item, fields)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, projectedItem)
+	}
+	return result, nil
+}
+
+// projectObject returns the JSON representation of the object containing only the given fields
+func projectObject(object types.Object, fields []string) (map[string]json.RawMessage, error) {
+	objectBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	allFields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(objectBytes, &allFields); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]json.RawMessage)
+	labels := types.Labels{}
+	for _, field := range fields {
+		if strings.HasPrefix(field, query.LabelFieldPrefix) {
+			key := strings.TrimPrefix(field, query.LabelFieldPrefix)
+			if values, found := object.GetLabels()[key]; found {
+				labels[key] = values
+			}
+			continue
+		}
+		if value, found := allFields[field]; found {
+			result[field] = value
+		}
+	}
+	if _, found := result[query.LabelsField]; !found && len(labels) != 0 {
+		labelsBytes, err := json.Marshal(labels)
+		if err != nil {
+			return nil, err
+		}
+		result[query.LabelsField] = labelsBytes
+	}
+	return result, nil
+}
+
+func jsonFieldNames(objectType reflect.Type) map[string]bool {
+	if objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+	names := make(map[string]bool)
+	for i := 0; i < objectType.NumField(); i++ {
+		field := objectType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name := range jsonFieldNames(field.Type) {
+				names[name] = true
+			}
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
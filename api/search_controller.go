@@ -0,0 +1,124 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// searchVectorColumn is the column holding the words of the names and descriptions of the searchable resources
+const searchVectorColumn = "search_vector"
+
+// searchableTypes are the types of the resources which can be searched by the names of their results in the response
+var searchableTypes = []struct {
+	name       string
+	objectType types.ObjectType
+}{
+	{name: "service_offerings", objectType: types.ServiceOfferingType},
+	{name: "service_plans", objectType: types.ServicePlanType},
+	{name: "service_brokers", objectType: types.ServiceBrokerType},
+	{name: "service_instances", objectType: types.ServiceInstanceType},
+}
+
+// SearchController implements api.Controller by providing full-text search over the names and descriptions of resources
+type SearchController struct {
+	repository      storage.Repository
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// NewSearchController returns a new controller for the search api
+func NewSearchController(options *Options) *SearchController {
+	return &SearchController{
+		repository:      options.Repository,
+		defaultPageSize: options.APISettings.DefaultPageSize,
+		maxPageSize:     options.APISettings.MaxPageSize,
+	}
+}
+
+func (c *SearchController) Routes() []web.Route {
+	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.SearchURL,
+			},
+			Handler: c.search,
+		},
+	}
+}
+
+// search returns for each searchable type the resources having words starting with each of the words in the search text,
+// ordered by name. The criteria in the request context, such as the visibility and tenant ones, apply per type.
+func (c *SearchController) search(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+
+	text := r.URL.Query().Get(web.QueryParamSearchText)
+	if strings.IndexFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) == -1 {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("%s query parameter should contain at least one word", web.QueryParamSearchText),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	limit, err := parseMaxItems(r.URL.Query().Get("max_items"), c.defaultPageSize, c.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.ObjectPage, len(searchableTypes))
+	for _, searchable := range searchableTypes {
+		page := &types.ObjectPage{Items: make([]types.Object, 0)}
+		result[searchable.name] = page
+		if query.IsTypeExcluded(ctx, searchable.objectType) {
+			continue
+		}
+
+		criteria := append(query.CriteriaForType(ctx, searchable.objectType),
+			query.ByField(query.TextSearchOperator, searchVectorColumn, text))
+		if page.ItemsCount, err = c.repository.Count(ctx, searchable.objectType, criteria...); err != nil {
+			return nil, util.HandleStorageError(err, searchable.objectType.String())
+		}
+		if page.ItemsCount == 0 || limit == 0 {
+			continue
+		}
+
+		log.C(ctx).Debugf("Searching %ss by %s", searchable.objectType, text)
+		criteria = append(criteria, query.OrderResultBy("name", query.AscOrder), query.LimitResultBy(limit))
+		objectList, err := c.repository.List(ctx, searchable.objectType, criteria...)
+		if err != nil {
+			return nil, util.HandleStorageError(err, searchable.objectType.String())
+		}
+		for i := 0; i < objectList.Len(); i++ {
+			obj := objectList.ItemAt(i)
+			cleanObject(ctx, obj)
+			page.Items = append(page.Items, obj)
+		}
+	}
+
+	return util.NewJSONResponse(http.StatusOK, result)
+}
@@ -38,12 +38,15 @@ type ServiceBindingController struct {
 }
 
 func NewServiceBindingController(ctx context.Context, options *Options) *ServiceBindingController {
-	return &ServiceBindingController{
+	controller := &ServiceBindingController{
 		BaseController: NewAsyncController(ctx, options, web.ServiceBindingsURL, types.ServiceBindingType, true, func() types.Object {
 			return &types.ServiceBinding{}
 		}, true),
 		osbVersion: options.APISettings.OSBVersion,
 	}
+	controller.supportsScheduling = true
+
+	return controller
 }
 
 func (c *ServiceBindingController) Routes() []web.Route {
@@ -69,6 +72,20 @@ func (c *ServiceBindingController) Routes() []web.Route {
 			},
 			Handler: c.GetOperation,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.ResourceOperationsURL, web.PathParamID, web.OperationCancelURL),
+			},
+			Handler: c.CancelOperation,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.ResourceHistoryURL),
+			},
+			Handler: c.GetHistory,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -20,13 +20,16 @@ import (
 	"context"
 	"fmt"
 	"github.com/Peripli/service-manager/api/osb"
+	"github.com/Peripli/service-manager/operations"
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/web"
 	"github.com/Peripli/service-manager/storage"
+	"github.com/tidwall/gjson"
 	"net/http"
+	"time"
 )
 
 const serviceInstanceOSBURL string = "%s/v2/service_instances/%s"
@@ -34,17 +37,42 @@ const serviceInstanceOSBURL string = "%s/v2/service_instances/%s"
 // ServiceInstanceController implements api.Controller by providing service Instances API logic
 type ServiceInstanceController struct {
 	*BaseController
-	osbVersion string
+	osbVersion        string
+	operationSettings *operations.Settings
+	tenantLabelKey    string
 }
 
 func NewServiceInstanceController(ctx context.Context, options *Options) *ServiceInstanceController {
-
-	return &ServiceInstanceController{
+	controller := &ServiceInstanceController{
 		BaseController: NewAsyncController(ctx, options, web.ServiceInstancesURL, types.ServiceInstanceType, true, func() types.Object {
 			return &types.ServiceInstance{}
 		}, true),
-		osbVersion: options.APISettings.OSBVersion,
+		osbVersion:        options.APISettings.OSBVersion,
+		operationSettings: options.OperationSettings,
+		tenantLabelKey:    options.TenantLabelKey,
 	}
+	controller.supportsScheduling = true
+	controller.updateDeferral = controller.deferPlanUpgrade
+
+	return controller
+}
+
+// deferPlanUpgrade defers updates of the maintenance info of instances, which upgrade their plans, to the next
+// maintenance window of their tenant or platform if none of their maintenance windows is open
+func (c *ServiceInstanceController) deferPlanUpgrade(ctx context.Context, body []byte, object types.Object) time.Time {
+	if !gjson.GetBytes(body, "maintenance_info").Exists() {
+		return time.Time{}
+	}
+	instance := object.(*types.ServiceInstance)
+	var tenant string
+	if tenants := instance.GetLabels()[c.tenantLabelKey]; c.tenantLabelKey != "" && len(tenants) != 0 {
+		tenant = tenants[0]
+	}
+	windowStart := c.operationSettings.NextMaintenanceWindow(instance.PlatformID, tenant, time.Now())
+	if !windowStart.IsZero() {
+		log.C(ctx).Infof("Deferring upgrade of %s with id %s to the maintenance window starting at %s", c.objectType, instance.ID, windowStart)
+	}
+	return windowStart
 }
 
 func (c *ServiceInstanceController) Routes() []web.Route {
@@ -79,6 +107,20 @@ func (c *ServiceInstanceController) Routes() []web.Route {
 			},
 			Handler: c.GetOperation,
 		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.ResourceOperationsURL, web.PathParamID, web.OperationCancelURL),
+			},
+			Handler: c.CancelOperation,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}%s", c.resourceBaseURL, web.PathParamResourceID, web.ResourceHistoryURL),
+			},
+			Handler: c.GetHistory,
+		},
 		{
 			Endpoint: web.Endpoint{
 				Method: http.MethodGet,
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// UpgradeCampaignController implements api.Controller by providing upgrade campaigns API logic
+type UpgradeCampaignController struct {
+	*BaseController
+}
+
+func NewUpgradeCampaignController(ctx context.Context, options *Options) *UpgradeCampaignController {
+	return &UpgradeCampaignController{
+		BaseController: NewController(ctx, options, web.UpgradeCampaignsURL, types.UpgradeCampaignType, func() types.Object {
+			return &types.UpgradeCampaign{
+				MaxConcurrency: 1,
+				State:          types.UpgradeCampaignInProgress,
+			}
+		}, false),
+	}
+}
+
+func (c *UpgradeCampaignController) Routes() []web.Route {
+	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   web.UpgradeCampaignsURL,
+			},
+			Handler: c.CreateCampaign,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}", web.UpgradeCampaignsURL, web.PathParamResourceID),
+			},
+			Handler: c.GetCampaign,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.UpgradeCampaignsURL,
+			},
+			Handler: c.ListObjects,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodDelete,
+				Path:   fmt.Sprintf("%s/{%s}", web.UpgradeCampaignsURL, web.PathParamResourceID),
+			},
+			Handler: c.DeleteSingleObject,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s", web.UpgradeCampaignsURL, web.PathParamResourceID, web.UpgradeCampaignPauseURL),
+			},
+			Handler: c.PauseCampaign,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   fmt.Sprintf("%s/{%s}%s", web.UpgradeCampaignsURL, web.PathParamResourceID, web.UpgradeCampaignResumeURL),
+			},
+			Handler: c.ResumeCampaign,
+		},
+	}
+}
+
+// CreateCampaign validates the queries and the initial state of the upgrade campaign before creating it
+func (c *UpgradeCampaignController) CreateCampaign(r *web.Request) (*web.Response, error) {
+	campaign := c.objectBlueprint().(*types.UpgradeCampaign)
+	if err := util.BytesToObject(r.Body, campaign); err != nil {
+		return nil, err
+	}
+	if campaign.IsFinished() {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("upgrade campaigns can only be created in state %s or %s", types.UpgradeCampaignInProgress, types.UpgradeCampaignPaused),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	if _, err := operations.UpgradeCampaignCriteria(campaign); err != nil {
+		return nil, err
+	}
+
+	return c.CreateObject(r)
+}
+
+// GetCampaign returns the upgrade campaign with the progress of its upgrades
+func (c *UpgradeCampaignController) GetCampaign(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	campaign, err := c.getCampaign(ctx, r.PathParams[web.PathParamResourceID])
+	if err != nil {
+		return nil, err
+	}
+
+	return c.campaignResponse(ctx, campaign)
+}
+
+// PauseCampaign stops the upgrade campaign from starting further upgrades. Upgrades which are in progress are not interrupted.
+func (c *UpgradeCampaignController) PauseCampaign(r *web.Request) (*web.Response, error) {
+	return c.changeCampaignState(r, types.UpgradeCampaignInProgress, types.UpgradeCampaignPaused)
+}
+
+// ResumeCampaign lets a paused upgrade campaign start further upgrades
+func (c *UpgradeCampaignController) ResumeCampaign(r *web.Request) (*web.Response, error) {
+	return c.changeCampaignState(r, types.UpgradeCampaignPaused, types.UpgradeCampaignInProgress)
+}
+
+func (c *UpgradeCampaignController) changeCampaignState(r *web.Request, from, to types.UpgradeCampaignState) (*web.Response, error) {
+	ctx := r.Context()
+	campaign, err := c.getCampaign(ctx, r.PathParams[web.PathParamResourceID])
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign.State != from {
+		return nil, &util.HTTPError{
+			ErrorType:   "Conflict",
+			Description: fmt.Sprintf("upgrade campaign with id %s is %s and cannot be changed to %s", campaign.ID, campaign.State, to),
+			StatusCode:  http.StatusConflict,
+		}
+	}
+
+	log.C(ctx).Infof("Changing state of upgrade campaign with id %s from %s to %s", campaign.ID, from, to)
+	campaign.State = to
+	byState := query.ByField(query.EqualsOperator, "state", string(from))
+	updatedObject, err := c.repository.Update(ctx, campaign, types.LabelChanges{}, byState)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.UpgradeCampaignType.String())
+	}
+
+	return c.campaignResponse(ctx, updatedObject.(*types.UpgradeCampaign))
+}
+
+func (c *UpgradeCampaignController) getCampaign(ctx context.Context, campaignID string) (*types.UpgradeCampaign, error) {
+	byID := query.ByField(query.EqualsOperator, "id", campaignID)
+	object, err := c.repository.Get(ctx, types.UpgradeCampaignType, byID)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.UpgradeCampaignType.String())
+	}
+
+	return object.(*types.UpgradeCampaign), nil
+}
+
+func (c *UpgradeCampaignController) campaignResponse(ctx context.Context, campaign *types.UpgradeCampaign) (*web.Response, error) {
+	progress, err := operations.UpgradeCampaignProgress(ctx, c.repository, campaign)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Progress = progress
+
+	return util.NewJSONResponse(http.StatusOK, campaign)
+}
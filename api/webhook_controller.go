@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+// WebhookController implements api.Controller by providing the webhook subscriptions API logic
+type WebhookController struct {
+	*BaseController
+}
+
+func NewWebhookController(ctx context.Context, options *Options) *WebhookController {
+	return &WebhookController{
+		BaseController: NewController(ctx, options, web.WebhooksURL, types.WebhookType, func() types.Object {
+			return &types.Webhook{}
+		}, false),
+	}
+}
+
+func (c *WebhookController) Routes() []web.Route {
+	return []web.Route{
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPost,
+				Path:   web.WebhooksURL,
+			},
+			Handler: c.CreateObject,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}", web.WebhooksURL, web.PathParamResourceID),
+			},
+			Handler: c.GetSingleObject,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   web.WebhooksURL,
+			},
+			Handler: c.ListObjects,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodPatch,
+				Path:   fmt.Sprintf("%s/{%s}", web.WebhooksURL, web.PathParamResourceID),
+			},
+			Handler: c.PatchObject,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodDelete,
+				Path:   fmt.Sprintf("%s/{%s}", web.WebhooksURL, web.PathParamResourceID),
+			},
+			Handler: c.DeleteSingleObject,
+		},
+		{
+			Endpoint: web.Endpoint{
+				Method: http.MethodGet,
+				Path:   fmt.Sprintf("%s/{%s}%s", web.WebhooksURL, web.PathParamResourceID, web.WebhookDeliveryHistoryURL),
+			},
+			Handler: c.ListDeliveries,
+		},
+	}
+}
+
+// ListDeliveries returns a page of the delivery attempts of the webhook in the order in which they were made
+func (c *WebhookController) ListDeliveries(r *web.Request) (*web.Response, error) {
+	ctx := r.Context()
+	webhookID := r.PathParams[web.PathParamResourceID]
+
+	// the label criteria of the request, such as the tenant of the token, restrict the webhook and not its deliveries
+	webhookCriteria := []query.Criterion{query.ByField(query.EqualsOperator, "id", webhookID)}
+	criteria := []query.Criterion{query.ByField(query.EqualsOperator, "webhook_id", webhookID)}
+	for _, criterion := range query.CriteriaForContext(ctx) {
+		if criterion.Type == query.LabelQuery {
+			webhookCriteria = append(webhookCriteria, criterion)
+		} else {
+			criteria = append(criteria, criterion)
+		}
+	}
+	if _, err := c.repository.Get(ctx, types.WebhookType, webhookCriteria...); err != nil {
+		return nil, util.HandleStorageError(err, types.WebhookType.String())
+	}
+
+	count, err := c.repository.Count(ctx, types.WebhookDeliveryType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.WebhookDeliveryType.String())
+	}
+
+	limit, err := c.parseMaxItemsQuery(r.URL.Query().Get("max_items"))
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		page := struct {
+			ItemsCount int `json:"num_items"`
+		}{
+			ItemsCount: count,
+		}
+		return util.NewJSONResponse(http.StatusOK, page)
+	}
+
+	pagingCriteria, tokenForItem, err := c.pagingCriteria(ctx, "", r.URL.Query().Get("token"))
+	if err != nil {
+		return nil, err
+	}
+	criteria = append(criteria, query.LimitResultBy(limit+pagingLimitOffset))
+	criteria = append(criteria, pagingCriteria...)
+
+	log.C(ctx).Debugf("Getting a page of the deliveries of webhook with id %s", webhookID)
+	deliveries, err := c.repository.ListNoLabels(ctx, types.WebhookDeliveryType, criteria...)
+	if err != nil {
+		return nil, util.HandleStorageError(err, types.WebhookDeliveryType.String())
+	}
+
+	page, err := pageFromObjectList(ctx, deliveries, count, limit, tokenForItem)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewJSONResponse(http.StatusOK, page)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/server"
 	"github.com/Peripli/service-manager/pkg/ws"
 	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/webhooks"
 	"github.com/spf13/pflag"
 )
 
@@ -48,6 +49,7 @@ type Settings struct {
 	Health       *health.Settings
 	Multitenancy *multitenancy.Settings
 	Agents       *agents.Settings
+	Webhooks     *webhooks.Settings
 }
 
 // AddPFlags adds the SM config flags to the provided flag set
@@ -69,6 +71,7 @@ func DefaultSettings() *Settings {
 		Health:       health.DefaultSettings(),
 		Multitenancy: multitenancy.DefaultSettings(),
 		Agents:       agents.DefaultSettings(),
+		Webhooks:     webhooks.DefaultSettings(),
 	}
 }
 
@@ -78,6 +81,9 @@ func New(env env.Environment) (*Settings, error) {
 	if err := env.Unmarshal(config); err != nil {
 		return nil, fmt.Errorf("error loading configuration: %s", err)
 	}
+	if config.Operations != nil {
+		config.Operations.ApplyReschedulingInterval()
+	}
 	return config, nil
 }
 
@@ -85,12 +91,16 @@ func New(env env.Environment) (*Settings, error) {
 func (c *Settings) Validate() error {
 	validatable := []interface {
 		Validate() error
-	}{c.Server, c.Storage, c.Log, c.Health, c.API, c.Operations, c.WebSocket, c.Multitenancy, c.Agents}
+	}{c.Server, c.Storage, c.Log, c.Health, c.API, c.Operations, c.WebSocket, c.Multitenancy, c.Agents, c.Webhooks}
 
 	for _, item := range validatable {
 		if err := item.Validate(); err != nil {
 			return err
 		}
 	}
+	// the server closes requests after the request timeout, including the long-polling notifications requests
+	if c.WebSocket.LongPollTimeout >= c.Server.RequestTimeout {
+		return fmt.Errorf("validate Settings: websocket long poll timeout should be < server request timeout")
+	}
 	return nil
 }
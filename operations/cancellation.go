@@ -0,0 +1,167 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// errOperationCanceled is recorded as the error of canceled operations
+var errOperationCanceled = &util.HTTPError{
+	ErrorType:   "OperationCanceled",
+	Description: "operation was canceled",
+	StatusCode:  http.StatusConflict,
+}
+
+type runningAction struct {
+	cancel    context.CancelFunc
+	canceled  bool
+	abandoned bool
+}
+
+// runningActions holds the actions executed by the schedulers of this Service Manager instance by operation id
+var runningActions = struct {
+	sync.Mutex
+	actions map[string]*runningAction
+}{actions: make(map[string]*runningAction)}
+
+// trackAction makes the action of the operation cancelable with CancelAction. The returned function must be called
+// once the action has returned and reports whether the action was canceled or abandoned.
+func trackAction(ctx context.Context, operationID string) (context.Context, func() (bool, bool)) {
+	ctx, cancel := context.WithCancel(ctx)
+	action := &runningAction{cancel: cancel}
+
+	runningActions.Lock()
+	runningActions.actions[operationID] = action
+	runningActions.Unlock()
+
+	return ctx, func() (bool, bool) {
+		runningActions.Lock()
+		defer runningActions.Unlock()
+		if runningActions.actions[operationID] == action {
+			delete(runningActions.actions, operationID)
+		}
+		cancel()
+		return action.canceled, action.abandoned
+	}
+}
+
+// CancelAction cancels the context of the action of the operation if it is executed by this Service Manager instance
+func CancelAction(operationID string) bool {
+	runningActions.Lock()
+	defer runningActions.Unlock()
+	action, found := runningActions.actions[operationID]
+	if !found {
+		return false
+	}
+	action.canceled = true
+	action.cancel()
+	return true
+}
+
+// abandonAction cancels the context of the action of the operation if it is executed by this Service Manager instance
+// and marks it as abandoned, so that the outcome of the action is not recorded in the operation. Actions are abandoned
+// once this instance no longer owns their operations.
+func abandonAction(operationID string) bool {
+	runningActions.Lock()
+	defer runningActions.Unlock()
+	action, found := runningActions.actions[operationID]
+	if !found {
+		return false
+	}
+	action.abandoned = true
+	action.cancel()
+	return true
+}
+
+// Cancel marks the operation as canceled and notifies all Service Manager instances so that the one executing its
+// action aborts it. Orphan mitigation of canceled CREATE operations is scheduled by the instance which executed the
+// action or, if no instance did, by the Maintainer. Operations scheduled for a later time can be canceled until they
+// are started.
+func Cancel(ctx context.Context, repository storage.Repository, notifier storage.CancellationNotifier, operation *types.Operation) (*types.Operation, error) {
+	if operation.State != types.IN_PROGRESS && !operation.IsScheduled() {
+		return nil, &util.HTTPError{
+			ErrorType:   "OperationNotCancelable",
+			Description: fmt.Sprintf("operation with id %s is %s and can no longer be canceled", operation.ID, operation.State),
+			StatusCode:  http.StatusConflict,
+		}
+	}
+	if operation.CascadeRootID != "" {
+		return nil, &util.HTTPError{
+			ErrorType:   "OperationNotCancelable",
+			Description: fmt.Sprintf("operation with id %s is part of a cascade operation and can not be canceled", operation.ID),
+			StatusCode:  http.StatusUnprocessableEntity,
+		}
+	}
+
+	byState := query.ByField(query.EqualsOperator, "state", string(operation.State))
+	operation.State = types.CANCELED
+	operation.Reschedule = false
+	operation.RescheduleTimestamp = time.Time{}
+	if operation.Type == types.CREATE && !operation.InOrphanMitigationState() {
+		// the broker may have created the resource before the action was canceled
+		operation.DeletionScheduled = time.Now().UTC()
+	}
+	if err := setOperationError(ctx, operation, errOperationCanceled); err != nil {
+		return nil, err
+	}
+
+	// the state is guarded by the update statement, so that an operation which finishes concurrently is not marked as canceled
+	canceledOperation, err := repository.Update(ctx, operation, types.LabelChanges{}, byState)
+	if err != nil {
+		if err == util.ErrConcurrentResourceModification {
+			return nil, &util.HTTPError{
+				ErrorType:   "OperationNotCancelable",
+				Description: fmt.Sprintf("operation with id %s has finished and can no longer be canceled", operation.ID),
+				StatusCode:  http.StatusConflict,
+			}
+		}
+		return nil, util.HandleStorageError(err, types.OperationType.String())
+	}
+	log.C(ctx).Infof("Canceled %s operation with id %s for %s entity with id %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID)
+
+	if notifier == nil {
+		CancelAction(operation.ID)
+	} else if err := notifier.NotifyCancellation(ctx, operation.ID); err != nil {
+		log.C(ctx).Errorf("Failed to notify cancellation of operation with id %s: %s", operation.ID, err)
+	}
+
+	return canceledOperation.(*types.Operation), nil
+}
+
+// CancelOperation cancels the operation like Cancel and notifies the post hooks of the scheduler of the cancellation.
+// The post hooks of canceled operations which require orphan mitigation are notified once the mitigation has finished.
+func (s *Scheduler) CancelOperation(ctx context.Context, notifier storage.CancellationNotifier, operation *types.Operation) (*types.Operation, error) {
+	canceledOperation, err := Cancel(ctx, s.repository, notifier, operation)
+	if err != nil {
+		return nil, err
+	}
+	if !canceledOperation.InOrphanMitigationState() {
+		s.notifyPostHooks(ctx, canceledOperation)
+	}
+	return canceledOperation, nil
+}
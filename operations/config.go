@@ -18,6 +18,10 @@ package operations
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/types"
@@ -25,8 +29,21 @@ import (
 
 const (
 	minTimePeriod = time.Nanosecond
+
+	maintenanceWindowStartLayout = "15:04"
 )
 
+// weekdays maps the days of maintenance windows to the days of the week
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
 // Settings type to be loaded from the environment
 type Settings struct {
 	ActionTimeout                  time.Duration `mapstructure:"action_timeout" description:"timeout for async operations"`
@@ -37,15 +54,39 @@ type Settings struct {
 	CleanupInterval         time.Duration `mapstructure:"cleanup_interval" description:"cleanup interval of old operations"`
 	MaintainerRetryInterval time.Duration `mapstructure:"maintainer_retry_interval" description:"maintenance retry interval"`
 	Lifespan                time.Duration `mapstructure:"lifespan" description:"after that time is passed since its creation, the operation can be cleaned up by the maintainer"`
+	EventLifespan           time.Duration `mapstructure:"event_lifespan" description:"after that time is passed since its creation, the operation event can be cleaned up by the maintainer"`
+
+	PollingInterval time.Duration `mapstructure:"polling_interval" description:"the interval between polls for async requests"`
 
-	ReschedulingInterval time.Duration `mapstructure:"rescheduling_interval" description:"the interval between auto rescheduling of operation actions"`
-	PollingInterval      time.Duration `mapstructure:"polling_interval" description:"the interval between polls for async requests"`
+	DefaultRetryPolicy RetryPolicySettings   `mapstructure:"default_retry_policy" description:"default policy for retrying failed operations"`
+	RetryPolicies      []RetryPolicySettings `mapstructure:"retry_policies" description:"defines the policies for retrying failed operations of specific resources"`
+	// ReschedulingInterval is kept for compatibility, it is the delay before the first retry of the default retry policy
+	// unless the policy sets its initial backoff
+	ReschedulingInterval time.Duration `mapstructure:"rescheduling_interval" description:"deprecated, use default_retry_policy.initial_backoff instead"`
 
 	DefaultPoolSize               int            `mapstructure:"default_pool_size" description:"default worker pool size"`
 	DefaultCascadePollingPoolSize int            `mapstructure:"default_cascade_polling_pool_size" description:"default worker pool size"`
 	Pools                         []PoolSettings `mapstructure:"pools" description:"defines the different available worker pools"`
 
+	DefaultTenantShare float64          `mapstructure:"default_tenant_share" description:"default fraction of the size of a pool which is the number of queued operations of a single tenant executed at a time across all instances"`
+	Priorities         PrioritySettings `mapstructure:"priorities" description:"defines the priorities of queued operations"`
+
+	MaintenanceWindows []MaintenanceWindowSettings `mapstructure:"maintenance_windows" description:"defines the weekly windows of platforms or tenants during which the plans of their instances are upgraded"`
+
+	UpgradeCampaignInterval time.Duration `mapstructure:"upgrade_campaign_interval" description:"the interval in which upgrade campaigns start the upgrades of further instances"`
+
 	SMSupportedPlatformType string `mapstructure:"sm_supported_platform_type" description:"defines the value of the supported platform for the SM platform"`
+
+	JobQueueEnabled      bool          `mapstructure:"job_queue_enabled" description:"whether asynchronous operations are queued in the database so that any instance can execute them"`
+	JobPollInterval      time.Duration `mapstructure:"job_poll_interval" description:"the interval between polls for queued jobs"`
+	JobLeaseDuration     time.Duration `mapstructure:"job_lease_duration" description:"the time after which a job which is not heartbeated by its worker can be leased by another worker"`
+	JobHeartbeatInterval time.Duration `mapstructure:"job_heartbeat_interval" description:"the interval in which workers extend the leases of the jobs they execute"`
+	JobMaxAttempts       int           `mapstructure:"job_max_attempts" description:"the number of times a job can be leased before its operation is failed"`
+	JobWorkerShare       float64       `mapstructure:"job_worker_share" description:"fraction of the workers of a pool which can execute queued jobs, the others are reserved for the actions executed in-process"`
+
+	Hooks             []HookSettings `mapstructure:"hooks" description:"defines the webhooks which approve operations before they are started or are notified once they have finished"`
+	HookRetryInterval time.Duration  `mapstructure:"hook_retry_interval" description:"the interval in which the pre hooks of operations awaiting approval are asked again"`
+	ApprovalTimeout   time.Duration  `mapstructure:"approval_timeout" description:"the time after which operations which still await the approval of their pre hooks are failed"`
 }
 
 // DefaultSettings returns default values for API settings
@@ -57,13 +98,41 @@ func DefaultSettings() *Settings {
 		CleanupInterval:                1 * time.Hour,
 		MaintainerRetryInterval:        10 * time.Minute,
 		Lifespan:                       7 * 24 * time.Hour,
-		ReschedulingInterval:           10 * time.Second,
+		EventLifespan:                  30 * 24 * time.Hour,
 		PollingInterval:                4 * time.Second,
 		PollCascadeInterval:            4 * time.Second,
 		DefaultPoolSize:                20,
 		DefaultCascadePollingPoolSize:  20,
 		Pools:                          []PoolSettings{},
+		DefaultTenantShare:             1,
 		SMSupportedPlatformType:        types.SMPlatform,
+		JobQueueEnabled:                true,
+		JobPollInterval:                1 * time.Second,
+		JobLeaseDuration:               30 * time.Second,
+		JobHeartbeatInterval:           10 * time.Second,
+		JobMaxAttempts:                 3,
+		JobWorkerShare:                 0.8,
+		ReschedulingInterval:           10 * time.Second,
+		DefaultRetryPolicy: RetryPolicySettings{
+			MaxAttempts: 0,
+			// the initial backoff is unset so that the rescheduling interval applies unless it is configured
+			InitialBackoff: 0,
+			MaxBackoff:     5 * time.Minute,
+			Multiplier:     2,
+			Jitter:         0.2,
+		},
+		RetryPolicies:           []RetryPolicySettings{},
+		MaintenanceWindows:      []MaintenanceWindowSettings{},
+		UpgradeCampaignInterval: 1 * time.Minute,
+		Hooks:                   []HookSettings{},
+		HookRetryInterval:       1 * time.Minute,
+		ApprovalTimeout:         24 * time.Hour,
+		Priorities: PrioritySettings{
+			Create:         2,
+			Update:         2,
+			Delete:         3,
+			Reconciliation: 1,
+		},
 	}
 }
 
@@ -78,34 +147,108 @@ func (s *Settings) Validate() error {
 	if s.ReconciliationOperationTimeout <= minTimePeriod {
 		return fmt.Errorf("validate Settings: ReconciliationOperationTimeout must be larger than %s", minTimePeriod)
 	}
-	if s.ReschedulingInterval <= minTimePeriod {
-		return fmt.Errorf("validate Settings: ReschedulingInterval must be larger than %s", minTimePeriod)
-	}
 	if s.PollingInterval <= minTimePeriod {
 		return fmt.Errorf("validate Settings: PollingInterval must be larger than %s", minTimePeriod)
 	}
 	if s.MaintainerRetryInterval <= minTimePeriod {
 		return fmt.Errorf("validate Settings: MaintainerRetryInterval must be larger than %s", minTimePeriod)
 	}
+	if s.EventLifespan <= minTimePeriod {
+		return fmt.Errorf("validate Settings: EventLifespan must be larger than %s", minTimePeriod)
+	}
+	if s.UpgradeCampaignInterval <= minTimePeriod {
+		return fmt.Errorf("validate Settings: UpgradeCampaignInterval must be larger than %s", minTimePeriod)
+	}
 	if s.DefaultPoolSize <= 0 {
 		return fmt.Errorf("validate Settings: DefaultPoolSize must be larger than 0")
 	}
 	if s.DefaultCascadePollingPoolSize <= 0 {
 		return fmt.Errorf("validate Settings: DefaultCascadePollingPoolSize must be larger than 0")
 	}
+	if s.DefaultTenantShare <= 0 || s.DefaultTenantShare > 1 {
+		return fmt.Errorf("validate Settings: DefaultTenantShare must be larger than 0 and at most 1")
+	}
+	if s.JobQueueEnabled {
+		if s.JobPollInterval <= minTimePeriod {
+			return fmt.Errorf("validate Settings: JobPollInterval must be larger than %s", minTimePeriod)
+		}
+		if s.JobHeartbeatInterval <= minTimePeriod {
+			return fmt.Errorf("validate Settings: JobHeartbeatInterval must be larger than %s", minTimePeriod)
+		}
+		if s.JobLeaseDuration <= s.JobHeartbeatInterval {
+			return fmt.Errorf("validate Settings: JobLeaseDuration must be larger than JobHeartbeatInterval")
+		}
+		if s.JobMaxAttempts <= 0 {
+			return fmt.Errorf("validate Settings: JobMaxAttempts must be larger than 0")
+		}
+		if s.JobWorkerShare <= 0 || s.JobWorkerShare > 1 {
+			return fmt.Errorf("validate Settings: JobWorkerShare must be larger than 0 and at most 1")
+		}
+	}
 	for _, pool := range s.Pools {
 		if err := pool.Validate(); err != nil {
 			return err
 		}
 	}
+	if s.ReschedulingInterval <= minTimePeriod {
+		return fmt.Errorf("validate Settings: ReschedulingInterval must be larger than %s", minTimePeriod)
+	}
+	if err := s.DefaultRetryPolicy.Validate(); err != nil {
+		return err
+	}
+	for _, policy := range s.RetryPolicies {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, window := range s.MaintenanceWindows {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(s.Hooks) != 0 {
+		if !s.JobQueueEnabled {
+			return fmt.Errorf("validate Settings: Hooks require the job queue to be enabled")
+		}
+		if s.HookRetryInterval <= minTimePeriod {
+			return fmt.Errorf("validate Settings: HookRetryInterval must be larger than %s", minTimePeriod)
+		}
+		if s.ApprovalTimeout <= s.HookRetryInterval {
+			return fmt.Errorf("validate Settings: ApprovalTimeout must be larger than HookRetryInterval")
+		}
+	}
+	for _, hook := range s.Hooks {
+		if err := hook.Validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// TenantLimit returns the number of queued operations of a single tenant which the workers of the pools of the
+// resource on all instances can execute at a time
+func (s *Settings) TenantLimit(resourceType types.ObjectType, poolSize int) int {
+	share := s.DefaultTenantShare
+	for _, pool := range s.Pools {
+		if pool.Resource == resourceType.String() && pool.TenantShare > 0 {
+			share = pool.TenantShare
+			break
+		}
+	}
+	return int(math.Max(1, math.Floor(share*float64(poolSize))))
+}
+
+// JobWorkerLimit returns the number of workers of a pool which can execute queued jobs at a time
+func (s *Settings) JobWorkerLimit(poolSize int) int {
+	return int(math.Max(1, math.Floor(s.JobWorkerShare*float64(poolSize))))
+}
+
 // PoolSettings defines the settings for a worker pool
 type PoolSettings struct {
-	Resource string `mapstructure:"resource" description:"name of the resource for which a worker pool is created"`
-	Size     int    `mapstructure:"size" description:"size of the worker pool"`
+	Resource    string  `mapstructure:"resource" description:"name of the resource for which a worker pool is created"`
+	Size        int     `mapstructure:"size" description:"size of the worker pool"`
+	TenantShare float64 `mapstructure:"tenant_share" description:"fraction of the size of the pool which is the number of queued operations of a single tenant executed at a time across all instances"`
 }
 
 // Validate validates the Pool settings
@@ -113,6 +256,250 @@ func (ps *PoolSettings) Validate() error {
 	if ps.Size <= 0 {
 		return fmt.Errorf("validate Settings: Pool size for resource '%s' must be larger than 0", ps.Resource)
 	}
+	if ps.TenantShare < 0 || ps.TenantShare > 1 {
+		return fmt.Errorf("validate Settings: Pool tenant share for resource '%s' must be between 0 and 1", ps.Resource)
+	}
 
 	return nil
 }
+
+// PrioritySettings defines the priorities of queued operations. The queued operations of a tenant are executed in the
+// order of their priorities, as are the operations of different tenants which are next in turn.
+type PrioritySettings struct {
+	Create         int `mapstructure:"create" description:"priority of create operations"`
+	Update         int `mapstructure:"update" description:"priority of update operations"`
+	Delete         int `mapstructure:"delete" description:"priority of delete operations"`
+	Reconciliation int `mapstructure:"reconciliation" description:"priority of rescheduled operations and of orphan mitigation"`
+}
+
+// Priority returns the priority of the operation
+func (ps *PrioritySettings) Priority(operation *types.Operation) int {
+	if operation.Reschedule || operation.InOrphanMitigationState() {
+		return ps.Reconciliation
+	}
+	switch operation.Type {
+	case types.CREATE:
+		return ps.Create
+	case types.UPDATE:
+		return ps.Update
+	default:
+		return ps.Delete
+	}
+}
+
+// ApplyReschedulingInterval sets the initial backoff of the default retry policy to the deprecated rescheduling
+// interval if the policy does not set it. The max backoff of the policy is raised to the interval if it is smaller.
+func (s *Settings) ApplyReschedulingInterval() {
+	if s.DefaultRetryPolicy.InitialBackoff != 0 {
+		return
+	}
+	s.DefaultRetryPolicy.InitialBackoff = s.ReschedulingInterval
+	if s.DefaultRetryPolicy.MaxBackoff < s.ReschedulingInterval {
+		s.DefaultRetryPolicy.MaxBackoff = s.ReschedulingInterval
+	}
+}
+
+// RetryPolicy returns the retry policy of the resource or the default retry policy if the resource has none
+func (s *Settings) RetryPolicy(resourceType types.ObjectType) *RetryPolicySettings {
+	for i := range s.RetryPolicies {
+		if s.RetryPolicies[i].Resource == resourceType.String() {
+			return &s.RetryPolicies[i]
+		}
+	}
+	return &s.DefaultRetryPolicy
+}
+
+// RetryPolicySettings defines how failed operations of a resource are retried automatically. Actions which failed with
+// a transient error are retried up to MaxActionRetries times, the orphan mitigation of failed operations up to
+// MaxAttempts times. The delay before a retry grows exponentially from InitialBackoff up to MaxBackoff and is
+// randomized by Jitter.
+type RetryPolicySettings struct {
+	Resource         string        `mapstructure:"resource" description:"name of the resource for which the retry policy is defined"`
+	MaxAttempts      int           `mapstructure:"max_attempts" description:"the maximum number of automatic retry attempts of the orphan mitigation of a failed operation, 0 retries until the reconciliation operation timeout is reached"`
+	MaxActionRetries int           `mapstructure:"max_action_retries" description:"the maximum number of automatic retries of an asynchronous action which failed with a transient error, 0 does not retry failed actions"`
+	InitialBackoff   time.Duration `mapstructure:"initial_backoff" description:"the delay before the first retry"`
+	MaxBackoff       time.Duration `mapstructure:"max_backoff" description:"the maximum delay between retries"`
+	Multiplier       float64       `mapstructure:"multiplier" description:"the factor by which the delay grows with each retry"`
+	Jitter           float64       `mapstructure:"jitter" description:"the fraction of the delay by which it is randomly increased or decreased"`
+}
+
+// Validate validates the retry policy settings
+func (rp *RetryPolicySettings) Validate() error {
+	if rp.MaxAttempts < 0 {
+		return fmt.Errorf("validate Settings: MaxAttempts of retry policy for resource '%s' must not be negative", rp.Resource)
+	}
+	if rp.MaxActionRetries < 0 {
+		return fmt.Errorf("validate Settings: MaxActionRetries of retry policy for resource '%s' must not be negative", rp.Resource)
+	}
+	if rp.InitialBackoff <= minTimePeriod {
+		return fmt.Errorf("validate Settings: InitialBackoff of retry policy for resource '%s' must be larger than %s", rp.Resource, minTimePeriod)
+	}
+	if rp.MaxBackoff < rp.InitialBackoff {
+		return fmt.Errorf("validate Settings: MaxBackoff of retry policy for resource '%s' must not be smaller than InitialBackoff", rp.Resource)
+	}
+	if rp.Multiplier < 1 {
+		return fmt.Errorf("validate Settings: Multiplier of retry policy for resource '%s' must be at least 1", rp.Resource)
+	}
+	if rp.Jitter < 0 || rp.Jitter > 1 {
+		return fmt.Errorf("validate Settings: Jitter of retry policy for resource '%s' must be between 0 and 1", rp.Resource)
+	}
+	return nil
+}
+
+// AttemptsExceeded reports whether the given retry exceeds the maximum attempts of the retry policy
+func (rp *RetryPolicySettings) AttemptsExceeded(retry int) bool {
+	return rp.MaxAttempts > 0 && retry > rp.MaxAttempts
+}
+
+// Backoff returns the delay before the given retry, starting with retry 1
+func (rp *RetryPolicySettings) Backoff(retry int) time.Duration {
+	backoff := float64(rp.InitialBackoff)
+	for i := 1; i < retry && backoff < float64(rp.MaxBackoff); i++ {
+		backoff *= rp.Multiplier
+	}
+	backoff = math.Min(backoff, float64(rp.MaxBackoff))
+	if rp.Jitter > 0 {
+		backoff += backoff * rp.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+// NextMaintenanceWindow returns the start of the next maintenance window of the tenant or, if the tenant has no
+// maintenance windows, of the platform. The zero time is returned if no maintenance windows apply or if one of them
+// is open at the given time.
+func (s *Settings) NextMaintenanceWindow(platformID, tenant string, now time.Time) time.Time {
+	var windows []MaintenanceWindowSettings
+	for _, window := range s.MaintenanceWindows {
+		if tenant != "" && window.Tenant == tenant {
+			windows = append(windows, window)
+		}
+	}
+	if len(windows) == 0 {
+		for _, window := range s.MaintenanceWindows {
+			if window.Tenant == "" && window.PlatformID == platformID {
+				windows = append(windows, window)
+			}
+		}
+	}
+
+	var next time.Time
+	for _, window := range windows {
+		if window.isOpen(now) {
+			return time.Time{}
+		}
+		if start := window.nextStart(now); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next
+}
+
+// MaintenanceWindowSettings defines a weekly window of a platform or of a tenant during which the plans of their
+// instances are upgraded. Updates of the maintenance info of instances requested outside of the window are deferred
+// until the window opens.
+type MaintenanceWindowSettings struct {
+	PlatformID string        `mapstructure:"platform_id" description:"the id of the platform to whose instances the window applies"`
+	Tenant     string        `mapstructure:"tenant" description:"the tenant to whose instances the window applies"`
+	Days       []string      `mapstructure:"days" description:"the days of the week on which the window opens, e.g. saturday"`
+	Start      string        `mapstructure:"start" description:"the UTC time of day at which the window opens in HH:MM format"`
+	Duration   time.Duration `mapstructure:"duration" description:"the time for which the window stays open"`
+}
+
+// Validate validates the maintenance window settings
+func (mw *MaintenanceWindowSettings) Validate() error {
+	if (mw.PlatformID == "") == (mw.Tenant == "") {
+		return fmt.Errorf("validate Settings: Maintenance window must apply to either a platform or a tenant")
+	}
+	if len(mw.Days) == 0 {
+		return fmt.Errorf("validate Settings: Days of maintenance window must not be empty")
+	}
+	for _, day := range mw.Days {
+		if _, found := weekdays[strings.ToLower(day)]; !found {
+			return fmt.Errorf("validate Settings: Day '%s' of maintenance window is not a day of the week", day)
+		}
+	}
+	if _, err := time.Parse(maintenanceWindowStartLayout, mw.Start); err != nil {
+		return fmt.Errorf("validate Settings: Start of maintenance window must be a time of day in HH:MM format")
+	}
+	if mw.Duration <= minTimePeriod || mw.Duration > 7*24*time.Hour {
+		return fmt.Errorf("validate Settings: Duration of maintenance window must be larger than %s and at most a week", minTimePeriod)
+	}
+	return nil
+}
+
+// starts returns the times at which the window opens during the week before and the week after the given time
+func (mw *MaintenanceWindowSettings) starts(now time.Time) []time.Time {
+	start, err := time.Parse(maintenanceWindowStartLayout, mw.Start)
+	if err != nil {
+		return nil
+	}
+	now = now.UTC()
+	var starts []time.Time
+	for offset := -7; offset <= 7; offset++ {
+		day := now.AddDate(0, 0, offset)
+		for _, weekday := range mw.Days {
+			if weekdays[strings.ToLower(weekday)] == day.Weekday() {
+				starts = append(starts, time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC))
+			}
+		}
+	}
+	return starts
+}
+
+func (mw *MaintenanceWindowSettings) isOpen(now time.Time) bool {
+	for _, start := range mw.starts(now) {
+		if !now.Before(start) && now.Before(start.Add(mw.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mw *MaintenanceWindowSettings) nextStart(now time.Time) time.Time {
+	var next time.Time
+	for _, start := range mw.starts(now) {
+		if start.After(now) && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next
+}
+
+// HookSettings defines a webhook which is invoked before or after the operations of some resources
+type HookSettings struct {
+	Name           string        `mapstructure:"name" description:"the name of the hook"`
+	URL            string        `mapstructure:"url" description:"the URL to which the operations are posted"`
+	Secret         string        `mapstructure:"secret" description:"the key with which the requests of the hook are signed using HMAC-SHA256"`
+	Phase          string        `mapstructure:"phase" description:"whether the hook approves operations before they are started (pre) or is notified once they have finished (post)"`
+	Resources      []string      `mapstructure:"resources" description:"the resources for whose operations the hook is invoked, all if empty"`
+	OperationTypes []string      `mapstructure:"operation_types" description:"the types of operations (create, update, delete) for which the hook is invoked, all if empty"`
+	Timeout        time.Duration `mapstructure:"timeout" description:"timeout of the requests of the hook"`
+}
+
+// Validate validates the hook settings
+func (hs *HookSettings) Validate() error {
+	if hs.Name == "" {
+		return fmt.Errorf("validate Settings: Name of hook must not be empty")
+	}
+	hookURL, err := url.Parse(hs.URL)
+	if err != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") || hookURL.Host == "" {
+		return fmt.Errorf("validate Settings: URL of hook '%s' must be an absolute http(s) URL", hs.Name)
+	}
+	if hs.Secret == "" {
+		return fmt.Errorf("validate Settings: Secret of hook '%s' must not be empty", hs.Name)
+	}
+	if HookPhase(hs.Phase) != PreHookPhase && HookPhase(hs.Phase) != PostHookPhase {
+		return fmt.Errorf("validate Settings: Phase of hook '%s' must be either %s or %s", hs.Name, PreHookPhase, PostHookPhase)
+	}
+	for _, operationType := range hs.OperationTypes {
+		switch types.OperationCategory(operationType) {
+		case types.CREATE, types.UPDATE, types.DELETE:
+		default:
+			return fmt.Errorf("validate Settings: Operation type '%s' of hook '%s' must be one of create, update or delete", operationType, hs.Name)
+		}
+	}
+	if hs.Timeout < 0 {
+		return fmt.Errorf("validate Settings: Timeout of hook '%s' must not be negative", hs.Name)
+	}
+	return nil
+}
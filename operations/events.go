@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// NewOperationEvent creates an event in the history of the resource of the operation. The labels of the operation
+// are copied to the event so that the history stays scoped to the tenant of the resource after it is deleted.
+func NewOperationEvent(operation *types.Operation, eventType types.OperationEventKind, description string) (*types.OperationEvent, error) {
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := types.Labels{}
+	for key, values := range operation.GetLabels() {
+		labels[key] = append([]string{}, values...)
+	}
+
+	now := time.Now()
+	return &types.OperationEvent{
+		Base: types.Base{
+			ID:        UUID.String(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Labels:    labels,
+			Ready:     true,
+		},
+		Type:          eventType,
+		Description:   description,
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		State:         operation.State,
+		ResourceID:    operation.ResourceID,
+		ResourceType:  operation.ResourceType,
+		CorrelationID: operation.CorrelationID,
+	}, nil
+}
+
+// RecordEvent appends an event for the operation to the history of its resource
+func RecordEvent(ctx context.Context, repository storage.Repository, operation *types.Operation, eventType types.OperationEventKind, description string) error {
+	event, err := NewOperationEvent(operation, eventType, description)
+	if err != nil {
+		return err
+	}
+	if _, err := repository.Create(ctx, event); err != nil {
+		return util.HandleStorageError(err, types.OperationEventType.String())
+	}
+
+	return nil
+}
+
+// eventCleanupBatchSize is the maximum number of operation events deleted by a single statement, so that the cleanup
+// of a large backlog does not hold locks on the events table for long
+const eventCleanupBatchSize = 1000
+
+// cleanupOperationEvents deletes the operation events which are older than the configured event lifespan in batches
+// of the oldest events
+func (om *Maintainer) cleanupOperationEvents(ctx context.Context) error {
+	olderThanLifespan := query.ByField(query.LessThanOperator, "created_at", util.ToRFCNanoFormat(time.Now().Add(-om.settings.EventLifespan)))
+
+	for ctx.Err() == nil {
+		events, err := om.repository.ListNoLabels(ctx, types.OperationEventType,
+			olderThanLifespan,
+			query.OrderResultBy("paging_sequence", query.AscOrder),
+			query.LimitResultBy(eventCleanupBatchSize))
+		if err != nil {
+			return fmt.Errorf("failed to fetch operation events to cleanup: %s", err)
+		}
+		if events.Len() == 0 {
+			break
+		}
+
+		lastPagingSequence := strconv.FormatInt(events.ItemAt(events.Len()-1).GetPagingSequence(), 10)
+		byPagingSequence := query.ByField(query.LessThanOrEqualOperator, "paging_sequence", lastPagingSequence)
+		if err := om.repository.Delete(ctx, types.OperationEventType, olderThanLifespan, byPagingSequence); err != nil && err != util.ErrNotFoundInStorage {
+			return fmt.Errorf("failed to cleanup operation events: %s", err)
+		}
+		if events.Len() < eventCleanupBatchSize {
+			break
+		}
+	}
+	log.C(ctx).Debug("Finished cleaning up operation events")
+	return nil
+}
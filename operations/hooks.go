@@ -0,0 +1,277 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// HookPhase is the phase of an operation in which a hook is invoked
+type HookPhase string
+
+const (
+	// PreHookPhase hooks are invoked before an operation is started and have to approve it
+	PreHookPhase HookPhase = "pre"
+
+	// PostHookPhase hooks are notified once an operation has finished
+	PostHookPhase HookPhase = "post"
+)
+
+// HookVerdict is the decision of a pre hook on an operation
+type HookVerdict string
+
+const (
+	// HookApproved lets the operation start
+	HookApproved HookVerdict = "approved"
+
+	// HookPending defers the operation until the hook is asked again
+	HookPending HookVerdict = "pending"
+
+	// HookRejected fails the operation without starting it
+	HookRejected HookVerdict = "rejected"
+)
+
+// HookRequest is passed to the hooks of an operation
+type HookRequest struct {
+	Phase     HookPhase        `json:"phase"`
+	Operation *types.Operation `json:"operation"`
+	// Resource is the resource of the operation as requested or as stored, without parameters and credentials
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// HookResult is the outcome of the invocation of a hook. The verdict of post hooks is ignored.
+type HookResult struct {
+	Verdict     HookVerdict `json:"verdict"`
+	Description string      `json:"description,omitempty"`
+}
+
+// OperationHook is an extension point for external workflow engines. Pre hooks must approve an operation before it
+// moves from pending to in progress, e.g. to require the approval of a ticket. While a pre hook defers an operation,
+// the operation stays pending and its queued job is retried, so that waiting for the approval survives restarts.
+// Post hooks are notified once an operation has finished.
+type OperationHook interface {
+	// Name returns the name of the hook
+	Name() string
+	// Phase returns the phase of the operations in which the hook is invoked
+	Phase() HookPhase
+	// Matches reports whether the hook is invoked for the operation
+	Matches(operation *types.Operation) bool
+	// Invoke invokes the hook. Pre hooks which cannot be invoked defer the operation.
+	Invoke(ctx context.Context, request *HookRequest) (*HookResult, error)
+}
+
+// Hooks holds the operation hooks which are invoked by the schedulers they are attached to
+type Hooks struct {
+	mutex sync.RWMutex
+	hooks []OperationHook
+}
+
+// NewHooks creates the webhooks configured in the operation settings
+func NewHooks(settings *Settings) *Hooks {
+	hooks := &Hooks{}
+	for _, hookSettings := range settings.Hooks {
+		hooks.Register(NewWebhook(hookSettings))
+	}
+	return hooks
+}
+
+// Register adds hooks which are invoked for the operations they match in the order in which they are registered
+func (h *Hooks) Register(hooks ...OperationHook) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.hooks = append(h.hooks, hooks...)
+}
+
+// Attach makes the scheduler invoke the hooks for its operations
+func (h *Hooks) Attach(scheduler *Scheduler) {
+	scheduler.hooks = h
+}
+
+func (h *Hooks) matching(phase HookPhase, operation *types.Operation) []OperationHook {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	var hooks []OperationHook
+	for _, hook := range h.hooks {
+		if hook.Phase() == phase && hook.Matches(operation) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// isNewOperation reports whether the operation is requested by a client rather than being a reschedule, an orphan
+// mitigation or part of a cascade, which are not subject to approval
+func isNewOperation(operation *types.Operation) bool {
+	return !operation.Reschedule && !operation.InOrphanMitigationState() && operation.CascadeRootID == ""
+}
+
+// approveOperation invokes the pre hooks of the operation one after another. It returns the name and the result of the
+// first hook which does not approve the operation, or an approval if all hooks approve it.
+func (s *Scheduler) approveOperation(ctx context.Context, operation *types.Operation, item *storage.BatchItem) (string, *HookResult) {
+	hooks := s.hooks.matching(PreHookPhase, operation)
+	if len(hooks) == 0 {
+		return "", &HookResult{Verdict: HookApproved}
+	}
+
+	request := &HookRequest{
+		Phase:     PreHookPhase,
+		Operation: operation,
+		Resource:  hookResource(ctx, s.repository, operation, item),
+	}
+	for _, hook := range hooks {
+		result, err := hook.Invoke(ctx, request)
+		if err != nil {
+			result = &HookResult{
+				Verdict:     HookPending,
+				Description: fmt.Sprintf("hook could not be invoked: %s", err),
+			}
+		}
+		log.C(ctx).Infof("Hook %s returned %s for %s operation with id %s: %s", hook.Name(), result.Verdict, operation.Type, operation.ID, result.Description)
+
+		// the operation is deferred again each time the hook is asked, which is recorded only once
+		if result.Verdict != HookPending || !operation.AwaitsApproval() {
+			description := fmt.Sprintf("pre hook %s returned %s", hook.Name(), result.Verdict)
+			if result.Description != "" {
+				description = fmt.Sprintf("%s: %s", description, result.Description)
+			}
+			if err := RecordEvent(ctx, s.repository, operation, types.OperationHookEvent, description); err != nil {
+				log.C(ctx).Warnf("Could not record hook event of operation with id %s: %s", operation.ID, err)
+			}
+		}
+
+		if result.Verdict != HookApproved {
+			return hook.Name(), result
+		}
+	}
+	return "", &HookResult{Verdict: HookApproved}
+}
+
+// awaitApproval stores the operation as pending and queues its storage item, so that its pre hooks are asked again
+// once the hook retry interval has passed
+func (s *Scheduler) awaitApproval(ctx context.Context, operation *types.Operation, item *storage.BatchItem, hook string) error {
+	if item == nil || s.jobQueue == nil {
+		return &util.HTTPError{
+			ErrorType:   "OperationRequiresApproval",
+			Description: fmt.Sprintf("operation requires the approval of hook %s which can only be awaited for queued operations", hook),
+			StatusCode:  http.StatusUnprocessableEntity,
+		}
+	}
+
+	operation.State = types.PENDING
+	operation.Reschedule = true
+	operation.RescheduleTimestamp = time.Now().UTC()
+	if operation.Context == nil {
+		operation.Context = &types.OperationContext{}
+	}
+	operation.Context.Async = true
+	log.C(ctx).Infof("%s operation with id %s awaits the approval of hook %s", operation.Type, operation.ID, hook)
+	return s.enqueueStorageItem(ctx, operation, item, time.Now().Add(s.retryPolicies.HookRetryInterval))
+}
+
+// notifyPostHooks notifies the post hooks of the operation once it has finished. The hooks are invoked in the
+// background and their failures are only logged.
+func (s *Scheduler) notifyPostHooks(ctx context.Context, operation *types.Operation) {
+	if s.hooks == nil {
+		return
+	}
+	if operation.State != types.SUCCEEDED && operation.State != types.FAILED && operation.State != types.CANCELED {
+		return
+	}
+	hooks := s.hooks.matching(PostHookPhase, operation)
+	if len(hooks) == 0 {
+		return
+	}
+
+	finishedOperation := *operation
+	hookCtx := log.ContextWithLogger(s.smCtx, log.C(ctx))
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		request := &HookRequest{
+			Phase:     PostHookPhase,
+			Operation: &finishedOperation,
+			Resource:  hookResource(hookCtx, s.repository, &finishedOperation, nil),
+		}
+		for _, hook := range hooks {
+			if _, err := hook.Invoke(hookCtx, request); err != nil {
+				log.C(hookCtx).Warnf("Could not notify hook %s of %s operation with id %s: %s", hook.Name(), finishedOperation.Type, finishedOperation.ID, err)
+				description := fmt.Sprintf("post hook %s could not be notified: %s", hook.Name(), err)
+				if err := RecordEvent(hookCtx, s.repository, &finishedOperation, types.OperationHookEvent, description); err != nil {
+					log.C(hookCtx).Warnf("Could not record hook event of operation with id %s: %s", finishedOperation.ID, err)
+				}
+			}
+		}
+	}()
+}
+
+// hookResource returns the requested object of the storage item or the stored resource of the operation without
+// its parameters and credentials
+func hookResource(ctx context.Context, repository storage.Repository, operation *types.Operation, item *storage.BatchItem) json.RawMessage {
+	var object types.Object
+	if item != nil && item.Object != nil {
+		object = item.Object
+	} else {
+		byID := query.ByField(query.EqualsOperator, "id", operation.ResourceID)
+		storedObject, err := repository.Get(ctx, operation.ResourceType, byID)
+		if err != nil {
+			if err != util.ErrNotFoundInStorage {
+				log.C(ctx).Warnf("Could not fetch %s with id %s for hooks: %s", operation.ResourceType, operation.ResourceID, err)
+			}
+			return nil
+		}
+		object = storedObject
+	}
+
+	bytes, err := json.Marshal(object)
+	if err != nil {
+		return nil
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(bytes, &fields); err != nil {
+		return nil
+	}
+	delete(fields, "parameters")
+	delete(fields, "credentials")
+	resource, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return resource
+}
+
+func operationRejectedError(hook string, result *HookResult) error {
+	description := fmt.Sprintf("operation was rejected by hook %s", hook)
+	if result.Description != "" {
+		description = fmt.Sprintf("%s: %s", description, result.Description)
+	}
+	return &util.HTTPError{
+		ErrorType:   "OperationRejected",
+		Description: description,
+		StatusCode:  http.StatusForbidden,
+	}
+}
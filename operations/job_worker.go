@@ -0,0 +1,560 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// jobPayload is the serialized storage item of a queued job together with the criteria of the request context in
+// which the job was scheduled, as they scope the interceptors executed with the item, e.g. to a tenant
+type jobPayload struct {
+	Type            types.OperationCategory `json:"type"`
+	Object          json.RawMessage         `json:"object,omitempty"`
+	LabelChanges    types.LabelChanges      `json:"label_changes,omitempty"`
+	Criteria        []query.Criterion       `json:"criteria,omitempty"`
+	Preconditions   []query.Criterion       `json:"preconditions,omitempty"`
+	ContextCriteria []query.Criterion       `json:"context_criteria,omitempty"`
+	// StoredFields are the fields of the object which are stored but are not part of its JSON representation
+	StoredFields *storedFields `json:"stored_fields,omitempty"`
+}
+
+// storedFields are the fields of the objects which are omitted from their JSON representation
+type storedFields struct {
+	UpdateValues   *types.InstanceUpdateValues `json:"update_values,omitempty"`
+	PreviousValues json.RawMessage             `json:"previous_values,omitempty"`
+}
+
+func newStoredFields(object types.Object) *storedFields {
+	instance, ok := object.(*types.ServiceInstance)
+	if !ok {
+		return nil
+	}
+	return &storedFields{
+		UpdateValues:   &instance.UpdateValues,
+		PreviousValues: instance.PreviousValues,
+	}
+}
+
+func (sf *storedFields) restore(object types.Object) {
+	instance, ok := object.(*types.ServiceInstance)
+	if sf == nil || !ok {
+		return
+	}
+	if sf.UpdateValues != nil {
+		instance.UpdateValues = *sf.UpdateValues
+	}
+	instance.PreviousValues = sf.PreviousValues
+}
+
+func newJobPayload(ctx context.Context, item *storage.BatchItem) ([]byte, error) {
+	payload := &jobPayload{
+		Type:            item.Type,
+		LabelChanges:    item.LabelChanges,
+		Criteria:        item.Criteria,
+		Preconditions:   item.Preconditions,
+		ContextCriteria: query.CriteriaForContext(ctx),
+	}
+	if item.Object != nil {
+		object, err := json.Marshal(item.Object)
+		if err != nil {
+			return nil, err
+		}
+		payload.Object = object
+		payload.StoredFields = newStoredFields(item.Object)
+	}
+	return json.Marshal(payload)
+}
+
+func (p *jobPayload) storageItem(resourceType types.ObjectType, blueprint func() types.Object) (*storage.BatchItem, error) {
+	item := &storage.BatchItem{
+		Type:          p.Type,
+		ObjectType:    resourceType,
+		LabelChanges:  p.LabelChanges,
+		Criteria:      p.Criteria,
+		Preconditions: p.Preconditions,
+	}
+	if len(p.Object) != 0 {
+		item.Object = blueprint()
+		if err := json.Unmarshal(p.Object, item.Object); err != nil {
+			return nil, err
+		}
+		p.StoredFields.restore(item.Object)
+	}
+	return item, nil
+}
+
+func storageItemAction(item *storage.BatchItem) storageAction {
+	return func(ctx context.Context, repository storage.Repository) (types.Object, error) {
+		err := item.Execute(ctx, repository)
+		return item.Object, util.HandleStorageError(err, item.ObjectType.String())
+	}
+}
+
+// enqueueStorageItem stores the operation and queues its storage item so that it is executed by the first available
+// worker of any Service Manager instance, but not before the given time if it is not zero
+func (s *Scheduler) enqueueStorageItem(ctx context.Context, operation *types.Operation, item *storage.BatchItem, notBefore time.Time) error {
+	initialLogMessage(ctx, operation, true)
+	if err := s.executeOperationPreconditions(ctx, operation); err != nil {
+		return err
+	}
+
+	payload, err := newJobPayload(ctx, item)
+	if err == nil {
+		err = s.jobQueue.EnqueueJob(ctx, &storage.Job{
+			OperationID:  operation.ID,
+			ResourceType: operation.ResourceType,
+			Tenant:       jobTenant(ctx, item, s.tenantLabelKey),
+			Priority:     s.priorities.Priority(operation),
+			Payload:      payload,
+			NotBefore:    notBefore,
+		})
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to queue %s operation with id %s: %s", operation.Type, operation.ID, err)
+		if opErr := updateOperationState(ctx, s.repository, operation, types.FAILED, err); opErr != nil {
+			log.C(ctx).Errorf("setting new operation state failed: %s", opErr)
+		}
+		return err
+	}
+	log.C(ctx).Infof("Queued %s operation with id %s for %s entity with id %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID)
+
+	// wake up the local worker instead of waiting for its next poll
+	select {
+	case s.jobsAvailable <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// jobTenant returns the tenant of the storage item from the labels of its object or from the criteria of the request
+// context, which are scoped to the tenant for tenant requests
+func jobTenant(ctx context.Context, item *storage.BatchItem, tenantLabelKey string) string {
+	if tenantLabelKey == "" {
+		return ""
+	}
+	if item.Object != nil {
+		if tenants := item.Object.GetLabels()[tenantLabelKey]; len(tenants) != 0 {
+			return tenants[0]
+		}
+	}
+	for _, criterion := range query.CriteriaForContext(ctx) {
+		if criterion.Type == query.LabelQuery && criterion.LeftOp == tenantLabelKey && len(criterion.RightOp) != 0 {
+			return criterion.RightOp[0]
+		}
+	}
+	return ""
+}
+
+// acquireWorkers acquires up to limit of the free workers of the scheduler without blocking
+func (s *Scheduler) acquireWorkers(limit int) int {
+	for acquired := 0; acquired < limit; acquired++ {
+		select {
+		case s.workers <- struct{}{}:
+		default:
+			return acquired
+		}
+	}
+	return limit
+}
+
+func (s *Scheduler) releaseWorkers(count int) {
+	for i := 0; i < count; i++ {
+		<-s.workers
+	}
+}
+
+type jobConsumer struct {
+	scheduler    *Scheduler
+	resourceType types.ObjectType
+	blueprint    func() types.Object
+	// slots bounds the workers of the scheduler which execute queued jobs, so that the other workers remain free for
+	// the actions executed in-process
+	slots chan struct{}
+}
+
+// acquireSlots acquires up to limit of the free job slots of the consumer without blocking
+func (c *jobConsumer) acquireSlots(limit int) int {
+	for acquired := 0; acquired < limit; acquired++ {
+		select {
+		case c.slots <- struct{}{}:
+		default:
+			return acquired
+		}
+	}
+	return limit
+}
+
+func (c *jobConsumer) releaseSlots(count int) {
+	for i := 0; i < count; i++ {
+		<-c.slots
+	}
+}
+
+// JobWorker leases the queued jobs of asynchronous operations and executes them using the schedulers registered for
+// their resource types. While a job is executed its lease is extended periodically, so that the jobs of crashed
+// Service Manager instances are executed by another instance once their leases expire. The jobs are leased round
+// robin across the tenants identified by the tenant label key.
+type JobWorker struct {
+	smCtx          context.Context
+	queue          storage.JobQueue
+	settings       *Settings
+	tenantLabelKey string
+	owner          string
+	wg             *sync.WaitGroup
+	consumers      []*jobConsumer
+}
+
+// NewJobWorker constructs a JobWorker which leases jobs from the given queue
+func NewJobWorker(smCtx context.Context, queue storage.JobQueue, settings *Settings, tenantLabelKey string, wg *sync.WaitGroup) (*JobWorker, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine job worker owner: %s", err)
+	}
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate GUID for job worker: %s", err)
+	}
+
+	return &JobWorker{
+		smCtx:          smCtx,
+		queue:          queue,
+		settings:       settings,
+		tenantLabelKey: tenantLabelKey,
+		owner:          fmt.Sprintf("%s-%s", hostname, UUID.String()),
+		wg:             wg,
+	}, nil
+}
+
+// Register makes the scheduler queue the storage items of its asynchronous operations. The queued jobs of the
+// resource type are executed by the workers of the scheduler once the JobWorker runs.
+func (jw *JobWorker) Register(scheduler *Scheduler, resourceType types.ObjectType, blueprint func() types.Object) {
+	jw.attach(scheduler)
+	scheduler.jobsAvailable = make(chan struct{}, 1)
+	jw.consumers = append(jw.consumers, &jobConsumer{
+		scheduler:    scheduler,
+		resourceType: resourceType,
+		blueprint:    blueprint,
+		slots:        make(chan struct{}, jw.settings.JobWorkerLimit(cap(scheduler.workers))),
+	})
+}
+
+// attach makes the scheduler queue the storage items of its asynchronous operations without consuming any jobs. The
+// jobs are executed by the schedulers registered for their resource types.
+func (jw *JobWorker) attach(scheduler *Scheduler) {
+	scheduler.jobQueue = jw.queue
+	scheduler.tenantLabelKey = jw.tenantLabelKey
+}
+
+// Run starts leasing the jobs of all registered resource types
+func (jw *JobWorker) Run() {
+	for _, consumer := range jw.consumers {
+		go jw.consume(consumer)
+	}
+}
+
+func (jw *JobWorker) consume(consumer *jobConsumer) {
+	ticker := time.NewTicker(jw.settings.JobPollInterval)
+	defer ticker.Stop()
+	for {
+		jw.leaseJobs(consumer)
+		select {
+		case <-ticker.C:
+		case <-consumer.scheduler.jobsAvailable:
+		case <-jw.smCtx.Done():
+			log.C(jw.smCtx).Infof("Server is shutting down. Stopping job worker for %s...", consumer.resourceType)
+			return
+		}
+	}
+}
+
+func (jw *JobWorker) leaseJobs(consumer *jobConsumer) {
+	scheduler := consumer.scheduler
+	slots := consumer.acquireSlots(cap(consumer.slots))
+	workers := scheduler.acquireWorkers(slots)
+	consumer.releaseSlots(slots - workers)
+	if workers == 0 {
+		return
+	}
+
+	tenantLimit := jw.settings.TenantLimit(consumer.resourceType, cap(consumer.slots))
+	jobs, err := jw.queue.LeaseJobs(jw.smCtx, jw.owner, consumer.resourceType, workers, tenantLimit, jw.settings.JobLeaseDuration)
+	if err != nil {
+		log.C(jw.smCtx).Errorf("Failed to lease jobs for %s: %s", consumer.resourceType, err)
+		jobs = nil
+	}
+	scheduler.releaseWorkers(workers - len(jobs))
+	consumer.releaseSlots(workers - len(jobs))
+
+	for _, job := range jobs {
+		jw.execute(consumer, job)
+	}
+}
+
+// execute executes the leased job using an acquired worker of the scheduler of the consumer
+func (jw *JobWorker) execute(consumer *jobConsumer, job *storage.Job) {
+	scheduler := consumer.scheduler
+	ctx := jw.smCtx
+	release := func() {
+		scheduler.releaseWorkers(1)
+		consumer.releaseSlots(1)
+	}
+	done := func() {
+		if err := jw.queue.CompleteJob(jw.smCtx, jw.owner, job.OperationID); err != nil {
+			log.C(ctx).Errorf("Failed to complete job of operation with id %s: %s", job.OperationID, err)
+		}
+		// more jobs may be waiting for the released worker
+		select {
+		case scheduler.jobsAvailable <- struct{}{}:
+		default:
+		}
+	}
+
+	byID := query.ByField(query.EqualsOperator, "id", job.OperationID)
+	operationObject, err := scheduler.repository.Get(ctx, types.OperationType, byID)
+	if err != nil {
+		release()
+		if err == util.ErrNotFoundInStorage {
+			done()
+			return
+		}
+		// the job will be leased again once its lease expires
+		log.C(ctx).Errorf("Failed to fetch operation with id %s of leased job: %s", job.OperationID, err)
+		return
+	}
+	operation := operationObject.(*types.Operation)
+	ctx = log.ContextWithLogger(ctx, log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID))
+	if operation.IsScheduled() || operation.AwaitsApproval() {
+		approved, err := jw.approve(ctx, consumer, job, operation)
+		if err != nil {
+			log.C(ctx).Errorf("Failed to start pending %s operation with id %s: %s", operation.Type, operation.ID, err)
+			if failed, opErr := failPendingOperation(ctx, scheduler.repository, operation, err); opErr != nil {
+				log.C(ctx).Errorf("setting new operation state failed: %s", opErr)
+			} else if failed {
+				scheduler.notifyPostHooks(ctx, operation)
+			}
+			release()
+			done()
+			return
+		}
+		if !approved {
+			release()
+			return
+		}
+		startedOperation, err := startPendingOperation(ctx, scheduler.repository, operation)
+		if err != nil {
+			// the job will be leased again once its lease expires
+			log.C(ctx).Errorf("Failed to start pending operation with id %s of leased job: %s", job.OperationID, err)
+			release()
+			return
+		}
+		if startedOperation != nil {
+			operation = startedOperation
+		}
+	}
+	if operation.State != types.IN_PROGRESS {
+		log.C(ctx).Infof("Dropping job of %s operation with id %s as the operation is %s", operation.Type, operation.ID, operation.State)
+		release()
+		done()
+		return
+	}
+
+	ctx, action, err := jw.jobAction(ctx, consumer, job)
+	if err == nil && job.Attempts > jw.settings.JobMaxAttempts {
+		err = &util.HTTPError{
+			ErrorType:   "InternalServerError",
+			Description: fmt.Sprintf("job was abandoned after %d attempts", jw.settings.JobMaxAttempts),
+			StatusCode:  http.StatusInternalServerError,
+		}
+	}
+	if err != nil {
+		log.C(ctx).Errorf("Failed to execute job of %s operation with id %s: %s", operation.Type, operation.ID, err)
+		if opErr := updateOperationState(ctx, scheduler.repository, operation, types.FAILED, err); opErr != nil {
+			log.C(ctx).Errorf("setting new operation state failed: %s", opErr)
+		}
+		release()
+		done()
+		return
+	}
+
+	// the lease is renewed before the action starts, as it may have expired while the operation awaited its approval
+	leaseExpiresAt, err := jw.extendLease(job)
+	if err != nil {
+		// the job will be leased again once its lease expires, unless it is already leased by another worker
+		log.C(ctx).Warnf("Not executing job of %s operation with id %s as its lease could not be renewed: %s", operation.Type, operation.ID, err)
+		release()
+		return
+	}
+
+	log.C(ctx).Infof("Executing job of %s operation with id %s for %s entity with id %s (attempt %d)", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID, job.Attempts)
+	stopHeartbeat := jw.heartbeat(ctx, job, leaseExpiresAt)
+	scheduler.executeAsync(ctx, operation, action, func() {
+		stopHeartbeat()
+		// the worker is released by the scheduler
+		consumer.releaseSlots(1)
+		done()
+	})
+}
+
+// approve asks the pre hooks of the pending operation of the job whether the operation can be started. If a hook
+// defers the operation, the job is queued again for the next hook retry interval and false is returned. An error is
+// returned if a hook rejects the operation or if the operation awaits approval for longer than the approval timeout.
+func (jw *JobWorker) approve(ctx context.Context, consumer *jobConsumer, job *storage.Job, operation *types.Operation) (bool, error) {
+	scheduler := consumer.scheduler
+	if scheduler.hooks == nil {
+		operation.Reschedule = false
+		operation.RescheduleTimestamp = time.Time{}
+		return true, nil
+	}
+
+	// hooks are passed the stored resource if the storage item of the job can not be decoded
+	_, item, err := jw.jobItem(ctx, consumer, job)
+	if err != nil {
+		item = nil
+	}
+	hook, result := scheduler.approveOperation(ctx, operation, item)
+	switch result.Verdict {
+	case HookApproved:
+		operation.Reschedule = false
+		operation.RescheduleTimestamp = time.Time{}
+		return true, nil
+	case HookRejected:
+		operation.Reschedule = false
+		operation.RescheduleTimestamp = time.Time{}
+		return false, operationRejectedError(hook, result)
+	}
+
+	if operation.AwaitsApproval() && time.Since(operation.RescheduleTimestamp) > jw.settings.ApprovalTimeout {
+		operation.Reschedule = false
+		operation.RescheduleTimestamp = time.Time{}
+		return false, &util.HTTPError{
+			ErrorType:   "ApprovalTimeout",
+			Description: fmt.Sprintf("operation was not approved by hook %s within %s", hook, jw.settings.ApprovalTimeout),
+			StatusCode:  http.StatusUnprocessableEntity,
+		}
+	}
+	if !operation.AwaitsApproval() {
+		// a scheduled operation which is due awaits the approval from now on
+		operation.Reschedule = true
+		operation.RescheduleTimestamp = time.Now().UTC()
+		byState := query.ByField(query.EqualsOperator, "state", string(types.PENDING))
+		if _, err := scheduler.repository.Update(ctx, operation, types.LabelChanges{}, byState); err != nil {
+			// the job will be leased again once its lease expires
+			log.C(ctx).Errorf("Failed to store that operation with id %s awaits approval: %s", operation.ID, err)
+			return false, nil
+		}
+	}
+
+	job.NotBefore = time.Now().Add(jw.settings.HookRetryInterval)
+	if err := jw.queue.EnqueueJob(jw.smCtx, job); err != nil {
+		// the job will be leased again once its lease expires
+		log.C(ctx).Errorf("Failed to queue job of operation with id %s awaiting approval: %s", operation.ID, err)
+	}
+	return false, nil
+}
+
+// jobItem restores the storage item of the job together with the criteria of the context in which it was scheduled
+func (jw *JobWorker) jobItem(ctx context.Context, consumer *jobConsumer, job *storage.Job) (context.Context, *storage.BatchItem, error) {
+	payload := &jobPayload{}
+	if err := json.Unmarshal(job.Payload, payload); err != nil {
+		return ctx, nil, fmt.Errorf("could not decode job payload: %s", err)
+	}
+	item, err := payload.storageItem(consumer.resourceType, consumer.blueprint)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("could not decode %s of job payload: %s", consumer.resourceType, err)
+	}
+	ctxWithCriteria, err := query.ContextWithCriteria(ctx, payload.ContextCriteria...)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctxWithCriteria, item, nil
+}
+
+// jobAction restores the storage item of the job as the action of its operation
+func (jw *JobWorker) jobAction(ctx context.Context, consumer *jobConsumer, job *storage.Job) (context.Context, storageAction, error) {
+	ctx, item, err := jw.jobItem(ctx, consumer, job)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, storageItemAction(item), nil
+}
+
+// extendLease renews the lease on the job and returns the time until which it is held at least
+func (jw *JobWorker) extendLease(job *storage.Job) (time.Time, error) {
+	// the local expiry is computed from the time before the request so that it never exceeds the expiry in the storage
+	expiresAt := time.Now().Add(jw.settings.JobLeaseDuration)
+	if err := jw.queue.ExtendLease(jw.smCtx, jw.owner, job.OperationID, jw.settings.JobLeaseDuration); err != nil {
+		return time.Time{}, err
+	}
+	return expiresAt, nil
+}
+
+// heartbeat extends the lease of the job until the returned function is called. Once the lease is lost, either
+// because another worker holds it or because it could not be renewed before it expired, the action of the operation
+// of the job is abandoned, as another worker may execute it again.
+func (jw *JobWorker) heartbeat(ctx context.Context, job *storage.Job, leaseExpiresAt time.Time) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jw.settings.JobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			expiry := time.NewTimer(time.Until(leaseExpiresAt))
+			select {
+			case <-ticker.C:
+				expiresAt, err := jw.extendLease(job)
+				if err == nil {
+					leaseExpiresAt = expiresAt
+				} else if err == util.ErrNotFoundInStorage {
+					log.C(ctx).Warnf("Lost lease on job of operation with id %s during its action, abandoning the action", job.OperationID)
+					abandonAction(job.OperationID)
+					expiry.Stop()
+					return
+				} else {
+					log.C(ctx).Warnf("Failed to extend lease on job of operation with id %s: %s", job.OperationID, err)
+				}
+			case <-expiry.C:
+				log.C(ctx).Warnf("Lease on job of operation with id %s expired during its action, abandoning the action", job.OperationID)
+				abandonAction(job.OperationID)
+				return
+			case <-stop:
+				expiry.Stop()
+				return
+			case <-jw.smCtx.Done():
+				expiry.Stop()
+				return
+			}
+			expiry.Stop()
+		}
+	}()
+	return func() {
+		close(stop)
+	}
+}
@@ -19,6 +19,7 @@ package operations
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -30,15 +31,14 @@ import (
 )
 
 const (
-	initialOperationsLockIndex = 200
-	ZeroTime                   = "0001-01-01 00:00:00+00"
+	ZeroTime = "0001-01-01 00:00:00+00"
 )
 
 // maintainerFunctor represents a named maintainer function which runs over a pre-defined period
 type maintainerFunctor struct {
 	name     string
 	interval time.Duration
-	execute  func()
+	execute  func(ctx context.Context) error
 }
 
 // Maintainer ensures that operations old enough are deleted
@@ -51,11 +51,11 @@ type Maintainer struct {
 	settings                *Settings
 	wg                      *sync.WaitGroup
 	functors                []maintainerFunctor
-	operationLockers        map[string]storage.Locker
+	elector                 *storage.LeaderElector
 }
 
 // NewMaintainer constructs a Maintainer
-func NewMaintainer(smCtx context.Context, repository storage.TransactionalRepository, lockerCreatorFunc storage.LockerCreatorFunc, options *Settings, wg *sync.WaitGroup) *Maintainer {
+func NewMaintainer(smCtx context.Context, repository storage.TransactionalRepository, elector *storage.LeaderElector, options *Settings, wg *sync.WaitGroup) *Maintainer {
 	maintainer := &Maintainer{
 		smCtx:                   smCtx,
 		repository:              repository,
@@ -63,6 +63,7 @@ func NewMaintainer(smCtx context.Context, repository storage.TransactionalReposi
 		cascadePollingScheduler: NewScheduler(smCtx, repository, options, options.DefaultCascadePollingPoolSize, wg),
 		settings:                options,
 		wg:                      wg,
+		elector:                 elector,
 	}
 
 	maintainer.functors = []maintainerFunctor{
@@ -111,40 +112,50 @@ func NewMaintainer(smCtx context.Context, repository storage.TransactionalReposi
 			execute:  maintainer.rescheduleOrphanMitigationOperations,
 			interval: options.MaintainerRetryInterval,
 		},
+		{
+			name:     "executeDueScheduledOperations",
+			execute:  maintainer.executeDueScheduledOperations,
+			interval: options.MaintainerRetryInterval,
+		},
+		{
+			name:     "markMissedScheduledOperationsFailed",
+			execute:  maintainer.markMissedScheduledOperationsFailed,
+			interval: options.MaintainerRetryInterval,
+		},
+		{
+			name:     "driveUpgradeCampaigns",
+			execute:  maintainer.driveUpgradeCampaigns,
+			interval: options.UpgradeCampaignInterval,
+		},
+		{
+			name:     "cleanupOperationEvents",
+			execute:  maintainer.cleanupOperationEvents,
+			interval: options.CleanupInterval,
+		},
 	}
 
-	operationLockers := make(map[string]storage.Locker)
-	advisoryLockStartIndex := initialOperationsLockIndex
-	for _, functor := range maintainer.functors {
-		operationLockers[functor.name] = lockerCreatorFunc(advisoryLockStartIndex)
-		advisoryLockStartIndex++
-	}
+	return maintainer
+}
 
-	maintainer.operationLockers = operationLockers
+// UseHooks makes the Maintainer notify the post hooks of the operations it fails
+func (om *Maintainer) UseHooks(hooks *Hooks) {
+	hooks.Attach(om.scheduler)
+	hooks.Attach(om.cascadePollingScheduler)
+}
 
-	return maintainer
+// UseJobWorker makes the Maintainer queue the upgrades of upgrade campaigns which are deferred to the maintenance
+// windows of their instances, so that they are executed by the workers of the service instances
+func (om *Maintainer) UseJobWorker(jobWorker *JobWorker) {
+	jobWorker.attach(om.scheduler)
 }
 
 // Run starts the two recurring jobs responsible for cleaning up operations which are too old
-// and deleting orphan operations
+// and deleting orphan operations. Each job is run only by the Service Manager instance which leads it.
 func (om *Maintainer) Run() {
 	for _, functor := range om.functors {
 		functor := functor
 		maintainerFunc := func() {
-			log.C(om.smCtx).Debugf("Attempting to retrieve lock for maintainer functor (%s)", functor.name)
-			err := om.operationLockers[functor.name].TryLock(om.smCtx)
-			if err != nil {
-				log.C(om.smCtx).Debugf("Failed to retrieve lock for maintainer functor (%s): %s", functor.name, err)
-				return
-			}
-			defer func() {
-				if err := om.operationLockers[functor.name].Unlock(om.smCtx); err != nil {
-					log.C(om.smCtx).Warnf("Could not unlock for maintainer functor (%s): %s", functor.name, err)
-				}
-			}()
-			log.C(om.smCtx).Debugf("Successfully retrieved lock for maintainer functor (%s)", functor.name)
-
-			functor.execute()
+			om.elector.Run(om.smCtx, functor.name, functor.execute)
 		}
 
 		go maintainerFunc()
@@ -174,7 +185,7 @@ func (om *Maintainer) processOperations(functor func(), functorName string, inte
 }
 
 // cleanUpExternalOperations cleans up periodically all external operations which are older than some specified time
-func (om *Maintainer) cleanupExternalOperations() {
+func (om *Maintainer) cleanupExternalOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		query.ByField(query.NotEqualsOperator, "platform_id", types.SMPlatform),
@@ -182,15 +193,15 @@ func (om *Maintainer) cleanupExternalOperations() {
 		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.Lifespan))),
 		query.ByNotExists(storage.GetSubQuery(storage.QueryForAllLastOperationsPerResource)),
 	}
-	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
-		log.C(om.smCtx).Debugf("Failed to cleanup operations: %s", err)
-		return
+	if err := om.repository.Delete(ctx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
+		return fmt.Errorf("failed to cleanup operations: %s", err)
 	}
-	log.C(om.smCtx).Debug("Finished cleaning up external operations")
+	log.C(ctx).Debug("Finished cleaning up external operations")
+	return nil
 }
 
 // cleanupFinishedCascadeOperations cleans up all successful/failed internal cascade operations which are older than some specified time
-func (om *Maintainer) CleanupFinishedCascadeOperations() {
+func (om *Maintainer) CleanupFinishedCascadeOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	rootsCriteria := []query.Criterion{
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
@@ -203,23 +214,23 @@ func (om *Maintainer) CleanupFinishedCascadeOperations() {
 		query.ByNotExists(storage.GetSubQuery(storage.QueryForAllLastOperationsPerResource)),
 	}
 
-	roots, err := om.repository.List(om.smCtx, types.OperationType, rootsCriteria...)
+	roots, err := om.repository.List(ctx, types.OperationType, rootsCriteria...)
 	if err != nil {
-		log.C(om.smCtx).Debugf("Failed to fetch finished cascade operations: %s", err)
-		return
+		return fmt.Errorf("failed to fetch finished cascade operations: %s", err)
 	}
 	for i := 0; i < roots.Len(); i++ {
 		root := roots.ItemAt(i)
 		byRootID := query.ByField(query.EqualsOperator, "cascade_root_id", root.GetID())
-		if err := om.repository.Delete(om.smCtx, types.OperationType, byRootID); err != nil && err != util.ErrNotFoundInStorage {
-			log.C(om.smCtx).Errorf("Failed to cleanup cascade operations: %s", err)
+		if err := om.repository.Delete(ctx, types.OperationType, byRootID); err != nil && err != util.ErrNotFoundInStorage {
+			log.C(ctx).Errorf("Failed to cleanup cascade operations: %s", err)
 		}
 	}
-	log.C(om.smCtx).Debug("Finished cleaning up successful cascade operations")
+	log.C(ctx).Debug("Finished cleaning up successful cascade operations")
+	return nil
 }
 
 // cleanupInternalCascadeOperations cleans up all finished internal cascade operations which are older than some specified time
-func (om *Maintainer) cleanupInternalSuccessfulOperations() {
+func (om *Maintainer) cleanupInternalSuccessfulOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
@@ -231,19 +242,19 @@ func (om *Maintainer) cleanupInternalSuccessfulOperations() {
 		query.ByNotExists(storage.GetSubQuery(storage.QueryForAllLastOperationsPerResource)),
 	}
 
-	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
-		log.C(om.smCtx).Debugf("Failed to cleanup operations: %s", err)
-		return
+	if err := om.repository.Delete(ctx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
+		return fmt.Errorf("failed to cleanup operations: %s", err)
 	}
-	log.C(om.smCtx).Debug("Finished cleaning up successful internal operations")
+	log.C(ctx).Debug("Finished cleaning up successful internal operations")
+	return nil
 }
 
-// cleanupInternalFailedOperations cleans up all failed internal operations which are older than some specified time
-func (om *Maintainer) cleanupInternalFailedOperations() {
+// cleanupInternalFailedOperations cleans up all failed or canceled internal operations which are older than some specified time
+func (om *Maintainer) cleanupInternalFailedOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
-		query.ByField(query.EqualsOperator, "state", string(types.FAILED)),
+		query.ByField(query.InOperator, "state", string(types.FAILED), string(types.CANCELED)),
 		query.ByField(query.EqualsOperator, "reschedule", "false"),
 		query.ByField(query.EqualsOperator, "deletion_scheduled", ZeroTime),
 		// ignore cascade operations
@@ -253,14 +264,14 @@ func (om *Maintainer) cleanupInternalFailedOperations() {
 		query.ByNotExists(storage.GetSubQuery(storage.QueryForAllLastOperationsPerResource)),
 	}
 
-	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
-		log.C(om.smCtx).Debugf("Failed to cleanup operations: %s", err)
-		return
+	if err := om.repository.Delete(ctx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
+		return fmt.Errorf("failed to cleanup operations: %s", err)
 	}
-	log.C(om.smCtx).Debug("Finished cleaning up failed internal operations")
+	log.C(ctx).Debug("Finished cleaning up failed internal operations")
+	return nil
 }
 
-func (om *Maintainer) CleanupResourcelessOperations() {
+func (om *Maintainer) CleanupResourcelessOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		// check if operation hasn't been updated for the operation's maximum allowed time to live in DB
@@ -276,7 +287,7 @@ func (om *Maintainer) CleanupResourcelessOperations() {
 		}
 		subQuery, err := storage.GetSubQueryWithParams(storage.QueryForOperationsWithResource, params)
 		if err != nil {
-			log.C(om.smCtx).Debugf(
+			log.C(ctx).Debugf(
 				"Failed resolving template parameters for sub-query: %v. Error: %v",
 				storage.QueryForOperationsWithResource,
 				err)
@@ -284,15 +295,15 @@ func (om *Maintainer) CleanupResourcelessOperations() {
 		byIDNotExistCriterion := query.ByNotExists(subQuery)
 		criteria = append(criteria, byIDNotExistCriterion)
 	}
-	if err := om.repository.Delete(om.smCtx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
-		log.C(om.smCtx).Debugf("Failed to cleanup operations: %s", err)
-		return
+	if err := om.repository.Delete(ctx, types.OperationType, criteria...); err != nil && err != util.ErrNotFoundInStorage {
+		return fmt.Errorf("failed to cleanup operations: %s", err)
 	}
-	log.C(om.smCtx).Debug("Finished cleaning up resource-less operations")
+	log.C(ctx).Debug("Finished cleaning up resource-less operations")
+	return nil
 }
 
 // rescheduleUnfinishedOperations reschedules IN_PROGRESS operations which are reschedulable, not scheduled for deletion and no goroutine is processing at the moment
-func (om *Maintainer) rescheduleUnfinishedOperations() {
+func (om *Maintainer) rescheduleUnfinishedOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
@@ -301,19 +312,20 @@ func (om *Maintainer) rescheduleUnfinishedOperations() {
 		query.ByField(query.EqualsOperator, "deletion_scheduled", ZeroTime),
 		// check if operation hasn't been updated for the operation's maximum allowed time to execute
 		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ActionTimeout))),
+		// queued operations are executed by the worker which leases their job
+		query.ByNotExists(storage.GetSubQuery(storage.QueryForOperationsWithUnleasedJob)),
 	}
 
-	objectList, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
+	objectList, err := om.repository.List(ctx, types.OperationType, criteria...)
 	if err != nil {
-		log.C(om.smCtx).Debugf("Failed to fetch unprocessed operations: %s", err)
-		return
+		return fmt.Errorf("failed to fetch unprocessed operations: %s", err)
 	}
 
 	operations := objectList.(*types.Operations)
 	for i := 0; i < operations.Len(); i++ {
 		operation := operations.ItemAt(i).(*types.Operation)
-		logger := log.C(om.smCtx).WithField(log.FieldCorrelationID, operation.CorrelationID)
-		ctx := log.ContextWithLogger(om.smCtx, logger)
+		logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+		ctx := log.ContextWithLogger(ctx, logger)
 
 		var action storageAction
 
@@ -361,18 +373,18 @@ func (om *Maintainer) rescheduleUnfinishedOperations() {
 
 		logger.Debugf("Successfully rescheduled unfinished operation %+v", operation)
 	}
+	return nil
 }
 
-func (om *Maintainer) pollPendingCascadeOperations() {
+func (om *Maintainer) pollPendingCascadeOperations(ctx context.Context) error {
 	criteria := []query.Criterion{
 		query.ByField(query.NotEqualsOperator, "cascade_root_id", ""),
 		query.ByField(query.EqualsOperator, "type", string(types.DELETE)),
 		query.ByField(query.EqualsOperator, "state", string(types.PENDING)),
 	}
-	operations, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
+	operations, err := om.repository.List(ctx, types.OperationType, criteria...)
 	if err != nil {
-		log.C(om.smCtx).Errorf("Failed to fetch cascaded operations in progress: %s", err)
-		return
+		return fmt.Errorf("failed to fetch cascaded operations in progress: %s", err)
 	}
 
 	skipSameResourcesForCurrentIteration := make(map[string]bool)
@@ -382,8 +394,8 @@ func (om *Maintainer) pollPendingCascadeOperations() {
 		if skipSameResourcesForCurrentIteration[operation.ResourceID] {
 			continue
 		}
-		logger := log.C(om.smCtx).WithField(log.FieldCorrelationID, operation.CorrelationID)
-		ctx := log.ContextWithLogger(om.smCtx, logger)
+		logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+		ctx := log.ContextWithLogger(ctx, logger)
 
 		subOperations, err := GetSubOperations(ctx, operation, om.repository)
 		if err != nil {
@@ -453,10 +465,11 @@ func (om *Maintainer) pollPendingCascadeOperations() {
 			}
 		}
 	}
+	return nil
 }
 
 // rescheduleOrphanMitigationOperations reschedules orphan mitigation operations which no goroutine is processing at the moment
-func (om *Maintainer) rescheduleOrphanMitigationOperations() {
+func (om *Maintainer) rescheduleOrphanMitigationOperations(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
@@ -466,17 +479,16 @@ func (om *Maintainer) rescheduleOrphanMitigationOperations() {
 		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ActionTimeout))),
 	}
 
-	objectList, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
+	objectList, err := om.repository.List(ctx, types.OperationType, criteria...)
 	if err != nil {
-		log.C(om.smCtx).Debugf("Failed to fetch unprocessed orphan mitigation operations: %s", err)
-		return
+		return fmt.Errorf("failed to fetch unprocessed orphan mitigation operations: %s", err)
 	}
 
 	operations := objectList.(*types.Operations)
 	for i := 0; i < operations.Len(); i++ {
 		operation := operations.ItemAt(i).(*types.Operation)
-		logger := log.C(om.smCtx).WithField(log.FieldCorrelationID, operation.CorrelationID)
-		ctx := log.ContextWithLogger(om.smCtx, logger)
+		logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+		ctx := log.ContextWithLogger(ctx, logger)
 
 		byID := query.ByField(query.EqualsOperator, "id", operation.ResourceID)
 
@@ -491,16 +503,19 @@ func (om *Maintainer) rescheduleOrphanMitigationOperations() {
 			return nil, nil
 		}
 
+		// the rescheduling counts as a retry of the orphan mitigation of the operation
+		operation.Retries++
 		if err := om.scheduler.ScheduleAsyncStorageAction(ctx, operation, action); err != nil {
 			logger.Warnf("Failed to reschedule unprocessed orphan mitigation operation with ID (%s): %s", operation.ID, err)
 		}
 
 		logger.Debugf("Successfully rescheduled orphan mitigation operation %+v", operation)
 	}
+	return nil
 }
 
 // markStuckOperationsFailed checks for operations which are stuck in state IN_PROGRESS, updates their status to FAILED and schedules a delete action
-func (om *Maintainer) markStuckOperationsFailed() {
+func (om *Maintainer) markStuckOperationsFailed(ctx context.Context) error {
 	currentTime := time.Now()
 	criteria := []query.Criterion{
 		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
@@ -509,18 +524,19 @@ func (om *Maintainer) markStuckOperationsFailed() {
 		query.ByField(query.EqualsOperator, "deletion_scheduled", ZeroTime),
 		// check if operation hasn't been updated for the operation's maximum allowed time to execute
 		query.ByField(query.LessThanOperator, "updated_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ActionTimeout))),
+		// queued operations are not stuck while they wait for a worker to lease their job
+		query.ByNotExists(storage.GetSubQuery(storage.QueryForOperationsWithUnleasedJob)),
 	}
 
-	objectList, err := om.repository.List(om.smCtx, types.OperationType, criteria...)
+	objectList, err := om.repository.List(ctx, types.OperationType, criteria...)
 	if err != nil {
-		log.C(om.smCtx).Debugf("Failed to fetch stuck operations: %s", err)
-		return
+		return fmt.Errorf("failed to fetch stuck operations: %s", err)
 	}
 
 	operations := objectList.(*types.Operations)
 	for i := 0; i < operations.Len(); i++ {
 		operation := operations.ItemAt(i).(*types.Operation)
-		logger := log.C(om.smCtx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+		logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
 
 		operation.State = types.FAILED
 
@@ -528,10 +544,14 @@ func (om *Maintainer) markStuckOperationsFailed() {
 			operation.DeletionScheduled = time.Now()
 		}
 
-		if _, err := om.repository.Update(om.smCtx, operation, types.LabelChanges{}); err != nil {
+		if _, err := om.repository.Update(ctx, operation, types.LabelChanges{}); err != nil {
 			logger.Warnf("Failed to update orphan operation with ID (%s) state to FAILED: %s", operation.ID, err)
 			continue
 		}
+		// the post hooks of operations which require orphan mitigation are notified once the mitigation has finished
+		if !operation.InOrphanMitigationState() {
+			om.scheduler.notifyPostHooks(ctx, operation)
+		}
 
 		if operation.Type == types.CREATE || operation.Type == types.DELETE {
 			byID := query.ByField(query.EqualsOperator, "id", operation.ResourceID)
@@ -546,11 +566,87 @@ func (om *Maintainer) markStuckOperationsFailed() {
 				return nil, nil
 			}
 
-			if err := om.scheduler.ScheduleAsyncStorageAction(om.smCtx, operation, action); err != nil {
+			if err := om.scheduler.ScheduleAsyncStorageAction(ctx, operation, action); err != nil {
 				logger.Warnf("Failed to schedule delete action for stuck operation with ID (%s): %s", operation.ID, err)
 			}
 		}
 	}
 
-	log.C(om.smCtx).Debug("Finished marking stuck operations as failed")
+	log.C(ctx).Debug("Finished marking stuck operations as failed")
+	return nil
+}
+
+// markMissedScheduledOperationsFailed marks operations scheduled for a later time as failed if they have not been
+// started within the reconciliation timeout after they were due, e.g. because their jobs were lost
+func (om *Maintainer) markMissedScheduledOperationsFailed(ctx context.Context) error {
+	currentTime := time.Now()
+	criteria := []query.Criterion{
+		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
+		query.ByField(query.EqualsOperator, "state", string(types.PENDING)),
+		query.ByField(query.EqualsOrNilOperator, "cascade_root_id", ""),
+		query.ByField(query.NotEqualsOperator, "scheduled_at", ZeroTime),
+		query.ByField(query.LessThanOperator, "scheduled_at", util.ToRFCNanoFormat(currentTime.Add(-om.settings.ReconciliationOperationTimeout))),
+	}
+
+	objectList, err := om.repository.List(ctx, types.OperationType, criteria...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch missed scheduled operations: %s", err)
+	}
+
+	operations := objectList.(*types.Operations)
+	for i := 0; i < operations.Len(); i++ {
+		operation := operations.ItemAt(i).(*types.Operation)
+		logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+		ctx := log.ContextWithLogger(ctx, logger)
+
+		err := &util.HTTPError{
+			ErrorType:   "ManualActionRequired",
+			Description: fmt.Sprintf("operation scheduled at %s was not started within the maximum reconciliation timeout of %v", operation.ScheduledAt, om.settings.ReconciliationOperationTimeout),
+			StatusCode:  http.StatusUnprocessableEntity,
+		}
+		// the operation may be started concurrently, so it is failed only if it is still pending
+		failed, opErr := failPendingOperation(ctx, om.repository, operation, err)
+		if opErr != nil {
+			logger.Warnf("Failed to update missed scheduled operation with ID (%s) state to FAILED: %s", operation.ID, opErr)
+		} else if failed {
+			om.scheduler.notifyPostHooks(ctx, operation)
+		}
+	}
+
+	log.C(ctx).Debug("Finished marking missed scheduled operations as failed")
+	return nil
+}
+
+// executeDueScheduledOperations starts the due operations which were scheduled for a later time while the job queue
+// was disabled. Operations with a job are started by the job workers which lease their jobs.
+func (om *Maintainer) executeDueScheduledOperations(ctx context.Context) error {
+	currentTime := time.Now()
+	criteria := []query.Criterion{
+		query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform),
+		query.ByField(query.EqualsOperator, "state", string(types.PENDING)),
+		query.ByField(query.EqualsOrNilOperator, "cascade_root_id", ""),
+		query.ByField(query.NotEqualsOperator, "scheduled_at", ZeroTime),
+		query.ByField(query.LessThanOrEqualOperator, "scheduled_at", util.ToRFCNanoFormat(currentTime)),
+		query.ByNotExists(storage.GetSubQuery(storage.QueryForOperationsWithJob)),
+		query.OrderResultBy("scheduled_at", query.AscOrder),
+	}
+
+	objectList, err := om.repository.List(ctx, types.OperationType, criteria...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch due scheduled operations: %s", err)
+	}
+
+	operations := objectList.(*types.Operations)
+	for i := 0; i < operations.Len(); i++ {
+		operation := operations.ItemAt(i).(*types.Operation)
+		logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+		ctx := log.ContextWithLogger(ctx, logger)
+
+		if err := om.scheduler.executeScheduledOperation(ctx, operation); err != nil {
+			logger.Warnf("Failed to execute scheduled operation with ID (%s): %s", operation.ID, err)
+		}
+	}
+
+	log.C(ctx).Debug("Finished executing due scheduled operations")
+	return nil
 }
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// ScheduleRetry executes the action of the failed or canceled operation again asynchronously. The action is derived
+// from the operation and the stored state of its resource: a CREATE operation creates the stored resource again, an
+// UPDATE operation applies the change stored with the operation again and a DELETE operation deletes the resource.
+func (s *Scheduler) ScheduleRetry(ctx context.Context, operation *types.Operation) error {
+	if err := s.validateRetry(ctx, operation); err != nil {
+		return err
+	}
+
+	action, err := s.retryAction(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	byState := query.ByField(query.EqualsOperator, "state", string(operation.State))
+	operation.State = types.IN_PROGRESS
+	operation.Errors = json.RawMessage{}
+	operation.Retries = 0
+	operation.Reschedule = false
+	operation.RescheduleTimestamp = time.Time{}
+	operation.DeletionScheduled = time.Time{}
+	operation.ExternalID = ""
+	if operation.Context == nil {
+		operation.Context = &types.OperationContext{}
+	}
+	operation.Context.Async = true
+
+	// the state is guarded so that the action is not executed twice by concurrent retries
+	if _, err := s.repository.Update(ctx, operation, types.LabelChanges{}, byState); err != nil {
+		if err == util.ErrConcurrentResourceModification {
+			return operationNotRetriableError("operation with id %s is already being retried", operation.ID)
+		}
+		return util.HandleStorageError(err, types.OperationType.String())
+	}
+	log.C(ctx).Infof("Retrying %s operation with id %s for %s entity with id %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID)
+
+	if err := s.ScheduleAsyncStorageAction(ctx, operation, action); err != nil {
+		if opErr := updateOperationState(ctx, s.repository, operation, types.FAILED, err); opErr != nil {
+			log.C(ctx).Errorf("Failed to update state of operation with id %s after its retry could not be scheduled: %s", operation.ID, opErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Scheduler) validateRetry(ctx context.Context, operation *types.Operation) error {
+	if operation.State != types.FAILED && operation.State != types.CANCELED {
+		return operationNotRetriableError("operation with id %s is %s and can not be retried", operation.ID, operation.State)
+	}
+	if operation.InOrphanMitigationState() {
+		return operationNotRetriableError("orphan mitigation of operation with id %s is still in progress", operation.ID)
+	}
+	if operation.CascadeRootID != "" || operation.PlatformID != types.SMPlatform {
+		return operationNotRetriableError("operation with id %s was not requested through the Service Manager API and can not be retried", operation.ID)
+	}
+
+	lastOperation, found, _, err := s.getResourceLastOperation(ctx, operation)
+	if err != nil {
+		return err
+	}
+	if found && lastOperation.ID != operation.ID {
+		return operationNotRetriableError("operation with id %s is not the last operation of %s with id %s", operation.ID, operation.ResourceType, operation.ResourceID)
+	}
+	return nil
+}
+
+func (s *Scheduler) retryAction(ctx context.Context, operation *types.Operation) (storageAction, error) {
+	byID := query.ByField(query.EqualsOperator, "id", operation.ResourceID)
+
+	switch operation.Type {
+	case types.CREATE:
+		object, err := s.getRetriedResource(ctx, operation)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, repository storage.Repository) (types.Object, error) {
+			item := &storage.BatchItem{
+				Type:       types.CREATE,
+				ObjectType: operation.ResourceType,
+				Object:     object,
+				// the resource stored by the failed action is removed without running its interceptors, so that the
+				// broker is not asked to delete it, and in the same transaction in which it is created again
+				Prepare: func(ctx context.Context, txStorage storage.Repository) error {
+					if err := txStorage.Delete(ctx, operation.ResourceType, byID); err != nil && err != util.ErrNotFoundInStorage {
+						return err
+					}
+					return nil
+				},
+			}
+			err := storage.ExecuteBatch(ctx, repository, item)
+			if batchErr, ok := err.(*storage.BatchError); ok {
+				err = batchErr.Err
+			}
+			return item.Object, util.HandleStorageError(err, operation.ResourceType.String())
+		}, nil
+	case types.UPDATE:
+		if len(operation.RetryPayload) == 0 {
+			return nil, operationNotRetriableError("the changes of operation with id %s are not stored and have to be requested again", operation.ID)
+		}
+		object, err := s.getRetriedResource(ctx, operation)
+		if err != nil {
+			return nil, err
+		}
+		payload := &jobPayload{}
+		if err := json.Unmarshal(operation.RetryPayload, payload); err != nil {
+			return nil, fmt.Errorf("could not decode the changes of operation with id %s: %s", operation.ID, err)
+		}
+		item, err := payload.storageItem(operation.ResourceType, func() types.Object {
+			return reflect.New(reflect.TypeOf(object).Elem()).Interface().(types.Object)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not decode the changes of operation with id %s: %s", operation.ID, err)
+		}
+		// the failed action may have modified the resource itself, e.g. to discard the changes the broker rejected
+		item.Preconditions = nil
+		return func(ctx context.Context, repository storage.Repository) (types.Object, error) {
+			ctx, err := query.ContextWithCriteria(ctx, payload.ContextCriteria...)
+			if err != nil {
+				return nil, err
+			}
+			return storageItemAction(item)(ctx, repository)
+		}, nil
+	case types.DELETE:
+		return func(ctx context.Context, repository storage.Repository) (types.Object, error) {
+			err := repository.Delete(ctx, operation.ResourceType, byID)
+			if err != nil {
+				if err == util.ErrNotFoundInStorage {
+					return nil, nil
+				}
+				return nil, util.HandleStorageError(err, operation.ResourceType.String())
+			}
+			return nil, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("operation type %s is unknown type", operation.Type)
+	}
+}
+
+// getRetriedResource returns the stored resource of the retried operation
+func (s *Scheduler) getRetriedResource(ctx context.Context, operation *types.Operation) (types.Object, error) {
+	object, err := s.repository.Get(ctx, operation.ResourceType, query.ByField(query.EqualsOperator, "id", operation.ResourceID))
+	if err != nil {
+		if err == util.ErrNotFoundInStorage {
+			return nil, operationNotRetriableError("%s with id %s no longer exists and has to be created again", operation.ResourceType, operation.ResourceID)
+		}
+		return nil, util.HandleStorageError(err, operation.ResourceType.String())
+	}
+	return object, nil
+}
+
+func operationNotRetriableError(format string, args ...interface{}) error {
+	return &util.HTTPError{
+		ErrorType:   "OperationNotRetriable",
+		Description: fmt.Sprintf(format, args...),
+		StatusCode:  http.StatusConflict,
+	}
+}
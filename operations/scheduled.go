@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// ScheduleStorageItemAt stores the operation as pending together with its storage item, so that it is not executed
+// before the given time. The item is queued if a job queue is available and is otherwise executed by the Maintainer
+// once it is due.
+func (s *Scheduler) ScheduleStorageItemAt(ctx context.Context, operation *types.Operation, item *storage.BatchItem, scheduledAt time.Time) error {
+	operation.State = types.PENDING
+	operation.ScheduledAt = scheduledAt.UTC()
+	if operation.Context == nil {
+		operation.Context = &types.OperationContext{}
+	}
+	operation.Context.Async = true
+
+	// the requested change is stored with the operation, so that it can be executed and retried without the job
+	payload, err := newJobPayload(ctx, item)
+	if err != nil {
+		return fmt.Errorf("could not store the changes of operation with id %s: %s", operation.ID, err)
+	}
+	operation.RetryPayload = payload
+
+	if s.jobQueue == nil {
+		initialLogMessage(ctx, operation, true)
+		return s.executeOperationPreconditions(ctx, operation)
+	}
+	return s.enqueueStorageItem(ctx, operation, item, operation.ScheduledAt)
+}
+
+// executeScheduledOperation starts the due operation which was scheduled while the job queue is disabled and executes
+// its stored storage item in a goroutine. The operation is left pending until the next run if all workers are busy.
+func (s *Scheduler) executeScheduledOperation(ctx context.Context, operation *types.Operation) error {
+	select {
+	case s.workers <- struct{}{}:
+	default:
+		log.C(ctx).Infof("All workers are busy. Scheduled %s operation with id %s will be started later", operation.Type, operation.ID)
+		return nil
+	}
+
+	itemCtx, item, err := s.scheduledItem(ctx, operation)
+	if err != nil {
+		<-s.workers
+		failed, opErr := failPendingOperation(ctx, s.repository, operation, err)
+		if opErr != nil {
+			return fmt.Errorf("%s: setting new operation state failed: %s", err, opErr)
+		}
+		if failed {
+			s.notifyPostHooks(ctx, operation)
+		}
+		return err
+	}
+
+	startedOperation, err := startPendingOperation(ctx, s.repository, operation)
+	if err != nil || startedOperation == nil {
+		<-s.workers
+		return err
+	}
+	s.executeAsync(itemCtx, startedOperation, storageItemAction(item), nil)
+	return nil
+}
+
+// scheduledItem restores the storage item of the scheduled operation together with the criteria of the context in
+// which it was scheduled
+func (s *Scheduler) scheduledItem(ctx context.Context, operation *types.Operation) (context.Context, *storage.BatchItem, error) {
+	if len(operation.RetryPayload) == 0 {
+		return ctx, nil, fmt.Errorf("the changes of scheduled operation with id %s are not stored", operation.ID)
+	}
+	payload := &jobPayload{}
+	if err := json.Unmarshal(operation.RetryPayload, payload); err != nil {
+		return ctx, nil, fmt.Errorf("could not decode the changes of operation with id %s: %s", operation.ID, err)
+	}
+
+	var resource types.Object
+	if len(payload.Object) != 0 {
+		var err error
+		byID := query.ByField(query.EqualsOperator, "id", operation.ResourceID)
+		if resource, err = s.repository.Get(ctx, operation.ResourceType, byID); err != nil {
+			return ctx, nil, util.HandleStorageError(err, operation.ResourceType.String())
+		}
+	}
+	item, err := payload.storageItem(operation.ResourceType, func() types.Object {
+		return reflect.New(reflect.TypeOf(resource).Elem()).Interface().(types.Object)
+	})
+	if err != nil {
+		return ctx, nil, fmt.Errorf("could not decode the changes of operation with id %s: %s", operation.ID, err)
+	}
+
+	ctxWithCriteria, err := query.ContextWithCriteria(ctx, payload.ContextCriteria...)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctxWithCriteria, item, nil
+}
+
+// startPendingOperation moves the pending operation to IN_PROGRESS once it is due and approved. It returns nil if the
+// operation is no longer pending, e.g. because it was canceled in the meantime.
+func startPendingOperation(ctx context.Context, repository storage.Repository, operation *types.Operation) (*types.Operation, error) {
+	byState := query.ByField(query.EqualsOperator, "state", string(types.PENDING))
+	operation.State = types.IN_PROGRESS
+	startedOperation, err := repository.Update(ctx, operation, types.LabelChanges{}, byState)
+	if err != nil {
+		if err == util.ErrConcurrentResourceModification {
+			return nil, nil
+		}
+		return nil, util.HandleStorageError(err, types.OperationType.String())
+	}
+	log.C(ctx).Infof("Starting pending %s operation with id %s for %s entity with id %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID)
+	return startedOperation.(*types.Operation), nil
+}
+
+// failPendingOperation marks the pending operation as failed. It returns false if the operation is no longer pending,
+// e.g. because it was started or canceled in the meantime.
+func failPendingOperation(ctx context.Context, repository storage.Repository, operation *types.Operation, opErr error) (bool, error) {
+	operation.State = types.FAILED
+	if err := setOperationError(ctx, operation, opErr); err != nil {
+		return false, err
+	}
+	byState := query.ByField(query.EqualsOperator, "state", string(types.PENDING))
+	if _, err := repository.Update(ctx, operation, types.LabelChanges{}, byState); err != nil {
+		if err == util.ErrConcurrentResourceModification {
+			return false, nil
+		}
+		return false, util.HandleStorageError(err, types.OperationType.String())
+	}
+	log.C(ctx).Infof("Successfully updated state of pending operation with id %s to %s", operation.ID, types.FAILED)
+	return true, nil
+}
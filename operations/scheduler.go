@@ -45,8 +45,15 @@ type Scheduler struct {
 	actionTimeout                  time.Duration
 	reconciliationOperationTimeout time.Duration
 	cascadeOrphanMitigationTimeout time.Duration
-	reschedulingDelay              time.Duration
+	retryPolicies                  *Settings
 	wg                             *sync.WaitGroup
+
+	jobQueue       storage.JobQueue
+	jobsAvailable  chan struct{}
+	tenantLabelKey string
+	priorities     PrioritySettings
+
+	hooks *Hooks
 }
 
 // NewScheduler constructs a Scheduler
@@ -58,16 +65,51 @@ func NewScheduler(smCtx context.Context, repository storage.TransactionalReposit
 		actionTimeout:                  settings.ActionTimeout,
 		reconciliationOperationTimeout: settings.ReconciliationOperationTimeout,
 		cascadeOrphanMitigationTimeout: settings.CascadeOrphanMitigationTimeout,
-		reschedulingDelay:              settings.ReschedulingInterval,
+		retryPolicies:                  settings,
+		priorities:                     settings.Priorities,
 		wg:                             wg,
 	}
 }
 
 //Identifies the preferred execution mode and execute the storage action
 func (s *Scheduler) ScheduleStorageAction(ctx context.Context, operation *types.Operation, action storageAction, isAsyncSupported bool) (types.Object, bool, error) {
+	return s.scheduleStorageAction(ctx, operation, action, nil, isAsyncSupported)
+}
+
+// ScheduleStorageItem identifies the preferred execution mode and executes the storage item. Unlike actions, items can
+// be queued in the storage when the operation is executed asynchronously, so that any Service Manager instance can
+// execute them.
+func (s *Scheduler) ScheduleStorageItem(ctx context.Context, operation *types.Operation, item *storage.BatchItem, isAsyncSupported bool) (types.Object, bool, error) {
+	return s.scheduleStorageAction(ctx, operation, storageItemAction(item), item, isAsyncSupported)
+}
+
+func (s *Scheduler) scheduleStorageAction(ctx context.Context, operation *types.Operation, action storageAction, item *storage.BatchItem, isAsyncSupported bool) (types.Object, bool, error) {
 	var object types.Object
 	var err error
 
+	if item != nil && item.Type == types.UPDATE {
+		// the requested change is stored with the operation, so that the operation can be retried once it has failed
+		if operation.RetryPayload, err = newJobPayload(ctx, item); err != nil {
+			return nil, false, fmt.Errorf("could not store the changes of operation with id %s: %s", operation.ID, err)
+		}
+	}
+
+	if s.hooks != nil && isNewOperation(operation) {
+		hook, result := s.approveOperation(ctx, operation, item)
+		switch result.Verdict {
+		case HookRejected:
+			return nil, false, operationRejectedError(hook, result)
+		case HookPending:
+			if !isAsyncSupported {
+				item = nil
+			}
+			if err := s.awaitApproval(ctx, operation, item, hook); err != nil {
+				return nil, false, err
+			}
+			return nil, true, nil
+		}
+	}
+
 	if operation.Context.IsAsyncNotDefined && isAsyncSupported {
 		object, err = s.ScheduleSyncStorageAction(ctx, operation, action)
 
@@ -81,7 +123,7 @@ func (s *Scheduler) ScheduleStorageAction(ctx context.Context, operation *types.
 		}
 
 		if lastOperation.Reschedule {
-			if err := s.ScheduleAsyncStorageAction(ctx, operation, action); err != nil {
+			if err := s.scheduleAsync(ctx, operation, action, item); err != nil {
 				return nil, false, err
 			}
 			return nil, true, nil
@@ -101,7 +143,7 @@ func (s *Scheduler) ScheduleStorageAction(ctx context.Context, operation *types.
 		}
 
 		log.C(ctx).Debugf("Request will be executed asynchronously")
-		if err := s.ScheduleAsyncStorageAction(ctx, operation, action); err != nil {
+		if err := s.scheduleAsync(ctx, operation, action, item); err != nil {
 			return nil, true, err
 		}
 		return nil, true, nil
@@ -125,11 +167,16 @@ func (s *Scheduler) ScheduleSyncStorageAction(ctx context.Context, operation *ty
 		return nil, err
 	}
 
-	object, actionErr := action(ctxWithOp, s.repository)
+	cancelableCtx, untrackAction := trackAction(ctxWithOp, operation.ID)
+	object, actionErr := action(cancelableCtx, s.repository)
 	if actionErr != nil {
 		log.C(ctx).Errorf("failed to execute action for %s operation with id %s for %s entity with id %s: %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID, actionErr)
 	}
 
+	// synchronous actions are not leased, so they are never abandoned
+	if canceled, _ := untrackAction(); canceled {
+		return nil, s.handleActionResponseCanceled(&util.StateContext{Context: ctx}, operation)
+	}
 	if object, err = s.handleActionResponse(&util.StateContext{Context: ctx}, object, actionErr, operation); err != nil {
 		return nil, err
 	}
@@ -147,68 +194,97 @@ func (s *Scheduler) ScheduleAsyncStorageAction(ctx context.Context, operation *t
 			return err
 		}
 
-		s.wg.Add(1)
-		stateCtx := util.StateContext{Context: ctx}
-		go func(operation *types.Operation) {
-			defer func() {
-				if panicErr := recover(); panicErr != nil {
-					errMessage := fmt.Errorf("job panicked while executing: %s", panicErr)
-					op, opErr := s.refetchOperation(stateCtx, operation)
-					if opErr != nil {
-						errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
-					}
+		s.executeAsync(ctx, operation, action, nil)
+	default:
+		log.C(ctx).Infof("Failed to schedule %s operation with id %s - all workers are busy.", operation.Type, operation.ID)
+		return &util.HTTPError{
+			ErrorType:   "ServiceUnavailable",
+			Description: "Failed to schedule job. Server is busy - try again in a few minutes.",
+			StatusCode:  http.StatusServiceUnavailable,
+		}
+	}
 
-					if opErr := updateOperationState(stateCtx, s.repository, op, types.FAILED, &util.HTTPError{
-						ErrorType:   "InternalServerError",
-						Description: "job interrupted",
-						StatusCode:  http.StatusInternalServerError,
-					}); opErr != nil {
-						errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
-					}
-					log.C(stateCtx).Errorf("panic error: %s", errMessage)
-					debug.PrintStack()
-				}
-				<-s.workers
-				s.wg.Done()
-			}()
+	return nil
+}
 
-			stateCtxWithOp, err := s.addOperationToContext(stateCtx, operation)
-			if err != nil {
-				log.C(stateCtx).Error(err)
-				return
-			}
+// scheduleAsync queues the item in the storage if a job queue is available and otherwise executes the action in a goroutine
+func (s *Scheduler) scheduleAsync(ctx context.Context, operation *types.Operation, action storageAction, item *storage.BatchItem) error {
+	if item == nil || s.jobQueue == nil {
+		return s.ScheduleAsyncStorageAction(ctx, operation, action)
+	}
+	return s.enqueueStorageItem(ctx, operation, item, operation.ScheduledAt)
+}
+
+// executeAsync executes the action of the operation in a goroutine using a worker which is already acquired.
+// The worker is released and onDone is called, if provided, once the operation state reflects the outcome of the action.
+func (s *Scheduler) executeAsync(ctx context.Context, operation *types.Operation, action storageAction, onDone func()) {
+	s.wg.Add(1)
+	stateCtx := util.StateContext{Context: ctx}
+	go func(operation *types.Operation) {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				errMessage := fmt.Errorf("job panicked while executing: %s", panicErr)
+				op, opErr := s.refetchOperation(stateCtx, operation)
+				if opErr != nil {
+					errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
+				}
 
-			stateCtxWithOpAndTimeout, timeoutCtxCancel := context.WithTimeout(stateCtxWithOp, s.actionTimeout)
-			defer timeoutCtxCancel()
-			go func() {
-				select {
-				case <-s.smCtx.Done():
-					timeoutCtxCancel()
-				case <-stateCtxWithOpAndTimeout.Done():
+				if opErr := updateOperationState(stateCtx, s.repository, op, types.FAILED, &util.HTTPError{
+					ErrorType:   "InternalServerError",
+					Description: "job interrupted",
+					StatusCode:  http.StatusInternalServerError,
+				}); opErr != nil {
+					errMessage = fmt.Errorf("%s: setting new operation state failed: %s ", errMessage, opErr)
 				}
+				log.C(stateCtx).Errorf("panic error: %s", errMessage)
+				debug.PrintStack()
+			}
+			<-s.workers
+			if onDone != nil {
+				onDone()
+			}
+			s.wg.Done()
+		}()
 
-			}()
+		stateCtxWithOp, err := s.addOperationToContext(stateCtx, operation)
+		if err != nil {
+			log.C(stateCtx).Error(err)
+			return
+		}
 
-			var actionErr error
-			var objectAfterAction types.Object
-			if objectAfterAction, actionErr = action(stateCtxWithOpAndTimeout, s.repository); actionErr != nil {
-				log.C(stateCtx).Errorf("failed to execute action for %s operation with id %s for %s entity with id %s: %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID, actionErr)
+		stateCtxWithOpAndTimeout, timeoutCtxCancel := context.WithTimeout(stateCtxWithOp, s.actionTimeout)
+		defer timeoutCtxCancel()
+		go func() {
+			select {
+			case <-s.smCtx.Done():
+				timeoutCtxCancel()
+			case <-stateCtxWithOpAndTimeout.Done():
 			}
 
-			if _, err := s.handleActionResponse(stateCtx, objectAfterAction, actionErr, operation); err != nil {
+		}()
+		cancelableCtx, untrackAction := trackAction(stateCtxWithOpAndTimeout, operation.ID)
+
+		var actionErr error
+		var objectAfterAction types.Object
+		if objectAfterAction, actionErr = action(cancelableCtx, s.repository); actionErr != nil {
+			log.C(stateCtx).Errorf("failed to execute action for %s operation with id %s for %s entity with id %s: %s", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID, actionErr)
+		}
+
+		canceled, abandoned := untrackAction()
+		if abandoned {
+			log.C(stateCtx).Warnf("Abandoned action of %s operation with id %s for %s entity with id %s as the operation is no longer owned by this instance", operation.Type, operation.ID, operation.ResourceType, operation.ResourceID)
+			return
+		}
+		if canceled {
+			if err := s.handleActionResponseCanceled(stateCtx, operation); err != nil && err != errOperationCanceled {
 				log.C(stateCtx).Error(err)
 			}
-		}(operation)
-	default:
-		log.C(ctx).Infof("Failed to schedule %s operation with id %s - all workers are busy.", operation.Type, operation.ID)
-		return &util.HTTPError{
-			ErrorType:   "ServiceUnavailable",
-			Description: "Failed to schedule job. Server is busy - try again in a few minutes.",
-			StatusCode:  http.StatusServiceUnavailable,
+			return
 		}
-	}
-
-	return nil
+		if _, err := s.handleActionResponse(stateCtx, objectAfterAction, actionErr, operation); err != nil {
+			log.C(stateCtx).Error(err)
+		}
+	}(operation)
 }
 
 func (s *Scheduler) getResourceLastOperation(ctx context.Context, operation *types.Operation) (*types.Operation, bool, bool, error) {
@@ -385,6 +461,27 @@ func updateTransitiveResources(ctx context.Context, storage storage.Repository,
 	return nil
 }
 
+// MarkCreated marks the created object and the resources created along with it as ready once their creation has
+// succeeded and returns the updated object
+func MarkCreated(ctx context.Context, repository storage.Repository, object types.Object, transitiveResources []*types.RelatedType) (types.Object, error) {
+	updatedObject, err := updateResource(ctx, repository, object, func(obj types.Object) {
+		if serviceInstance, ok := obj.(*types.ServiceInstance); ok {
+			serviceInstance.Usable = true
+		}
+		obj.SetReady(true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateTransitiveResources(ctx, repository, transitiveResources, func(obj types.Object) {
+		obj.SetReady(true)
+	}); err != nil {
+		return nil, err
+	}
+	return updatedObject, nil
+}
+
 func updateResource(ctx context.Context, repository storage.Repository, objectAfterAction types.Object, updateFunc func(obj types.Object)) (types.Object, error) {
 	updateFunc(objectAfterAction)
 	updatedObject, err := repository.Update(ctx, objectAfterAction, types.LabelChanges{})
@@ -415,18 +512,9 @@ func updateOperationState(ctx context.Context, repository storage.Repository, op
 	operation.State = state
 
 	if opErr != nil {
-		httpError := util.ToHTTPError(ctx, opErr)
-		bytes, err := json.Marshal(httpError)
-		if err != nil {
+		if err := setOperationError(ctx, operation, opErr); err != nil {
 			return err
 		}
-
-		if len(operation.Errors) == 0 {
-			log.C(ctx).Debugf("setting error of operation with id %s to %s", operation.ID, httpError)
-			operation.Errors = json.RawMessage(bytes)
-		} else {
-			log.C(ctx).Debugf("operation with id %s already has a root cause error %s. Current error %s will not be written", operation.ID, string(operation.Errors), httpError)
-		}
 	}
 
 	// this also updates updated_at which serves as "reporting" that someone is working on the operation
@@ -443,6 +531,23 @@ func updateOperationState(ctx context.Context, repository storage.Repository, op
 	return nil
 }
 
+// setOperationError sets the error of the operation unless it already has a root cause error
+func setOperationError(ctx context.Context, operation *types.Operation, opErr error) error {
+	httpError := util.ToHTTPError(ctx, opErr)
+	bytes, err := json.Marshal(httpError)
+	if err != nil {
+		return err
+	}
+
+	if len(operation.Errors) == 0 {
+		log.C(ctx).Debugf("setting error of operation with id %s to %s", operation.ID, httpError)
+		operation.Errors = json.RawMessage(bytes)
+	} else {
+		log.C(ctx).Debugf("operation with id %s already has a root cause error %s. Current error %s will not be written", operation.ID, string(operation.Errors), httpError)
+	}
+	return nil
+}
+
 func (s *Scheduler) refetchOperation(ctx context.Context, operation *types.Operation) (*types.Operation, error) {
 	opObject, opErr := s.repository.Get(ctx, types.OperationType, query.ByField(query.EqualsOperator, "id", operation.ID))
 	if opErr != nil {
@@ -461,8 +566,9 @@ func (s *Scheduler) handleActionResponse(ctx context.Context, actionObject types
 	if err != nil {
 		return nil, err
 	}
-	// Store the transitive resources in the refeched operation as they were added to the one in the context (opBeforeJob)
+	// Store the transitive resources and the pending events in the refeched operation as they were added to the one in the context (opBeforeJob)
 	opAfterJob.TransitiveResources = opBeforeJob.TransitiveResources
+	opAfterJob.PendingEvents = opBeforeJob.PendingEvents
 	// add the operation to context because we want to work with the refeched operation for further storage actions
 	ctx, err = s.addOperationToContext(ctx, opAfterJob)
 	if err != nil {
@@ -482,10 +588,43 @@ func (s *Scheduler) handleActionResponse(ctx context.Context, actionObject types
 	return actionObject, nil
 }
 
+// handleActionResponseCanceled marks the operation whose action was canceled as canceled regardless of the outcome of
+// the action and schedules orphan mitigation for CREATE operations, as the broker may have already created the resource
+func (s *Scheduler) handleActionResponseCanceled(ctx context.Context, opBeforeJob *types.Operation) error {
+	opAfterJob, err := s.refetchOperation(ctx, opBeforeJob)
+	if err != nil {
+		return err
+	}
+	opAfterJob.TransitiveResources = opBeforeJob.TransitiveResources
+	opAfterJob.PendingEvents = opBeforeJob.PendingEvents
+	ctx, err = s.addOperationToContext(ctx, opAfterJob)
+	if err != nil {
+		return err
+	}
+	log.C(ctx).Infof("%s operation with id %s for %s entity with id %s was canceled", opAfterJob.Type, opAfterJob.ID, opAfterJob.ResourceType, opAfterJob.ResourceID)
+
+	// the operation may have been overwritten by the action after it was canceled
+	opAfterJob.State = types.CANCELED
+	opAfterJob.Reschedule = false
+	opAfterJob.RescheduleTimestamp = time.Time{}
+	if opAfterJob.Type == types.CREATE && !opAfterJob.InOrphanMitigationState() {
+		opAfterJob.DeletionScheduled = time.Now().UTC()
+	}
+	return s.handleActionResponseFailure(ctx, errOperationCanceled, opAfterJob)
+}
+
 func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError error, opAfterJob *types.Operation) error {
+	if s.isActionRetryRequired(opAfterJob, actionError) {
+		if err := s.retryFailedAction(ctx, actionError, opAfterJob); err != nil {
+			log.C(ctx).Errorf("Failed to retry %s operation with id %s: %s", opAfterJob.Type, opAfterJob.ID, err)
+		} else {
+			return actionError
+		}
+	}
+
 	if err := s.repository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
-		// after a failed FAILED CREATE operation, update the ready field to false
-		if opAfterJob.Type == types.CREATE && opAfterJob.State == types.FAILED {
+		// after a failed FAILED or CANCELED CREATE operation, update the ready field to false
+		if opAfterJob.Type == types.CREATE && (opAfterJob.State == types.FAILED || opAfterJob.State == types.CANCELED) {
 			if err := fetchAndUpdateResource(ctx, storage, opAfterJob.ResourceID, opAfterJob.ResourceType, func(obj types.Object) {
 				obj.SetReady(false)
 			}); err != nil {
@@ -500,6 +639,10 @@ func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError
 		}
 
 		newState := types.FAILED
+		if opAfterJob.State == types.CANCELED {
+			// a canceled operation remains canceled even if its orphan mitigation fails
+			newState = types.CANCELED
+		}
 		// if this is a force cascade action, we are trying to delete it directly from the database
 		// in case we are failing to delete it the operation will be marked as failed
 		if opAfterJob.IsForceDeleteCascadeOperation() && !opAfterJob.InOrphanMitigationState() {
@@ -524,6 +667,9 @@ func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError
 	isDeleteRescheduleRequired := opAfterJob.InOrphanMitigationState() &&
 		time.Now().UTC().Before(opAfterJob.DeletionScheduled.Add(s.reconciliationOperationTimeout)) &&
 		opAfterJob.State != types.SUCCEEDED
+	if !isDeleteRescheduleRequired {
+		s.notifyPostHooks(ctx, opAfterJob)
+	}
 
 	if isDeleteRescheduleRequired {
 		deletionAction := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
@@ -539,10 +685,17 @@ func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError
 			return nil, nil
 		}
 
-		log.C(ctx).Infof("Scheduling of required delete operation after actual operation with id %s failed", opAfterJob.ID)
-		// if deletion timestamp was set on the op, reschedule the same op with delete action and wait for reschedulingDelay time
-		// so that we don't DOS the broker
-		reschedulingDelayTimeout := time.After(s.reschedulingDelay)
+		// if deletion timestamp was set on the op, reschedule the same op with delete action and wait for the backoff of
+		// the retry policy of the resource so that we don't DOS the broker
+		retryPolicy := s.retryPolicies.RetryPolicy(opAfterJob.ResourceType)
+		opAfterJob.Retries++
+		reschedulingDelay := retryPolicy.Backoff(opAfterJob.Retries)
+		if retryPolicy.AttemptsExceeded(opAfterJob.Retries) {
+			// the preconditions fail the operation without executing the action
+			reschedulingDelay = 0
+		}
+		log.C(ctx).Infof("Scheduling of required delete operation after actual operation with id %s failed (retry %d in %s)", opAfterJob.ID, opAfterJob.Retries, reschedulingDelay)
+		reschedulingDelayTimeout := time.After(reschedulingDelay)
 		select {
 		case <-s.smCtx.Done():
 			return fmt.Errorf("sm context canceled: %s", s.smCtx.Err())
@@ -559,12 +712,57 @@ func (s *Scheduler) handleActionResponseFailure(ctx context.Context, actionError
 	return actionError
 }
 
+// isActionRetryRequired reports whether the action of the operation failed with a transient error and is retried
+// automatically according to the retry policy of the resource
+func (s *Scheduler) isActionRetryRequired(operation *types.Operation, actionError error) bool {
+	httpError, ok := actionError.(*util.HTTPError)
+	if !ok || httpError.StatusCode < http.StatusInternalServerError {
+		return false
+	}
+	if operation.State != types.IN_PROGRESS || operation.InOrphanMitigationState() || operation.Reschedule {
+		return false
+	}
+	// only asynchronous operations requested through the Service Manager API can be retried
+	if operation.Context == nil || !operation.Context.Async || operation.CascadeRootID != "" || operation.PlatformID != types.SMPlatform {
+		return false
+	}
+	if operation.Type == types.UPDATE && len(operation.RetryPayload) == 0 {
+		return false
+	}
+	return operation.Retries < s.retryPolicies.RetryPolicy(operation.ResourceType).MaxActionRetries
+}
+
+// retryFailedAction keeps the operation in progress and executes its action again once the backoff of the retry
+// policy of the resource has passed
+func (s *Scheduler) retryFailedAction(ctx context.Context, actionError error, opAfterJob *types.Operation) error {
+	action, err := s.retryAction(ctx, opAfterJob)
+	if err != nil {
+		return err
+	}
+
+	opAfterJob.Retries++
+	opAfterJob.Errors = json.RawMessage{}
+	if err := setOperationError(ctx, opAfterJob, actionError); err != nil {
+		return err
+	}
+	reschedulingDelay := s.retryPolicies.RetryPolicy(opAfterJob.ResourceType).Backoff(opAfterJob.Retries)
+	log.C(ctx).Infof("Scheduling retry %d of %s operation with id %s in %s after its action failed: %s", opAfterJob.Retries, opAfterJob.Type, opAfterJob.ID, reschedulingDelay, actionError)
+	select {
+	case <-s.smCtx.Done():
+		return fmt.Errorf("sm context canceled: %s", s.smCtx.Err())
+	case <-time.After(reschedulingDelay):
+		return s.ScheduleAsyncStorageAction(ctx, opAfterJob, action)
+	}
+}
+
 func (s *Scheduler) handleActionResponseSuccess(ctx context.Context, actionObject types.Object, opAfterJob *types.Operation) (types.Object, error) {
 	if err := s.repository.InTransaction(ctx, func(ctx context.Context, storage storage.Repository) error {
 		finalState := opAfterJob.State
 		if opAfterJob.Type != types.DELETE && opAfterJob.InOrphanMitigationState() {
-			// successful orphan mitigation for CREATE/UPDATE should still leave the operation as FAILED
-			finalState = types.FAILED
+			// successful orphan mitigation for CREATE/UPDATE should still leave the operation as FAILED or CANCELED
+			if finalState != types.CANCELED {
+				finalState = types.FAILED
+			}
 		} else {
 			// Guard to avoid set SUCCEEDED state on pending cascade operations
 			if len(opAfterJob.CascadeRootID) == 0 || opAfterJob.State != types.PENDING {
@@ -584,18 +782,7 @@ func (s *Scheduler) handleActionResponseSuccess(ctx context.Context, actionObjec
 		// after a successful CREATE operation, update the ready field to true
 		if opAfterJob.Type == types.CREATE && finalState == types.SUCCEEDED {
 			var err error
-			if actionObject, err = updateResource(ctx, storage, actionObject, func(obj types.Object) {
-				if serviceInstance, ok := obj.(*types.ServiceInstance); ok {
-					serviceInstance.Usable = true
-				}
-				obj.SetReady(true)
-			}); err != nil {
-				return err
-			}
-
-			if err := updateTransitiveResources(ctx, storage, opAfterJob.TransitiveResources, func(obj types.Object) {
-				obj.SetReady(true)
-			}); err != nil {
+			if actionObject, err = MarkCreated(ctx, storage, actionObject, opAfterJob.TransitiveResources); err != nil {
 				return err
 			}
 		}
@@ -609,6 +796,7 @@ func (s *Scheduler) handleActionResponseSuccess(ctx context.Context, actionObjec
 	}); err != nil {
 		return nil, fmt.Errorf("failed to update resource ready or operation state after a successfully executing operation with id %s: %s", opAfterJob.ID, err)
 	}
+	s.notifyPostHooks(ctx, opAfterJob)
 	log.C(ctx).Infof("Successful executed operation with ID (%s)", opAfterJob.ID)
 
 	return actionObject, nil
@@ -628,6 +816,14 @@ func (s *Scheduler) addOperationToContext(ctx context.Context, operation *types.
 }
 
 func (s *Scheduler) validateOperationDoesNotExceedTimeouts(operation *types.Operation) error {
+	retryPolicy := s.retryPolicies.RetryPolicy(operation.ResourceType)
+	if operation.InOrphanMitigationState() && retryPolicy.AttemptsExceeded(operation.Retries) {
+		return &util.HTTPError{
+			ErrorType:   "ManualActionRequired",
+			Description: fmt.Sprintf("operation has exceeded the maximum of %d retry attempts. Rootcause error: %s", retryPolicy.MaxAttempts, operation.Errors),
+			StatusCode:  http.StatusUnprocessableEntity,
+		}
+	}
 	if operation.CascadeRootID != "" && operation.InOrphanMitigationState() && time.Now().UTC().After(operation.CreatedAt.Add(s.cascadeOrphanMitigationTimeout)) {
 		return &util.HTTPError{
 			ErrorType:   "ManualActionRequired",
@@ -635,7 +831,12 @@ func (s *Scheduler) validateOperationDoesNotExceedTimeouts(operation *types.Oper
 			StatusCode:  http.StatusUnprocessableEntity,
 		}
 	}
-	if time.Now().UTC().After(operation.CreatedAt.Add(s.reconciliationOperationTimeout)) {
+	// scheduled operations are reconciled from the time they are due
+	startedAt := operation.CreatedAt
+	if operation.ScheduledAt.After(startedAt) {
+		startedAt = operation.ScheduledAt
+	}
+	if time.Now().UTC().After(startedAt.Add(s.reconciliationOperationTimeout)) {
 		return &util.HTTPError{
 			ErrorType:   "ManualActionRequired",
 			Description: fmt.Sprintf("operation is older than %v and has exceeded the maximum reconciliation timeout. Rootcause error: %s", s.reconciliationOperationTimeout, operation.Errors),
@@ -647,7 +848,7 @@ func (s *Scheduler) validateOperationDoesNotExceedTimeouts(operation *types.Oper
 
 func (s *Scheduler) executeOperationPreconditions(ctx context.Context, operation *types.Operation) error {
 	if operation.State == types.SUCCEEDED ||
-		(operation.State == types.FAILED && !operation.InOrphanMitigationState()) {
+		((operation.State == types.FAILED || operation.State == types.CANCELED) && !operation.InOrphanMitigationState()) {
 		return fmt.Errorf("scheduling for operations %+v is not allowed due to invalid state", operation)
 	}
 
@@ -671,7 +872,8 @@ func (s *Scheduler) executeOperationPreconditions(ctx context.Context, operation
 		return err
 	}
 
-	if lastOpFound {
+	// an operation which is retried is the last operation of its resource itself
+	if lastOpFound && lastOperation.ID != operation.ID {
 		if err := s.checkForConcurrentOperations(ctx, operation, lastOperation); err != nil {
 			log.C(ctx).Errorf("concurrent operation has been rejected: last operation is %+v, current operation is %+v and error is %s", lastOperation, operation, err)
 			return err
@@ -679,7 +881,7 @@ func (s *Scheduler) executeOperationPreconditions(ctx context.Context, operation
 
 		// Block updates of service instances or bindings that were not created successfully
 		if operation.Type == types.UPDATE {
-			if lastOperation.Type == types.CREATE && lastOperation.State == types.FAILED {
+			if lastOperation.Type == types.CREATE && (lastOperation.State == types.FAILED || lastOperation.State == types.CANCELED) {
 				if operation.ResourceType == types.ServiceBindingType || operation.ResourceType == types.ServiceInstanceType {
 					return &util.HTTPError{
 						ErrorType:   "UpdateOperationIsNotAllowed",
@@ -704,6 +906,8 @@ func initialLogMessage(ctx context.Context, operation *types.Operation, async bo
 		logPrefix = "Rescheduling (reschedule=true)"
 	} else if operation.InOrphanMitigationState() {
 		logPrefix = "Scheduling orphan mitigation"
+	} else if operation.IsScheduled() {
+		logPrefix = fmt.Sprintf("Scheduling delayed (scheduled_at=%s)", operation.ScheduledAt)
 	} else {
 		logPrefix = "Scheduling new"
 	}
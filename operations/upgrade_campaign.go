@@ -0,0 +1,262 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// UpgradeCampaignCriteria returns the criteria selecting the service instances of an upgrade campaign.
+// Only instances managed by the Service Manager platform are selected as their updates are sent to the brokers by SM.
+func UpgradeCampaignCriteria(campaign *types.UpgradeCampaign) ([]query.Criterion, error) {
+	fieldCriteria, err := query.Parse(query.FieldQuery, campaign.FieldQuery)
+	if err != nil {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("invalid field_query of upgrade campaign: %s", err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	labelCriteria, err := query.Parse(query.LabelQuery, campaign.LabelQuery)
+	if err != nil {
+		return nil, &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("invalid label_query of upgrade campaign: %s", err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	criteria := []query.Criterion{query.ByField(query.EqualsOperator, "platform_id", types.SMPlatform)}
+	criteria = append(criteria, fieldCriteria...)
+	return append(criteria, labelCriteria...), nil
+}
+
+// UpgradeCampaignProgress computes how many of the instances of the upgrade campaign are still to be upgraded,
+// are being upgraded and have been upgraded
+func UpgradeCampaignProgress(ctx context.Context, repository storage.Repository, campaign *types.UpgradeCampaign) (*types.UpgradeCampaignProgress, error) {
+	_, _, progress, err := upgradeCampaignStatus(ctx, repository, campaign)
+	return progress, err
+}
+
+// upgradeCampaignStatus returns the instances of the upgrade campaign which are still to be upgraded together with
+// their plans and the progress of the campaign. An instance is still to be upgraded if the version of its maintenance
+// info lags behind the version of its plan and no operation of the campaign has been executed for it.
+func upgradeCampaignStatus(ctx context.Context, repository storage.Repository, campaign *types.UpgradeCampaign) ([]*types.ServiceInstance, map[string]*types.ServicePlan, *types.UpgradeCampaignProgress, error) {
+	progress := &types.UpgradeCampaignProgress{}
+
+	operationList, err := repository.List(ctx, types.OperationType, query.ByLabel(query.EqualsOperator, types.UpgradeCampaignLabelKey, campaign.ID))
+	if err != nil {
+		return nil, nil, nil, util.HandleStorageError(err, types.OperationType.String())
+	}
+	upgradedInstances := make(map[string]bool)
+	for i := 0; i < operationList.Len(); i++ {
+		operation := operationList.ItemAt(i).(*types.Operation)
+		upgradedInstances[operation.ResourceID] = true
+		switch operation.State {
+		case types.SUCCEEDED:
+			progress.Succeeded++
+		case types.FAILED, types.CANCELED:
+			progress.Failed++
+		default:
+			// pending upgrades wait for the maintenance window of their instance and count towards the concurrency
+			progress.InProgress++
+		}
+	}
+
+	criteria, err := UpgradeCampaignCriteria(campaign)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	criteria = append(criteria, query.OrderResultBy("paging_sequence", query.AscOrder))
+	instanceList, err := repository.List(ctx, types.ServiceInstanceType, criteria...)
+	if err != nil {
+		return nil, nil, nil, util.HandleStorageError(err, types.ServiceInstanceType.String())
+	}
+
+	instances := make([]*types.ServiceInstance, 0)
+	planIDs := make([]string, 0)
+	for i := 0; i < instanceList.Len(); i++ {
+		instance := instanceList.ItemAt(i).(*types.ServiceInstance)
+		if upgradedInstances[instance.ID] {
+			continue
+		}
+		instances = append(instances, instance)
+		planIDs = append(planIDs, instance.ServicePlanID)
+	}
+
+	plans := make(map[string]*types.ServicePlan)
+	if len(planIDs) != 0 {
+		planList, err := repository.List(ctx, types.ServicePlanType, query.ByField(query.InOperator, "id", planIDs...))
+		if err != nil {
+			return nil, nil, nil, util.HandleStorageError(err, types.ServicePlanType.String())
+		}
+		for i := 0; i < planList.Len(); i++ {
+			plan := planList.ItemAt(i).(*types.ServicePlan)
+			plans[plan.ID] = plan
+		}
+	}
+
+	pendingInstances := make([]*types.ServiceInstance, 0)
+	for _, instance := range instances {
+		plan, found := plans[instance.ServicePlanID]
+		if !found || !types.MaintenanceInfoLags(instance.MaintenanceInfo, plan.MaintenanceInfo) {
+			continue
+		}
+		pendingInstances = append(pendingInstances, instance)
+	}
+	progress.Pending = len(pendingInstances)
+	progress.Total = progress.Pending + progress.InProgress + progress.Succeeded + progress.Failed
+
+	return pendingInstances, plans, progress, nil
+}
+
+// driveUpgradeCampaigns starts the upgrades of the instances of the campaigns in progress within the concurrency
+// limits of the campaigns and marks the campaigns without further upgrades as finished
+func (om *Maintainer) driveUpgradeCampaigns(ctx context.Context) error {
+	criteria := query.ByField(query.EqualsOperator, "state", string(types.UpgradeCampaignInProgress))
+	campaignList, err := om.repository.List(ctx, types.UpgradeCampaignType, criteria)
+	if err != nil {
+		return fmt.Errorf("failed to fetch upgrade campaigns in progress: %s", err)
+	}
+
+	for i := 0; i < campaignList.Len(); i++ {
+		campaign := campaignList.ItemAt(i).(*types.UpgradeCampaign)
+		if err := om.driveUpgradeCampaign(ctx, campaign); err != nil {
+			log.C(ctx).Warnf("Failed to drive upgrade campaign with ID (%s): %s", campaign.ID, err)
+		}
+	}
+
+	log.C(ctx).Debug("Finished driving upgrade campaigns")
+	return nil
+}
+
+func (om *Maintainer) driveUpgradeCampaign(ctx context.Context, campaign *types.UpgradeCampaign) error {
+	pendingInstances, plans, progress, err := upgradeCampaignStatus(ctx, om.repository, campaign)
+	if err != nil {
+		return err
+	}
+
+	if progress.Pending == 0 && progress.InProgress == 0 {
+		campaign.State = types.UpgradeCampaignSucceeded
+		if progress.Failed != 0 {
+			campaign.State = types.UpgradeCampaignFailed
+		}
+		// the campaign might have been paused meanwhile in which case it is finished once it is resumed
+		byState := query.ByField(query.EqualsOperator, "state", string(types.UpgradeCampaignInProgress))
+		if _, err := om.repository.Update(ctx, campaign, types.LabelChanges{}, byState); err != nil && err != util.ErrConcurrentResourceModification {
+			return err
+		}
+		log.C(ctx).Infof("Upgrade campaign with ID (%s) finished with state %s: %+v", campaign.ID, campaign.State, progress)
+		return nil
+	}
+
+	for i := 0; i < campaign.MaxConcurrency-progress.InProgress && i < len(pendingInstances); i++ {
+		instance := pendingInstances[i]
+		if err := om.upgradeInstance(ctx, campaign, instance, plans[instance.ServicePlanID]); err != nil {
+			log.C(ctx).Warnf("Failed to start the upgrade of instance with ID (%s) of upgrade campaign with ID (%s): %s", instance.ID, campaign.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// upgradeInstance updates the maintenance info of the instance to the one of its plan through the interceptable
+// repository so that the service instance interceptors send the update to the broker. The update is deferred to the
+// next maintenance window of the instance if none of its windows is open.
+func (om *Maintainer) upgradeInstance(ctx context.Context, campaign *types.UpgradeCampaign, instance *types.ServiceInstance, plan *types.ServicePlan) error {
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	correlationID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	operation := &types.Operation{
+		Base: types.Base{
+			ID:        UUID.String(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Labels: types.Labels{
+				types.UpgradeCampaignLabelKey: {campaign.ID},
+			},
+			Ready: true,
+		},
+		Type:          types.UPDATE,
+		State:         types.IN_PROGRESS,
+		ResourceID:    instance.ID,
+		ResourceType:  types.ServiceInstanceType,
+		PlatformID:    types.SMPlatform,
+		CorrelationID: correlationID.String(),
+		Context: &types.OperationContext{
+			Async:         true,
+			ServicePlanID: instance.ServicePlanID,
+		},
+	}
+
+	var tenant string
+	if tenants := instance.GetLabels()[om.scheduler.tenantLabelKey]; om.scheduler.tenantLabelKey != "" && len(tenants) != 0 {
+		tenant = tenants[0]
+	}
+	if tenant != "" {
+		// the operation is visible to the tenant of the instance although it is not created by a tenant request
+		operation.Labels[om.scheduler.tenantLabelKey] = []string{tenant}
+	}
+	windowStart := om.settings.NextMaintenanceWindow(instance.PlatformID, tenant, now)
+
+	logger := log.C(ctx).WithField(log.FieldCorrelationID, operation.CorrelationID)
+	ctx = log.ContextWithLogger(ctx, logger)
+	logger.Infof("Upgrading maintenance info of instance with ID (%s) from %s to %s as part of upgrade campaign with ID (%s)",
+		instance.ID, instance.MaintenanceInfo, plan.MaintenanceInfo, campaign.ID)
+
+	// the upgrade must not overwrite changes of the instance made until it is executed
+	updatedAt := query.ByField(query.EqualsOperator, "updated_at", instance.GetUpdatedAt().UTC().Format(time.RFC3339Nano))
+	byID := query.ByField(query.EqualsOperator, "id", instance.ID)
+	instance.MaintenanceInfo = plan.MaintenanceInfo
+
+	if !windowStart.IsZero() {
+		logger.Infof("Deferring upgrade of instance with ID (%s) to the maintenance window starting at %s", instance.ID, windowStart)
+		item := &storage.BatchItem{
+			Type:          types.UPDATE,
+			ObjectType:    types.ServiceInstanceType,
+			Object:        instance,
+			LabelChanges:  types.LabelChanges{},
+			Criteria:      []query.Criterion{byID},
+			Preconditions: []query.Criterion{updatedAt},
+		}
+		return om.scheduler.ScheduleStorageItemAt(ctx, operation, item, windowStart)
+	}
+
+	action := func(ctx context.Context, repository storage.Repository) (types.Object, error) {
+		object, err := repository.Update(ctx, instance, types.LabelChanges{}, byID)
+		return object, util.HandleStorageError(err, types.ServiceInstanceType.String())
+	}
+	return om.scheduler.ScheduleAsyncStorageAction(ctx, operation, action)
+}
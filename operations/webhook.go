@@ -0,0 +1,144 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+const (
+	// HookSignatureHeader contains the hex encoded HMAC-SHA256 of the timestamp and the body of a webhook request
+	HookSignatureHeader = "X-Service-Manager-Signature"
+
+	// HookTimestampHeader contains the unix time at which a webhook request was signed
+	HookTimestampHeader = "X-Service-Manager-Timestamp"
+
+	defaultHookTimeout = 30 * time.Second
+)
+
+// Webhook is an operation hook which posts the operations it matches to an HTTP endpoint. The requests are signed
+// with the secret of the hook. Pre hooks approve an operation by responding with 200 OK or 204 No Content, defer it
+// with 202 Accepted and reject it with 403 Forbidden or 422 Unprocessable Entity. Other responses, e.g. of a
+// misconfigured or rate limited hook, and failed requests defer the operation.
+type Webhook struct {
+	settings HookSettings
+	client   *http.Client
+}
+
+// NewWebhook creates a webhook with the given settings
+func NewWebhook(settings HookSettings) *Webhook {
+	timeout := settings.Timeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	return &Webhook{
+		settings: settings,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the name of the webhook
+func (w *Webhook) Name() string {
+	return w.settings.Name
+}
+
+// Phase returns the phase of the operations in which the webhook is invoked
+func (w *Webhook) Phase() HookPhase {
+	return HookPhase(w.settings.Phase)
+}
+
+// Matches reports whether the operation is of one of the resources and types of the webhook
+func (w *Webhook) Matches(operation *types.Operation) bool {
+	return matchesAny(w.settings.Resources, operation.ResourceType.String()) &&
+		matchesAny(w.settings.OperationTypes, string(operation.Type))
+}
+
+// Invoke posts the request to the URL of the webhook
+func (w *Webhook) Invoke(ctx context.Context, request *HookRequest) (*HookResult, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, w.settings.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set(HookTimestampHeader, timestamp)
+	httpRequest.Header.Set(HookSignatureHeader, SignHookRequest(w.settings.Secret, timestamp, body))
+
+	response, err := w.client.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HookResult{}
+	if len(responseBody) != 0 {
+		// the verdict is determined by the status code, the body may only contain a description
+		_ = json.Unmarshal(responseBody, result)
+	}
+	switch {
+	case response.StatusCode == http.StatusOK || response.StatusCode == http.StatusNoContent:
+		result.Verdict = HookApproved
+	case response.StatusCode == http.StatusAccepted:
+		result.Verdict = HookPending
+	case response.StatusCode == http.StatusForbidden || response.StatusCode == http.StatusUnprocessableEntity:
+		result.Verdict = HookRejected
+	default:
+		return nil, fmt.Errorf("hook responded with status %d", response.StatusCode)
+	}
+	return result, nil
+}
+
+// SignHookRequest returns the signature of a webhook request with the given timestamp and body
+func SignHookRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
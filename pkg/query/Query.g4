@@ -1,19 +1,23 @@
 grammar Query ;
 
 expression: criterions EOF ;
-criterions: criterion (Concat criterions)? ;
-criterion: multivariate | univariate  ;
+criterions: andCriterions (Or criterions)? ;
+andCriterions: criterion (Concat andCriterions)? ;
+criterion: Not criterion | OpenBracket criterions CloseBracket | multivariate | univariate  ;
 multivariate: Key Whitespace MultiOp Whitespace multiValues ;
 univariate: Key Whitespace UniOp Whitespace Value ;
 multiValues: OpenBracket manyValues? CloseBracket ;
 manyValues: Value (ValueSeparator manyValues)? ;
 
 MultiOp:  'in' | 'notin' ;
-UniOp: 'eq' | 'ne' | 'gt' | 'lt' | 'ge' | 'le' | 'en' ;
+UniOp: 'eq' | 'ne' | 'gt' | 'lt' | 'ge' | 'le' | 'en' | 'contains' | 'startswith' | 'matches' ;
 Concat: Whitespace 'and' Whitespace ;
+Or: Whitespace 'or' Whitespace ;
+// not is a keyword, so fields and labels with key not cannot be queried
+Not: 'not' Whitespace ;
 Value: STRING | NUMBER | BOOLEAN | DATETIME ;
 ValueSeparator: ',' | ', ' ;
-Key: [-_/a-zA-Z0-9\\]+ ;
+Key: [-_/.a-zA-Z0-9\\]+ ;
 OpenBracket: '(' ;
 CloseBracket: ')';
 
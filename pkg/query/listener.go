@@ -36,6 +36,42 @@ type queryListener struct {
 	result       []Criterion
 }
 
+// ExitExpression is called when production expression is exited.
+func (s *queryListener) ExitExpression(ctx *parser.ExpressionContext) {
+	if s.err != nil || len(s.result) != 1 {
+		return
+	}
+	// criteria joined only by "and" are kept as a flat list, as they are already combined by conjunction
+	if root := s.result[0]; root.Operator == AndOperator {
+		s.result = root.Criteria
+	}
+}
+
+// ExitCriterions is called when production criterions is exited.
+func (s *queryListener) ExitCriterions(ctx *parser.CriterionsContext) {
+	if s.err != nil || ctx.Or() == nil {
+		return
+	}
+	s.combineCriteria(OrOperator)
+}
+
+// ExitAndCriterions is called when production andCriterions is exited.
+func (s *queryListener) ExitAndCriterions(ctx *parser.AndCriterionsContext) {
+	if s.err != nil || ctx.Concat() == nil {
+		return
+	}
+	s.combineCriteria(AndOperator)
+}
+
+// ExitCriterion is called when production criterion is exited.
+func (s *queryListener) ExitCriterion(ctx *parser.CriterionContext) {
+	if s.err != nil || ctx.Not() == nil {
+		return
+	}
+	criterion := s.popCriterion()
+	s.result = append(s.result, ByNot(criterion))
+}
+
 // ExitUnivariate is called when production univariate is exited.
 func (s *queryListener) ExitUnivariate(ctx *parser.UnivariateContext) {
 	if s.err != nil {
@@ -86,6 +122,28 @@ func (s *queryListener) storeCriterion() error {
 	return nil
 }
 
+// combineCriteria replaces the last two parsed criteria with a single criterion joining them with the given operator
+func (s *queryListener) combineCriteria(operator Operator) {
+	right := s.popCriterion()
+	left := s.popCriterion()
+	criteria := make([]Criterion, 0, 2)
+	for _, criterion := range []Criterion{left, right} {
+		if criterion.Operator == operator {
+			criteria = append(criteria, criterion.Criteria...)
+		} else {
+			criteria = append(criteria, criterion)
+		}
+	}
+	s.result = append(s.result, newLogicalCriterion(operator, criteria...))
+}
+
+func (s *queryListener) popCriterion() Criterion {
+	last := len(s.result) - 1
+	criterion := s.result[last]
+	s.result = s.result[:last]
+	return criterion
+}
+
 func (s *queryListener) ReportAmbiguity(recognizer antlr.Parser, dfa *antlr.DFA, startIndex, stopIndex int, exact bool, ambigAlts *antlr.BitSet, configs antlr.ATNConfigSet) {
 }
 
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util/slice"
+)
+
+// MatchesLabels tests if the labels satisfy all label criteria without querying the storage. As in the label queries
+// on the storage, a label criterion is satisfied if any of the values of its label satisfies the operator.
+func MatchesLabels(labels types.Labels, criteria ...Criterion) (bool, error) {
+	for _, criterion := range criteria {
+		matches, err := matchesLabelCriterion(labels, criterion)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchesLabelCriterion(labels types.Labels, criterion Criterion) (bool, error) {
+	if criterion.IsLogical() {
+		switch criterion.Operator {
+		case AndOperator:
+			return MatchesLabels(labels, criterion.Criteria...)
+		case OrOperator:
+			for _, c := range criterion.Criteria {
+				matches, err := matchesLabelCriterion(labels, c)
+				if err != nil || matches {
+					return matches, err
+				}
+			}
+			return false, nil
+		case NotOperator:
+			matches, err := MatchesLabels(labels, criterion.Criteria...)
+			return !matches, err
+		}
+	}
+	if criterion.Type != LabelQuery {
+		return false, fmt.Errorf("only label criteria can be matched against labels, got %s", criterion.Type)
+	}
+
+	for _, value := range labels[criterion.LeftOp] {
+		matches, err := matchesValue(value, criterion.Operator, criterion.RightOp)
+		if err != nil || matches {
+			return matches, err
+		}
+	}
+	return false, nil
+}
+
+func matchesValue(value string, operator Operator, rightOp []string) (bool, error) {
+	switch operator {
+	case EqualsOperator:
+		return value == rightOp[0], nil
+	case NotEqualsOperator:
+		return value != rightOp[0], nil
+	case InOperator:
+		return slice.StringsAnyEquals(rightOp, value), nil
+	case NotInOperator:
+		return !slice.StringsAnyEquals(rightOp, value), nil
+	case ContainsOperator:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(rightOp[0])), nil
+	case StartsWithOperator:
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(rightOp[0])), nil
+	case MatchesOperator:
+		return regexp.MatchString("(?i)"+rightOp[0], value)
+	case GreaterThanOperator, GreaterThanOrEqualOperator, LessThanOperator, LessThanOrEqualOperator:
+		left, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			// label values which are not numeric do not satisfy numeric comparisons
+			return false, nil
+		}
+		right, err := strconv.ParseFloat(rightOp[0], 64)
+		if err != nil {
+			return false, fmt.Errorf("%s is not numeric", rightOp[0])
+		}
+		switch operator {
+		case GreaterThanOperator:
+			return left > right, nil
+		case GreaterThanOrEqualOperator:
+			return left >= right, nil
+		case LessThanOperator:
+			return left < right, nil
+		default:
+			return left <= right, nil
+		}
+	}
+	return false, fmt.Errorf("operator %s is not supported when matching labels", operator)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/Peripli/service-manager/pkg/types"
+
+	. "github.com/Peripli/service-manager/pkg/query"
+)
+
+var _ = Describe("Match labels", func() {
+	labels := types.Labels{
+		"env":     {"Dev", "test"},
+		"version": {"10"},
+	}
+
+	DescribeTable("matches the labels with",
+		func(criterion Criterion, expected bool) {
+			matches, err := MatchesLabels(labels, criterion)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(Equal(expected))
+		},
+		Entry("eq", ByLabel(EqualsOperator, "env", "Dev"), true),
+		Entry("eq comparing case", ByLabel(EqualsOperator, "env", "dev"), false),
+		Entry("ne", ByLabel(NotEqualsOperator, "env", "Dev"), true),
+		Entry("in", ByLabel(InOperator, "env", "prod", "test"), true),
+		Entry("in without matching value", ByLabel(InOperator, "env", "prod"), false),
+		Entry("notin", ByLabel(NotInOperator, "env", "prod"), true),
+		Entry("contains", ByLabel(ContainsOperator, "env", "es"), true),
+		Entry("contains ignoring case", ByLabel(ContainsOperator, "env", "EV"), true),
+		Entry("contains without matching value", ByLabel(ContainsOperator, "env", "prod"), false),
+		Entry("startswith", ByLabel(StartsWithOperator, "env", "te"), true),
+		Entry("startswith ignoring case", ByLabel(StartsWithOperator, "env", "dE"), true),
+		Entry("startswith without matching value", ByLabel(StartsWithOperator, "env", "ev"), false),
+		Entry("matches", ByLabel(MatchesOperator, "env", "^t.*t$"), true),
+		Entry("matches ignoring case", ByLabel(MatchesOperator, "env", "^DEV$"), true),
+		Entry("matches without matching value", ByLabel(MatchesOperator, "env", "^prod"), false),
+		Entry("gt", ByLabel(GreaterThanOperator, "version", "9"), true),
+		Entry("ge", ByLabel(GreaterThanOrEqualOperator, "version", "10"), true),
+		Entry("lt", ByLabel(LessThanOperator, "version", "10"), false),
+		Entry("le", ByLabel(LessThanOrEqualOperator, "version", "10"), true),
+		Entry("numeric operator on non-numeric values", ByLabel(GreaterThanOperator, "env", "1"), false),
+		Entry("missing label", ByLabel(EqualsOperator, "region", "eu"), false),
+		Entry("and", ByAll(ByLabel(EqualsOperator, "env", "test"), ByLabel(EqualsOperator, "version", "10")), true),
+		Entry("or", ByAny(ByLabel(EqualsOperator, "env", "prod"), ByLabel(EqualsOperator, "version", "10")), true),
+		Entry("not", ByNot(ByLabel(EqualsOperator, "env", "prod")), true),
+	)
+
+	It("fails for criteria which are not label criteria", func() {
+		_, err := MatchesLabels(labels, ByField(EqualsOperator, "env", "Dev"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for invalid regular expressions", func() {
+		_, err := MatchesLabels(labels, ByLabel(MatchesOperator, "env", "("))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -41,6 +41,21 @@ const (
 	ExistsSubquery existsSubquery = "exists"
 	// EqualsOrNilOperator takes two operands and tests if the left is equal to the right, or if the left is nil
 	EqualsOrNilOperator enOperator = "en"
+	// ContainsOperator takes two operands and tests if the left contains the right as a substring ignoring case
+	ContainsOperator containsOperator = "contains"
+	// StartsWithOperator takes two operands and tests if the left starts with the right ignoring case
+	StartsWithOperator startsWithOperator = "startswith"
+	// MatchesOperator takes two operands and tests if the left matches the regular expression in the right ignoring case
+	MatchesOperator matchesOperator = "matches"
+	// TextSearchOperator takes two operands and tests if the left, a text search column, contains words starting with
+	// each of the words in the right. It is not supported in field queries.
+	TextSearchOperator textSearchOperator = "search"
+	// AndOperator combines criteria and tests if all of them are satisfied
+	AndOperator andOperator = "and"
+	// OrOperator combines criteria and tests if at least one of them is satisfied
+	OrOperator orOperator = "or"
+	// NotOperator takes a single criterion and tests if it is not satisfied
+	NotOperator notOperator = "not"
 
 	NoOperator noOperator = "nop"
 )
@@ -260,3 +275,129 @@ func (noOperator) IsNullable() bool {
 func (noOperator) IsNumeric() bool {
 	return false
 }
+
+type containsOperator string
+
+func (o containsOperator) String() string {
+	return string(o)
+}
+
+func (containsOperator) Type() OperatorType {
+	return UnivariateOperator
+}
+
+func (containsOperator) IsNullable() bool {
+	return false
+}
+
+func (containsOperator) IsNumeric() bool {
+	return false
+}
+
+type startsWithOperator string
+
+func (o startsWithOperator) String() string {
+	return string(o)
+}
+
+func (startsWithOperator) Type() OperatorType {
+	return UnivariateOperator
+}
+
+func (startsWithOperator) IsNullable() bool {
+	return false
+}
+
+func (startsWithOperator) IsNumeric() bool {
+	return false
+}
+
+type matchesOperator string
+
+func (o matchesOperator) String() string {
+	return string(o)
+}
+
+func (matchesOperator) Type() OperatorType {
+	return UnivariateOperator
+}
+
+func (matchesOperator) IsNullable() bool {
+	return false
+}
+
+func (matchesOperator) IsNumeric() bool {
+	return false
+}
+
+type textSearchOperator string
+
+func (o textSearchOperator) String() string {
+	return string(o)
+}
+
+func (textSearchOperator) Type() OperatorType {
+	return UnivariateOperator
+}
+
+func (textSearchOperator) IsNullable() bool {
+	return false
+}
+
+func (textSearchOperator) IsNumeric() bool {
+	return false
+}
+
+type andOperator string
+
+func (o andOperator) String() string {
+	return string(o)
+}
+
+func (andOperator) Type() OperatorType {
+	return LogicalOperator
+}
+
+func (andOperator) IsNullable() bool {
+	return false
+}
+
+func (andOperator) IsNumeric() bool {
+	return false
+}
+
+type orOperator string
+
+func (o orOperator) String() string {
+	return string(o)
+}
+
+func (orOperator) Type() OperatorType {
+	return LogicalOperator
+}
+
+func (orOperator) IsNullable() bool {
+	return false
+}
+
+func (orOperator) IsNumeric() bool {
+	return false
+}
+
+type notOperator string
+
+func (o notOperator) String() string {
+	return string(o)
+}
+
+func (notOperator) Type() OperatorType {
+	return LogicalOperator
+}
+
+func (notOperator) IsNullable() bool {
+	return false
+}
+
+func (notOperator) IsNumeric() bool {
+	return false
+}
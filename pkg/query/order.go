@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// ParseOrderBy parses an order by expression such as "name asc,created_at desc" and builds the result criteria for it.
+// The order type is optional and defaults to ascending. Fields prefixed with labels. order the result by the value of the label
+// with the remaining key.
+func ParseOrderBy(expression string) ([]Criterion, error) {
+	if strings.TrimSpace(expression) == "" {
+		return []Criterion{}, nil
+	}
+	criteria := make([]Criterion, 0)
+	fields := make(map[string]bool)
+	for _, rule := range strings.Split(expression, ",") {
+		parts := strings.Fields(rule)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("invalid order by rule \"%s\": expected field name optionally followed by order type", strings.TrimSpace(rule))}
+		}
+		field := parts[0]
+		orderType := AscOrder
+		if len(parts) == 2 {
+			orderType = OrderType(strings.ToUpper(parts[1]))
+			if orderType != AscOrder && orderType != DescOrder {
+				return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported order type: %s", parts[1])}
+			}
+		}
+		if fields[field] {
+			return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("duplicate order by field: %s", field)}
+		}
+		fields[field] = true
+
+		if strings.HasPrefix(field, LabelFieldPrefix) {
+			key := strings.TrimPrefix(field, LabelFieldPrefix)
+			if key == "" {
+				return nil, &util.UnsupportedQueryError{Message: "order by label expects label key"}
+			}
+			criteria = append(criteria, OrderResultByLabel(key, orderType))
+		} else {
+			criteria = append(criteria, OrderResultBy(field, orderType))
+		}
+	}
+	return criteria, nil
+}
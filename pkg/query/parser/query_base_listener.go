@@ -31,6 +31,12 @@ func (s *BaseQueryListener) EnterCriterions(ctx *CriterionsContext) {}
 // ExitCriterions is called when production criterions is exited.
 func (s *BaseQueryListener) ExitCriterions(ctx *CriterionsContext) {}
 
+// EnterAndCriterions is called when production andCriterions is entered.
+func (s *BaseQueryListener) EnterAndCriterions(ctx *AndCriterionsContext) {}
+
+// ExitAndCriterions is called when production andCriterions is exited.
+func (s *BaseQueryListener) ExitAndCriterions(ctx *AndCriterionsContext) {}
+
 // EnterCriterion is called when production criterion is entered.
 func (s *BaseQueryListener) EnterCriterion(ctx *CriterionContext) {}
 
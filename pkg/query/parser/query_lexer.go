@@ -12,109 +12,127 @@ var _ = fmt.Printf
 var _ = unicode.IsLetter
 
 var serializedLexerAtn = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 12, 238,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 14, 279,
 	8, 1, 4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7,
 	9, 7, 4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12,
 	4, 13, 9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4,
 	18, 9, 18, 4, 19, 9, 19, 4, 20, 9, 20, 4, 21, 9, 21, 4, 22, 9, 22, 4, 23,
 	9, 23, 4, 24, 9, 24, 4, 25, 9, 25, 4, 26, 9, 26, 4, 27, 9, 27, 4, 28, 9,
 	28, 4, 29, 9, 29, 4, 30, 9, 30, 4, 31, 9, 31, 4, 32, 9, 32, 4, 33, 9, 33,
-	4, 34, 9, 34, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 5, 2, 77, 10, 2,
+	4, 34, 9, 34, 4, 35, 9, 35, 4, 36, 9, 36, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2,
+	3, 2, 3, 2, 5, 2, 81, 10, 2, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 5, 3, 93, 10, 3, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4,
-	3, 5, 3, 5, 3, 5, 3, 5, 5, 5, 106, 10, 5, 3, 6, 3, 6, 3, 6, 5, 6, 111,
-	10, 6, 3, 7, 6, 7, 114, 10, 7, 13, 7, 14, 7, 115, 3, 8, 3, 8, 3, 9, 3,
-	9, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 3, 10, 5, 10,
-	131, 10, 10, 3, 11, 3, 11, 3, 11, 3, 11, 3, 11, 3, 11, 7, 11, 139, 10,
-	11, 12, 11, 14, 11, 142, 11, 11, 3, 11, 3, 11, 3, 12, 3, 12, 3, 12, 3,
-	12, 3, 12, 3, 13, 3, 13, 3, 13, 3, 14, 3, 14, 3, 14, 3, 15, 3, 15, 3, 16,
-	3, 16, 3, 16, 3, 17, 3, 17, 3, 17, 3, 18, 3, 18, 3, 18, 3, 19, 3, 19, 6,
-	19, 170, 10, 19, 13, 19, 14, 19, 171, 3, 20, 3, 20, 3, 20, 3, 20, 3, 20,
-	3, 21, 3, 21, 5, 21, 181, 10, 21, 3, 22, 3, 22, 3, 22, 3, 22, 3, 22, 3,
-	22, 5, 22, 189, 10, 22, 3, 23, 3, 23, 3, 23, 3, 23, 3, 23, 3, 23, 3, 24,
-	3, 24, 3, 24, 3, 25, 3, 25, 3, 25, 3, 25, 3, 26, 3, 26, 3, 26, 3, 27, 3,
-	27, 3, 27, 3, 28, 3, 28, 3, 28, 3, 29, 5, 29, 214, 10, 29, 3, 29, 3, 29,
-	3, 29, 5, 29, 219, 10, 29, 3, 30, 3, 30, 3, 31, 6, 31, 224, 10, 31, 13,
-	31, 14, 31, 225, 3, 32, 3, 32, 3, 33, 3, 33, 3, 34, 6, 34, 233, 10, 34,
-	13, 34, 14, 34, 234, 3, 34, 3, 34, 2, 2, 35, 3, 3, 5, 4, 7, 5, 9, 6, 11,
-	7, 13, 8, 15, 9, 17, 10, 19, 2, 21, 2, 23, 2, 25, 2, 27, 2, 29, 2, 31,
-	2, 33, 2, 35, 2, 37, 2, 39, 2, 41, 2, 43, 2, 45, 2, 47, 2, 49, 2, 51, 2,
-	53, 2, 55, 2, 57, 2, 59, 2, 61, 2, 63, 2, 65, 11, 67, 12, 3, 2, 8, 8, 2,
-	47, 47, 49, 59, 67, 92, 94, 94, 97, 97, 99, 124, 4, 2, 41, 41, 94, 94,
-	4, 2, 86, 86, 118, 118, 4, 2, 45, 45, 47, 47, 3, 2, 50, 59, 5, 2, 11, 12,
-	15, 15, 34, 34, 2, 237, 2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2,
-	2, 2, 2, 9, 3, 2, 2, 2, 2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3,
-	2, 2, 2, 2, 17, 3, 2, 2, 2, 2, 65, 3, 2, 2, 2, 2, 67, 3, 2, 2, 2, 3, 76,
-	3, 2, 2, 2, 5, 92, 3, 2, 2, 2, 7, 94, 3, 2, 2, 2, 9, 105, 3, 2, 2, 2, 11,
-	110, 3, 2, 2, 2, 13, 113, 3, 2, 2, 2, 15, 117, 3, 2, 2, 2, 17, 119, 3,
-	2, 2, 2, 19, 130, 3, 2, 2, 2, 21, 132, 3, 2, 2, 2, 23, 145, 3, 2, 2, 2,
-	25, 150, 3, 2, 2, 2, 27, 153, 3, 2, 2, 2, 29, 156, 3, 2, 2, 2, 31, 158,
-	3, 2, 2, 2, 33, 161, 3, 2, 2, 2, 35, 164, 3, 2, 2, 2, 37, 167, 3, 2, 2,
-	2, 39, 173, 3, 2, 2, 2, 41, 180, 3, 2, 2, 2, 43, 182, 3, 2, 2, 2, 45, 190,
-	3, 2, 2, 2, 47, 196, 3, 2, 2, 2, 49, 199, 3, 2, 2, 2, 51, 203, 3, 2, 2,
-	2, 53, 206, 3, 2, 2, 2, 55, 209, 3, 2, 2, 2, 57, 213, 3, 2, 2, 2, 59, 220,
-	3, 2, 2, 2, 61, 223, 3, 2, 2, 2, 63, 227, 3, 2, 2, 2, 65, 229, 3, 2, 2,
-	2, 67, 232, 3, 2, 2, 2, 69, 70, 7, 107, 2, 2, 70, 77, 7, 112, 2, 2, 71,
-	72, 7, 112, 2, 2, 72, 73, 7, 113, 2, 2, 73, 74, 7, 118, 2, 2, 74, 75, 7,
-	107, 2, 2, 75, 77, 7, 112, 2, 2, 76, 69, 3, 2, 2, 2, 76, 71, 3, 2, 2, 2,
-	77, 4, 3, 2, 2, 2, 78, 79, 7, 103, 2, 2, 79, 93, 7, 115, 2, 2, 80, 81,
-	7, 112, 2, 2, 81, 93, 7, 103, 2, 2, 82, 83, 7, 105, 2, 2, 83, 93, 7, 118,
-	2, 2, 84, 85, 7, 110, 2, 2, 85, 93, 7, 118, 2, 2, 86, 87, 7, 105, 2, 2,
-	87, 93, 7, 103, 2, 2, 88, 89, 7, 110, 2, 2, 89, 93, 7, 103, 2, 2, 90, 91,
-	7, 103, 2, 2, 91, 93, 7, 112, 2, 2, 92, 78, 3, 2, 2, 2, 92, 80, 3, 2, 2,
-	2, 92, 82, 3, 2, 2, 2, 92, 84, 3, 2, 2, 2, 92, 86, 3, 2, 2, 2, 92, 88,
-	3, 2, 2, 2, 92, 90, 3, 2, 2, 2, 93, 6, 3, 2, 2, 2, 94, 95, 5, 65, 33, 2,
-	95, 96, 7, 99, 2, 2, 96, 97, 7, 112, 2, 2, 97, 98, 7, 102, 2, 2, 98, 99,
-	3, 2, 2, 2, 99, 100, 5, 65, 33, 2, 100, 8, 3, 2, 2, 2, 101, 106, 5, 21,
-	11, 2, 102, 106, 5, 57, 29, 2, 103, 106, 5, 19, 10, 2, 104, 106, 5, 49,
-	25, 2, 105, 101, 3, 2, 2, 2, 105, 102, 3, 2, 2, 2, 105, 103, 3, 2, 2, 2,
-	105, 104, 3, 2, 2, 2, 106, 10, 3, 2, 2, 2, 107, 111, 7, 46, 2, 2, 108,
-	109, 7, 46, 2, 2, 109, 111, 7, 34, 2, 2, 110, 107, 3, 2, 2, 2, 110, 108,
-	3, 2, 2, 2, 111, 12, 3, 2, 2, 2, 112, 114, 9, 2, 2, 2, 113, 112, 3, 2,
-	2, 2, 114, 115, 3, 2, 2, 2, 115, 113, 3, 2, 2, 2, 115, 116, 3, 2, 2, 2,
-	116, 14, 3, 2, 2, 2, 117, 118, 7, 42, 2, 2, 118, 16, 3, 2, 2, 2, 119, 120,
-	7, 43, 2, 2, 120, 18, 3, 2, 2, 2, 121, 122, 7, 118, 2, 2, 122, 123, 7,
-	116, 2, 2, 123, 124, 7, 119, 2, 2, 124, 131, 7, 103, 2, 2, 125, 126, 7,
-	104, 2, 2, 126, 127, 7, 99, 2, 2, 127, 128, 7, 110, 2, 2, 128, 129, 7,
-	117, 2, 2, 129, 131, 7, 103, 2, 2, 130, 121, 3, 2, 2, 2, 130, 125, 3, 2,
-	2, 2, 131, 20, 3, 2, 2, 2, 132, 140, 7, 41, 2, 2, 133, 134, 7, 94, 2, 2,
-	134, 139, 11, 2, 2, 2, 135, 136, 7, 41, 2, 2, 136, 139, 7, 41, 2, 2, 137,
-	139, 10, 3, 2, 2, 138, 133, 3, 2, 2, 2, 138, 135, 3, 2, 2, 2, 138, 137,
-	3, 2, 2, 2, 139, 142, 3, 2, 2, 2, 140, 138, 3, 2, 2, 2, 140, 141, 3, 2,
-	2, 2, 141, 143, 3, 2, 2, 2, 142, 140, 3, 2, 2, 2, 143, 144, 7, 41, 2, 2,
-	144, 22, 3, 2, 2, 2, 145, 146, 5, 61, 31, 2, 146, 147, 5, 61, 31, 2, 147,
-	148, 5, 61, 31, 2, 148, 149, 5, 61, 31, 2, 149, 24, 3, 2, 2, 2, 150, 151,
-	5, 61, 31, 2, 151, 152, 5, 61, 31, 2, 152, 26, 3, 2, 2, 2, 153, 154, 5,
-	61, 31, 2, 154, 155, 5, 61, 31, 2, 155, 28, 3, 2, 2, 2, 156, 157, 9, 4,
-	2, 2, 157, 30, 3, 2, 2, 2, 158, 159, 5, 61, 31, 2, 159, 160, 5, 61, 31,
-	2, 160, 32, 3, 2, 2, 2, 161, 162, 5, 61, 31, 2, 162, 163, 5, 61, 31, 2,
-	163, 34, 3, 2, 2, 2, 164, 165, 5, 61, 31, 2, 165, 166, 5, 61, 31, 2, 166,
-	36, 3, 2, 2, 2, 167, 169, 7, 48, 2, 2, 168, 170, 5, 61, 31, 2, 169, 168,
-	3, 2, 2, 2, 170, 171, 3, 2, 2, 2, 171, 169, 3, 2, 2, 2, 171, 172, 3, 2,
-	2, 2, 172, 38, 3, 2, 2, 2, 173, 174, 9, 5, 2, 2, 174, 175, 5, 31, 16, 2,
-	175, 176, 7, 60, 2, 2, 176, 177, 5, 33, 17, 2, 177, 40, 3, 2, 2, 2, 178,
-	181, 7, 92, 2, 2, 179, 181, 5, 39, 20, 2, 180, 178, 3, 2, 2, 2, 180, 179,
-	3, 2, 2, 2, 181, 42, 3, 2, 2, 2, 182, 183, 5, 31, 16, 2, 183, 184, 7, 60,
-	2, 2, 184, 185, 5, 33, 17, 2, 185, 186, 7, 60, 2, 2, 186, 188, 5, 35, 18,
-	2, 187, 189, 5, 37, 19, 2, 188, 187, 3, 2, 2, 2, 188, 189, 3, 2, 2, 2,
-	189, 44, 3, 2, 2, 2, 190, 191, 5, 23, 12, 2, 191, 192, 7, 47, 2, 2, 192,
-	193, 5, 25, 13, 2, 193, 194, 7, 47, 2, 2, 194, 195, 5, 27, 14, 2, 195,
-	46, 3, 2, 2, 2, 196, 197, 5, 43, 22, 2, 197, 198, 5, 41, 21, 2, 198, 48,
-	3, 2, 2, 2, 199, 200, 5, 45, 23, 2, 200, 201, 5, 29, 15, 2, 201, 202, 5,
-	47, 24, 2, 202, 50, 3, 2, 2, 2, 203, 204, 5, 53, 27, 2, 204, 205, 5, 61,
-	31, 2, 205, 52, 3, 2, 2, 2, 206, 207, 5, 55, 28, 2, 207, 208, 5, 55, 28,
-	2, 208, 54, 3, 2, 2, 2, 209, 210, 5, 61, 31, 2, 210, 211, 5, 61, 31, 2,
-	211, 56, 3, 2, 2, 2, 212, 214, 5, 59, 30, 2, 213, 212, 3, 2, 2, 2, 213,
-	214, 3, 2, 2, 2, 214, 215, 3, 2, 2, 2, 215, 218, 5, 61, 31, 2, 216, 217,
-	7, 48, 2, 2, 217, 219, 5, 61, 31, 2, 218, 216, 3, 2, 2, 2, 218, 219, 3,
-	2, 2, 2, 219, 58, 3, 2, 2, 2, 220, 221, 9, 5, 2, 2, 221, 60, 3, 2, 2, 2,
-	222, 224, 5, 63, 32, 2, 223, 222, 3, 2, 2, 2, 224, 225, 3, 2, 2, 2, 225,
-	223, 3, 2, 2, 2, 225, 226, 3, 2, 2, 2, 226, 62, 3, 2, 2, 2, 227, 228, 9,
-	6, 2, 2, 228, 64, 3, 2, 2, 2, 229, 230, 7, 34, 2, 2, 230, 66, 3, 2, 2,
-	2, 231, 233, 9, 7, 2, 2, 232, 231, 3, 2, 2, 2, 233, 234, 3, 2, 2, 2, 234,
-	232, 3, 2, 2, 2, 234, 235, 3, 2, 2, 2, 235, 236, 3, 2, 2, 2, 236, 237,
-	8, 34, 2, 2, 237, 68, 3, 2, 2, 2, 18, 2, 76, 92, 105, 110, 115, 130, 138,
-	140, 171, 180, 188, 213, 218, 225, 234, 3, 8, 2, 2,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5, 3, 122, 10, 3, 3, 4,
+	3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5,
+	3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 7, 3, 7, 3, 7, 3, 7, 5, 7, 147,
+	10, 7, 3, 8, 3, 8, 3, 8, 5, 8, 152, 10, 8, 3, 9, 6, 9, 155, 10, 9, 13,
+	9, 14, 9, 156, 3, 10, 3, 10, 3, 11, 3, 11, 3, 12, 3, 12, 3, 12, 3, 12,
+	3, 12, 3, 12, 3, 12, 3, 12, 3, 12, 5, 12, 172, 10, 12, 3, 13, 3, 13, 3,
+	13, 3, 13, 3, 13, 3, 13, 7, 13, 180, 10, 13, 12, 13, 14, 13, 183, 11, 13,
+	3, 13, 3, 13, 3, 14, 3, 14, 3, 14, 3, 14, 3, 14, 3, 15, 3, 15, 3, 15, 3,
+	16, 3, 16, 3, 16, 3, 17, 3, 17, 3, 18, 3, 18, 3, 18, 3, 19, 3, 19, 3, 19,
+	3, 20, 3, 20, 3, 20, 3, 21, 3, 21, 6, 21, 211, 10, 21, 13, 21, 14, 21,
+	212, 3, 22, 3, 22, 3, 22, 3, 22, 3, 22, 3, 23, 3, 23, 5, 23, 222, 10, 23,
+	3, 24, 3, 24, 3, 24, 3, 24, 3, 24, 3, 24, 5, 24, 230, 10, 24, 3, 25, 3,
+	25, 3, 25, 3, 25, 3, 25, 3, 25, 3, 26, 3, 26, 3, 26, 3, 27, 3, 27, 3, 27,
+	3, 27, 3, 28, 3, 28, 3, 28, 3, 29, 3, 29, 3, 29, 3, 30, 3, 30, 3, 30, 3,
+	31, 5, 31, 255, 10, 31, 3, 31, 3, 31, 3, 31, 5, 31, 260, 10, 31, 3, 32,
+	3, 32, 3, 33, 6, 33, 265, 10, 33, 13, 33, 14, 33, 266, 3, 34, 3, 34, 3,
+	35, 3, 35, 3, 36, 6, 36, 274, 10, 36, 13, 36, 14, 36, 275, 3, 36, 3, 36,
+	2, 2, 37, 3, 3, 5, 4, 7, 5, 9, 6, 11, 7, 13, 8, 15, 9, 17, 10, 19, 11,
+	21, 12, 23, 2, 25, 2, 27, 2, 29, 2, 31, 2, 33, 2, 35, 2, 37, 2, 39, 2,
+	41, 2, 43, 2, 45, 2, 47, 2, 49, 2, 51, 2, 53, 2, 55, 2, 57, 2, 59, 2, 61,
+	2, 63, 2, 65, 2, 67, 2, 69, 13, 71, 14, 3, 2, 8, 7, 2, 47, 59, 67, 92,
+	94, 94, 97, 97, 99, 124, 4, 2, 41, 41, 94, 94, 4, 2, 86, 86, 118, 118,
+	4, 2, 45, 45, 47, 47, 3, 2, 50, 59, 5, 2, 11, 12, 15, 15, 34, 34, 2, 281,
+	2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2, 2, 2, 2, 9, 3, 2, 2, 2,
+	2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3, 2, 2, 2, 2, 17, 3, 2, 2,
+	2, 2, 19, 3, 2, 2, 2, 2, 21, 3, 2, 2, 2, 2, 69, 3, 2, 2, 2, 2, 71, 3, 2,
+	2, 2, 3, 80, 3, 2, 2, 2, 5, 121, 3, 2, 2, 2, 7, 123, 3, 2, 2, 2, 9, 130,
+	3, 2, 2, 2, 11, 136, 3, 2, 2, 2, 13, 146, 3, 2, 2, 2, 15, 151, 3, 2, 2,
+	2, 17, 154, 3, 2, 2, 2, 19, 158, 3, 2, 2, 2, 21, 160, 3, 2, 2, 2, 23, 171,
+	3, 2, 2, 2, 25, 173, 3, 2, 2, 2, 27, 186, 3, 2, 2, 2, 29, 191, 3, 2, 2,
+	2, 31, 194, 3, 2, 2, 2, 33, 197, 3, 2, 2, 2, 35, 199, 3, 2, 2, 2, 37, 202,
+	3, 2, 2, 2, 39, 205, 3, 2, 2, 2, 41, 208, 3, 2, 2, 2, 43, 214, 3, 2, 2,
+	2, 45, 221, 3, 2, 2, 2, 47, 223, 3, 2, 2, 2, 49, 231, 3, 2, 2, 2, 51, 237,
+	3, 2, 2, 2, 53, 240, 3, 2, 2, 2, 55, 244, 3, 2, 2, 2, 57, 247, 3, 2, 2,
+	2, 59, 250, 3, 2, 2, 2, 61, 254, 3, 2, 2, 2, 63, 261, 3, 2, 2, 2, 65, 264,
+	3, 2, 2, 2, 67, 268, 3, 2, 2, 2, 69, 270, 3, 2, 2, 2, 71, 273, 3, 2, 2,
+	2, 73, 74, 7, 107, 2, 2, 74, 81, 7, 112, 2, 2, 75, 76, 7, 112, 2, 2, 76,
+	77, 7, 113, 2, 2, 77, 78, 7, 118, 2, 2, 78, 79, 7, 107, 2, 2, 79, 81, 7,
+	112, 2, 2, 80, 73, 3, 2, 2, 2, 80, 75, 3, 2, 2, 2, 81, 4, 3, 2, 2, 2, 82,
+	83, 7, 103, 2, 2, 83, 122, 7, 115, 2, 2, 84, 85, 7, 112, 2, 2, 85, 122,
+	7, 103, 2, 2, 86, 87, 7, 105, 2, 2, 87, 122, 7, 118, 2, 2, 88, 89, 7, 110,
+	2, 2, 89, 122, 7, 118, 2, 2, 90, 91, 7, 105, 2, 2, 91, 122, 7, 103, 2,
+	2, 92, 93, 7, 110, 2, 2, 93, 122, 7, 103, 2, 2, 94, 95, 7, 103, 2, 2, 95,
+	122, 7, 112, 2, 2, 96, 97, 7, 101, 2, 2, 97, 98, 7, 113, 2, 2, 98, 99,
+	7, 112, 2, 2, 99, 100, 7, 118, 2, 2, 100, 101, 7, 99, 2, 2, 101, 102, 7,
+	107, 2, 2, 102, 103, 7, 112, 2, 2, 103, 122, 7, 117, 2, 2, 104, 105, 7,
+	117, 2, 2, 105, 106, 7, 118, 2, 2, 106, 107, 7, 99, 2, 2, 107, 108, 7,
+	116, 2, 2, 108, 109, 7, 118, 2, 2, 109, 110, 7, 117, 2, 2, 110, 111, 7,
+	121, 2, 2, 111, 112, 7, 107, 2, 2, 112, 113, 7, 118, 2, 2, 113, 122, 7,
+	106, 2, 2, 114, 115, 7, 111, 2, 2, 115, 116, 7, 99, 2, 2, 116, 117, 7,
+	118, 2, 2, 117, 118, 7, 101, 2, 2, 118, 119, 7, 106, 2, 2, 119, 120, 7,
+	103, 2, 2, 120, 122, 7, 117, 2, 2, 121, 82, 3, 2, 2, 2, 121, 84, 3, 2,
+	2, 2, 121, 86, 3, 2, 2, 2, 121, 88, 3, 2, 2, 2, 121, 90, 3, 2, 2, 2, 121,
+	92, 3, 2, 2, 2, 121, 94, 3, 2, 2, 2, 121, 96, 3, 2, 2, 2, 121, 104, 3,
+	2, 2, 2, 121, 114, 3, 2, 2, 2, 122, 6, 3, 2, 2, 2, 123, 124, 5, 69, 35,
+	2, 124, 125, 7, 99, 2, 2, 125, 126, 7, 112, 2, 2, 126, 127, 7, 102, 2,
+	2, 127, 128, 3, 2, 2, 2, 128, 129, 5, 69, 35, 2, 129, 8, 3, 2, 2, 2, 130,
+	131, 5, 69, 35, 2, 131, 132, 7, 113, 2, 2, 132, 133, 7, 116, 2, 2, 133,
+	134, 3, 2, 2, 2, 134, 135, 5, 69, 35, 2, 135, 10, 3, 2, 2, 2, 136, 137,
+	7, 112, 2, 2, 137, 138, 7, 113, 2, 2, 138, 139, 7, 118, 2, 2, 139, 140,
+	3, 2, 2, 2, 140, 141, 5, 69, 35, 2, 141, 12, 3, 2, 2, 2, 142, 147, 5, 25,
+	13, 2, 143, 147, 5, 61, 31, 2, 144, 147, 5, 23, 12, 2, 145, 147, 5, 53,
+	27, 2, 146, 142, 3, 2, 2, 2, 146, 143, 3, 2, 2, 2, 146, 144, 3, 2, 2, 2,
+	146, 145, 3, 2, 2, 2, 147, 14, 3, 2, 2, 2, 148, 152, 7, 46, 2, 2, 149,
+	150, 7, 46, 2, 2, 150, 152, 7, 34, 2, 2, 151, 148, 3, 2, 2, 2, 151, 149,
+	3, 2, 2, 2, 152, 16, 3, 2, 2, 2, 153, 155, 9, 2, 2, 2, 154, 153, 3, 2,
+	2, 2, 155, 156, 3, 2, 2, 2, 156, 154, 3, 2, 2, 2, 156, 157, 3, 2, 2, 2,
+	157, 18, 3, 2, 2, 2, 158, 159, 7, 42, 2, 2, 159, 20, 3, 2, 2, 2, 160, 161,
+	7, 43, 2, 2, 161, 22, 3, 2, 2, 2, 162, 163, 7, 118, 2, 2, 163, 164, 7,
+	116, 2, 2, 164, 165, 7, 119, 2, 2, 165, 172, 7, 103, 2, 2, 166, 167, 7,
+	104, 2, 2, 167, 168, 7, 99, 2, 2, 168, 169, 7, 110, 2, 2, 169, 170, 7,
+	117, 2, 2, 170, 172, 7, 103, 2, 2, 171, 162, 3, 2, 2, 2, 171, 166, 3, 2,
+	2, 2, 172, 24, 3, 2, 2, 2, 173, 181, 7, 41, 2, 2, 174, 175, 7, 94, 2, 2,
+	175, 180, 11, 2, 2, 2, 176, 177, 7, 41, 2, 2, 177, 180, 7, 41, 2, 2, 178,
+	180, 10, 3, 2, 2, 179, 174, 3, 2, 2, 2, 179, 176, 3, 2, 2, 2, 179, 178,
+	3, 2, 2, 2, 180, 183, 3, 2, 2, 2, 181, 179, 3, 2, 2, 2, 181, 182, 3, 2,
+	2, 2, 182, 184, 3, 2, 2, 2, 183, 181, 3, 2, 2, 2, 184, 185, 7, 41, 2, 2,
+	185, 26, 3, 2, 2, 2, 186, 187, 5, 65, 33, 2, 187, 188, 5, 65, 33, 2, 188,
+	189, 5, 65, 33, 2, 189, 190, 5, 65, 33, 2, 190, 28, 3, 2, 2, 2, 191, 192,
+	5, 65, 33, 2, 192, 193, 5, 65, 33, 2, 193, 30, 3, 2, 2, 2, 194, 195, 5,
+	65, 33, 2, 195, 196, 5, 65, 33, 2, 196, 32, 3, 2, 2, 2, 197, 198, 9, 4,
+	2, 2, 198, 34, 3, 2, 2, 2, 199, 200, 5, 65, 33, 2, 200, 201, 5, 65, 33,
+	2, 201, 36, 3, 2, 2, 2, 202, 203, 5, 65, 33, 2, 203, 204, 5, 65, 33, 2,
+	204, 38, 3, 2, 2, 2, 205, 206, 5, 65, 33, 2, 206, 207, 5, 65, 33, 2, 207,
+	40, 3, 2, 2, 2, 208, 210, 7, 48, 2, 2, 209, 211, 5, 65, 33, 2, 210, 209,
+	3, 2, 2, 2, 211, 212, 3, 2, 2, 2, 212, 210, 3, 2, 2, 2, 212, 213, 3, 2,
+	2, 2, 213, 42, 3, 2, 2, 2, 214, 215, 9, 5, 2, 2, 215, 216, 5, 35, 18, 2,
+	216, 217, 7, 60, 2, 2, 217, 218, 5, 37, 19, 2, 218, 44, 3, 2, 2, 2, 219,
+	222, 7, 92, 2, 2, 220, 222, 5, 43, 22, 2, 221, 219, 3, 2, 2, 2, 221, 220,
+	3, 2, 2, 2, 222, 46, 3, 2, 2, 2, 223, 224, 5, 35, 18, 2, 224, 225, 7, 60,
+	2, 2, 225, 226, 5, 37, 19, 2, 226, 227, 7, 60, 2, 2, 227, 229, 5, 39, 20,
+	2, 228, 230, 5, 41, 21, 2, 229, 228, 3, 2, 2, 2, 229, 230, 3, 2, 2, 2,
+	230, 48, 3, 2, 2, 2, 231, 232, 5, 27, 14, 2, 232, 233, 7, 47, 2, 2, 233,
+	234, 5, 29, 15, 2, 234, 235, 7, 47, 2, 2, 235, 236, 5, 31, 16, 2, 236,
+	50, 3, 2, 2, 2, 237, 238, 5, 47, 24, 2, 238, 239, 5, 45, 23, 2, 239, 52,
+	3, 2, 2, 2, 240, 241, 5, 49, 25, 2, 241, 242, 5, 33, 17, 2, 242, 243, 5,
+	51, 26, 2, 243, 54, 3, 2, 2, 2, 244, 245, 5, 57, 29, 2, 245, 246, 5, 65,
+	33, 2, 246, 56, 3, 2, 2, 2, 247, 248, 5, 59, 30, 2, 248, 249, 5, 59, 30,
+	2, 249, 58, 3, 2, 2, 2, 250, 251, 5, 65, 33, 2, 251, 252, 5, 65, 33, 2,
+	252, 60, 3, 2, 2, 2, 253, 255, 5, 63, 32, 2, 254, 253, 3, 2, 2, 2, 254,
+	255, 3, 2, 2, 2, 255, 256, 3, 2, 2, 2, 256, 259, 5, 65, 33, 2, 257, 258,
+	7, 48, 2, 2, 258, 260, 5, 65, 33, 2, 259, 257, 3, 2, 2, 2, 259, 260, 3,
+	2, 2, 2, 260, 62, 3, 2, 2, 2, 261, 262, 9, 5, 2, 2, 262, 64, 3, 2, 2, 2,
+	263, 265, 5, 67, 34, 2, 264, 263, 3, 2, 2, 2, 265, 266, 3, 2, 2, 2, 266,
+	264, 3, 2, 2, 2, 266, 267, 3, 2, 2, 2, 267, 66, 3, 2, 2, 2, 268, 269, 9,
+	6, 2, 2, 269, 68, 3, 2, 2, 2, 270, 271, 7, 34, 2, 2, 271, 70, 3, 2, 2,
+	2, 272, 274, 9, 7, 2, 2, 273, 272, 3, 2, 2, 2, 274, 275, 3, 2, 2, 2, 275,
+	273, 3, 2, 2, 2, 275, 276, 3, 2, 2, 2, 276, 277, 3, 2, 2, 2, 277, 278,
+	8, 36, 2, 2, 278, 72, 3, 2, 2, 2, 18, 2, 80, 121, 146, 151, 156, 171, 179,
+	181, 212, 221, 229, 254, 259, 266, 275, 3, 8, 2, 2,
 }
 
 var lexerDeserializer = antlr.NewATNDeserializer(nil)
@@ -129,20 +147,20 @@ var lexerModeNames = []string{
 }
 
 var lexerLiteralNames = []string{
-	"", "", "", "", "", "", "", "'('", "')'", "' '",
+	"", "", "", "", "", "", "", "", "", "'('", "')'", "' '",
 }
 
 var lexerSymbolicNames = []string{
-	"", "MultiOp", "UniOp", "Concat", "Value", "ValueSeparator", "Key", "OpenBracket",
-	"CloseBracket", "Whitespace", "WS",
+	"", "MultiOp", "UniOp", "Concat", "Or", "Not", "Value", "ValueSeparator",
+	"Key", "OpenBracket", "CloseBracket", "Whitespace", "WS",
 }
 
 var lexerRuleNames = []string{
-	"MultiOp", "UniOp", "Concat", "Value", "ValueSeparator", "Key", "OpenBracket",
-	"CloseBracket", "BOOLEAN", "STRING", "YEAR", "MONTH", "DAY", "DELIM", "HOUR",
-	"MINUTE", "SECOND", "SECFRAC", "NUMOFFSET", "OFFSET", "PARTIAL_TIME", "FULL_DATE",
-	"FULL_TIME", "DATETIME", "FIVE_DIGITS", "FOUR_DIGITS", "TWO_DIGITS", "NUMBER",
-	"SIGN", "DIGIT", "INTEGER", "Whitespace", "WS",
+	"MultiOp", "UniOp", "Concat", "Or", "Not", "Value", "ValueSeparator", "Key",
+	"OpenBracket", "CloseBracket", "BOOLEAN", "STRING", "YEAR", "MONTH", "DAY",
+	"DELIM", "HOUR", "MINUTE", "SECOND", "SECFRAC", "NUMOFFSET", "OFFSET",
+	"PARTIAL_TIME", "FULL_DATE", "FULL_TIME", "DATETIME", "FIVE_DIGITS", "FOUR_DIGITS",
+	"TWO_DIGITS", "NUMBER", "SIGN", "DIGIT", "INTEGER", "Whitespace", "WS",
 }
 
 type QueryLexer struct {
@@ -183,11 +201,13 @@ const (
 	QueryLexerMultiOp        = 1
 	QueryLexerUniOp          = 2
 	QueryLexerConcat         = 3
-	QueryLexerValue          = 4
-	QueryLexerValueSeparator = 5
-	QueryLexerKey            = 6
-	QueryLexerOpenBracket    = 7
-	QueryLexerCloseBracket   = 8
-	QueryLexerWhitespace     = 9
-	QueryLexerWS             = 10
+	QueryLexerOr             = 4
+	QueryLexerNot            = 5
+	QueryLexerValue          = 6
+	QueryLexerValueSeparator = 7
+	QueryLexerKey            = 8
+	QueryLexerOpenBracket    = 9
+	QueryLexerCloseBracket   = 10
+	QueryLexerWhitespace     = 11
+	QueryLexerWS             = 12
 )
@@ -12,6 +12,9 @@ type QueryListener interface {
 	// EnterCriterions is called when entering the criterions production.
 	EnterCriterions(c *CriterionsContext)
 
+	// EnterAndCriterions is called when entering the andCriterions production.
+	EnterAndCriterions(c *AndCriterionsContext)
+
 	// EnterCriterion is called when entering the criterion production.
 	EnterCriterion(c *CriterionContext)
 
@@ -33,6 +36,9 @@ type QueryListener interface {
 	// ExitCriterions is called when exiting the criterions production.
 	ExitCriterions(c *CriterionsContext)
 
+	// ExitAndCriterions is called when exiting the andCriterions production.
+	ExitAndCriterions(c *AndCriterionsContext)
+
 	// ExitCriterion is called when exiting the criterion production.
 	ExitCriterion(c *CriterionContext)
 
@@ -14,41 +14,46 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 12, 52, 4,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 14, 65, 4,
 	2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7, 4,
-	8, 9, 8, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 3, 5, 3, 23, 10, 3, 3, 4, 3,
-	4, 5, 4, 27, 10, 4, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3, 6, 3, 6, 3,
-	6, 3, 6, 3, 6, 3, 6, 3, 7, 3, 7, 5, 7, 43, 10, 7, 3, 7, 3, 7, 3, 8, 3,
-	8, 3, 8, 5, 8, 50, 10, 8, 3, 8, 2, 2, 9, 2, 4, 6, 8, 10, 12, 14, 2, 2,
-	2, 48, 2, 16, 3, 2, 2, 2, 4, 19, 3, 2, 2, 2, 6, 26, 3, 2, 2, 2, 8, 28,
-	3, 2, 2, 2, 10, 34, 3, 2, 2, 2, 12, 40, 3, 2, 2, 2, 14, 46, 3, 2, 2, 2,
-	16, 17, 5, 4, 3, 2, 17, 18, 7, 2, 2, 3, 18, 3, 3, 2, 2, 2, 19, 22, 5, 6,
-	4, 2, 20, 21, 7, 5, 2, 2, 21, 23, 5, 4, 3, 2, 22, 20, 3, 2, 2, 2, 22, 23,
-	3, 2, 2, 2, 23, 5, 3, 2, 2, 2, 24, 27, 5, 8, 5, 2, 25, 27, 5, 10, 6, 2,
-	26, 24, 3, 2, 2, 2, 26, 25, 3, 2, 2, 2, 27, 7, 3, 2, 2, 2, 28, 29, 7, 8,
-	2, 2, 29, 30, 7, 11, 2, 2, 30, 31, 7, 3, 2, 2, 31, 32, 7, 11, 2, 2, 32,
-	33, 5, 12, 7, 2, 33, 9, 3, 2, 2, 2, 34, 35, 7, 8, 2, 2, 35, 36, 7, 11,
-	2, 2, 36, 37, 7, 4, 2, 2, 37, 38, 7, 11, 2, 2, 38, 39, 7, 6, 2, 2, 39,
-	11, 3, 2, 2, 2, 40, 42, 7, 9, 2, 2, 41, 43, 5, 14, 8, 2, 42, 41, 3, 2,
-	2, 2, 42, 43, 3, 2, 2, 2, 43, 44, 3, 2, 2, 2, 44, 45, 7, 10, 2, 2, 45,
-	13, 3, 2, 2, 2, 46, 49, 7, 6, 2, 2, 47, 48, 7, 7, 2, 2, 48, 50, 5, 14,
-	8, 2, 49, 47, 3, 2, 2, 2, 49, 50, 3, 2, 2, 2, 50, 15, 3, 2, 2, 2, 6, 22,
-	26, 42, 49,
+	8, 9, 8, 4, 9, 9, 9, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 3, 5, 3, 25, 10,
+	3, 3, 4, 3, 4, 3, 4, 5, 4, 30, 10, 4, 3, 5, 3, 5, 3, 5, 3, 5, 3, 5, 3,
+	5, 3, 5, 3, 5, 5, 5, 40, 10, 5, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3, 6, 3,
+	7, 3, 7, 3, 7, 3, 7, 3, 7, 3, 7, 3, 8, 3, 8, 5, 8, 56, 10, 8, 3, 8, 3,
+	8, 3, 9, 3, 9, 3, 9, 5, 9, 63, 10, 9, 3, 9, 2, 2, 10, 2, 4, 6, 8, 10, 12,
+	14, 16, 2, 2, 2, 63, 2, 18, 3, 2, 2, 2, 4, 21, 3, 2, 2, 2, 6, 26, 3, 2,
+	2, 2, 8, 39, 3, 2, 2, 2, 10, 41, 3, 2, 2, 2, 12, 47, 3, 2, 2, 2, 14, 53,
+	3, 2, 2, 2, 16, 59, 3, 2, 2, 2, 18, 19, 5, 4, 3, 2, 19, 20, 7, 2, 2, 3,
+	20, 3, 3, 2, 2, 2, 21, 24, 5, 6, 4, 2, 22, 23, 7, 6, 2, 2, 23, 25, 5, 4,
+	3, 2, 24, 22, 3, 2, 2, 2, 24, 25, 3, 2, 2, 2, 25, 5, 3, 2, 2, 2, 26, 29,
+	5, 8, 5, 2, 27, 28, 7, 5, 2, 2, 28, 30, 5, 6, 4, 2, 29, 27, 3, 2, 2, 2,
+	29, 30, 3, 2, 2, 2, 30, 7, 3, 2, 2, 2, 31, 32, 7, 7, 2, 2, 32, 40, 5, 8,
+	5, 2, 33, 34, 7, 11, 2, 2, 34, 35, 5, 4, 3, 2, 35, 36, 7, 12, 2, 2, 36,
+	40, 3, 2, 2, 2, 37, 40, 5, 10, 6, 2, 38, 40, 5, 12, 7, 2, 39, 31, 3, 2,
+	2, 2, 39, 33, 3, 2, 2, 2, 39, 37, 3, 2, 2, 2, 39, 38, 3, 2, 2, 2, 40, 9,
+	3, 2, 2, 2, 41, 42, 7, 10, 2, 2, 42, 43, 7, 13, 2, 2, 43, 44, 7, 3, 2,
+	2, 44, 45, 7, 13, 2, 2, 45, 46, 5, 14, 8, 2, 46, 11, 3, 2, 2, 2, 47, 48,
+	7, 10, 2, 2, 48, 49, 7, 13, 2, 2, 49, 50, 7, 4, 2, 2, 50, 51, 7, 13, 2,
+	2, 51, 52, 7, 8, 2, 2, 52, 13, 3, 2, 2, 2, 53, 55, 7, 11, 2, 2, 54, 56,
+	5, 16, 9, 2, 55, 54, 3, 2, 2, 2, 55, 56, 3, 2, 2, 2, 56, 57, 3, 2, 2, 2,
+	57, 58, 7, 12, 2, 2, 58, 15, 3, 2, 2, 2, 59, 62, 7, 8, 2, 2, 60, 61, 7,
+	9, 2, 2, 61, 63, 5, 16, 9, 2, 62, 60, 3, 2, 2, 2, 62, 63, 3, 2, 2, 2, 63,
+	17, 3, 2, 2, 2, 7, 24, 29, 39, 55, 62,
 }
 var deserializer = antlr.NewATNDeserializer(nil)
 var deserializedATN = deserializer.DeserializeFromUInt16(parserATN)
 
 var literalNames = []string{
-	"", "", "", "", "", "", "", "'('", "')'", "' '",
+	"", "", "", "", "", "", "", "", "", "'('", "')'", "' '",
 }
 var symbolicNames = []string{
-	"", "MultiOp", "UniOp", "Concat", "Value", "ValueSeparator", "Key", "OpenBracket",
-	"CloseBracket", "Whitespace", "WS",
+	"", "MultiOp", "UniOp", "Concat", "Or", "Not", "Value", "ValueSeparator",
+	"Key", "OpenBracket", "CloseBracket", "Whitespace", "WS",
 }
 
 var ruleNames = []string{
-	"expression", "criterions", "criterion", "multivariate", "univariate",
-	"multiValues", "manyValues",
+	"expression", "criterions", "andCriterions", "criterion", "multivariate",
+	"univariate", "multiValues", "manyValues",
 }
 var decisionToDFA = make([]*antlr.DFA, len(deserializedATN.DecisionToState))
 
@@ -82,24 +87,27 @@ const (
 	QueryParserMultiOp        = 1
 	QueryParserUniOp          = 2
 	QueryParserConcat         = 3
-	QueryParserValue          = 4
-	QueryParserValueSeparator = 5
-	QueryParserKey            = 6
-	QueryParserOpenBracket    = 7
-	QueryParserCloseBracket   = 8
-	QueryParserWhitespace     = 9
-	QueryParserWS             = 10
+	QueryParserOr             = 4
+	QueryParserNot            = 5
+	QueryParserValue          = 6
+	QueryParserValueSeparator = 7
+	QueryParserKey            = 8
+	QueryParserOpenBracket    = 9
+	QueryParserCloseBracket   = 10
+	QueryParserWhitespace     = 11
+	QueryParserWS             = 12
 )
 
 // QueryParser rules.
 const (
-	QueryParserRULE_expression   = 0
-	QueryParserRULE_criterions   = 1
-	QueryParserRULE_criterion    = 2
-	QueryParserRULE_multivariate = 3
-	QueryParserRULE_univariate   = 4
-	QueryParserRULE_multiValues  = 5
-	QueryParserRULE_manyValues   = 6
+	QueryParserRULE_expression    = 0
+	QueryParserRULE_criterions    = 1
+	QueryParserRULE_andCriterions = 2
+	QueryParserRULE_criterion     = 3
+	QueryParserRULE_multivariate  = 4
+	QueryParserRULE_univariate    = 5
+	QueryParserRULE_multiValues   = 6
+	QueryParserRULE_manyValues    = 7
 )
 
 // IExpressionContext is an interface to support dynamic dispatch.
@@ -196,11 +204,11 @@ func (p *QueryParser) Expression() (localctx IExpressionContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(14)
+		p.SetState(16)
 		p.Criterions()
 	}
 	{
-		p.SetState(15)
+		p.SetState(17)
 		p.Match(QueryParserEOF)
 	}
 
@@ -245,18 +253,18 @@ func NewCriterionsContext(parser antlr.Parser, parent antlr.ParserRuleContext, i
 
 func (s *CriterionsContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *CriterionsContext) Criterion() ICriterionContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICriterionContext)(nil)).Elem(), 0)
+func (s *CriterionsContext) AndCriterions() IAndCriterionsContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IAndCriterionsContext)(nil)).Elem(), 0)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(ICriterionContext)
+	return t.(IAndCriterionsContext)
 }
 
-func (s *CriterionsContext) Concat() antlr.TerminalNode {
-	return s.GetToken(QueryParserConcat, 0)
+func (s *CriterionsContext) Or() antlr.TerminalNode {
+	return s.GetToken(QueryParserOr, 0)
 }
 
 func (s *CriterionsContext) Criterions() ICriterionsContext {
@@ -312,21 +320,148 @@ func (p *QueryParser) Criterions() (localctx ICriterionsContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(17)
+		p.SetState(19)
+		p.AndCriterions()
+	}
+	p.SetState(22)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	if _la == QueryParserOr {
+		{
+			p.SetState(20)
+			p.Match(QueryParserOr)
+		}
+		{
+			p.SetState(21)
+			p.Criterions()
+		}
+
+	}
+
+	return localctx
+}
+
+// IAndCriterionsContext is an interface to support dynamic dispatch.
+type IAndCriterionsContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// IsAndCriterionsContext differentiates from other interfaces.
+	IsAndCriterionsContext()
+}
+
+type AndCriterionsContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+}
+
+func NewEmptyAndCriterionsContext() *AndCriterionsContext {
+	var p = new(AndCriterionsContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = QueryParserRULE_andCriterions
+	return p
+}
+
+func (*AndCriterionsContext) IsAndCriterionsContext() {}
+
+func NewAndCriterionsContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *AndCriterionsContext {
+	var p = new(AndCriterionsContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = QueryParserRULE_andCriterions
+
+	return p
+}
+
+func (s *AndCriterionsContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *AndCriterionsContext) Criterion() ICriterionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICriterionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(ICriterionContext)
+}
+
+func (s *AndCriterionsContext) Concat() antlr.TerminalNode {
+	return s.GetToken(QueryParserConcat, 0)
+}
+
+func (s *AndCriterionsContext) AndCriterions() IAndCriterionsContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IAndCriterionsContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IAndCriterionsContext)
+}
+
+func (s *AndCriterionsContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *AndCriterionsContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *AndCriterionsContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(QueryListener); ok {
+		listenerT.EnterAndCriterions(s)
+	}
+}
+
+func (s *AndCriterionsContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(QueryListener); ok {
+		listenerT.ExitAndCriterions(s)
+	}
+}
+
+func (p *QueryParser) AndCriterions() (localctx IAndCriterionsContext) {
+	localctx = NewAndCriterionsContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 4, QueryParserRULE_andCriterions)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(24)
 		p.Criterion()
 	}
-	p.SetState(20)
+	p.SetState(27)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	if _la == QueryParserConcat {
 		{
-			p.SetState(18)
+			p.SetState(25)
 			p.Match(QueryParserConcat)
 		}
 		{
-			p.SetState(19)
-			p.Criterions()
+			p.SetState(26)
+			p.AndCriterions()
 		}
 
 	}
@@ -372,6 +507,38 @@ func NewCriterionContext(parser antlr.Parser, parent antlr.ParserRuleContext, in
 
 func (s *CriterionContext) GetParser() antlr.Parser { return s.parser }
 
+func (s *CriterionContext) Not() antlr.TerminalNode {
+	return s.GetToken(QueryParserNot, 0)
+}
+
+func (s *CriterionContext) Criterion() ICriterionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICriterionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(ICriterionContext)
+}
+
+func (s *CriterionContext) OpenBracket() antlr.TerminalNode {
+	return s.GetToken(QueryParserOpenBracket, 0)
+}
+
+func (s *CriterionContext) Criterions() ICriterionsContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICriterionsContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(ICriterionsContext)
+}
+
+func (s *CriterionContext) CloseBracket() antlr.TerminalNode {
+	return s.GetToken(QueryParserCloseBracket, 0)
+}
+
 func (s *CriterionContext) Multivariate() IMultivariateContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*IMultivariateContext)(nil)).Elem(), 0)
 
@@ -414,7 +581,7 @@ func (s *CriterionContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *QueryParser) Criterion() (localctx ICriterionContext) {
 	localctx = NewCriterionContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 4, QueryParserRULE_criterion)
+	p.EnterRule(localctx, 6, QueryParserRULE_criterion)
 
 	defer func() {
 		p.ExitRule()
@@ -432,20 +599,46 @@ func (p *QueryParser) Criterion() (localctx ICriterionContext) {
 		}
 	}()
 
-	p.SetState(24)
+	p.SetState(37)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 1, p.GetParserRuleContext()) {
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 2, p.GetParserRuleContext()) {
 	case 1:
 		p.EnterOuterAlt(localctx, 1)
 		{
-			p.SetState(22)
-			p.Multivariate()
+			p.SetState(29)
+			p.Match(QueryParserNot)
+		}
+		{
+			p.SetState(30)
+			p.Criterion()
 		}
 
 	case 2:
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(23)
+			p.SetState(31)
+			p.Match(QueryParserOpenBracket)
+		}
+		{
+			p.SetState(32)
+			p.Criterions()
+		}
+		{
+			p.SetState(33)
+			p.Match(QueryParserCloseBracket)
+		}
+
+	case 3:
+		p.EnterOuterAlt(localctx, 3)
+		{
+			p.SetState(35)
+			p.Multivariate()
+		}
+
+	case 4:
+		p.EnterOuterAlt(localctx, 4)
+		{
+			p.SetState(36)
 			p.Univariate()
 		}
 
@@ -540,7 +733,7 @@ func (s *MultivariateContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *QueryParser) Multivariate() (localctx IMultivariateContext) {
 	localctx = NewMultivariateContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 6, QueryParserRULE_multivariate)
+	p.EnterRule(localctx, 8, QueryParserRULE_multivariate)
 
 	defer func() {
 		p.ExitRule()
@@ -560,23 +753,23 @@ func (p *QueryParser) Multivariate() (localctx IMultivariateContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(26)
+		p.SetState(39)
 		p.Match(QueryParserKey)
 	}
 	{
-		p.SetState(27)
+		p.SetState(40)
 		p.Match(QueryParserWhitespace)
 	}
 	{
-		p.SetState(28)
+		p.SetState(41)
 		p.Match(QueryParserMultiOp)
 	}
 	{
-		p.SetState(29)
+		p.SetState(42)
 		p.Match(QueryParserWhitespace)
 	}
 	{
-		p.SetState(30)
+		p.SetState(43)
 		p.MultiValues()
 	}
 
@@ -663,7 +856,7 @@ func (s *UnivariateContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *QueryParser) Univariate() (localctx IUnivariateContext) {
 	localctx = NewUnivariateContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 8, QueryParserRULE_univariate)
+	p.EnterRule(localctx, 10, QueryParserRULE_univariate)
 
 	defer func() {
 		p.ExitRule()
@@ -683,23 +876,23 @@ func (p *QueryParser) Univariate() (localctx IUnivariateContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(32)
+		p.SetState(45)
 		p.Match(QueryParserKey)
 	}
 	{
-		p.SetState(33)
+		p.SetState(46)
 		p.Match(QueryParserWhitespace)
 	}
 	{
-		p.SetState(34)
+		p.SetState(47)
 		p.Match(QueryParserUniOp)
 	}
 	{
-		p.SetState(35)
+		p.SetState(48)
 		p.Match(QueryParserWhitespace)
 	}
 	{
-		p.SetState(36)
+		p.SetState(49)
 		p.Match(QueryParserValue)
 	}
 
@@ -784,7 +977,7 @@ func (s *MultiValuesContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *QueryParser) MultiValues() (localctx IMultiValuesContext) {
 	localctx = NewMultiValuesContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 10, QueryParserRULE_multiValues)
+	p.EnterRule(localctx, 12, QueryParserRULE_multiValues)
 	var _la int
 
 	defer func() {
@@ -805,22 +998,22 @@ func (p *QueryParser) MultiValues() (localctx IMultiValuesContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(38)
+		p.SetState(51)
 		p.Match(QueryParserOpenBracket)
 	}
-	p.SetState(40)
+	p.SetState(53)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	if _la == QueryParserValue {
 		{
-			p.SetState(39)
+			p.SetState(52)
 			p.ManyValues()
 		}
 
 	}
 	{
-		p.SetState(42)
+		p.SetState(55)
 		p.Match(QueryParserCloseBracket)
 	}
 
@@ -905,7 +1098,7 @@ func (s *ManyValuesContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *QueryParser) ManyValues() (localctx IManyValuesContext) {
 	localctx = NewManyValuesContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 12, QueryParserRULE_manyValues)
+	p.EnterRule(localctx, 14, QueryParserRULE_manyValues)
 	var _la int
 
 	defer func() {
@@ -926,20 +1119,20 @@ func (p *QueryParser) ManyValues() (localctx IManyValuesContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(44)
+		p.SetState(57)
 		p.Match(QueryParserValue)
 	}
-	p.SetState(47)
+	p.SetState(60)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	if _la == QueryParserValueSeparator {
 		{
-			p.SetState(45)
+			p.SetState(58)
 			p.Match(QueryParserValueSeparator)
 		}
 		{
-			p.SetState(46)
+			p.SetState(59)
 			p.ManyValues()
 		}
 
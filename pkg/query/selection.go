@@ -18,8 +18,10 @@ package query
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,6 +30,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/query/parser"
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 
+	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
 )
 
@@ -58,6 +61,8 @@ const (
 	UnivariateOperator OperatorType = "univariate"
 	// MultivariateOperator denotes that the operator expects more than one variable on the right side
 	MultivariateOperator OperatorType = "multivariate"
+	// LogicalOperator denotes that the operator combines the nested criteria instead of comparing operands
+	LogicalOperator OperatorType = "logical"
 )
 
 // OrderType is the type of the order in which result is presented
@@ -73,10 +78,23 @@ const (
 const (
 	// OrderBy should be used as a left operand in Criterion
 	OrderBy string = "orderBy"
+	// OrderByLabel should be used as a left operand in Criterion to order the result by the value of a label
+	OrderByLabel string = "orderByLabel"
+	// After should be used as a left operand in Criterion to signify the sort keys after which the result starts
+	After string = "after"
+	// Fields should be used as a left operand in Criterion to signify the fields to which the result is projected
+	Fields string = "fields"
 	// Limit should be used as a left operand in Criterion to signify the
 	Limit string = "limit"
 )
 
+const (
+	// LabelsField is the name of the field holding the labels of an object
+	LabelsField = "labels"
+	// LabelFieldPrefix prefixes the label key when a single label is referenced as a field in projections and ordering
+	LabelFieldPrefix = LabelsField + "."
+)
+
 var (
 	// Operators returns the supported query operators
 	Operators = []Operator{
@@ -84,9 +102,16 @@ var (
 		GreaterThanOperator, LessThanOperator,
 		GreaterThanOrEqualOperator, LessThanOrEqualOperator,
 		InOperator, NotInOperator, EqualsOrNilOperator,
+		ContainsOperator, StartsWithOperator, MatchesOperator,
 	}
 	// CriteriaTypes returns the supported query criteria types
 	CriteriaTypes = []CriterionType{FieldQuery, LabelQuery, ExistQuery}
+
+	// allOperators are all operators which can be used in criteria, including the ones not available in queries
+	allOperators = append([]Operator{
+		TextSearchOperator, ExistsSubquery, NotExistsSubquery,
+		AndOperator, OrOperator, NotOperator, NoOperator,
+	}, Operators...)
 )
 
 // Operator is a query operator
@@ -111,6 +136,8 @@ type Criterion struct {
 	RightOp []string
 	// Type is the type of the query
 	Type CriterionType
+	// Criteria are the nested criteria combined by a logical operator
+	Criteria []Criterion
 }
 
 // ByField constructs a new criterion for field querying
@@ -136,18 +163,105 @@ func OrderResultBy(field string, orderType OrderType) Criterion {
 	return NewCriterion(OrderBy, NoOperator, []string{field, string(orderType)}, ResultQuery)
 }
 
+// OrderResultByLabel constructs a new criterion for result order by the value of the label with the given key
+func OrderResultByLabel(key string, orderType OrderType) Criterion {
+	return NewCriterion(OrderByLabel, NoOperator, []string{key, string(orderType)}, ResultQuery)
+}
+
+// ResultAfter constructs a new criterion which skips the results up to and including the one with the given sort keys.
+// The values should be provided in the order of the order by criteria
+func ResultAfter(values ...string) Criterion {
+	return NewCriterion(After, NoOperator, values, ResultQuery)
+}
+
+// SelectResultFields constructs a new criterion which projects the result to the given fields. The fields are the JSON
+// names of the object fields, labels selects all labels and labels.<key> selects only the label with the given key.
+// Other fields of the result objects are not guaranteed to be populated
+func SelectResultFields(fields ...string) Criterion {
+	return NewCriterion(Fields, NoOperator, fields, ResultQuery)
+}
+
 // LimitResultBy constructs a new criterion for limit result with
 func LimitResultBy(limit int) Criterion {
 	limitString := strconv.Itoa(limit)
 	return NewCriterion(Limit, NoOperator, []string{limitString}, ResultQuery)
 }
 
+// ByAll constructs a new criterion which is satisfied when all of the given criteria are satisfied
+func ByAll(criteria ...Criterion) Criterion {
+	return newLogicalCriterion(AndOperator, criteria...)
+}
+
+// ByAny constructs a new criterion which is satisfied when any of the given criteria is satisfied
+func ByAny(criteria ...Criterion) Criterion {
+	return newLogicalCriterion(OrOperator, criteria...)
+}
+
+// ByNot constructs a new criterion which is satisfied when the given criterion is not satisfied
+func ByNot(criterion Criterion) Criterion {
+	return newLogicalCriterion(NotOperator, criterion)
+}
+
+func newLogicalCriterion(operator Operator, criteria ...Criterion) Criterion {
+	var criteriaType CriterionType
+	if len(criteria) > 0 {
+		criteriaType = criteria[0].Type
+	}
+	return Criterion{Operator: operator, Type: criteriaType, Criteria: criteria}
+}
+
+// IsLogical returns true if the criterion combines nested criteria with a logical operator
+func (c Criterion) IsLogical() bool {
+	return c.Operator != nil && c.Operator.Type() == LogicalOperator
+}
+
 func NewCriterion(leftOp string, operator Operator, rightOp []string, criteriaType CriterionType) Criterion {
 	return Criterion{LeftOp: leftOp, Operator: operator, RightOp: rightOp, Type: criteriaType}
 }
 
+// jsonCriterion is the JSON representation of a criterion in which the operator is referenced by name
+type jsonCriterion struct {
+	LeftOp   string        `json:"left_op,omitempty"`
+	Operator string        `json:"operator,omitempty"`
+	RightOp  []string      `json:"right_op,omitempty"`
+	Type     CriterionType `json:"type,omitempty"`
+	Criteria []Criterion   `json:"criteria,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler so that criteria can be persisted, e.g. with queued jobs
+func (c Criterion) MarshalJSON() ([]byte, error) {
+	result := jsonCriterion{LeftOp: c.LeftOp, RightOp: c.RightOp, Type: c.Type, Criteria: c.Criteria}
+	if c.Operator != nil {
+		result.Operator = c.Operator.String()
+	}
+	return json.Marshal(result)
+}
+
+// UnmarshalJSON implements json.Unmarshaler and resolves the operator of the criterion by its name
+func (c *Criterion) UnmarshalJSON(data []byte) error {
+	var result jsonCriterion
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*c = Criterion{LeftOp: result.LeftOp, RightOp: result.RightOp, Type: result.Type, Criteria: result.Criteria}
+	if result.Operator == "" {
+		return nil
+	}
+	for _, op := range allOperators {
+		if op.String() == result.Operator {
+			c.Operator = op
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported query operator %s", result.Operator)
+}
+
 // Validate the criterion fields
 func (c Criterion) Validate() error {
+	if c.IsLogical() {
+		return c.validateLogical()
+	}
+
 	if len(c.RightOp) == 0 {
 		return errors.New("missing right operand")
 	}
@@ -163,7 +277,7 @@ func (c Criterion) Validate() error {
 			}
 		}
 
-		if c.LeftOp == OrderBy {
+		if c.LeftOp == OrderBy || c.LeftOp == OrderByLabel {
 			if len(c.RightOp) < 2 {
 				return &util.UnsupportedQueryError{Message: "order by result expects field name and order type"}
 			}
@@ -181,12 +295,21 @@ func (c Criterion) Validate() error {
 	if c.Operator.IsNumeric() && !isNumeric(c.RightOp[0]) && !isDateTime(c.RightOp[0]) {
 		return &util.UnsupportedQueryError{Message: fmt.Sprintf("%s is numeric operator, but the right operand %s is not numeric or datetime", c.Operator, c.RightOp[0])}
 	}
+	if c.Operator == MatchesOperator {
+		if err := validateRegularExpression(c.RightOp[0]); err != nil {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("%s operator expects a valid regular expression, but %s is provided: %s", c.Operator, c.RightOp[0], err)}
+		}
+	}
 	if strings.Contains(c.LeftOp, fmt.Sprintf(" %s ", Separator)) ||
 		strings.Contains(c.LeftOp, fmt.Sprintf("%s ", Separator)) ||
 		strings.Contains(c.LeftOp, fmt.Sprintf(" %s", Separator)) ||
 		c.LeftOp == Separator {
 		return &util.UnsupportedQueryError{Message: fmt.Sprintf("separator %s is not allowed in %s with left operand \"%s\".", Separator, c.Type, c.LeftOp)}
 	}
+	// not is a keyword of the query language, so criteria with it as left operand could not be expressed as queries
+	if c.LeftOp == string(NotOperator) {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("keyword %s is not allowed as left operand in %s.", NotOperator, c.Type)}
+	}
 	for _, op := range c.RightOp {
 		if strings.ContainsRune(op, '\n') && c.Type != ExistQuery {
 			return &util.UnsupportedQueryError{Message: fmt.Sprintf("%s with key \"%s\" has value \"%s\" contaning forbidden new line character", c.Type, c.LeftOp, op)}
@@ -195,11 +318,97 @@ func (c Criterion) Validate() error {
 	return nil
 }
 
+// regexpEscapes are the escapes supported in regular expressions by both Go and the storage
+const regexpEscapes = `dDsSwWnt.\*+?()[]{}|^$-/`
+
+var regexpRepetition = regexp.MustCompile(`^\{([0-9]+)(,([0-9]*))?\}`)
+
+// maxRegexpRepetition is the highest repetition count supported in regular expressions by the storage
+const maxRegexpRepetition = 255
+
+// validateRegularExpression checks that the regular expression uses only the syntax which has the same meaning in Go
+// and in the POSIX regular expressions of the storage, so that labels are matched in memory as they are in queries
+func validateRegularExpression(expression string) error {
+	if _, err := regexp.Compile(expression); err != nil {
+		return err
+	}
+	inBrackets := false
+	for i := 0; i < len(expression); i++ {
+		switch ch := expression[i]; {
+		case ch == '\\':
+			escape := expression[i+1]
+			if !strings.ContainsRune(regexpEscapes, rune(escape)) || (inBrackets && strings.ContainsRune("DSW", rune(escape))) {
+				return fmt.Errorf("escape \\%c is not supported", escape)
+			}
+			i++
+		case inBrackets:
+			if ch == ']' {
+				inBrackets = false
+			} else if ch == '[' && i+1 < len(expression) && expression[i+1] == ':' {
+				// character classes such as [:alpha:] are supported by both
+				end := strings.Index(expression[i:], ":]")
+				i += end + 1
+			}
+		case ch == '[':
+			inBrackets = true
+			// a closing bracket right after the opening one or its negation is part of the set
+			if i+1 < len(expression) && expression[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(expression) && expression[i+1] == ']' {
+				i++
+			}
+		case ch == '(' && i+1 < len(expression) && expression[i+1] == '?':
+			if i+2 >= len(expression) || expression[i+2] != ':' {
+				return fmt.Errorf("only non-capturing groups (?: are supported")
+			}
+		case ch == '{':
+			repetition := regexpRepetition.FindStringSubmatch(expression[i:])
+			if repetition == nil {
+				return fmt.Errorf("{ has to be escaped if it does not start a repetition")
+			}
+			for _, count := range []string{repetition[1], repetition[3]} {
+				if n, err := strconv.Atoi(count); err == nil && n > maxRegexpRepetition {
+					return fmt.Errorf("repetition count %d exceeds %d", n, maxRegexpRepetition)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c Criterion) validateLogical() error {
+	if len(c.Criteria) == 0 {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("logical operator %s expects at least one criterion", c.Operator)}
+	}
+	if c.Type != FieldQuery && c.Type != LabelQuery {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("logical operator %s is supported only for field and label queries", c.Operator)}
+	}
+	if c.Operator == NotOperator && len(c.Criteria) > 1 {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("logical operator %s expects exactly one criterion", c.Operator)}
+	}
+	for _, criterion := range c.Criteria {
+		if criterion.Type != c.Type {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("%s cannot be combined with %s using logical operator %s", criterion.Type, c.Type, c.Operator)}
+		}
+		if err := criterion.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCriteria validates each of the criteria and rejects duplicate label queries. The criteria combined by logical
+// criteria are validated by the logical criteria themselves, but not checked for duplicate label queries, as they may
+// select the same label, e.g. with alternative values.
 func validateCriteria(criteria []Criterion) error {
 	fieldQueryLeftOperands := make(map[string]int)
 	labelQueryLeftOperands := make(map[string]int)
 
 	for _, criterion := range criteria {
+		if criterion.IsLogical() {
+			continue
+		}
 		if criterion.Type == FieldQuery {
 			fieldQueryLeftOperands[criterion.LeftOp]++
 		}
@@ -242,6 +451,45 @@ func CriteriaForContext(ctx context.Context) []Criterion {
 	return currentCriteria.([]Criterion)
 }
 
+type typeCriteriaCtxKey struct {
+	objectType types.ObjectType
+}
+
+type excludedTypeCtxKey struct {
+	objectType types.ObjectType
+}
+
+// AddCriteriaForType adds criteria to the context which apply only when objects of the given type are queried.
+// It allows requests spanning several object types, such as the search, to be restricted per object type.
+func AddCriteriaForType(ctx context.Context, objectType types.ObjectType, newCriteria ...Criterion) (context.Context, error) {
+	currentCriteria, _ := ctx.Value(typeCriteriaCtxKey{objectType: objectType}).([]Criterion)
+	criteria := append(append([]Criterion{}, currentCriteria...), newCriteria...)
+	if err := validateCriteria(append(CriteriaForContext(ctx), criteria...)); err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, typeCriteriaCtxKey{objectType: objectType}, criteria), nil
+}
+
+// CriteriaForType returns the criteria for the given context followed by the ones which apply only to objects of the given type
+func CriteriaForType(ctx context.Context, objectType types.ObjectType) []Criterion {
+	criteria := append([]Criterion{}, CriteriaForContext(ctx)...)
+	if typeCriteria, ok := ctx.Value(typeCriteriaCtxKey{objectType: objectType}).([]Criterion); ok {
+		criteria = append(criteria, typeCriteria...)
+	}
+	return criteria
+}
+
+// ExcludeType returns a new context in which no objects of the given type should be queried
+func ExcludeType(ctx context.Context, objectType types.ObjectType) context.Context {
+	return context.WithValue(ctx, excludedTypeCtxKey{objectType: objectType}, true)
+}
+
+// IsTypeExcluded returns true if no objects of the given type should be queried for the given context
+func IsTypeExcluded(ctx context.Context, objectType types.ObjectType) bool {
+	excluded, _ := ctx.Value(excludedTypeCtxKey{objectType: objectType}).(bool)
+	return excluded
+}
+
 // ContextWithCriteria returns a new context with given criteria
 func ContextWithCriteria(ctx context.Context, criteria ...Criterion) (context.Context, error) {
 	if err := validateCriteria(criteria); err != nil {
@@ -307,6 +555,7 @@ func isDateTime(str string) bool {
 
 func validateWholeCriteria(criteria ...Criterion) error {
 	isLimited := false
+	hasCursor := false
 	for _, criterion := range criteria {
 		if criterion.LeftOp == Limit {
 			if isLimited {
@@ -314,6 +563,12 @@ func validateWholeCriteria(criteria ...Criterion) error {
 			}
 			isLimited = true
 		}
+		if criterion.Type == ResultQuery && criterion.LeftOp == After {
+			if hasCursor {
+				return fmt.Errorf("zero/one after criterion expected but multiple provided")
+			}
+			hasCursor = true
+		}
 	}
 	return nil
 }
@@ -18,6 +18,7 @@ package query_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -25,6 +26,8 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"github.com/Peripli/service-manager/pkg/types"
+
 	. "github.com/Peripli/service-manager/pkg/query"
 )
 
@@ -122,6 +125,52 @@ new line`))
 		})
 	})
 
+	Describe("Criteria for type", func() {
+		It("Returns the criteria for the context followed by the ones for the type", func() {
+			var err error
+			ctx, err = AddCriteria(ctx, ByField(EqualsOperator, "name", "value"))
+			Expect(err).ToNot(HaveOccurred())
+			ctx, err = AddCriteriaForType(ctx, types.ServicePlanType, ByField(EqualsOperator, "id", "1"))
+			Expect(err).ToNot(HaveOccurred())
+			ctx, err = AddCriteriaForType(ctx, types.ServicePlanType, ByLabel(EqualsOperator, "tenant", "t1"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(CriteriaForType(ctx, types.ServicePlanType)).To(Equal([]Criterion{
+				ByField(EqualsOperator, "name", "value"),
+				ByField(EqualsOperator, "id", "1"),
+				ByLabel(EqualsOperator, "tenant", "t1"),
+			}))
+			Expect(CriteriaForType(ctx, types.ServiceOfferingType)).To(Equal([]Criterion{ByField(EqualsOperator, "name", "value")}))
+			Expect(CriteriaForContext(ctx)).To(Equal([]Criterion{ByField(EqualsOperator, "name", "value")}))
+		})
+
+		It("Validates the criteria together with the ones for the context", func() {
+			var err error
+			ctx, err = AddCriteria(ctx, LimitResultBy(10))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = AddCriteriaForType(ctx, types.ServicePlanType, LimitResultBy(5))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Rejects duplicate label queries unless they are alternatives", func() {
+			_, err := AddCriteria(ctx, ByLabel(EqualsOperator, "env", "dev"), ByLabel(EqualsOperator, "env", "test"))
+			Expect(err).To(HaveOccurred())
+			_, err = AddCriteria(ctx, ByAny(ByLabel(EqualsOperator, "env", "dev"), ByLabel(EqualsOperator, "env", "test")))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Validates the criteria combined by logical criteria", func() {
+			_, err := AddCriteria(ctx, ByNot(ByLabel(EqualsOrNilOperator, "env", "dev")))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Excludes only the given type", func() {
+			ctx = ExcludeType(ctx, types.ServicePlanType)
+			Expect(IsTypeExcluded(ctx, types.ServicePlanType)).To(BeTrue())
+			Expect(IsTypeExcluded(ctx, types.ServiceOfferingType)).To(BeFalse())
+		})
+	})
+
 	Describe("Parse query", func() {
 		for _, queryType := range CriteriaTypes {
 			Context("With no query", func() {
@@ -164,6 +213,14 @@ new line`))
 				})
 			})
 
+			Context("When passing a key with path separators", func() {
+				It("Should keep the key as provided", func() {
+					criteria, err := Parse(queryType, "context.organization_guid eq 'org' and context/space_guid eq 'space'")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(NewCriterion("context.organization_guid", EqualsOperator, []string{"org"}, queryType), NewCriterion("context/space_guid", EqualsOperator, []string{"space"}, queryType)))
+				})
+			})
+
 			Context("Operator is unsupported", func() {
 				It("Should return error", func() {
 					criteria, err := Parse(queryType, "leftop1 @ ('rightop', 'rightop2')")
@@ -295,6 +352,78 @@ new line`))
 				})
 			})
 		}
+
+		for _, queryType := range []CriterionType{FieldQuery, LabelQuery} {
+			queryType := queryType
+			Context(fmt.Sprintf("With logical operators and %s query type", queryType), func() {
+				criterion := func(leftOp, rightOp string) Criterion {
+					return NewCriterion(leftOp, EqualsOperator, []string{rightOp}, queryType)
+				}
+
+				It("Should combine criteria with or", func() {
+					criteria, err := Parse(queryType, "leftop1 eq 'a' or leftop2 eq 'b'")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(ByAny(criterion("leftop1", "a"), criterion("leftop2", "b"))))
+				})
+
+				It("Should give and higher precedence than or", func() {
+					criteria, err := Parse(queryType, "leftop1 eq 'a' or leftop2 eq 'b' and leftop3 eq 'c'")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(ByAny(criterion("leftop1", "a"), ByAll(criterion("leftop2", "b"), criterion("leftop3", "c")))))
+				})
+
+				It("Should negate criteria with not", func() {
+					criteria, err := Parse(queryType, "leftop1 eq 'a' and not leftop2 eq 'b'")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(criterion("leftop1", "a"), ByNot(criterion("leftop2", "b"))))
+				})
+
+				It("Should group criteria with brackets", func() {
+					criteria, err := Parse(queryType, "(leftop1 eq 'a' or leftop2 in ('b','c')) and not (leftop3 eq 'd' or leftop4 eq 'e')")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(
+						ByAny(criterion("leftop1", "a"), NewCriterion("leftop2", InOperator, []string{"c", "b"}, queryType)),
+						ByNot(ByAny(criterion("leftop3", "d"), criterion("leftop4", "e"))),
+					))
+				})
+
+				It("Should flatten repeated or", func() {
+					criteria, err := Parse(queryType, "leftop1 eq 'a' or (leftop2 eq 'b' or leftop3 eq 'c')")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(ByAny(criterion("leftop1", "a"), criterion("leftop2", "b"), criterion("leftop3", "c"))))
+				})
+
+				It("Should allow the same key in alternatives", func() {
+					criteria, err := Parse(queryType, "leftop1 eq 'a' or leftop1 eq 'b'")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(ByAny(criterion("leftop1", "a"), criterion("leftop1", "b"))))
+				})
+
+				It("Should allow keys starting with the keyword not", func() {
+					criteria, err := Parse(queryType, "notes eq 'a' and not notes eq 'b'")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(criteria).To(ConsistOf(criterion("notes", "a"), ByNot(criterion("notes", "b"))))
+				})
+
+				It("Should return error for the reserved key not", func() {
+					criteria, err := Parse(queryType, "not eq 'a'")
+					Expect(err).To(HaveOccurred())
+					Expect(criteria).To(BeNil())
+				})
+
+				It("Should return error for unbalanced brackets", func() {
+					criteria, err := Parse(queryType, "(leftop1 eq 'a' or leftop2 eq 'b'")
+					Expect(err).To(HaveOccurred())
+					Expect(criteria).To(BeNil())
+				})
+
+				It("Should return error for invalid nested criterion", func() {
+					criteria, err := Parse(queryType, "leftop1 eq 'a' or leftop2 lt 'b'")
+					Expect(err).To(HaveOccurred())
+					Expect(criteria).To(BeNil())
+				})
+			})
+		}
 	})
 
 	DescribeTable("Validate Criterion",
@@ -346,8 +475,150 @@ new line`))
 		Entry("Separator word 'and' is not allowed to appear as a standalone word in end of left operand",
 			ByField(EqualsOperator, "a and", "this"),
 			"separator and is not allowed"),
+		Entry("Keyword 'not' is allowed to appear in left operand as a substring",
+			ByField(EqualsOperator, "notes", "music")),
+		Entry("Keyword 'not' is not allowed to be the left operand",
+			ByField(EqualsOperator, "not", "this"),
+			"keyword not is not allowed"),
 		Entry("New line character is not allowed in right operand",
 			ByField(EqualsOperator, "left", "one\ntwo"),
 			"forbidden new line character"),
+		Entry("Invalid regular expression is not allowed for matches operator",
+			ByField(MatchesOperator, "left", "[a-"),
+			"expects a valid regular expression"),
+		Entry("Valid regular expression is allowed for matches operator",
+			ByField(MatchesOperator, "left", "^prod-[a-z]+$")),
+		Entry("Regular expression with classes, escapes and repetitions supported by the storage is allowed for matches operator",
+			ByField(MatchesOperator, "left", `^(?:[[:alpha:]]|\.)+\d{2,3}[]a-]$`)),
+		Entry("Escapes with a different meaning in the storage are not allowed for matches operator",
+			ByField(MatchesOperator, "left", `\bprod`),
+			"escape \\b is not supported"),
+		Entry("Flags and named groups are not allowed for matches operator",
+			ByField(MatchesOperator, "left", "(?P<name>prod)"),
+			"only non-capturing groups"),
+		Entry("Unescaped braces which do not start a repetition are not allowed for matches operator",
+			ByField(MatchesOperator, "left", "prod{"),
+			"has to be escaped"),
+		Entry("Repetitions not supported by the storage are not allowed for matches operator",
+			ByField(MatchesOperator, "left", "a{256}"),
+			"exceeds 255"),
+		Entry("Logical operator combining field criteria is allowed",
+			ByAny(ByField(EqualsOperator, "left1", "right1"), ByNot(ByField(EqualsOperator, "left2", "right2")))),
+		Entry("Logical operator without criteria is not allowed",
+			ByAny(),
+			"expects at least one criterion"),
+		Entry("Not operator with multiple criteria is not allowed",
+			Criterion{Operator: NotOperator, Type: FieldQuery, Criteria: []Criterion{ByField(EqualsOperator, "left1", "right1"), ByField(EqualsOperator, "left2", "right2")}},
+			"expects exactly one criterion"),
+		Entry("Logical operator combining field and label criteria is not allowed",
+			ByAll(ByField(EqualsOperator, "left1", "right1"), ByLabel(EqualsOperator, "left2", "right2")),
+			"cannot be combined"),
+		Entry("Logical operator with invalid nested criterion is not allowed",
+			ByAny(ByField(EqualsOperator, "left1", "right1"), ByField(LessThanOperator, "left2", "not numeric")),
+			"not numeric or datetime"),
 	)
+
+	Describe("Parse order by", func() {
+		Context("with empty expression", func() {
+			It("returns no criteria", func() {
+				criteria, err := ParseOrderBy("")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(criteria).To(BeEmpty())
+			})
+		})
+
+		Context("with multiple fields", func() {
+			It("returns order criteria in the given order", func() {
+				criteria, err := ParseOrderBy("name asc, created_at DESC,id")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(criteria).To(Equal([]Criterion{
+					OrderResultBy("name", AscOrder),
+					OrderResultBy("created_at", DescOrder),
+					OrderResultBy("id", AscOrder),
+				}))
+			})
+		})
+
+		Context("with label field", func() {
+			It("returns order by label criterion", func() {
+				criteria, err := ParseOrderBy("labels.env desc,name")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(criteria).To(Equal([]Criterion{
+					OrderResultByLabel("env", DescOrder),
+					OrderResultBy("name", AscOrder),
+				}))
+			})
+		})
+
+		DescribeTable("with invalid expression",
+			func(expression, expectedErr string) {
+				_, err := ParseOrderBy(expression)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedErr))
+			},
+			Entry("unknown order type", "name up", "unsupported order type: up"),
+			Entry("too many parts", "name asc desc", "invalid order by rule"),
+			Entry("empty rule", "name,,id", "invalid order by rule"),
+			Entry("duplicate field", "name asc,name desc", "duplicate order by field: name"),
+			Entry("label without key", "labels. asc", "order by label expects label key"),
+		)
+	})
+
+	Describe("Match labels", func() {
+		labels := types.Labels{
+			"env":    {"dev", "test"},
+			"region": {"eu10"},
+			"size":   {"10"},
+		}
+
+		DescribeTable("matches label criteria",
+			func(expression string, expected bool) {
+				criteria, err := Parse(LabelQuery, expression)
+				Expect(err).ToNot(HaveOccurred())
+				matches, err := MatchesLabels(labels, criteria...)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(matches).To(Equal(expected))
+			},
+			Entry("eq on any of the values", "env eq 'test'", true),
+			Entry("eq without matching value", "env eq 'prod'", false),
+			Entry("missing label", "team eq 'a'", false),
+			Entry("in", "region in ('eu10','us10')", true),
+			Entry("notin", "region notin ('eu10')", false),
+			Entry("numeric comparison", "size gt 5", true),
+			Entry("multiple criteria", "env eq 'dev' and region eq 'us10'", false),
+			Entry("or", "env eq 'prod' or region eq 'eu10'", true),
+			Entry("not", "not (env eq 'dev')", false),
+			Entry("contains ignoring case", "region contains 'EU'", true),
+			Entry("startswith ignoring case", "env startswith 'TE'", true),
+			Entry("matches ignoring case", "region matches '^EU[0-9]+$'", true),
+		)
+
+		It("fails for field criteria", func() {
+			_, err := MatchesLabels(labels, ByField(EqualsOperator, "name", "n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("JSON", func() {
+		It("restores the criteria with their operators", func() {
+			criteria := []Criterion{
+				ByField(InOperator, "state", "a", "b"),
+				ByLabel(EqualsOperator, "tenant", "t1"),
+				ByAny(ByField(EqualsOperator, "name", "n"), ByNot(ByField(ContainsOperator, "description", "d"))),
+				ByNotExists("SELECT 1"),
+			}
+			bytes, err := json.Marshal(criteria)
+			Expect(err).ToNot(HaveOccurred())
+
+			var result []Criterion
+			Expect(json.Unmarshal(bytes, &result)).To(Succeed())
+			Expect(result).To(Equal(criteria))
+		})
+
+		It("fails for unknown operators", func() {
+			var result Criterion
+			err := json.Unmarshal([]byte(`{"left_op":"name","operator":"like","right_op":["n"],"type":"fieldQuery"}`), &result)
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -58,6 +58,7 @@ import (
 	_ "github.com/Kount/pq-timeouts"
 	"github.com/Peripli/service-manager/api/filters"
 	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/webhooks"
 	osbc "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
 )
 
@@ -69,7 +70,12 @@ type ServiceManagerBuilder struct {
 	Storage              *storage.InterceptableTransactionalRepository
 	Notificator          storage.Notificator
 	NotificationCleaner  *storage.NotificationCleaner
+	WebhookDispatcher    *webhooks.Dispatcher
+	CancellationNotifier storage.CancellationNotifier
 	OperationMaintainer  *operations.Maintainer
+	LeaderElector        *storage.LeaderElector
+	JobWorker            *operations.JobWorker
+	OperationHooks       *operations.Hooks
 	OSBClientProvider    osbc.CreateFunc
 	ctx                  context.Context
 	wg                   *sync.WaitGroup
@@ -80,11 +86,13 @@ type ServiceManagerBuilder struct {
 
 // ServiceManager  struct
 type ServiceManager struct {
-	ctx                 context.Context
-	wg                  *sync.WaitGroup
-	Server              *server.Server
-	Notificator         storage.Notificator
-	NotificationCleaner *storage.NotificationCleaner
+	ctx                  context.Context
+	wg                   *sync.WaitGroup
+	Server               *server.Server
+	Notificator          storage.Notificator
+	NotificationCleaner  *storage.NotificationCleaner
+	WebhookDispatcher    *webhooks.Dispatcher
+	CancellationNotifier storage.CancellationNotifier
 }
 
 // New returns service-manager Server with default setup
@@ -135,15 +143,41 @@ func New(ctx context.Context, cancel context.CancelFunc, e env.Environment, cfg
 		return nil, fmt.Errorf("could not create notificator: %v", err)
 	}
 
+	cancellationNotifier, err := postgres.NewCancellationNotifier(smStorage, cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cancellation notifier: %v", err)
+	}
+
+	var jobWorker *operations.JobWorker
+	if cfg.Operations.JobQueueEnabled {
+		// the queued jobs may contain credentials, e.g. of brokers, so they are encrypted like the credentials in the storage
+		jobQueue, err := storage.EncryptingJobQueue(ctx, smStorage, &security.AESEncrypter{}, smStorage, postgres.EncryptingLocker(smStorage))
+		if err != nil {
+			return nil, fmt.Errorf("could not create job queue: %v", err)
+		}
+		if jobWorker, err = operations.NewJobWorker(ctx, jobQueue, cfg.Operations, cfg.Multitenancy.LabelKey, waitGroup); err != nil {
+			return nil, fmt.Errorf("could not create job worker: %v", err)
+		}
+	}
+
+	operationHooks := operations.NewHooks(cfg.Operations)
+
 	apiOptions := &api.Options{
-		Repository:        interceptableRepository,
-		APISettings:       cfg.API,
-		OperationSettings: cfg.Operations,
-		WSSettings:        cfg.WebSocket,
-		Notificator:       pgNotificator,
-		WaitGroup:         waitGroup,
-		TenantLabelKey:    cfg.Multitenancy.LabelKey,
-		Agents:            cfg.Agents,
+		Repository:           interceptableRepository,
+		APISettings:          cfg.API,
+		OperationSettings:    cfg.Operations,
+		WSSettings:           cfg.WebSocket,
+		Notificator:          pgNotificator,
+		NotificationAcks:     smStorage,
+		WaitGroup:            waitGroup,
+		TenantLabelKey:       cfg.Multitenancy.LabelKey,
+		Agents:               cfg.Agents,
+		JobWorker:            jobWorker,
+		CancellationNotifier: cancellationNotifier,
+		LeaseStore:           smStorage,
+		RevisionHorizon:      smStorage,
+		ColumnMapper:         smStorage,
+		OperationHooks:       operationHooks,
 	}
 	API, err := api.New(ctx, e, apiOptions)
 	if err != nil {
@@ -172,16 +206,26 @@ func New(ctx context.Context, cancel context.CancelFunc, e env.Environment, cfg
 	}
 	API.SetIndicator(healthcheck.NewMonitoredPlatformsIndicator(ctx, interceptableRepository, cfg.Health.MonitoredPlatformsThreshold))
 
-	notificationCleaner := &storage.NotificationCleaner{
-		Storage:  interceptableRepository,
-		Settings: *cfg.Storage,
+	leaderElector, err := storage.NewLeaderElector(smStorage, cfg.Storage.LeaderElection)
+	if err != nil {
+		return nil, fmt.Errorf("error creating leader elector: %s", err)
 	}
 
-	postgresLockerCreatorFunc := func(advisoryIndex int) storage.Locker {
-		return &postgres.Locker{Storage: smStorage, AdvisoryIndex: advisoryIndex}
+	notificationCleaner := &storage.NotificationCleaner{
+		Storage:    interceptableRepository,
+		Tombstones: smStorage,
+		Elector:    leaderElector,
+		Settings:   *cfg.Storage,
 	}
 
-	operationMaintainer := operations.NewMaintainer(ctx, interceptableRepository, postgresLockerCreatorFunc, cfg.Operations, waitGroup)
+	// the webhook dispatcher maintains the delivery state of the webhooks, so it bypasses their interceptors
+	webhookDispatcher := webhooks.NewDispatcher(transactionalRepository, smStorage, leaderElector, cfg.Webhooks, cfg.Multitenancy.LabelKey)
+
+	operationMaintainer := operations.NewMaintainer(ctx, interceptableRepository, leaderElector, cfg.Operations, waitGroup)
+	operationMaintainer.UseHooks(operationHooks)
+	if jobWorker != nil {
+		operationMaintainer.UseJobWorker(jobWorker)
+	}
 	osbClientTimeout := math.Min(float64(cfg.HTTPClient.Timeout), float64(cfg.Server.RequestTimeout))
 	osbClientTimeoutDuration := time.Duration(osbClientTimeout)
 	osbClientProvider := osb.NewBrokerClientProvider(cfg.HTTPClient.SkipSSLValidation, int(osbClientTimeoutDuration.Seconds()))
@@ -195,7 +239,12 @@ func New(ctx context.Context, cancel context.CancelFunc, e env.Environment, cfg
 		Storage:              interceptableRepository,
 		Notificator:          pgNotificator,
 		NotificationCleaner:  notificationCleaner,
+		WebhookDispatcher:    webhookDispatcher,
+		CancellationNotifier: cancellationNotifier,
 		OperationMaintainer:  operationMaintainer,
+		LeaderElector:        leaderElector,
+		JobWorker:            jobWorker,
+		OperationHooks:       operationHooks,
 		ctx:                  ctx,
 		wg:                   waitGroup,
 		cfg:                  cfg,
@@ -274,7 +323,35 @@ func New(ctx context.Context, cancel context.CancelFunc, e env.Environment, cfg
 		WithDeleteAroundTxInterceptorProvider(types.ServiceBindingType, &interceptors.ServiceBindingDeleteInterceptorProvider{
 			BaseSMAAPInterceptorProvider: baseSMAAPInterceptorProvider,
 		}).Register().
-		WithCreateOnTxInterceptorProvider(types.OperationType, &interceptors.CascadeOperationCreateInterceptorProvider{}).Register()
+		WithCreateOnTxInterceptorProvider(types.OperationType, &interceptors.CascadeOperationCreateInterceptorProvider{}).Register().
+		WithCreateOnTxInterceptorProvider(types.OperationType, &interceptors.OperationEventsCreateInterceptorProvider{}).Register().
+		WithUpdateOnTxInterceptorProvider(types.OperationType, &interceptors.OperationEventsUpdateInterceptorProvider{}).Register().
+		WithCreateOnTxInterceptorProvider(types.WebhookType, &interceptors.WebhookCreateInterceptorProvider{
+			AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+		}).Register().
+		WithUpdateOnTxInterceptorProvider(types.WebhookType, &interceptors.WebhookUpdateInterceptorProvider{
+			AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+		}).Register()
+
+	for _, objectType := range []types.ObjectType{types.ServiceInstanceType, types.ServiceBindingType, types.OperationType, types.ServicePlanType} {
+		smb.
+			WithCreateOnTxInterceptorProvider(objectType, &interceptors.ResourceEventsCreateInterceptorProvider{
+				TenantKey: cfg.Multitenancy.LabelKey,
+			}).Register().
+			WithUpdateOnTxInterceptorProvider(objectType, &interceptors.ResourceEventsUpdateInterceptorProvider{
+				TenantKey: cfg.Multitenancy.LabelKey,
+			}).Register().
+			WithDeleteOnTxInterceptorProvider(objectType, &interceptors.ResourceEventsDeleteInterceptorProvider{
+				TenantKey: cfg.Multitenancy.LabelKey,
+			}).Register()
+	}
+	smb.
+		WithDeleteOnTxInterceptorProvider(types.ServiceOfferingType, &interceptors.PlanEventsCascadeInterceptorProvider{
+			TenantKey: cfg.Multitenancy.LabelKey,
+		}).Register().
+		WithDeleteOnTxInterceptorProvider(types.ServiceBrokerType, &interceptors.PlanEventsCascadeInterceptorProvider{
+			TenantKey: cfg.Multitenancy.LabelKey,
+		}).Register()
 
 	return smb, nil
 }
@@ -298,19 +375,31 @@ func (smb *ServiceManagerBuilder) Build() *ServiceManager {
 		log.C(smb.ctx).Panic(err)
 	}
 
+	// start renewing the leases of the background jobs led by this instance
+	if err := smb.LeaderElector.Start(smb.ctx, smb.wg); err != nil {
+		log.C(smb.ctx).Panic(err)
+	}
+
 	// start the operation maintainer
 	smb.OperationMaintainer.Run()
 
+	// start executing the queued operations once all interceptors are registered
+	if smb.JobWorker != nil {
+		smb.JobWorker.Run()
+	}
+
 	if err := smb.registerSMPlatform(); err != nil {
 		log.C(smb.ctx).Panic(err)
 	}
 
 	return &ServiceManager{
-		ctx:                 smb.ctx,
-		wg:                  smb.wg,
-		Server:              srv,
-		Notificator:         smb.Notificator,
-		NotificationCleaner: smb.NotificationCleaner,
+		ctx:                  smb.ctx,
+		wg:                   smb.wg,
+		Server:               srv,
+		Notificator:          smb.Notificator,
+		NotificationCleaner:  smb.NotificationCleaner,
+		WebhookDispatcher:    smb.WebhookDispatcher,
+		CancellationNotifier: smb.CancellationNotifier,
 	}
 }
 
@@ -369,6 +458,14 @@ func (sm *ServiceManager) Run() {
 	if err := sm.NotificationCleaner.Start(sm.ctx, sm.wg); err != nil {
 		log.C(sm.ctx).WithError(err).Panicf("could not start Service Manager notification cleaner")
 	}
+	if err := sm.WebhookDispatcher.Start(sm.ctx, sm.wg); err != nil {
+		log.C(sm.ctx).WithError(err).Panicf("could not start Service Manager webhook dispatcher")
+	}
+	if err := sm.CancellationNotifier.Start(sm.ctx, sm.wg, func(operationID string) {
+		operations.CancelAction(operationID)
+	}); err != nil {
+		log.C(sm.ctx).WithError(err).Panicf("could not start Service Manager cancellation notifier")
+	}
 
 	sm.Server.Run(sm.ctx, sm.wg)
 
@@ -87,6 +87,28 @@ type ObjectPage struct {
 	Token      string   `json:"token,omitempty"`
 	ItemsCount int      `json:"num_items"`
 	Items      []Object `json:"items"`
+	// Deleted are the resources deleted since the time from which the changes are listed
+	Deleted []Deletion `json:"deleted,omitempty"`
+}
+
+// Deletion is the record of a deleted object
+type Deletion struct {
+	ID        string    `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// AggregationGroup is the number of objects having a particular value of the field or label by which they are grouped.
+// Value is nil for the group of objects without value.
+type AggregationGroup struct {
+	Value      *string `json:"value"`
+	ItemsCount int     `json:"num_items"`
+}
+
+// Aggregation is the DTO for the counts of objects grouped by a field or label
+type Aggregation struct {
+	GroupBy    string             `json:"group_by"`
+	ItemsCount int                `json:"num_items"`
+	Groups     []AggregationGroup `json:"groups"`
 }
 
 // ObjectArray is an ObjectList backed by a slice of Object's
@@ -19,6 +19,7 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"reflect"
 
 	"github.com/Peripli/service-manager/pkg/util"
@@ -41,6 +42,20 @@ const (
 	InvalidRevision int64 = -1
 )
 
+// NotifiedResourceTypes are the types of the resources whose changes are notified
+var NotifiedResourceTypes = []ObjectType{ServiceBrokerType, VisibilityType}
+
+// IsNotifiedResourceType returns true if the changes of the resources of the given type are notified. The type may be
+// given either as name, e.g. visibilities, or as API path, e.g. /v1/visibilities.
+func IsNotifiedResourceType(resourceType string) bool {
+	for _, notifiedType := range NotifiedResourceTypes {
+		if path.Base(string(notifiedType)) == path.Base(resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
 //go:generate smgen api Notification
 // Notification struct
 type Notification struct {
@@ -55,6 +55,9 @@ const (
 
 	// FAILED represents the state of an operation after unsuccessful execution
 	FAILED OperationState = "failed"
+
+	// CANCELED represents the state of an operation whose execution was aborted on request
+	CANCELED OperationState = "canceled"
 )
 
 type RelatedType struct {
@@ -87,6 +90,16 @@ type Operation struct {
 	RescheduleTimestamp time.Time `json:"reschedule_timestamp,omitempty"`
 	// DeletionScheduled specifies the time when an operation was marked for deletion
 	DeletionScheduled time.Time `json:"deletion_scheduled,omitempty"`
+	// Retries is the number of times the failed action of the operation has been retried automatically
+	Retries int `json:"retries"`
+	// ScheduledAt is the time when a pending operation which was scheduled for a later time is due
+	ScheduledAt time.Time `json:"scheduled_at,omitempty"`
+	// RetryPayload is the requested change of an UPDATE or scheduled operation, stored so that the operation can be
+	// retried or started by the Maintainer once it is due
+	RetryPayload json.RawMessage `json:"-"`
+	// PendingEvents are the events of the communication with the broker which are recorded in the transaction of the
+	// next update of the operation
+	PendingEvents []*OperationEvent `json:"-"`
 }
 
 type OperationContext struct {
@@ -143,8 +156,8 @@ func (e *Operation) Validate() error {
 		return fmt.Errorf("missing resource type")
 	}
 
-	if e.State == PENDING && e.CascadeRootID == "" {
-		return fmt.Errorf("PENDING state only allowed for cascade operations")
+	if e.State == PENDING && e.CascadeRootID == "" && e.ScheduledAt.IsZero() {
+		return fmt.Errorf("PENDING state only allowed for cascade and scheduled operations")
 	}
 
 	if len(e.CascadeRootID) > 0 && len(e.ParentID) == 0 && e.CascadeRootID != e.ID {
@@ -158,6 +171,16 @@ func (e *Operation) InOrphanMitigationState() bool {
 	return !e.DeletionScheduled.IsZero()
 }
 
+// IsScheduled reports whether the operation was scheduled for a later time and has not been started yet
+func (e *Operation) IsScheduled() bool {
+	return e.State == PENDING && !e.ScheduledAt.IsZero()
+}
+
+// AwaitsApproval reports whether the operation has not been started yet as it awaits the approval of its pre hooks
+func (e *Operation) AwaitsApproval() bool {
+	return e.State == PENDING && e.Reschedule
+}
+
 func (e *Operation) Sanitize(context.Context) {
 	if e != nil {
 		e.Context = nil
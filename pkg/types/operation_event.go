@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// OperationEventKind represents the kind of an event in the history of an operation
+type OperationEventKind string
+
+const (
+	// OperationCreatedEvent is recorded when an operation is stored
+	OperationCreatedEvent OperationEventKind = "created"
+
+	// OperationStateChangedEvent is recorded when the state of an operation changes
+	OperationStateChangedEvent OperationEventKind = "state_changed"
+
+	// OperationRetryEvent is recorded when the orphan mitigation of an operation is retried automatically
+	OperationRetryEvent OperationEventKind = "retry"
+
+	// OperationRescheduledEvent is recorded when an operation waits for an asynchronous broker operation to finish
+	OperationRescheduledEvent OperationEventKind = "rescheduled"
+
+	// OrphanMitigationStartedEvent is recorded when the resource of a failed operation has to be cleaned up in the broker
+	OrphanMitigationStartedEvent OperationEventKind = "orphan_mitigation_started"
+
+	// OrphanMitigationFinishedEvent is recorded when the orphan mitigation of an operation is finished
+	OrphanMitigationFinishedEvent OperationEventKind = "orphan_mitigation_finished"
+
+	// BrokerRequestEvent is recorded when a request is sent to the broker of the resource of an operation
+	BrokerRequestEvent OperationEventKind = "broker_request"
+
+	// BrokerResponseEvent is recorded when the broker of the resource of an operation responds successfully
+	BrokerResponseEvent OperationEventKind = "broker_response"
+
+	// BrokerErrorEvent is recorded when a request to the broker of the resource of an operation fails
+	BrokerErrorEvent OperationEventKind = "broker_error"
+
+	// OperationHookEvent is recorded when a hook approves, defers or rejects an operation or fails to be invoked
+	OperationHookEvent OperationEventKind = "hook"
+)
+
+//go:generate smgen api OperationEvent
+// OperationEvent is an entry of the append-only history of the operations of a resource
+type OperationEvent struct {
+	Base
+
+	Type          OperationEventKind `json:"type"`
+	Description   string             `json:"description,omitempty"`
+	OperationID   string             `json:"operation_id"`
+	OperationType OperationCategory  `json:"operation_type"`
+	State         OperationState     `json:"state"`
+	ResourceID    string             `json:"resource_id"`
+	ResourceType  ObjectType         `json:"resource_type"`
+	CorrelationID string             `json:"correlation_id,omitempty"`
+}
+
+func (e *OperationEvent) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	event := obj.(*OperationEvent)
+	if e.Type != event.Type ||
+		e.Description != event.Description ||
+		e.OperationID != event.OperationID ||
+		e.OperationType != event.OperationType ||
+		e.State != event.State ||
+		e.ResourceID != event.ResourceID ||
+		e.ResourceType != event.ResourceType ||
+		e.CorrelationID != event.CorrelationID {
+		return false
+	}
+
+	return true
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *OperationEvent) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.Type == "" {
+		return errors.New("missing operation event type")
+	}
+	if e.OperationID == "" {
+		return errors.New("missing operation id")
+	}
+	if e.ResourceID == "" {
+		return errors.New("missing resource id")
+	}
+	if e.ResourceType == "" {
+		return errors.New("missing resource type")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// GENERATED. DO NOT MODIFY!
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const OperationEventType ObjectType = web.OperationEventsURL
+
+type OperationEvents struct {
+	OperationEvents []*OperationEvent `json:"operation_events"`
+}
+
+func (e *OperationEvents) Add(object Object) {
+	e.OperationEvents = append(e.OperationEvents, object.(*OperationEvent))
+}
+
+func (e *OperationEvents) ItemAt(index int) Object {
+	return e.OperationEvents[index]
+}
+
+func (e *OperationEvents) Len() int {
+	return len(e.OperationEvents)
+}
+
+func (e *OperationEvent) GetType() ObjectType {
+	return OperationEventType
+}
+
+// MarshalJSON override json serialization for http response
+func (e *OperationEvent) MarshalJSON() ([]byte, error) {
+	type E OperationEvent
+	toMarshal := struct {
+		*E
+		CreatedAt *string `json:"created_at,omitempty"`
+		UpdatedAt *string `json:"updated_at,omitempty"`
+		Labels    Labels  `json:"labels,omitempty"`
+	}{
+		E:      (*E)(e),
+		Labels: e.Labels,
+	}
+	if !e.CreatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.CreatedAt)
+		toMarshal.CreatedAt = &str
+	}
+	if !e.UpdatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.UpdatedAt)
+		toMarshal.UpdatedAt = &str
+	}
+	hasNoLabels := true
+	for key, values := range e.Labels {
+		if key != "" && len(values) != 0 {
+			hasNoLabels = false
+			break
+		}
+	}
+	if hasNoLabels {
+		toMarshal.Labels = nil
+	}
+	return json.Marshal(toMarshal)
+}
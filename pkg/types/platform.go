@@ -59,6 +59,9 @@ type Platform struct {
 	Integrity         []byte       `json:"-"`
 	CredentialsActive bool         `json:"credentials_active,omitempty"`
 	Technical         bool         `json:"technical,omitempty"` //technical platforms are only used for managing visibilities, and are excluded in notification and credential management flows
+	// LastAckedRevision is the revision of the last notification acknowledged by the platform; 0 if it has not acknowledged any
+	LastAckedRevision int64     `json:"-"`
+	LastAckedAt       time.Time `json:"-"`
 }
 
 func (e *Platform) Equals(obj Object) bool {
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+//go:generate smgen api ResourceEvent
+// ResourceEvent is an entry of the stream of the changes of the resources. The revision of an event is assigned when
+// the change is recorded, so concurrent changes may be committed out of the order of their revisions.
+type ResourceEvent struct {
+	Base
+
+	Revision      int64                 `json:"revision"`
+	Type          NotificationOperation `json:"type"`
+	ResourceType  ObjectType            `json:"resource_type"`
+	ResourceID    string                `json:"resource_id"`
+	Resource      json.RawMessage       `json:"resource"`
+	CorrelationID string                `json:"correlation_id,omitempty"`
+}
+
+func (e *ResourceEvent) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	event := obj.(*ResourceEvent)
+	if e.Revision != event.Revision ||
+		e.Type != event.Type ||
+		e.ResourceType != event.ResourceType ||
+		e.ResourceID != event.ResourceID ||
+		e.CorrelationID != event.CorrelationID ||
+		!reflect.DeepEqual(e.Resource, event.Resource) {
+		return false
+	}
+
+	return true
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *ResourceEvent) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.Type == "" {
+		return errors.New("missing resource event type")
+	}
+	if e.ResourceType == "" {
+		return errors.New("missing resource type")
+	}
+	if e.ResourceID == "" {
+		return errors.New("missing resource id")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// GENERATED. DO NOT MODIFY!
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const ResourceEventType ObjectType = web.EventsURL
+
+type ResourceEvents struct {
+	ResourceEvents []*ResourceEvent `json:"resource_events"`
+}
+
+func (e *ResourceEvents) Add(object Object) {
+	e.ResourceEvents = append(e.ResourceEvents, object.(*ResourceEvent))
+}
+
+func (e *ResourceEvents) ItemAt(index int) Object {
+	return e.ResourceEvents[index]
+}
+
+func (e *ResourceEvents) Len() int {
+	return len(e.ResourceEvents)
+}
+
+func (e *ResourceEvent) GetType() ObjectType {
+	return ResourceEventType
+}
+
+// MarshalJSON override json serialization for http response
+func (e *ResourceEvent) MarshalJSON() ([]byte, error) {
+	type E ResourceEvent
+	toMarshal := struct {
+		*E
+		CreatedAt *string `json:"created_at,omitempty"`
+		UpdatedAt *string `json:"updated_at,omitempty"`
+		Labels    Labels  `json:"labels,omitempty"`
+	}{
+		E:      (*E)(e),
+		Labels: e.Labels,
+	}
+	if !e.CreatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.CreatedAt)
+		toMarshal.CreatedAt = &str
+	}
+	if !e.UpdatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.UpdatedAt)
+		toMarshal.UpdatedAt = &str
+	}
+	hasNoLabels := true
+	for key, values := range e.Labels {
+		if key != "" && len(values) != 0 {
+			hasNoLabels = false
+			break
+		}
+	}
+	if hasNoLabels {
+		toMarshal.Labels = nil
+	}
+	return json.Marshal(toMarshal)
+}
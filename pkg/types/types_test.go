@@ -71,6 +71,36 @@ var _ = Describe("Types test", func() {
 			},
 			baseObjectCreateFunc: createOperation,
 		},
+		{
+			expectedEqual: []string{
+				"Base.Labels", "Base.UpdatedAt", "Base.PagingSequence",
+			},
+			baseObjectCreateFunc: createUpgradeCampaign,
+		},
+		{
+			expectedEqual: []string{
+				"Base.Labels", "Base.UpdatedAt", "Base.PagingSequence",
+			},
+			baseObjectCreateFunc: createOperationEvent,
+		},
+		{
+			expectedEqual: []string{
+				"Base.Labels", "Base.UpdatedAt", "Base.PagingSequence",
+			},
+			baseObjectCreateFunc: createResourceEvent,
+		},
+		{
+			expectedEqual: []string{
+				"Base.Labels", "Base.UpdatedAt", "Base.PagingSequence",
+			},
+			baseObjectCreateFunc: createWebhook,
+		},
+		{
+			expectedEqual: []string{
+				"Base.Labels", "Base.UpdatedAt", "Base.PagingSequence",
+			},
+			baseObjectCreateFunc: createWebhookDelivery,
+		},
 	}
 
 	for i := range entries {
@@ -181,6 +211,16 @@ func setProps(object interface{}, propPath string) []propChange {
 					path:  currentPath,
 					value: OperationState("changed"),
 				})
+			case UpgradeCampaignState:
+				result = append(result, propChange{
+					path:  currentPath,
+					value: UpgradeCampaignState("changed"),
+				})
+			case OperationEventKind:
+				result = append(result, propChange{
+					path:  currentPath,
+					value: OperationEventKind("changed"),
+				})
 			case Labels:
 				result = append(result, propChange{
 					path: currentPath,
@@ -273,6 +313,103 @@ func createOperation(now time.Time) Object {
 	}
 }
 
+func createUpgradeCampaign(now time.Time) Object {
+	labels := Labels{
+		"label_key": []string{"value"},
+	}
+	return &UpgradeCampaign{
+		Base: Base{
+			ID:        "id",
+			Labels:    labels,
+			CreatedAt: now,
+			UpdatedAt: now.Add(time.Second * 10),
+		},
+		Name:           "name",
+		Description:    "description",
+		FieldQuery:     "service_plan_id eq '1'",
+		LabelQuery:     "label_key eq 'value'",
+		MaxConcurrency: 1,
+		State:          UpgradeCampaignState("state"),
+	}
+}
+
+func createOperationEvent(now time.Time) Object {
+	labels := Labels{
+		"label_key": []string{"value"},
+	}
+	return &OperationEvent{
+		Base: Base{
+			ID:        "id",
+			Labels:    labels,
+			CreatedAt: now,
+			UpdatedAt: now.Add(time.Second * 10),
+		},
+		Type:          OperationEventKind("type"),
+		Description:   "description",
+		OperationID:   "operation_id",
+		OperationType: OperationCategory("type"),
+		State:         OperationState("state"),
+		ResourceID:    "resource_id",
+		ResourceType:  ObjectType("resource_type"),
+		CorrelationID: "correlation_id",
+	}
+}
+
+func createResourceEvent(now time.Time) Object {
+	return &ResourceEvent{
+		Base: Base{
+			ID:        "id",
+			Labels:    Labels{},
+			CreatedAt: now,
+			UpdatedAt: now.Add(time.Second * 10),
+		},
+		Revision:      1,
+		Type:          CREATED,
+		ResourceType:  ObjectType("resource_type"),
+		ResourceID:    "resource_id",
+		Resource:      []byte(`{"id":"resource_id"}`),
+		CorrelationID: "correlation_id",
+	}
+}
+
+func createWebhook(now time.Time) Object {
+	return &Webhook{
+		Base: Base{
+			ID:        "id",
+			Labels:    Labels{},
+			CreatedAt: now,
+			UpdatedAt: now.Add(time.Second * 10),
+		},
+		Name:                "name",
+		URL:                 "https://example.com/events",
+		Secret:              "secret",
+		LabelQuery:          "key eq 'value'",
+		Disabled:            true,
+		DisabledReason:      "reason",
+		LastRevision:        1,
+		ConsecutiveFailures: 1,
+		NextAttemptAt:       now,
+	}
+}
+
+func createWebhookDelivery(now time.Time) Object {
+	return &WebhookDelivery{
+		Base: Base{
+			ID:        "id",
+			Labels:    Labels{},
+			CreatedAt: now,
+			UpdatedAt: now.Add(time.Second * 10),
+		},
+		WebhookID:      "webhook_id",
+		NotificationID: "notification_id",
+		Revision:       1,
+		Attempt:        1,
+		Succeeded:      true,
+		StatusCode:     200,
+		Error:          "error",
+	}
+}
+
 func createServiceInstance(now time.Time) Object {
 	labels := Labels{
 		"label_key": []string{"value"},
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// UpgradeCampaignLabelKey is the label key of the operations executed by an upgrade campaign
+const UpgradeCampaignLabelKey = "upgrade_campaign_id"
+
+// UpgradeCampaignState represents the state of an upgrade campaign
+type UpgradeCampaignState string
+
+const (
+	// UpgradeCampaignInProgress represents the state of an upgrade campaign which is upgrading the instances lagging behind their plans
+	UpgradeCampaignInProgress UpgradeCampaignState = "in progress"
+
+	// UpgradeCampaignPaused represents the state of an upgrade campaign which does not start further upgrades until it is resumed
+	UpgradeCampaignPaused UpgradeCampaignState = "paused"
+
+	// UpgradeCampaignSucceeded represents the state of an upgrade campaign after all of its upgrades have succeeded
+	UpgradeCampaignSucceeded UpgradeCampaignState = "succeeded"
+
+	// UpgradeCampaignFailed represents the state of an upgrade campaign after all of its upgrades have finished and some have failed
+	UpgradeCampaignFailed UpgradeCampaignState = "failed"
+)
+
+//go:generate smgen api UpgradeCampaign
+// UpgradeCampaign upgrades the maintenance info of the service instances selected by its queries to the maintenance info of their plans
+type UpgradeCampaign struct {
+	Base
+
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	FieldQuery string `json:"field_query,omitempty"`
+	LabelQuery string `json:"label_query,omitempty"`
+
+	MaxConcurrency int                  `json:"max_concurrency"`
+	State          UpgradeCampaignState `json:"state"`
+
+	Progress *UpgradeCampaignProgress `json:"progress,omitempty"`
+}
+
+// UpgradeCampaignProgress reports the number of instances of an upgrade campaign in each stage of their upgrade
+type UpgradeCampaignProgress struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	InProgress int `json:"in_progress"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+}
+
+// IsFinished returns whether the upgrade campaign has reached a final state
+func (e *UpgradeCampaign) IsFinished() bool {
+	return e.State == UpgradeCampaignSucceeded || e.State == UpgradeCampaignFailed
+}
+
+func (e *UpgradeCampaign) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	campaign := obj.(*UpgradeCampaign)
+	if e.Name != campaign.Name ||
+		e.Description != campaign.Description ||
+		e.FieldQuery != campaign.FieldQuery ||
+		e.LabelQuery != campaign.LabelQuery ||
+		e.MaxConcurrency != campaign.MaxConcurrency ||
+		e.State != campaign.State {
+		return false
+	}
+
+	return true
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *UpgradeCampaign) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.Name == "" {
+		return errors.New("missing upgrade campaign name")
+	}
+	if e.MaxConcurrency <= 0 {
+		return errors.New("max_concurrency must be larger than 0")
+	}
+	switch e.State {
+	case UpgradeCampaignInProgress, UpgradeCampaignPaused, UpgradeCampaignSucceeded, UpgradeCampaignFailed:
+	default:
+		return fmt.Errorf("invalid upgrade campaign state %s", e.State)
+	}
+
+	return nil
+}
+
+// MaintenanceInfoLags returns whether the version of the maintenance info of an instance is lower than the version of
+// the maintenance info of its plan. Plans without a maintenance info version are never lagged behind.
+func MaintenanceInfoLags(instanceMaintenanceInfo, planMaintenanceInfo json.RawMessage) bool {
+	planVersion := gjson.GetBytes(planMaintenanceInfo, "version").String()
+	if planVersion == "" {
+		return false
+	}
+	instanceVersion := gjson.GetBytes(instanceMaintenanceInfo, "version").String()
+	if instanceVersion == "" {
+		return true
+	}
+
+	return compareVersions(instanceVersion, planVersion) < 0
+}
+
+// compareVersions compares two semantic versions and returns -1, 0 or 1 if the first one is lower than, equal to
+// or higher than the second one. Build metadata is ignored and segments which are not numeric are compared lexically.
+func compareVersions(v1, v2 string) int {
+	core1, pre1 := splitVersion(v1)
+	core2, pre2 := splitVersion(v2)
+
+	if result := compareSegments(strings.Split(core1, "."), strings.Split(core2, ".")); result != 0 {
+		return result
+	}
+	switch {
+	case pre1 == pre2:
+		return 0
+	case pre1 == "":
+		return 1
+	case pre2 == "":
+		return -1
+	}
+	return compareSegments(strings.Split(pre1, "."), strings.Split(pre2, "."))
+}
+
+func splitVersion(version string) (string, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.Index(version, "-"); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+	return version, ""
+}
+
+func compareSegments(segments1, segments2 []string) int {
+	for i := 0; i < len(segments1) || i < len(segments2); i++ {
+		s1, s2 := "0", "0"
+		if i < len(segments1) {
+			s1 = segments1[i]
+		}
+		if i < len(segments2) {
+			s2 = segments2[i]
+		}
+
+		n1, err1 := strconv.ParseUint(s1, 10, 64)
+		n2, err2 := strconv.ParseUint(s2, 10, 64)
+		switch {
+		case err1 == nil && err2 == nil:
+			if n1 != n2 {
+				if n1 < n2 {
+					return -1
+				}
+				return 1
+			}
+		case err1 == nil:
+			return -1
+		case err2 == nil:
+			return 1
+		default:
+			if result := strings.Compare(s1, s2); result != 0 {
+				return result
+			}
+		}
+	}
+	return 0
+}
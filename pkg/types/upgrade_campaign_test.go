@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaintenanceInfoLags", func() {
+	maintenanceInfo := func(version string) json.RawMessage {
+		if version == "" {
+			return json.RawMessage(`{}`)
+		}
+		return json.RawMessage(`{"version":"` + version + `"}`)
+	}
+
+	DescribeTable("compares the maintenance info versions of instances and plans",
+		func(instanceVersion, planVersion string, lags bool) {
+			Expect(MaintenanceInfoLags(maintenanceInfo(instanceVersion), maintenanceInfo(planVersion))).To(Equal(lags))
+		},
+		Entry("lower patch version", "1.0.0", "1.0.1", true),
+		Entry("lower minor version compared numerically", "1.9.0", "1.10.0", true),
+		Entry("lower major version", "1.2.3", "2.0.0", true),
+		Entry("equal versions", "2.0.0", "2.0.0", false),
+		Entry("equal versions with different build metadata", "2.0.0+build.1", "2.0.0+build.2", false),
+		Entry("higher version", "2.1.0", "2.0.0", false),
+		Entry("pre-release of the plan version", "2.0.0-rc.1", "2.0.0", true),
+		Entry("release of a plan pre-release", "2.0.0", "2.0.0-rc.1", false),
+		Entry("missing instance version", "", "1.0.0", true),
+		Entry("missing plan version", "1.0.0", "", false),
+	)
+})
@@ -0,0 +1,65 @@
+// GENERATED. DO NOT MODIFY!
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const UpgradeCampaignType ObjectType = web.UpgradeCampaignsURL
+
+type UpgradeCampaigns struct {
+	UpgradeCampaigns []*UpgradeCampaign `json:"upgrade_campaigns"`
+}
+
+func (e *UpgradeCampaigns) Add(object Object) {
+	e.UpgradeCampaigns = append(e.UpgradeCampaigns, object.(*UpgradeCampaign))
+}
+
+func (e *UpgradeCampaigns) ItemAt(index int) Object {
+	return e.UpgradeCampaigns[index]
+}
+
+func (e *UpgradeCampaigns) Len() int {
+	return len(e.UpgradeCampaigns)
+}
+
+func (e *UpgradeCampaign) GetType() ObjectType {
+	return UpgradeCampaignType
+}
+
+// MarshalJSON override json serialization for http response
+func (e *UpgradeCampaign) MarshalJSON() ([]byte, error) {
+	type E UpgradeCampaign
+	toMarshal := struct {
+		*E
+		CreatedAt *string `json:"created_at,omitempty"`
+		UpdatedAt *string `json:"updated_at,omitempty"`
+		Labels    Labels  `json:"labels,omitempty"`
+	}{
+		E:      (*E)(e),
+		Labels: e.Labels,
+	}
+	if !e.CreatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.CreatedAt)
+		toMarshal.CreatedAt = &str
+	}
+	if !e.UpdatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.UpdatedAt)
+		toMarshal.UpdatedAt = &str
+	}
+	hasNoLabels := true
+	for key, values := range e.Labels {
+		if key != "" && len(values) != 0 {
+			hasNoLabels = false
+			break
+		}
+	}
+	if hasNoLabels {
+		toMarshal.Labels = nil
+	}
+	return json.Marshal(toMarshal)
+}
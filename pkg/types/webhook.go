@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+//go:generate smgen api Webhook
+// Webhook subscribes an HTTP endpoint to the notifications. The notifications matching the resource types, the
+// operation types and the label query of the webhook are delivered to the endpoint in the order of their revisions.
+type Webhook struct {
+	Base
+	Secured `json:"-"`
+	Strip   `json:"-"`
+
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret is the key of the HMAC signatures of the deliveries. It is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+
+	ResourceTypes  []string `json:"resource_types,omitempty"`
+	OperationTypes []string `json:"operation_types,omitempty"`
+	LabelQuery     string   `json:"label_query,omitempty"`
+
+	// Disabled webhooks do not receive notifications until they are enabled again
+	Disabled       bool   `json:"disabled"`
+	DisabledReason string `json:"disabled_reason,omitempty"`
+
+	// LastRevision is the revision of the last notification delivered to the endpoint or skipped as not matching
+	LastRevision int64 `json:"last_revision"`
+	// ConsecutiveFailures is the number of failed delivery attempts since the last successful delivery
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// NextAttemptAt is the time before which no delivery is attempted after a failed one
+	NextAttemptAt time.Time `json:"-"`
+}
+
+func (e *Webhook) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	webhook := obj.(*Webhook)
+	if e.Name != webhook.Name ||
+		e.URL != webhook.URL ||
+		e.Secret != webhook.Secret ||
+		e.LabelQuery != webhook.LabelQuery ||
+		e.Disabled != webhook.Disabled ||
+		e.DisabledReason != webhook.DisabledReason ||
+		e.LastRevision != webhook.LastRevision ||
+		e.ConsecutiveFailures != webhook.ConsecutiveFailures ||
+		!e.NextAttemptAt.Equal(webhook.NextAttemptAt) ||
+		!reflect.DeepEqual(e.ResourceTypes, webhook.ResourceTypes) ||
+		!reflect.DeepEqual(e.OperationTypes, webhook.OperationTypes) {
+		return false
+	}
+
+	return true
+}
+
+// Sanitize removes the secret of the webhook, which is only returned when the webhook is created
+func (e *Webhook) Sanitize(context.Context) {
+	e.Secret = ""
+}
+
+func (e *Webhook) Encrypt(ctx context.Context, encryptionFunc func(context.Context, []byte) ([]byte, error)) error {
+	return e.transform(ctx, encryptionFunc)
+}
+
+func (e *Webhook) Decrypt(ctx context.Context, decryptionFunc func(context.Context, []byte) ([]byte, error)) error {
+	return e.transform(ctx, decryptionFunc)
+}
+
+func (e *Webhook) transform(ctx context.Context, transformationFunc func(context.Context, []byte) ([]byte, error)) error {
+	if e.Secret == "" {
+		return nil
+	}
+	transformedSecret, err := transformationFunc(ctx, []byte(e.Secret))
+	if err != nil {
+		return err
+	}
+	e.Secret = string(transformedSecret)
+	return nil
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *Webhook) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.Name == "" {
+		return errors.New("missing webhook name")
+	}
+	endpoint, err := url.Parse(e.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("webhook url %s is not a valid http or https url", e.URL)
+	}
+	for _, resourceType := range e.ResourceTypes {
+		if !IsNotifiedResourceType(resourceType) {
+			return fmt.Errorf("unknown webhook resource type %s", resourceType)
+		}
+	}
+	for _, operationType := range e.OperationTypes {
+		switch NotificationOperation(operationType) {
+		case CREATED, MODIFIED, DELETED:
+		default:
+			return fmt.Errorf("unknown webhook operation type %s", operationType)
+		}
+	}
+
+	return nil
+}
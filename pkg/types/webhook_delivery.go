@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+//go:generate smgen api WebhookDelivery
+// WebhookDelivery records an attempt to deliver a notification to the endpoint of a webhook
+type WebhookDelivery struct {
+	Base
+
+	WebhookID      string `json:"webhook_id"`
+	NotificationID string `json:"notification_id"`
+	Revision       int64  `json:"revision"`
+	// Attempt is the number of the attempt to deliver the notification, starting with 1
+	Attempt    int    `json:"attempt"`
+	Succeeded  bool   `json:"succeeded"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (e *WebhookDelivery) Equals(obj Object) bool {
+	if !Equals(e, obj) {
+		return false
+	}
+
+	delivery := obj.(*WebhookDelivery)
+	if e.WebhookID != delivery.WebhookID ||
+		e.NotificationID != delivery.NotificationID ||
+		e.Revision != delivery.Revision ||
+		e.Attempt != delivery.Attempt ||
+		e.Succeeded != delivery.Succeeded ||
+		e.StatusCode != delivery.StatusCode ||
+		e.Error != delivery.Error {
+		return false
+	}
+
+	return true
+}
+
+// Validate implements InputValidator and verifies all mandatory fields are populated
+func (e *WebhookDelivery) Validate() error {
+	if util.HasRFC3986ReservedSymbols(e.ID) {
+		return fmt.Errorf("%s contains invalid character(s)", e.ID)
+	}
+	if e.WebhookID == "" {
+		return errors.New("missing webhook id")
+	}
+	if e.NotificationID == "" {
+		return errors.New("missing notification id")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// GENERATED. DO NOT MODIFY!
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const WebhookType ObjectType = web.WebhooksURL
+
+type Webhooks struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+func (e *Webhooks) Add(object Object) {
+	e.Webhooks = append(e.Webhooks, object.(*Webhook))
+}
+
+func (e *Webhooks) ItemAt(index int) Object {
+	return e.Webhooks[index]
+}
+
+func (e *Webhooks) Len() int {
+	return len(e.Webhooks)
+}
+
+func (e *Webhook) GetType() ObjectType {
+	return WebhookType
+}
+
+// MarshalJSON override json serialization for http response
+func (e *Webhook) MarshalJSON() ([]byte, error) {
+	type E Webhook
+	toMarshal := struct {
+		*E
+		CreatedAt *string `json:"created_at,omitempty"`
+		UpdatedAt *string `json:"updated_at,omitempty"`
+		Labels    Labels  `json:"labels,omitempty"`
+	}{
+		E:      (*E)(e),
+		Labels: e.Labels,
+	}
+	if !e.CreatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.CreatedAt)
+		toMarshal.CreatedAt = &str
+	}
+	if !e.UpdatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.UpdatedAt)
+		toMarshal.UpdatedAt = &str
+	}
+	hasNoLabels := true
+	for key, values := range e.Labels {
+		if key != "" && len(values) != 0 {
+			hasNoLabels = false
+			break
+		}
+	}
+	if hasNoLabels {
+		toMarshal.Labels = nil
+	}
+	return json.Marshal(toMarshal)
+}
@@ -0,0 +1,65 @@
+// GENERATED. DO NOT MODIFY!
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+)
+
+const WebhookDeliveryType ObjectType = web.WebhookDeliveriesURL
+
+type WebhookDeliveries struct {
+	WebhookDeliveries []*WebhookDelivery `json:"webhook_deliveries"`
+}
+
+func (e *WebhookDeliveries) Add(object Object) {
+	e.WebhookDeliveries = append(e.WebhookDeliveries, object.(*WebhookDelivery))
+}
+
+func (e *WebhookDeliveries) ItemAt(index int) Object {
+	return e.WebhookDeliveries[index]
+}
+
+func (e *WebhookDeliveries) Len() int {
+	return len(e.WebhookDeliveries)
+}
+
+func (e *WebhookDelivery) GetType() ObjectType {
+	return WebhookDeliveryType
+}
+
+// MarshalJSON override json serialization for http response
+func (e *WebhookDelivery) MarshalJSON() ([]byte, error) {
+	type E WebhookDelivery
+	toMarshal := struct {
+		*E
+		CreatedAt *string `json:"created_at,omitempty"`
+		UpdatedAt *string `json:"updated_at,omitempty"`
+		Labels    Labels  `json:"labels,omitempty"`
+	}{
+		E:      (*E)(e),
+		Labels: e.Labels,
+	}
+	if !e.CreatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.CreatedAt)
+		toMarshal.CreatedAt = &str
+	}
+	if !e.UpdatedAt.IsZero() {
+		str := util.ToRFCNanoFormat(e.UpdatedAt)
+		toMarshal.UpdatedAt = &str
+	}
+	hasNoLabels := true
+	for key, values := range e.Labels {
+		if key != "" && len(values) != 0 {
+			hasNoLabels = false
+			break
+		}
+	}
+	if hasNoLabels {
+		toMarshal.Labels = nil
+	}
+	return json.Marshal(toMarshal)
+}
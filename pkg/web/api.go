@@ -42,6 +42,27 @@ const (
 
 	// QueryParamForce is the value used to denote if the requested resource should be purged from db
 	QueryParamForce = "force"
+
+	// QueryParamOrderBy is the value used to denote the fields by which the listed resources should be ordered
+	QueryParamOrderBy = "orderBy"
+
+	// QueryParamFields is the value used to denote the fields of the requested resources which should be returned
+	QueryParamFields = "fields"
+
+	// QueryParamGroupBy is the value used to denote the field or label by which the counted resources should be grouped
+	QueryParamGroupBy = "group_by"
+
+	// QueryParamSearchText is the value used to denote the text by which resources should be searched
+	QueryParamSearchText = "q"
+
+	// QueryParamUpdatedSince is the value used to denote the time after which the listed resources should have been changed
+	QueryParamUpdatedSince = "updated_since"
+
+	// QueryParamLastRevision is the value used to denote the revision of the last resource event known to the client
+	QueryParamLastRevision = "last_revision"
+
+	// QueryParamScheduledAt is the value used to denote the time at which the requested operation should be executed
+	QueryParamScheduledAt = "scheduled_at"
 )
 
 // API is the primary point for REST API registration
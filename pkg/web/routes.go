@@ -33,6 +33,12 @@ const (
 	// MonitorHealthURL is the path of the healthcheck endpoint
 	MonitorHealthURL = "/" + apiVersion + "/monitor/health"
 
+	// MonitorJobsURL is the path of the background jobs monitoring endpoint
+	MonitorJobsURL = "/" + apiVersion + "/monitor/jobs"
+
+	// MonitorNotificationsURL is the path of the platform notifications monitoring endpoint
+	MonitorNotificationsURL = "/" + apiVersion + "/monitor/notifications"
+
 	// InfoURL is the path of the info endpoint
 	InfoURL = "/" + apiVersion + "/info"
 
@@ -42,9 +48,27 @@ const (
 	// LoggingConfigURL is the Logging Configuration API URL path
 	LoggingConfigURL = ConfigURL + "/logging"
 
+	// SearchURL is the URL path to search resources by their names and descriptions
+	SearchURL = "/" + apiVersion + "/search"
+
 	// ResourceOperationsURL is the URL path fetch operations for a resource
 	ResourceOperationsURL = "/operations"
 
+	// OperationCancelURL is the URL path to cancel an operation
+	OperationCancelURL = "/cancel"
+
+	// OperationRetryURL is the URL path to retry a failed operation
+	OperationRetryURL = "/retry"
+
+	// ResourceAggregationsURL is the URL path to fetch the counts of resources grouped by a field or label
+	ResourceAggregationsURL = "/aggregations"
+
+	// ResourceHistoryURL is the URL path to fetch the history of the operations of a resource
+	ResourceHistoryURL = "/history"
+
+	// ResourceBulkURL is the URL path to create, update and delete multiple resources in a single request
+	ResourceBulkURL = "/bulk"
+
 	ParametersURL = "/parameters"
 
 	// OperationsURL is the operations API base URL path
@@ -53,6 +77,30 @@ const (
 	// BrokerPlatformCredentialsURL is the URL path to manage service broker platform credentials
 	BrokerPlatformCredentialsURL = "/" + apiVersion + "/credentials"
 
+	// OperationEventsURL is the URL path identifying operation events, which are exposed through the history of their resources
+	OperationEventsURL = "/" + apiVersion + "/operation_events"
+
+	// EventsURL is the URL path to follow the changes of the resources in the order in which they were committed
+	EventsURL = "/" + apiVersion + "/events"
+
+	// WebhooksURL is the URL path to manage the subscriptions of HTTP endpoints to the notifications
+	WebhooksURL = "/" + apiVersion + "/webhooks"
+
+	// WebhookDeliveriesURL is the URL path identifying webhook deliveries, which are exposed through the deliveries of their webhooks
+	WebhookDeliveriesURL = "/" + apiVersion + "/webhook_deliveries"
+
+	// WebhookDeliveryHistoryURL is the URL path to fetch the delivery attempts of a webhook
+	WebhookDeliveryHistoryURL = "/deliveries"
+
+	// UpgradeCampaignsURL is the URL path to manage campaigns upgrading the maintenance info of service instances
+	UpgradeCampaignsURL = "/" + apiVersion + "/upgrade_campaigns"
+
+	// UpgradeCampaignPauseURL is the URL path to pause an upgrade campaign
+	UpgradeCampaignPauseURL = "/pause"
+
+	// UpgradeCampaignResumeURL is the URL path to resume a paused upgrade campaign
+	UpgradeCampaignResumeURL = "/resume"
+
 	// ProfileURL is the Configuration API base URL path
 	ProfileURL = "/" + apiVersion + "/profile"
 
@@ -8,13 +8,20 @@ import (
 type Settings struct {
 	PingTimeout  time.Duration `mapstructure:"ping_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// SSEKeepAliveInterval is the interval of the keep-alive comments sent to the platforms receiving notifications as server-sent events
+	SSEKeepAliveInterval time.Duration `mapstructure:"sse_keep_alive_interval"`
+	// LongPollTimeout is how long a long-polling notifications request waits for new notifications.
+	// It has to be below the request timeout of the server.
+	LongPollTimeout time.Duration `mapstructure:"long_poll_timeout"`
 }
 
 // DefaultSettings return the default values for ws server
 func DefaultSettings() *Settings {
 	return &Settings{
-		PingTimeout:  time.Second * 30,
-		WriteTimeout: time.Second * 30,
+		PingTimeout:          time.Second * 30,
+		WriteTimeout:         time.Second * 30,
+		SSEKeepAliveInterval: time.Second * 15,
+		LongPollTimeout:      time.Second * 2,
 	}
 }
 
@@ -28,5 +35,13 @@ func (s *Settings) Validate() error {
 		return fmt.Errorf("validate ws settings: WriteTimeout should be > 0")
 	}
 
+	if s.SSEKeepAliveInterval <= 0 {
+		return fmt.Errorf("validate ws settings: SSEKeepAliveInterval should be > 0")
+	}
+
+	if s.LongPollTimeout <= 0 {
+		return fmt.Errorf("validate ws settings: LongPollTimeout should be > 0")
+	}
+
 	return nil
 }
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Peripli/service-manager/operations/opcontext"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+// BatchItem is a single create, update or delete executed as part of a batch
+type BatchItem struct {
+	// Type is the kind of the change - create, update or delete
+	Type types.OperationCategory
+	// ObjectType is the type of the changed object
+	ObjectType types.ObjectType
+	// Object is the object to create or update. After the item is executed it holds the stored object.
+	Object types.Object
+	// LabelChanges are the label changes applied on update
+	LabelChanges types.LabelChanges
+	// Criteria select the object to update or the objects to delete
+	Criteria []query.Criterion
+	// Preconditions must be satisfied by the selected objects as they were when the item was prepared. If they are
+	// not, the objects have been modified in the meantime.
+	Preconditions []query.Criterion
+	// Operation, if provided, is put in the context while the item is executed, so that the resources created along
+	// with the object are recorded as its transitive resources
+	Operation *types.Operation
+	// Prepare, if provided, fills the item right before it is executed. It reads and writes through the transaction of
+	// the batch without running any interceptors, so that the item can refer to the changes of the items executed
+	// before it in the same batch.
+	Prepare func(ctx context.Context, repository Repository) error
+}
+
+type keepUpdatedAtKey struct{}
+
+// ContextWithKeptUpdatedAt marks the updates executed with the returned context as bookkeeping which keeps the
+// updated_at of the updated objects, so that the preconditions of an update in progress still hold after the
+// interceptors of the update stored intermediate state of the object
+func ContextWithKeptUpdatedAt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keepUpdatedAtKey{}, true)
+}
+
+// KeepsUpdatedAt returns true if the updates executed with the context keep the updated_at of the updated objects
+func KeepsUpdatedAt(ctx context.Context) bool {
+	keep, ok := ctx.Value(keepUpdatedAtKey{}).(bool)
+	return ok && keep
+}
+
+// prepare fills the item using its Prepare function, if provided
+func (bi *BatchItem) prepare(ctx context.Context, repository Repository) error {
+	if bi.Prepare == nil {
+		return nil
+	}
+	return bi.Prepare(ctx, repository)
+}
+
+// Execute executes the item using the given repository
+func (bi *BatchItem) Execute(ctx context.Context, repository Repository) error {
+	var err error
+	if bi.Operation != nil {
+		if ctx, err = opcontext.Set(ctx, bi.Operation); err != nil {
+			return err
+		}
+	}
+	criteria := append(append([]query.Criterion{}, bi.Criteria...), bi.Preconditions...)
+	switch bi.Type {
+	case types.CREATE:
+		bi.Object, err = repository.Create(ctx, bi.Object)
+	case types.UPDATE:
+		if len(bi.Preconditions) != 0 {
+			// the interceptors of the update may have effects outside of the storage before the update is stored, e.g.
+			// the broker updates the resource, so the preconditions are checked before the update is executed as well
+			if err = checkPreconditions(ctx, repository, bi.ObjectType, criteria); err != nil {
+				return err
+			}
+		}
+		bi.Object, err = repository.Update(ctx, bi.Object, bi.LabelChanges, criteria...)
+	case types.DELETE:
+		err = repository.Delete(ctx, bi.ObjectType, criteria...)
+		if err == util.ErrNotFoundInStorage && len(bi.Preconditions) != 0 {
+			// the object existed when the preconditions were checked so it has been modified since then
+			err = util.ErrConcurrentResourceModification
+		}
+	default:
+		err = fmt.Errorf("unsupported batch item type %s", bi.Type)
+	}
+	return err
+}
+
+// checkPreconditions checks that the object selected by the criteria still satisfies the preconditions among them.
+// The object is locked until the end of the transaction, if the repository is scoped to one.
+func checkPreconditions(ctx context.Context, repository Repository, objectType types.ObjectType, criteria []query.Criterion) error {
+	if _, err := repository.GetForUpdate(ctx, objectType, criteria...); err != nil {
+		if err == util.ErrNotFoundInStorage {
+			// the object existed when the preconditions were checked first so it has been modified since then
+			return util.ErrConcurrentResourceModification
+		}
+		return err
+	}
+	return nil
+}
+
+// BatchError is returned when an item of a batch fails. It holds the index of the failed item.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (be *BatchError) Error() string {
+	return fmt.Sprintf("batch item %d failed: %s", be.Index, be.Err)
+}
+
+// BatchRepository executes batches of changes
+type BatchRepository interface {
+	// InBatch executes all items in a single transaction. Either all items are stored or none of them is.
+	InBatch(ctx context.Context, items ...*BatchItem) error
+}
+
+// ExecuteBatch executes the items one after another in a single transaction using the given repository. Each item is
+// prepared right before it is executed. If the repository is a BatchRepository, the batch is delegated to it.
+func ExecuteBatch(ctx context.Context, repository Repository, items ...*BatchItem) error {
+	if batchRepository, ok := repository.(BatchRepository); ok {
+		return batchRepository.InBatch(ctx, items...)
+	}
+	transactionalRepository, ok := repository.(TransactionalRepository)
+	if !ok {
+		return errors.New("batches can only be executed by transactional repositories")
+	}
+
+	return transactionalRepository.InTransaction(ctx, func(ctx context.Context, txStorage Repository) error {
+		for i, item := range items {
+			if err := item.prepare(ctx, txStorage); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+			if err := item.Execute(ctx, txStorage); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
@@ -41,40 +41,50 @@ type KeyStore interface {
 // EncryptingDecorator creates a TransactionalRepositoryDecorator that can be used to add encrypting/decrypting logic to a TransactionalRepository
 func EncryptingDecorator(ctx context.Context, encrypter security.Encrypter, keyStore KeyStore, locker Locker) TransactionalRepositoryDecorator {
 	return func(next TransactionalRepository) (TransactionalRepository, error) {
-		ctx, cancelFunc := context.WithTimeout(ctx, 2*time.Second)
-		defer cancelFunc()
-
-		if err := locker.Lock(ctx); err != nil {
+		encryptionKey, err := getEncryptionKey(ctx, encrypter, keyStore, locker)
+		if err != nil {
 			return nil, err
 		}
-		defer func() {
-			if err := locker.Unlock(ctx); err != nil {
-				log.C(ctx).WithError(err).Error("error while unlocking keystore")
-			}
-		}()
 
-		encryptionKey, err := keyStore.GetEncryptionKey(ctx, encrypter.Decrypt)
-		if err != nil {
-			return nil, err
+		return NewEncryptingRepository(next, encrypter, encryptionKey)
+	}
+}
+
+// getEncryptionKey returns the encryption key from the KeyStore and generates a new one if none is present yet
+func getEncryptionKey(ctx context.Context, encrypter security.Encrypter, keyStore KeyStore, locker Locker) ([]byte, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, 2*time.Second)
+	defer cancelFunc()
+
+	if err := locker.Lock(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := locker.Unlock(ctx); err != nil {
+			log.C(ctx).WithError(err).Error("error while unlocking keystore")
 		}
+	}()
 
-		if len(encryptionKey) == 0 {
-			logger := log.C(ctx)
-			logger.Info("No encryption key is present. Generating new one...")
-			newEncryptionKey := make([]byte, 32)
-			if _, err = rand.Read(newEncryptionKey); err != nil {
-				return nil, fmt.Errorf("could not generate encryption key: %v", err)
-			}
-
-			encryptionKey = newEncryptionKey
-			if err = keyStore.SetEncryptionKey(ctx, newEncryptionKey, encrypter.Encrypt); err != nil {
-				return nil, err
-			}
-			logger.Info("Successfully generated new encryption key")
+	encryptionKey, err := keyStore.GetEncryptionKey(ctx, encrypter.Decrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encryptionKey) == 0 {
+		logger := log.C(ctx)
+		logger.Info("No encryption key is present. Generating new one...")
+		newEncryptionKey := make([]byte, 32)
+		if _, err = rand.Read(newEncryptionKey); err != nil {
+			return nil, fmt.Errorf("could not generate encryption key: %v", err)
 		}
 
-		return NewEncryptingRepository(next, encrypter, encryptionKey)
+		encryptionKey = newEncryptionKey
+		if err = keyStore.SetEncryptionKey(ctx, newEncryptionKey, encrypter.Encrypt); err != nil {
+			return nil, err
+		}
+		logger.Info("Successfully generated new encryption key")
 	}
+
+	return encryptionKey, nil
 }
 
 //NewEncryptingRepository creates a new TransactionalEncryptingRepository using the specified encrypter and encryption key
@@ -201,12 +211,20 @@ func (er *encryptingRepository) CountLabelValues(ctx context.Context, objectType
 	return er.repository.CountLabelValues(ctx, objectType, criteria...)
 }
 
-func (er *encryptingRepository) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, _ ...query.Criterion) (types.Object, error) {
+func (er *encryptingRepository) CountGroupedBy(ctx context.Context, objectType types.ObjectType, groupBy string, criteria ...query.Criterion) ([]types.AggregationGroup, error) {
+	return er.repository.CountGroupedBy(ctx, objectType, groupBy, criteria...)
+}
+
+func (er *encryptingRepository) ListDeleted(ctx context.Context, objectType types.ObjectType, since time.Time, criteria ...query.Criterion) ([]types.Deletion, error) {
+	return er.repository.ListDeleted(ctx, objectType, since, criteria...)
+}
+
+func (er *encryptingRepository) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, criteria ...query.Criterion) (types.Object, error) {
 	if err := er.encrypt(ctx, obj); err != nil {
 		return nil, err
 	}
 
-	updatedObj, err := er.repository.Update(ctx, obj, labelChanges)
+	updatedObj, err := er.repository.Update(ctx, obj, labelChanges, criteria...)
 	if err != nil {
 		return nil, err
 	}
@@ -19,6 +19,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Peripli/service-manager/pkg/security"
 
@@ -148,6 +149,14 @@ func (cr *integrityRepository) CountLabelValues(ctx context.Context, objectType
 	return cr.repository.CountLabelValues(ctx, objectType, criteria...)
 }
 
+func (cr *integrityRepository) CountGroupedBy(ctx context.Context, objectType types.ObjectType, groupBy string, criteria ...query.Criterion) ([]types.AggregationGroup, error) {
+	return cr.repository.CountGroupedBy(ctx, objectType, groupBy, criteria...)
+}
+
+func (cr *integrityRepository) ListDeleted(ctx context.Context, objectType types.ObjectType, since time.Time, criteria ...query.Criterion) ([]types.Deletion, error) {
+	return cr.repository.ListDeleted(ctx, objectType, since, criteria...)
+}
+
 func (cr *integrityRepository) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	return cr.repository.DeleteReturning(ctx, objectType, criteria...)
 }
@@ -19,6 +19,8 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/Peripli/service-manager/pkg/util"
 
 	"github.com/Peripli/service-manager/operations/opcontext"
@@ -220,6 +222,14 @@ func (ir *queryScopedInterceptableRepository) CountLabelValues(ctx context.Conte
 	return ir.repositoryInTransaction.CountLabelValues(ctx, objectType, criteria...)
 }
 
+func (ir *queryScopedInterceptableRepository) CountGroupedBy(ctx context.Context, objectType types.ObjectType, groupBy string, criteria ...query.Criterion) ([]types.AggregationGroup, error) {
+	return ir.repositoryInTransaction.CountGroupedBy(ctx, objectType, groupBy, criteria...)
+}
+
+func (ir *queryScopedInterceptableRepository) ListDeleted(ctx context.Context, objectType types.ObjectType, since time.Time, criteria ...query.Criterion) ([]types.Deletion, error) {
+	return ir.repositoryInTransaction.ListDeleted(ctx, objectType, since, criteria...)
+}
+
 func (ir *queryScopedInterceptableRepository) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	var resultList types.ObjectList
 	deleteObjectFunc := func(ctx context.Context, _ Repository, _ types.ObjectList, deletionCriteria ...query.Criterion) error {
@@ -413,6 +423,82 @@ func (itr *InterceptableTransactionalRepository) InTransaction(ctx context.Conte
 	return itr.RawRepository.InTransaction(ctx, fWrapper)
 }
 
+// InBatch executes all items one after another in a single transaction. Each item is prepared right before it is
+// executed, so that it can refer to the items executed before it. As the items are not known before the transaction
+// starts, the around transaction interceptors of each item run inside of the transaction, around the execution of the
+// item and its on transaction interceptors.
+func (itr *InterceptableTransactionalRepository) InBatch(ctx context.Context, items ...*BatchItem) error {
+	providedCreateInterceptors, providedUpdateInterceptors, providedDeleteInterceptors := itr.provideInterceptors()
+
+	return itr.RawRepository.InTransaction(ctx, func(ctx context.Context, txStorage Repository) error {
+		interceptableRepository := newScopedRepositoryWithInterceptors(txStorage, providedCreateInterceptors, providedUpdateInterceptors, providedDeleteInterceptors)
+		for i, item := range items {
+			if err := item.prepare(ctx, txStorage); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+			execute := aroundTxBatchItem(item, func(ctx context.Context) error {
+				return item.Execute(ctx, interceptableRepository)
+			}, providedCreateInterceptors, providedUpdateInterceptors, providedDeleteInterceptors)
+			if err := execute(ctx); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// aroundTxBatchItem wraps the execution of the item in its around transaction interceptors
+func aroundTxBatchItem(item *BatchItem, next func(ctx context.Context) error,
+	providedCreateInterceptors map[types.ObjectType]CreateInterceptor,
+	providedUpdateInterceptors map[types.ObjectType]UpdateInterceptor,
+	providedDeleteInterceptors map[types.ObjectType]DeleteInterceptor) func(ctx context.Context) error {
+	switch item.Type {
+	case types.CREATE:
+		if interceptor := providedCreateInterceptors[item.Object.GetType()]; interceptor != nil {
+			return func(ctx context.Context) error {
+				createdObj, err := interceptor.AroundTxCreate(func(ctx context.Context, obj types.Object) (types.Object, error) {
+					item.Object = obj
+					if err := next(ctx); err != nil {
+						return nil, err
+					}
+					return item.Object, nil
+				})(ctx, item.Object)
+				if err == nil {
+					item.Object = createdObj
+				}
+				return err
+			}
+		}
+	case types.UPDATE:
+		if interceptor := providedUpdateInterceptors[item.Object.GetType()]; interceptor != nil {
+			return func(ctx context.Context) error {
+				updatedObj, err := interceptor.AroundTxUpdate(func(ctx context.Context, obj types.Object, labelChanges ...*types.LabelChange) (types.Object, error) {
+					item.Object = obj
+					item.LabelChanges = labelChanges
+					if err := next(ctx); err != nil {
+						return nil, err
+					}
+					return item.Object, nil
+				})(ctx, item.Object, item.LabelChanges...)
+				if err == nil {
+					item.Object = updatedObj
+				}
+				return err
+			}
+		}
+	case types.DELETE:
+		if interceptor := providedDeleteInterceptors[item.ObjectType]; interceptor != nil {
+			return func(ctx context.Context) error {
+				return interceptor.AroundTxDelete(func(ctx context.Context, deletionCriteria ...query.Criterion) error {
+					item.Criteria = deletionCriteria
+					return next(ctx)
+				})(ctx, item.Criteria...)
+			}
+		}
+	}
+	return next
+}
+
 func (itr *InterceptableTransactionalRepository) QueryForList(ctx context.Context, objectType types.ObjectType, queryName NamedQuery, queryParams map[string]interface{}) (types.ObjectList, error) {
 	return itr.RawRepository.QueryForList(ctx, objectType, queryName, queryParams)
 }
@@ -615,6 +701,14 @@ func (itr *InterceptableTransactionalRepository) CountLabelValues(ctx context.Co
 	return itr.RawRepository.CountLabelValues(ctx, objectType, criteria...)
 }
 
+func (itr *InterceptableTransactionalRepository) CountGroupedBy(ctx context.Context, objectType types.ObjectType, groupBy string, criteria ...query.Criterion) ([]types.AggregationGroup, error) {
+	return itr.RawRepository.CountGroupedBy(ctx, objectType, groupBy, criteria...)
+}
+
+func (itr *InterceptableTransactionalRepository) ListDeleted(ctx context.Context, objectType types.ObjectType, since time.Time, criteria ...query.Criterion) ([]types.Deletion, error) {
+	return itr.RawRepository.ListDeleted(ctx, objectType, since, criteria...)
+}
+
 func (itr *InterceptableTransactionalRepository) DeleteReturning(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	providedCreateInterceptors, providedUpdateInterceptors, providedDeleteInterceptors := itr.provideInterceptors()
 
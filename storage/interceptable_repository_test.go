@@ -2,6 +2,7 @@ package storage_test
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/query"
@@ -475,6 +476,122 @@ var _ = Describe("Interceptable TransactionalRepository", func() {
 		})
 	})
 
+	Describe("In batch", func() {
+		var items []*storage.BatchItem
+
+		BeforeEach(func() {
+			fakeStorage.CreateCalls(func(ctx context.Context, obj types.Object) (types.Object, error) {
+				return obj, nil
+			})
+			byID := query.ByField(query.EqualsOperator, "id", "id")
+			items = []*storage.BatchItem{
+				{Type: types.CREATE, ObjectType: types.ServiceBrokerType, Object: &types.ServiceBroker{}},
+				{Type: types.UPDATE, ObjectType: types.ServiceBrokerType, Object: &types.ServiceBroker{
+					Base: types.Base{
+						UpdatedAt: updateTime,
+						Ready:     true,
+					},
+				}, Criteria: []query.Criterion{byID}},
+				{Type: types.DELETE, ObjectType: types.ServiceBrokerType, Criteria: []query.Criterion{byID}},
+			}
+		})
+
+		It("invokes all interceptors of all items in a single transaction", func() {
+			err := interceptableRepository.InBatch(ctx, items...)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(fakeStorage.InTransactionCallCount()).To(Equal(1))
+
+			Expect(fakeCreateAroundTxInterceptor.AroundTxCreateCallCount()).To(Equal(1))
+			Expect(fakeCreateOnTxInterceptor.OnTxCreateCallCount()).To(Equal(1))
+			Expect(fakeCreateInterceptor.AroundTxCreateCallCount()).To(Equal(1))
+			Expect(fakeCreateInterceptor.OnTxCreateCallCount()).To(Equal(1))
+
+			Expect(fakeUpdateAroundTxInterceptor.AroundTxUpdateCallCount()).To(Equal(1))
+			Expect(fakeUpdateOnTxIntercetptor.OnTxUpdateCallCount()).To(Equal(1))
+			Expect(fakeUpdateIntercetptor.AroundTxUpdateCallCount()).To(Equal(1))
+			Expect(fakeUpdateIntercetptor.OnTxUpdateCallCount()).To(Equal(1))
+
+			Expect(fakeDeleteAroundTxInterceptor.AroundTxDeleteCallCount()).To(Equal(1))
+			Expect(fakeDeleteOnTxInterceptor.OnTxDeleteCallCount()).To(Equal(1))
+			Expect(fakeDeleteInterceptor.AroundTxDeleteCallCount()).To(Equal(1))
+			Expect(fakeDeleteInterceptor.OnTxDeleteCallCount()).To(Equal(1))
+
+			Expect(fakeStorage.CreateCallCount()).To(Equal(1))
+			Expect(fakeStorage.UpdateCallCount()).To(Equal(1))
+			Expect(fakeStorage.DeleteCallCount()).To(Equal(1))
+		})
+
+		Context("when an around transaction interceptor of an item fails", func() {
+			It("does not execute the following items and returns the index of the item", func() {
+				fakeUpdateAroundTxInterceptor.AroundTxUpdateCalls(func(next storage.InterceptUpdateAroundTxFunc) storage.InterceptUpdateAroundTxFunc {
+					return func(ctx context.Context, obj types.Object, labelChanges ...*types.LabelChange) (types.Object, error) {
+						return nil, errors.New("expected")
+					}
+				})
+
+				err := interceptableRepository.InBatch(ctx, items...)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*storage.BatchError).Index).To(Equal(1))
+
+				Expect(fakeStorage.InTransactionCallCount()).To(Equal(1))
+				Expect(fakeCreateAroundTxInterceptor.AroundTxCreateCallCount()).To(Equal(1))
+				Expect(fakeStorage.UpdateCallCount()).To(Equal(0))
+				Expect(fakeDeleteAroundTxInterceptor.AroundTxDeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when items are prepared", func() {
+			It("prepares each item right after the previous item is executed", func() {
+				for i := range items {
+					executedItems := i
+					items[i].Prepare = func(ctx context.Context, repository storage.Repository) error {
+						Expect(fakeStorage.CreateCallCount() + fakeStorage.UpdateCallCount()).To(Equal(executedItems))
+						return nil
+					}
+				}
+
+				err := interceptableRepository.InBatch(ctx, items...)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(fakeStorage.DeleteCallCount()).To(Equal(1))
+			})
+
+			It("writes through the transaction without running interceptors", func() {
+				items[0].Prepare = func(ctx context.Context, repository storage.Repository) error {
+					return repository.Delete(ctx, types.ServiceBrokerType, query.ByField(query.EqualsOperator, "id", "id"))
+				}
+
+				err := interceptableRepository.InBatch(ctx, items...)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(fakeStorage.InTransactionCallCount()).To(Equal(1))
+				Expect(fakeStorage.DeleteCallCount()).To(Equal(2))
+				Expect(fakeDeleteAroundTxInterceptor.AroundTxDeleteCallCount()).To(Equal(1))
+				Expect(fakeDeleteOnTxInterceptor.OnTxDeleteCallCount()).To(Equal(1))
+			})
+
+			It("returns the index of the item which failed to be prepared", func() {
+				items[1].Prepare = func(ctx context.Context, repository storage.Repository) error {
+					return errors.New("expected")
+				}
+
+				err := interceptableRepository.InBatch(ctx, items...)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*storage.BatchError).Index).To(Equal(1))
+				Expect(fakeStorage.UpdateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when an item fails in the transaction", func() {
+			It("returns the index of the item", func() {
+				fakeStorage.DeleteReturns(errors.New("expected"))
+
+				err := interceptableRepository.InBatch(ctx, items...)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*storage.BatchError).Index).To(Equal(2))
+			})
+		})
+	})
+
 	Describe("Register interceptor", func() {
 		BeforeEach(func() {
 			interceptableRepository = storage.NewInterceptableTransactionalRepository(nil)
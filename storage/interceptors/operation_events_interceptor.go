@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interceptors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	OperationEventsCreateInterceptorName = "OperationEventsCreateInterceptor"
+	OperationEventsUpdateInterceptorName = "OperationEventsUpdateInterceptor"
+)
+
+// OperationEventsCreateInterceptorProvider provides an interceptor which records the creation of operations in the history of their resources
+type OperationEventsCreateInterceptorProvider struct {
+}
+
+func (*OperationEventsCreateInterceptorProvider) Name() string {
+	return OperationEventsCreateInterceptorName
+}
+
+func (*OperationEventsCreateInterceptorProvider) Provide() storage.CreateOnTxInterceptor {
+	return &operationEventsInterceptor{}
+}
+
+// OperationEventsUpdateInterceptorProvider provides an interceptor which records the progress of operations in the history of their resources
+type OperationEventsUpdateInterceptorProvider struct {
+}
+
+func (*OperationEventsUpdateInterceptorProvider) Name() string {
+	return OperationEventsUpdateInterceptorName
+}
+
+func (*OperationEventsUpdateInterceptorProvider) Provide() storage.UpdateOnTxInterceptor {
+	return &operationEventsInterceptor{}
+}
+
+// operationEventsInterceptor records the operation events in the same transaction as the operation changes
+// so that the history of a resource does not diverge from its operations
+type operationEventsInterceptor struct {
+}
+
+func (*operationEventsInterceptor) OnTxCreate(h storage.InterceptCreateOnTxFunc) storage.InterceptCreateOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, obj types.Object) (types.Object, error) {
+		createdObj, err := h(ctx, txStorage, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		operation := createdObj.(*types.Operation)
+		description := fmt.Sprintf("%s operation for %s with id %s created in state %s", operation.Type, operation.ResourceType, operation.ResourceID, operation.State)
+		if err := recordOperationEvent(ctx, txStorage, operation, types.OperationCreatedEvent, description); err != nil {
+			return nil, err
+		}
+
+		return createdObj, nil
+	}
+}
+
+func (*operationEventsInterceptor) OnTxUpdate(h storage.InterceptUpdateOnTxFunc) storage.InterceptUpdateOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, oldObj, newObj types.Object, labelChanges ...*types.LabelChange) (types.Object, error) {
+		updatedObj, err := h(ctx, txStorage, oldObj, newObj, labelChanges...)
+		if err != nil {
+			return nil, err
+		}
+
+		oldOperation := oldObj.(*types.Operation)
+		operation := updatedObj.(*types.Operation)
+
+		pendingOperation := newObj.(*types.Operation)
+		for _, event := range pendingOperation.PendingEvents {
+			if _, err := txStorage.Create(ctx, event); err != nil {
+				return nil, fmt.Errorf("could not record %s event of operation with id %s: %s", event.Type, operation.ID, util.HandleStorageError(err, types.OperationEventType.String()))
+			}
+		}
+		pendingOperation.PendingEvents = nil
+
+		if operation.Retries > oldOperation.Retries {
+			description := fmt.Sprintf("retry %d of the orphan mitigation", operation.Retries)
+			if err := recordOperationEvent(ctx, txStorage, operation, types.OperationRetryEvent, description); err != nil {
+				return nil, err
+			}
+		}
+		if operation.Reschedule && !oldOperation.Reschedule {
+			description := "waiting for the broker to finish the asynchronous operation"
+			if err := recordOperationEvent(ctx, txStorage, operation, types.OperationRescheduledEvent, description); err != nil {
+				return nil, err
+			}
+		}
+		if !operation.DeletionScheduled.IsZero() && oldOperation.DeletionScheduled.IsZero() {
+			description := "orphan mitigation of the resource in the broker is required"
+			if err := recordOperationEvent(ctx, txStorage, operation, types.OrphanMitigationStartedEvent, description); err != nil {
+				return nil, err
+			}
+		}
+		if operation.DeletionScheduled.IsZero() && !oldOperation.DeletionScheduled.IsZero() {
+			description := "orphan mitigation of the resource in the broker finished"
+			if err := recordOperationEvent(ctx, txStorage, operation, types.OrphanMitigationFinishedEvent, description); err != nil {
+				return nil, err
+			}
+		}
+		if operation.State != oldOperation.State {
+			description := fmt.Sprintf("state changed from %s to %s", oldOperation.State, operation.State)
+			if operation.State == types.FAILED && len(operation.Errors) != 0 {
+				description = fmt.Sprintf("%s with errors %s", description, operation.Errors)
+			}
+			if err := recordOperationEvent(ctx, txStorage, operation, types.OperationStateChangedEvent, description); err != nil {
+				return nil, err
+			}
+		}
+
+		return updatedObj, nil
+	}
+}
+
+func recordOperationEvent(ctx context.Context, txStorage storage.Repository, operation *types.Operation, eventType types.OperationEventKind, description string) error {
+	if err := operations.RecordEvent(ctx, txStorage, operation, eventType, description); err != nil {
+		return fmt.Errorf("could not record %s event of operation with id %s: %s", eventType, operation.ID, err)
+	}
+
+	log.C(ctx).Debugf("Recorded %s event of operation with id %s", eventType, operation.ID)
+	return nil
+}
+
+// recordBrokerEvent keeps an event of the communication with the broker of the resource of the operation pending
+// until the next update of the operation, so that it is recorded in the same transaction as the operation changes.
+func recordBrokerEvent(ctx context.Context, operation *types.Operation, eventType types.OperationEventKind, description string) {
+	if operation == nil {
+		return
+	}
+	event, err := operations.NewOperationEvent(operation, eventType, description)
+	if err != nil {
+		log.C(ctx).Warnf("Could not create %s event of operation with id %s: %s", eventType, operation.ID, err)
+		return
+	}
+	operation.PendingEvents = append(operation.PendingEvents, event)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/storage"
 )
 
@@ -61,7 +62,11 @@ func (c *operationsCreateInterceptor) OnTxCreate(h storage.InterceptCreateOnTxFu
 		}
 
 		if tenantID == "" {
-			log.C(ctx).Infof("Could not add %s label to operation with id %s. Label not found in context criteria.", c.TenantIdentifier, operation.ID)
+			tenantID = c.resourceTenant(ctx, storage, operation)
+		}
+
+		if tenantID == "" {
+			log.C(ctx).Infof("Could not add %s label to operation with id %s. Label not found in context criteria or on the resource.", c.TenantIdentifier, operation.ID)
 			return h(ctx, storage, operation)
 		}
 
@@ -79,3 +84,22 @@ func (c *operationsCreateInterceptor) OnTxCreate(h storage.InterceptCreateOnTxFu
 		return h(ctx, storage, operation)
 	}
 }
+
+// resourceTenant returns the tenant label of the resource of the operation. Operations which are not created by tenant
+// requests, such as the ones created by the Maintainer, upgrade campaigns, retries and OSB requests, get the tenant of
+// their resource this way.
+func (c *operationsCreateInterceptor) resourceTenant(ctx context.Context, repository storage.Repository, operation *types.Operation) string {
+	byID := query.ByField(query.EqualsOperator, "id", operation.ResourceID)
+	resource, err := repository.Get(ctx, operation.ResourceType, byID)
+	if err != nil {
+		if err != util.ErrNotFoundInStorage {
+			log.C(ctx).WithError(err).Debugf("Could not get %s with id %s of operation with id %s", operation.ResourceType, operation.ResourceID, operation.ID)
+		}
+		return ""
+	}
+
+	if tenants := resource.GetLabels()[c.TenantIdentifier]; len(tenants) > 0 {
+		return tenants[0]
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Peripli/service-manager/pkg/client"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	osbc "github.com/kubernetes-sigs/go-open-service-broker-client/v2"
+)
+
+// maintenanceInfoAPIVersion is the OSB API version which introduced the maintenance info of service instances
+const maintenanceInfoAPIVersion = "2.15"
+
+type updateInstanceMaintenanceInfoBody struct {
+	ServiceID       string                             `json:"service_id"`
+	PlanID          *string                            `json:"plan_id,omitempty"`
+	Parameters      map[string]interface{}             `json:"parameters,omitempty"`
+	Context         map[string]interface{}             `json:"context,omitempty"`
+	PreviousValues  *previousValuesMaintenanceInfoBody `json:"previous_values,omitempty"`
+	MaintenanceInfo json.RawMessage                    `json:"maintenance_info"`
+}
+
+type previousValuesMaintenanceInfoBody struct {
+	PlanID          string          `json:"plan_id,omitempty"`
+	MaintenanceInfo json.RawMessage `json:"maintenance_info,omitempty"`
+}
+
+type updateInstanceMaintenanceInfoResponse struct {
+	DashboardURL *string `json:"dashboard_url,omitempty"`
+	Operation    *string `json:"operation,omitempty"`
+}
+
+// updateInstanceMaintenanceInfo sends the update instance request together with the new maintenance info of the
+// instance to the broker. The OSB client does not support maintenance info, so the request is sent directly.
+func updateInstanceMaintenanceInfo(ctx context.Context, broker *types.ServiceBroker, request *osbc.UpdateInstanceRequest, maintenanceInfo, previousMaintenanceInfo json.RawMessage) (*osbc.UpdateInstanceResponse, error) {
+	brokerClient, err := client.NewBrokerClient(broker, util.ClientRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &updateInstanceMaintenanceInfoBody{
+		ServiceID:       request.ServiceID,
+		PlanID:          request.PlanID,
+		Parameters:      request.Parameters,
+		Context:         request.Context,
+		MaintenanceInfo: maintenanceInfo,
+	}
+	if request.PreviousValues != nil || len(previousMaintenanceInfo) != 0 {
+		body.PreviousValues = &previousValuesMaintenanceInfoBody{
+			MaintenanceInfo: previousMaintenanceInfo,
+		}
+		if request.PreviousValues != nil {
+			body.PreviousValues.PlanID = request.PreviousValues.PlanID
+		}
+	}
+
+	url := fmt.Sprintf("%s/v2/service_instances/%s", strings.TrimRight(broker.BrokerURL, "/"), request.InstanceID)
+	params := map[string]string{"accepts_incomplete": fmt.Sprint(request.AcceptsIncomplete)}
+	headers := map[string]string{"X-Broker-API-Version": maintenanceInfoAPIVersion}
+	response, err := brokerClient.SendRequest(ctx, http.MethodPatch, url, params, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		responseBytes, err := util.BodyToBytes(response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("could not read update instance response with status %d: %s", response.StatusCode, err)
+		}
+		responseBody := &updateInstanceMaintenanceInfoResponse{}
+		if len(responseBytes) != 0 {
+			if err := json.Unmarshal(responseBytes, responseBody); err != nil {
+				return nil, fmt.Errorf("could not decode update instance response with status %d: %s", response.StatusCode, err)
+			}
+		}
+		updateInstanceResponse := &osbc.UpdateInstanceResponse{
+			Async:        response.StatusCode == http.StatusAccepted,
+			DashboardURL: responseBody.DashboardURL,
+		}
+		if responseBody.Operation != nil {
+			operationKey := osbc.OperationKey(*responseBody.Operation)
+			updateInstanceResponse.OperationKey = &operationKey
+		}
+		return updateInstanceResponse, nil
+	default:
+		return nil, util.HandleResponseError(response)
+	}
+}
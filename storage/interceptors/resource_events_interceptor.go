@@ -0,0 +1,252 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/tidwall/sjson"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const (
+	ResourceEventsCreateInterceptorName = "ResourceEventsCreateInterceptor"
+	ResourceEventsUpdateInterceptorName = "ResourceEventsUpdateInterceptor"
+	ResourceEventsDeleteInterceptorName = "ResourceEventsDeleteInterceptor"
+	PlanEventsCascadeInterceptorName    = "PlanEventsCascadeInterceptor"
+)
+
+// resourceEventExcludedFields are the fields of the resources which are not kept in the events as they may contain secrets
+var resourceEventExcludedFields = []string{"credentials", "parameters"}
+
+// ResourceEventsCreateInterceptorProvider provides an interceptor which records the creation of resources in the resource events
+type ResourceEventsCreateInterceptorProvider struct {
+	TenantKey string
+}
+
+func (*ResourceEventsCreateInterceptorProvider) Name() string {
+	return ResourceEventsCreateInterceptorName
+}
+
+func (p *ResourceEventsCreateInterceptorProvider) Provide() storage.CreateOnTxInterceptor {
+	return &resourceEventsInterceptor{tenantKey: p.TenantKey}
+}
+
+// ResourceEventsUpdateInterceptorProvider provides an interceptor which records the modification of resources in the resource events
+type ResourceEventsUpdateInterceptorProvider struct {
+	TenantKey string
+}
+
+func (*ResourceEventsUpdateInterceptorProvider) Name() string {
+	return ResourceEventsUpdateInterceptorName
+}
+
+func (p *ResourceEventsUpdateInterceptorProvider) Provide() storage.UpdateOnTxInterceptor {
+	return &resourceEventsInterceptor{tenantKey: p.TenantKey}
+}
+
+// ResourceEventsDeleteInterceptorProvider provides an interceptor which records the deletion of resources in the resource events
+type ResourceEventsDeleteInterceptorProvider struct {
+	TenantKey string
+}
+
+func (*ResourceEventsDeleteInterceptorProvider) Name() string {
+	return ResourceEventsDeleteInterceptorName
+}
+
+func (p *ResourceEventsDeleteInterceptorProvider) Provide() storage.DeleteOnTxInterceptor {
+	return &resourceEventsInterceptor{tenantKey: p.TenantKey}
+}
+
+// resourceEventsInterceptor records the resource events in the same transaction as the resource changes, so that
+// the events of committed changes are never lost and the events of rolled back changes are never seen
+type resourceEventsInterceptor struct {
+	tenantKey string
+}
+
+func (i *resourceEventsInterceptor) OnTxCreate(h storage.InterceptCreateOnTxFunc) storage.InterceptCreateOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, obj types.Object) (types.Object, error) {
+		createdObj, err := h(ctx, txStorage, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := recordResourceEvent(ctx, txStorage, i.tenantKey, types.CREATED, createdObj); err != nil {
+			return nil, err
+		}
+		return createdObj, nil
+	}
+}
+
+func (i *resourceEventsInterceptor) OnTxUpdate(h storage.InterceptUpdateOnTxFunc) storage.InterceptUpdateOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, oldObj, newObj types.Object, labelChanges ...*types.LabelChange) (types.Object, error) {
+		updatedObj, err := h(ctx, txStorage, oldObj, newObj, labelChanges...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := recordResourceEvent(ctx, txStorage, i.tenantKey, types.MODIFIED, updatedObj); err != nil {
+			return nil, err
+		}
+		return updatedObj, nil
+	}
+}
+
+func (i *resourceEventsInterceptor) OnTxDelete(h storage.InterceptDeleteOnTxFunc) storage.InterceptDeleteOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, objects types.ObjectList, deletionCriteria ...query.Criterion) error {
+		if err := h(ctx, txStorage, objects, deletionCriteria...); err != nil {
+			return err
+		}
+
+		return recordDeletedResourceEvents(ctx, txStorage, i.tenantKey, objects)
+	}
+}
+
+// PlanEventsCascadeInterceptorProvider provides an interceptor which records the deletion of the service plans
+// deleted by the database together with their service offerings or brokers
+type PlanEventsCascadeInterceptorProvider struct {
+	TenantKey string
+}
+
+func (*PlanEventsCascadeInterceptorProvider) Name() string {
+	return PlanEventsCascadeInterceptorName
+}
+
+func (p *PlanEventsCascadeInterceptorProvider) Provide() storage.DeleteOnTxInterceptor {
+	return &planEventsCascadeInterceptor{tenantKey: p.TenantKey}
+}
+
+type planEventsCascadeInterceptor struct {
+	tenantKey string
+}
+
+func (i *planEventsCascadeInterceptor) OnTxDelete(h storage.InterceptDeleteOnTxFunc) storage.InterceptDeleteOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, objects types.ObjectList, deletionCriteria ...query.Criterion) error {
+		plans, err := cascadedPlans(ctx, txStorage, objects)
+		if err != nil {
+			return err
+		}
+
+		if err := h(ctx, txStorage, objects, deletionCriteria...); err != nil {
+			return err
+		}
+
+		if plans == nil {
+			return nil
+		}
+		return recordDeletedResourceEvents(ctx, txStorage, i.tenantKey, plans)
+	}
+}
+
+// cascadedPlans lists the service plans of the service offerings or brokers to be deleted
+func cascadedPlans(ctx context.Context, txStorage storage.Repository, objects types.ObjectList) (types.ObjectList, error) {
+	var offeringIDs []string
+	switch objects.(type) {
+	case *types.ServiceBrokers:
+		brokerIDs := objectIDs(objects)
+		if len(brokerIDs) == 0 {
+			return nil, nil
+		}
+		offerings, err := txStorage.ListNoLabels(ctx, types.ServiceOfferingType, query.ByField(query.InOperator, "broker_id", brokerIDs...))
+		if err != nil {
+			return nil, err
+		}
+		offeringIDs = objectIDs(offerings)
+	case *types.ServiceOfferings:
+		offeringIDs = objectIDs(objects)
+	default:
+		return nil, fmt.Errorf("could not list the service plans of %T", objects)
+	}
+
+	if len(offeringIDs) == 0 {
+		return nil, nil
+	}
+	return txStorage.List(ctx, types.ServicePlanType, query.ByField(query.InOperator, "service_offering_id", offeringIDs...))
+}
+
+func objectIDs(objects types.ObjectList) []string {
+	ids := make([]string, 0, objects.Len())
+	for i := 0; i < objects.Len(); i++ {
+		ids = append(ids, objects.ItemAt(i).GetID())
+	}
+	return ids
+}
+
+func recordDeletedResourceEvents(ctx context.Context, txStorage storage.Repository, tenantKey string, objects types.ObjectList) error {
+	for i := 0; i < objects.Len(); i++ {
+		if err := recordResourceEvent(ctx, txStorage, tenantKey, types.DELETED, objects.ItemAt(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordResourceEvent stores an event with the state of the resource after the change, or before it for deletions.
+// The event is labeled with the tenant of the resource, so that the tenants only see the events of their resources.
+func recordResourceEvent(ctx context.Context, txStorage storage.Repository, tenantKey string, eventType types.NotificationOperation, obj types.Object) error {
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("could not generate GUID for %s event of %s with id %s: %s", eventType, obj.GetType(), obj.GetID(), err)
+	}
+
+	resource, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	for _, field := range resourceEventExcludedFields {
+		if resource, err = sjson.DeleteBytes(resource, field); err != nil {
+			return err
+		}
+	}
+
+	labels := types.Labels{}
+	if tenantKey != "" {
+		if tenant, found := obj.GetLabels()[tenantKey]; found && len(tenant) != 0 {
+			labels[tenantKey] = tenant
+		}
+	}
+
+	now := time.Now()
+	event := &types.ResourceEvent{
+		Base: types.Base{
+			ID:        UUID.String(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Labels:    labels,
+			Ready:     true,
+		},
+		Type:          eventType,
+		ResourceType:  obj.GetType(),
+		ResourceID:    obj.GetID(),
+		Resource:      resource,
+		CorrelationID: log.CorrelationIDFromContext(ctx),
+	}
+	if _, err := txStorage.Create(ctx, event); err != nil {
+		return fmt.Errorf("could not record %s event of %s with id %s: %s", eventType, obj.GetType(), obj.GetID(), err)
+	}
+
+	log.C(ctx).Debugf("Recorded %s event of %s with id %s", eventType, obj.GetType(), obj.GetID())
+	return nil
+}
@@ -167,6 +167,8 @@ func (i *ServiceBindingInterceptor) AroundTxCreate(f storage.InterceptCreateArou
 			binding.Context = contextBytes
 
 			log.C(ctx).Infof("Sending bind request %s to broker with name %s", logBindRequest(bindRequest), broker.Name)
+			recordBrokerEvent(ctx, operation, types.BrokerRequestEvent,
+				fmt.Sprintf("bind request %s sent to broker with name %s", logBindRequest(bindRequest), broker.Name))
 			bindResponse, err = osbClient.Bind(bindRequest)
 			if err != nil {
 				brokerError := &util.HTTPError{
@@ -174,6 +176,7 @@ func (i *ServiceBindingInterceptor) AroundTxCreate(f storage.InterceptCreateArou
 					Description: fmt.Sprintf("Failed bind request %s: %s", logBindRequest(bindRequest), err),
 					StatusCode:  http.StatusBadGateway,
 				}
+				recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
 				if shouldStartOrphanMitigation(err) {
 					// store the instance data on the operation context so that later on we can do orphan mitigation
 					operation.DeletionScheduled = time.Now()
@@ -192,6 +195,9 @@ func (i *ServiceBindingInterceptor) AroundTxCreate(f storage.InterceptCreateArou
 				}
 				return nil, brokerError
 			}
+			// the credentials of the binding are never recorded in its history
+			recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+				fmt.Sprintf("bind response %s received from broker with name %s", logBindResponse(bindResponse), broker.Name))
 
 			bindResponseDetails := &bindResponseDetails{
 				Credentials:     bindResponse.Credentials,
@@ -326,11 +332,15 @@ func (i *ServiceBindingInterceptor) deleteSingleBinding(ctx context.Context, bin
 		unbindRequest := prepareUnbindRequest(instance, binding, service.CatalogID, plan.CatalogID, service.BindingsRetrievable)
 
 		log.C(ctx).Infof("Sending unbind request %s to broker with name %s", logUnbindRequest(unbindRequest), broker.Name)
+		recordBrokerEvent(ctx, operation, types.BrokerRequestEvent,
+			fmt.Sprintf("unbind request %s sent to broker with name %s", logUnbindRequest(unbindRequest), broker.Name))
 		unbindResponse, err = osbClient.Unbind(unbindRequest)
 		if err != nil {
 			if osbc.IsGoneError(err) {
 				log.C(ctx).Infof("Synchronous unbind %s to broker %s returned 410 GONE and is considered success",
 					logUnbindRequest(unbindRequest), broker.Name)
+				recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+					fmt.Sprintf("unbind response 410 GONE received from broker with name %s", broker.Name))
 				return nil
 			}
 			brokerError := &util.HTTPError{
@@ -338,6 +348,7 @@ func (i *ServiceBindingInterceptor) deleteSingleBinding(ctx context.Context, bin
 				Description: fmt.Sprintf("Failed unbind request %s: %s", logUnbindRequest(unbindRequest), err),
 				StatusCode:  http.StatusBadGateway,
 			}
+			recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
 			if shouldStartOrphanMitigation(err) {
 				operation.DeletionScheduled = time.Now()
 				operation.Reschedule = false
@@ -348,6 +359,8 @@ func (i *ServiceBindingInterceptor) deleteSingleBinding(ctx context.Context, bin
 			}
 			return brokerError
 		}
+		recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+			fmt.Sprintf("unbind response %s received from broker with name %s", logUnbindResponse(unbindResponse), broker.Name))
 
 		if unbindResponse.Async {
 			log.C(ctx).Infof("Successful asynchronous unbind request %s to broker %s returned response %s",
@@ -547,6 +560,8 @@ func (i *ServiceBindingInterceptor) pollServiceBinding(ctx context.Context, osbC
 			if err != nil {
 				if osbc.IsGoneError(err) && operation.Type == types.DELETE {
 					log.C(ctx).Infof("Successfully finished polling operation for binding with id %s and name %s", binding.ID, binding.Name)
+					recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+						fmt.Sprintf("poll last operation response 410 GONE received for request %s", logPollBindingRequest(pollingRequest)))
 
 					operation.Reschedule = false
 					operation.RescheduleTimestamp = time.Time{}
@@ -556,12 +571,14 @@ func (i *ServiceBindingInterceptor) pollServiceBinding(ctx context.Context, osbC
 					return nil
 				}
 
-				return &util.HTTPError{
+				brokerError := &util.HTTPError{
 					ErrorType: "BrokerError",
 					Description: fmt.Sprintf("Failed poll last operation request %s for binding with id %s and name %s: %s",
 						logPollBindingRequest(pollingRequest), binding.ID, binding.Name, err),
 					StatusCode: http.StatusBadGateway,
 				}
+				recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
+				return brokerError
 			}
 
 			switch pollingResponse.State {
@@ -571,6 +588,8 @@ func (i *ServiceBindingInterceptor) pollServiceBinding(ctx context.Context, osbC
 
 			case osbc.StateSucceeded:
 				log.C(ctx).Infof("Successfully finished polling operation for binding with id %s and name %s", binding.ID, binding.Name)
+				recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+					fmt.Sprintf("poll last operation response %s received for request %s", logPollBindingResponse(pollingResponse), logPollBindingRequest(pollingRequest)))
 
 				operation.Reschedule = false
 				operation.RescheduleTimestamp = time.Time{}
@@ -593,6 +612,8 @@ func (i *ServiceBindingInterceptor) pollServiceBinding(ctx context.Context, osbC
 			case osbc.StateFailed:
 				log.C(ctx).Infof("Failed polling operation for binding with id %s and name %s with response %s",
 					binding.ID, binding.Name, logPollBindingResponse(pollingResponse))
+				recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+					fmt.Sprintf("poll last operation response %s received for request %s", logPollBindingResponse(pollingResponse), logPollBindingRequest(pollingRequest)))
 				operation.Reschedule = false
 				operation.RescheduleTimestamp = time.Time{}
 				if enableOrphanMitigation {
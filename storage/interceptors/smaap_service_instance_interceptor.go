@@ -17,6 +17,7 @@
 package interceptors
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -154,6 +155,8 @@ func (i *ServiceInstanceInterceptor) AroundTxCreate(f storage.InterceptCreateAro
 				return nil, fmt.Errorf("failed to prepare provision request: %s", err)
 			}
 			log.C(ctx).Infof("Sending provision request %s to broker with name %s", logProvisionRequest(provisionRequest), broker.Name)
+			recordBrokerEvent(ctx, operation, types.BrokerRequestEvent,
+				fmt.Sprintf("provision request %s sent to broker with name %s", logProvisionRequest(provisionRequest), broker.Name))
 			provisionResponse, err = osbClient.ProvisionInstance(provisionRequest)
 			if err != nil {
 				brokerError := &util.HTTPError{
@@ -161,6 +164,7 @@ func (i *ServiceInstanceInterceptor) AroundTxCreate(f storage.InterceptCreateAro
 					Description: fmt.Sprintf("Failed provisioning request %s: %s", logProvisionRequest(provisionRequest), err),
 					StatusCode:  http.StatusBadGateway,
 				}
+				recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
 
 				if shouldStartOrphanMitigation(err) {
 					// mark the operation as deletion scheduled meaning orphan mitigation is required
@@ -182,6 +186,8 @@ func (i *ServiceInstanceInterceptor) AroundTxCreate(f storage.InterceptCreateAro
 
 				return nil, brokerError
 			}
+			recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+				fmt.Sprintf("provision response %s received from broker with name %s", logProvisionResponse(provisionResponse), broker.Name))
 
 			if provisionResponse.DashboardURL != nil {
 				dashboardURL := *provisionResponse.DashboardURL
@@ -249,7 +255,7 @@ func (i *ServiceInstanceInterceptor) AroundTxUpdate(f storage.InterceptUpdateAro
 		if operation.Reschedule {
 			if err := i.pollServiceInstance(ctx, osbClient, updatedInstance, plan, operation, service.CatalogID, plan.CatalogID, false); err != nil {
 				updatedInstance.UpdateValues = types.InstanceUpdateValues{}
-				_, updateErr := i.repository.RawRepository.Update(ctx, updatedInstance, types.LabelChanges{})
+				_, updateErr := i.repository.RawRepository.Update(storage.ContextWithKeptUpdatedAt(ctx), updatedInstance, types.LabelChanges{})
 				if updateErr != nil {
 					return nil, updateErr
 				}
@@ -292,16 +298,25 @@ func (i *ServiceInstanceInterceptor) AroundTxUpdate(f storage.InterceptUpdateAro
 				return nil, fmt.Errorf("faied to prepare update instance request: %s", err)
 			}
 			log.C(ctx).Infof("Sending update instance request %s to broker with name %s", logUpdateInstanceRequest(updateInstanceRequest), broker.Name)
-			updateInstanceResponse, err = osbClient.UpdateInstance(updateInstanceRequest)
+			recordBrokerEvent(ctx, operation, types.BrokerRequestEvent,
+				fmt.Sprintf("update instance request %s sent to broker with name %s", logUpdateInstanceRequest(updateInstanceRequest), broker.Name))
+			if len(updatedInstance.MaintenanceInfo) != 0 && !bytes.Equal(updatedInstance.MaintenanceInfo, instance.MaintenanceInfo) {
+				updateInstanceResponse, err = updateInstanceMaintenanceInfo(ctx, broker, updateInstanceRequest, updatedInstance.MaintenanceInfo, instance.MaintenanceInfo)
+			} else {
+				updateInstanceResponse, err = osbClient.UpdateInstance(updateInstanceRequest)
+			}
 			if err != nil {
 				brokerError := &util.HTTPError{
 					ErrorType:   "BrokerError",
 					Description: fmt.Sprintf("Failed update instance request %s: %s", logUpdateInstanceRequest(updateInstanceRequest), err),
 					StatusCode:  http.StatusBadGateway,
 				}
+				recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
 
 				return nil, brokerError
 			}
+			recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+				fmt.Sprintf("update instance response %s received from broker with name %s", logUpdateInstanceResponse(updateInstanceResponse), broker.Name))
 
 			// SM should not not store parameters
 			updatedInstance.Parameters = nil
@@ -314,8 +329,9 @@ func (i *ServiceInstanceInterceptor) AroundTxUpdate(f storage.InterceptUpdateAro
 				ServiceInstance: updatedInstance,
 				LabelChanges:    labelChanges,
 			}
-			// use repository with no interceptors to attach the update details to the instance
-			instanceObjAfterRawUpdate, err := i.repository.RawRepository.Update(ctx, instance, types.LabelChanges{})
+			// use repository with no interceptors to attach the update details to the instance. The updated_at is kept,
+			// as the update is not stored yet and its preconditions must still hold when it is
+			instanceObjAfterRawUpdate, err := i.repository.RawRepository.Update(storage.ContextWithKeptUpdatedAt(ctx), instance, types.LabelChanges{})
 			if err != nil {
 				return nil, err
 			}
@@ -348,7 +364,7 @@ func (i *ServiceInstanceInterceptor) AroundTxUpdate(f storage.InterceptUpdateAro
 		if shouldStartPolling(operation) {
 			if err := i.pollServiceInstance(ctx, osbClient, updatedInstance, plan, operation, service.CatalogID, plan.CatalogID, false); err != nil {
 				instance.UpdateValues = types.InstanceUpdateValues{}
-				_, updateErr := i.repository.RawRepository.Update(ctx, instance, types.LabelChanges{})
+				_, updateErr := i.repository.RawRepository.Update(storage.ContextWithKeptUpdatedAt(ctx), instance, types.LabelChanges{})
 				if updateErr != nil {
 					return nil, updateErr
 				}
@@ -446,11 +462,15 @@ func (i *ServiceInstanceInterceptor) deleteSingleInstance(ctx context.Context, i
 		deprovisionRequest := prepareDeprovisionRequest(instance, service.CatalogID, plan.CatalogID)
 
 		log.C(ctx).Infof("Sending deprovision request %s to broker with name %s", logDeprovisionRequest(deprovisionRequest), broker.Name)
+		recordBrokerEvent(ctx, operation, types.BrokerRequestEvent,
+			fmt.Sprintf("deprovision request %s sent to broker with name %s", logDeprovisionRequest(deprovisionRequest), broker.Name))
 		deprovisionResponse, err = osbClient.DeprovisionInstance(deprovisionRequest)
 		if err != nil {
 			if osbc.IsGoneError(err) {
 				log.C(ctx).Infof("Synchronous deprovisioning %s to broker %s returned 410 GONE and is considered success",
 					logDeprovisionRequest(deprovisionRequest), broker.Name)
+				recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+					fmt.Sprintf("deprovision response 410 GONE received from broker with name %s", broker.Name))
 				return nil
 			}
 			brokerError := &util.HTTPError{
@@ -458,6 +478,7 @@ func (i *ServiceInstanceInterceptor) deleteSingleInstance(ctx context.Context, i
 				Description: fmt.Sprintf("Failed deprovisioning request %s: %s", logDeprovisionRequest(deprovisionRequest), err),
 				StatusCode:  http.StatusBadGateway,
 			}
+			recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
 
 			if shouldStartOrphanMitigation(err) {
 				operation.DeletionScheduled = time.Now()
@@ -469,6 +490,8 @@ func (i *ServiceInstanceInterceptor) deleteSingleInstance(ctx context.Context, i
 			}
 			return brokerError
 		}
+		recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+			fmt.Sprintf("deprovision response %s received from broker with name %s", logDeprovisionResponse(deprovisionResponse), broker.Name))
 
 		if deprovisionResponse.Async {
 			log.C(ctx).Infof("Successful asynchronous deprovisioning request %s to broker %s returned response %s",
@@ -549,6 +572,8 @@ func (i *ServiceInstanceInterceptor) pollServiceInstance(ctx context.Context, os
 			if err != nil {
 				if osbc.IsGoneError(err) && operation.Type == types.DELETE {
 					log.C(ctx).Infof("Successfully finished polling operation for instance with id %s and name %s", instance.ID, instance.Name)
+					recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+						fmt.Sprintf("poll last operation response 410 GONE received for request %s", logPollInstanceRequest(pollingRequest)))
 
 					operation.Reschedule = false
 					operation.RescheduleTimestamp = time.Time{}
@@ -560,12 +585,14 @@ func (i *ServiceInstanceInterceptor) pollServiceInstance(ctx context.Context, os
 					log.C(ctx).Errorf("Broker temporarily unreachable. Rescheduling polling last operation request %s to for provisioning of instance with id %s and name %s...",
 						logPollInstanceRequest(pollingRequest), instance.ID, instance.Name)
 				} else {
-					return &util.HTTPError{
+					brokerError := &util.HTTPError{
 						ErrorType: "BrokerError",
 						Description: fmt.Sprintf("Failed poll last operation request %s for instance with id %s and name %s: %s",
 							logPollInstanceRequest(pollingRequest), instance.ID, instance.Name, err),
 						StatusCode: http.StatusBadGateway,
 					}
+					recordBrokerEvent(ctx, operation, types.BrokerErrorEvent, brokerError.Description)
+					return brokerError
 				}
 			} else {
 				switch pollingResponse.State {
@@ -575,6 +602,8 @@ func (i *ServiceInstanceInterceptor) pollServiceInstance(ctx context.Context, os
 
 				case osbc.StateSucceeded:
 					log.C(ctx).Infof("Successfully finished polling operation for instance with id %s and name %s", instance.ID, instance.Name)
+					recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+						fmt.Sprintf("poll last operation response %s received for request %s", logPollInstanceResponse(pollingResponse), logPollInstanceRequest(pollingRequest)))
 
 					operation.Reschedule = false
 					operation.RescheduleTimestamp = time.Time{}
@@ -585,6 +614,8 @@ func (i *ServiceInstanceInterceptor) pollServiceInstance(ctx context.Context, os
 					return nil
 				case osbc.StateFailed:
 					log.C(ctx).Infof("Failed polling operation for instance with id %s and name %s with response %s", instance.ID, instance.Name, logPollInstanceResponse(pollingResponse))
+					recordBrokerEvent(ctx, operation, types.BrokerResponseEvent,
+						fmt.Sprintf("poll last operation response %s received for request %s", logPollInstanceResponse(pollingResponse), logPollInstanceRequest(pollingRequest)))
 					operation.Reschedule = false
 					operation.RescheduleTimestamp = time.Time{}
 					if enableOrphanMitigation {
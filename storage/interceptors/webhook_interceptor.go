@@ -0,0 +1,178 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/webhooks"
+)
+
+const (
+	WebhookCreateInterceptorName = "WebhookCreateInterceptor"
+	WebhookUpdateInterceptorName = "WebhookUpdateInterceptor"
+)
+
+// WebhookCreateInterceptorProvider provides an interceptor which generates the secret of new webhooks and subscribes
+// them to the notifications created after them
+type WebhookCreateInterceptorProvider struct {
+	AllowPrivateNetworks bool
+}
+
+func (*WebhookCreateInterceptorProvider) Name() string {
+	return WebhookCreateInterceptorName
+}
+
+func (p *WebhookCreateInterceptorProvider) Provide() storage.CreateOnTxInterceptor {
+	return &webhookInterceptor{allowPrivateNetworks: p.AllowPrivateNetworks}
+}
+
+// WebhookUpdateInterceptorProvider provides an interceptor which keeps the delivery state of modified webhooks
+type WebhookUpdateInterceptorProvider struct {
+	AllowPrivateNetworks bool
+}
+
+func (*WebhookUpdateInterceptorProvider) Name() string {
+	return WebhookUpdateInterceptorName
+}
+
+func (p *WebhookUpdateInterceptorProvider) Provide() storage.UpdateOnTxInterceptor {
+	return &webhookInterceptor{allowPrivateNetworks: p.AllowPrivateNetworks}
+}
+
+type webhookInterceptor struct {
+	allowPrivateNetworks bool
+}
+
+func (i *webhookInterceptor) OnTxCreate(h storage.InterceptCreateOnTxFunc) storage.InterceptCreateOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, obj types.Object) (types.Object, error) {
+		webhook := obj.(*types.Webhook)
+		if err := validateWebhookFilter(ctx, webhook); err != nil {
+			return nil, err
+		}
+		if err := i.validateWebhookTarget(webhook); err != nil {
+			return nil, err
+		}
+
+		if webhook.Secret == "" {
+			secret, err := generateWebhookSecret()
+			if err != nil {
+				return nil, fmt.Errorf("could not generate secret of webhook: %s", err)
+			}
+			webhook.Secret = secret
+		}
+
+		// the webhook receives the notifications created after it
+		lastRevision, err := lastNotificationRevision(ctx, txStorage)
+		if err != nil {
+			return nil, err
+		}
+		webhook.LastRevision = lastRevision
+		webhook.ConsecutiveFailures = 0
+		webhook.NextAttemptAt = time.Now()
+		webhook.DisabledReason = ""
+
+		return h(ctx, txStorage, webhook)
+	}
+}
+
+func (i *webhookInterceptor) OnTxUpdate(h storage.InterceptUpdateOnTxFunc) storage.InterceptUpdateOnTxFunc {
+	return func(ctx context.Context, txStorage storage.Repository, oldObj, newObj types.Object, labelChanges ...*types.LabelChange) (types.Object, error) {
+		oldWebhook := oldObj.(*types.Webhook)
+		newWebhook := newObj.(*types.Webhook)
+		if err := validateWebhookFilter(ctx, newWebhook); err != nil {
+			return nil, err
+		}
+		if err := i.validateWebhookTarget(newWebhook); err != nil {
+			return nil, err
+		}
+
+		// the delivery state is maintained by the dispatcher only
+		newWebhook.LastRevision = oldWebhook.LastRevision
+		newWebhook.ConsecutiveFailures = oldWebhook.ConsecutiveFailures
+		newWebhook.NextAttemptAt = oldWebhook.NextAttemptAt
+		newWebhook.DisabledReason = oldWebhook.DisabledReason
+		if newWebhook.Secret == "" {
+			newWebhook.Secret = oldWebhook.Secret
+		}
+
+		// enabling a webhook retries the failed delivery right away
+		if oldWebhook.Disabled && !newWebhook.Disabled {
+			newWebhook.ConsecutiveFailures = 0
+			newWebhook.NextAttemptAt = time.Now()
+			newWebhook.DisabledReason = ""
+		}
+
+		return h(ctx, txStorage, oldObj, newWebhook, labelChanges...)
+	}
+}
+
+func validateWebhookFilter(ctx context.Context, webhook *types.Webhook) error {
+	if _, err := webhooks.Filter(ctx, webhook); err != nil {
+		return &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: fmt.Sprintf("invalid label_query of webhook: %s", err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+// validateWebhookTarget rejects the webhooks targeting private addresses, unless they are allowed
+func (i *webhookInterceptor) validateWebhookTarget(webhook *types.Webhook) error {
+	if i.allowPrivateNetworks {
+		return nil
+	}
+	if err := webhooks.CheckTarget(webhook.URL); err != nil {
+		return &util.HTTPError{
+			ErrorType:   "BadRequest",
+			Description: err.Error(),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(secret), nil
+}
+
+func lastNotificationRevision(ctx context.Context, txStorage storage.Repository) (int64, error) {
+	notifications, err := txStorage.ListNoLabels(ctx, types.NotificationType,
+		query.OrderResultBy("revision", query.DescOrder),
+		query.LimitResultBy(1))
+	if err != nil {
+		return 0, fmt.Errorf("could not get last notification revision: %s", err)
+	}
+	if notifications.Len() == 0 {
+		return 0, nil
+	}
+	return notifications.ItemAt(0).(*types.Notification).Revision, nil
+}
@@ -58,15 +58,17 @@ var (
 
 // Settings type to be loaded from the environment
 type Settings struct {
-	URI                string                `mapstructure:"uri" description:"URI of the storage"`
-	MigrationsURL      string                `mapstructure:"migrations_url" description:"location of a directory containing sql migrations scripts"`
-	EncryptionKey      string                `mapstructure:"encryption_key" description:"key to use for encrypting database entries"`
-	SkipSSLValidation  bool                  `mapstructure:"skip_ssl_validation" description:"whether to skip ssl verification when connecting to the storage"`
-	MaxIdleConnections int                   `mapstructure:"max_idle_connections" description:"sets the maximum number of connections in the idle connection pool"`
-	MaxOpenConnections int                   `mapstructure:"max_open_connections" description:"sets the maximum number of open connections to the database"`
-	ReadTimeout        int                   `mapstructure:"read_timeout" description:"sets the limit for reading in milliseconds"`
-	WriteTimeout       int                   `mapstructure:"write_timeout" description:"sets the limit for writing in milliseconds"`
-	Notification       *NotificationSettings `mapstructure:"notification"`
+	URI                string                  `mapstructure:"uri" description:"URI of the storage"`
+	MigrationsURL      string                  `mapstructure:"migrations_url" description:"location of a directory containing sql migrations scripts"`
+	EncryptionKey      string                  `mapstructure:"encryption_key" description:"key to use for encrypting database entries"`
+	SkipSSLValidation  bool                    `mapstructure:"skip_ssl_validation" description:"whether to skip ssl verification when connecting to the storage"`
+	MaxIdleConnections int                     `mapstructure:"max_idle_connections" description:"sets the maximum number of connections in the idle connection pool"`
+	MaxOpenConnections int                     `mapstructure:"max_open_connections" description:"sets the maximum number of open connections to the database"`
+	ReadTimeout        int                     `mapstructure:"read_timeout" description:"sets the limit for reading in milliseconds"`
+	WriteTimeout       int                     `mapstructure:"write_timeout" description:"sets the limit for writing in milliseconds"`
+	Notification       *NotificationSettings   `mapstructure:"notification"`
+	LeaderElection     *LeaderElectionSettings `mapstructure:"leader_election"`
+	ResourceEvents     *ResourceEventSettings  `mapstructure:"resource_events"`
 	IntegrityProcessor security.IntegrityProcessor
 }
 
@@ -82,6 +84,8 @@ func DefaultSettings() *Settings {
 		ReadTimeout:        900000, //15 minutes
 		WriteTimeout:       900000, //15 minutes
 		Notification:       DefaultNotificationSettings(),
+		LeaderElection:     DefaultLeaderElectionSettings(),
+		ResourceEvents:     DefaultResourceEventSettings(),
 		IntegrityProcessor: &security.HashingIntegrityProcessor{
 			HashingFunc: func(data []byte) []byte {
 				hash := sha256.Sum256(data)
@@ -105,9 +109,35 @@ func (s *Settings) Validate() error {
 	if s.IntegrityProcessor == nil {
 		return fmt.Errorf("validate Settings: StorageIntegrityProcessor must not be nil")
 	}
+	if err := s.LeaderElection.Validate(); err != nil {
+		return err
+	}
+	if err := s.ResourceEvents.Validate(); err != nil {
+		return err
+	}
 	return s.Notification.Validate()
 }
 
+// ResourceEventSettings type to be loaded from the environment
+type ResourceEventSettings struct {
+	KeepFor time.Duration `mapstructure:"keep_for" description:"the time to keep a resource event in the storage"`
+}
+
+// DefaultResourceEventSettings returns default values for the resource event settings
+func DefaultResourceEventSettings() *ResourceEventSettings {
+	return &ResourceEventSettings{
+		KeepFor: 7 * 24 * time.Hour,
+	}
+}
+
+// Validate validates the resource event settings
+func (s *ResourceEventSettings) Validate() error {
+	if s.KeepFor <= 0 {
+		return fmt.Errorf("resource event keep for (%s) should be greater than 0", s.KeepFor)
+	}
+	return nil
+}
+
 // NotificationSettings type to be loaded from the environment
 type NotificationSettings struct {
 	QueuesSize           int           `mapstructure:"queues_size" description:"maximum number of notifications queued for sending to a client"`
@@ -195,6 +225,14 @@ type Repository interface {
 	// Count label values of retrieved objects of particular type in SM DB
 	CountLabelValues(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (int, error)
 
+	// CountGroupedBy retrieves number of objects of particular type in SM DB grouped by the values of a field or a label (labels.<key>).
+	// Objects with multiple values of the label are counted in the group of each value
+	CountGroupedBy(ctx context.Context, objectType types.ObjectType, groupBy string, criteria ...query.Criterion) ([]types.AggregationGroup, error)
+
+	// ListDeleted retrieves the objects of particular type deleted after the given time. The criteria are applied
+	// on the state of the objects at the time of their deletion
+	ListDeleted(ctx context.Context, objectType types.ObjectType, since time.Time, criteria ...query.Criterion) ([]types.Deletion, error)
+
 	// Query for list retrieves a list of items using a named query
 	QueryForList(ctx context.Context, objectType types.ObjectType, queryName NamedQuery, queryParams map[string]interface{}) (types.ObjectList, error)
 
@@ -266,8 +304,9 @@ type Notificator interface {
 	// RegisterConsumer returns notification queue, last_known_revision and error if any.
 	// Notifications after lastKnownRevision will be added to the queue.
 	// If lastKnownRevision is -1 no previous notifications will be sent.
+	// If filter is not nil, only the notifications matching it will be added to the queue.
 	// When consumer wants to stop listening for notifications it must unregister the notification queue.
-	RegisterConsumer(consumer *types.Platform, lastKnownRevision int64) (NotificationQueue, int64, error)
+	RegisterConsumer(consumer *types.Platform, lastKnownRevision int64, filter NotificationFilter) (NotificationQueue, int64, error)
 
 	// UnregisterConsumer must be called to stop receiving notifications in the queue
 	UnregisterConsumer(queue NotificationQueue) error
@@ -276,5 +315,47 @@ type Notificator interface {
 	RegisterFilter(f ReceiversFilterFunc)
 }
 
+// CancellationNotifier propagates the cancellation of operations to all Service Manager instances, so that the
+// instance executing the action of a canceled operation can abort it
+type CancellationNotifier interface {
+	// Start starts listening for cancellations and calls onCancel with the id of each canceled operation
+	Start(ctx context.Context, group *sync.WaitGroup, onCancel func(operationID string)) error
+
+	// NotifyCancellation notifies all Service Manager instances that the operation with the given id has been canceled
+	NotifyCancellation(ctx context.Context, operationID string) error
+}
+
+// NotificationAckStore stores the revisions of the notifications acknowledged by the platforms. The acknowledgements
+// are not changes of the platforms, so they do not change their updated_at.
+type NotificationAckStore interface {
+	// AckNotifications stores the revision as the last revision acknowledged by the platform at the given time, unless
+	// a later revision has been acknowledged already
+	AckNotifications(ctx context.Context, platformID string, revision int64, ackedAt time.Time) error
+
+	// ResetNotificationAcks removes the acknowledged revision of the platform
+	ResetNotificationAcks(ctx context.Context, platformID string) error
+}
+
+// RevisionHorizon provides the revisions up to which the records of a type are committed. The revisions are assigned
+// in the order of insertion, so a transaction may still commit a record with a revision lower than the revision of an
+// already committed record. Consumers reading the records in the order of their revisions have to stop at the horizon,
+// otherwise they may skip such records.
+type RevisionHorizon interface {
+	// CommittedRevision returns the revision up to which all the records of the given type are committed
+	CommittedRevision(ctx context.Context, objectType types.ObjectType) (int64, error)
+}
+
+// ColumnMapper maps the objects to the columns in which they are stored. The field names of the objects differ from
+// the columns for some fields, while the query criteria always refer to the columns.
+type ColumnMapper interface {
+	// OrderableColumnValues returns the values of the columns of the object by which the objects of its type can be
+	// ordered and grouped, by column name. Missing values are represented by empty strings, the same way they are
+	// compared in the storage.
+	OrderableColumnValues(obj types.Object) (map[string]string, error)
+}
+
+// NotificationFilter decides if a notification should be added to the queue of a single consumer
+type NotificationFilter func(notification *types.Notification) bool
+
 // ReceiversFilterFunc filters recipients for a given notifications
 type ReceiversFilterFunc func(recipients []*types.Platform, notification *types.Notification) (filteredRecipients []*types.Platform)
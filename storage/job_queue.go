@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/security"
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// Job is the queued storage action of an asynchronous operation
+type Job struct {
+	// OperationID is the id of the operation executed by the job
+	OperationID string
+	// ResourceType is the type of the resource of the operation
+	ResourceType types.ObjectType
+	// Tenant is the tenant which requested the operation, if any
+	Tenant string
+	// Priority orders the jobs of a tenant, jobs with higher priority are leased first
+	Priority int
+	// Payload is the serialized storage action of the job
+	Payload []byte
+	// Attempts is the number of times the job has been leased
+	Attempts int
+	// NotBefore is the time before which the job is not leased, if any
+	NotBefore time.Time
+}
+
+// JobQueue is a durable queue of jobs shared by all Service Manager instances. A leased job is hidden from other
+// workers until its lease expires, so the jobs of crashed workers are leased again once their leases are not renewed.
+// Jobs are leased round robin across tenants, so that the jobs of one tenant do not delay the jobs of all others.
+type JobQueue interface {
+	// EnqueueJob adds the job to the queue. A job which is already queued for the operation is replaced.
+	EnqueueJob(ctx context.Context, job *Job) error
+	// LeaseJobs leases up to limit of the available jobs of the resource type for the given owner. Jobs are available
+	// once they are due and not leased by another owner. No job of a tenant is
+	// leased while the tenant already holds tenantLimit leases of any owner. Jobs without a tenant are not limited. The jobs of the tenants which hold the fewest leases are leased
+	// first, then those with higher priority and then the oldest ones.
+	LeaseJobs(ctx context.Context, owner string, resourceType types.ObjectType, limit, tenantLimit int, leaseDuration time.Duration) ([]*Job, error)
+	// ExtendLease renews the lease of the owner on the job of the operation. It returns util.ErrNotFoundInStorage
+	// if the owner no longer holds the lease.
+	ExtendLease(ctx context.Context, owner, operationID string, leaseDuration time.Duration) error
+	// CompleteJob removes the job of the operation from the queue if it is still leased by the owner
+	CompleteJob(ctx context.Context, owner, operationID string) error
+}
+
+// EncryptingJobQueue creates a JobQueue which encrypts the payloads of the jobs before they are stored, as they may
+// contain credentials, and decrypts them when the jobs are leased
+func EncryptingJobQueue(ctx context.Context, queue JobQueue, encrypter security.Encrypter, keyStore KeyStore, locker Locker) (JobQueue, error) {
+	encryptionKey, err := getEncryptionKey(ctx, encrypter, keyStore, locker)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingJobQueue{
+		JobQueue:      queue,
+		encrypter:     encrypter,
+		encryptionKey: encryptionKey,
+	}, nil
+}
+
+type encryptingJobQueue struct {
+	JobQueue
+	encrypter     security.Encrypter
+	encryptionKey []byte
+}
+
+func (q *encryptingJobQueue) EnqueueJob(ctx context.Context, job *Job) error {
+	payload, err := q.encrypter.Encrypt(ctx, job.Payload, q.encryptionKey)
+	if err != nil {
+		return err
+	}
+	encryptedJob := *job
+	encryptedJob.Payload = payload
+	return q.JobQueue.EnqueueJob(ctx, &encryptedJob)
+}
+
+func (q *encryptingJobQueue) LeaseJobs(ctx context.Context, owner string, resourceType types.ObjectType, limit, tenantLimit int, leaseDuration time.Duration) ([]*Job, error) {
+	jobs, err := q.JobQueue.LeaseJobs(ctx, owner, resourceType, limit, tenantLimit, leaseDuration)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Payload, err = q.encrypter.Decrypt(ctx, job.Payload, q.encryptionKey); err != nil {
+			// the job is still returned so that its operation can be failed instead of leasing the job again
+			log.C(ctx).Errorf("Could not decrypt payload of job of operation with id %s: %s", job.OperationID, err)
+			job.Payload = nil
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/security/securityfakes"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type inMemoryJobQueue struct {
+	jobs []*storage.Job
+}
+
+func (q *inMemoryJobQueue) EnqueueJob(ctx context.Context, job *storage.Job) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+func (q *inMemoryJobQueue) LeaseJobs(ctx context.Context, owner string, resourceType types.ObjectType, limit, tenantLimit int, leaseDuration time.Duration) ([]*storage.Job, error) {
+	jobs := make([]*storage.Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		leased := *job
+		jobs = append(jobs, &leased)
+	}
+	return jobs, nil
+}
+
+func (q *inMemoryJobQueue) ExtendLease(ctx context.Context, owner, operationID string, leaseDuration time.Duration) error {
+	return nil
+}
+
+func (q *inMemoryJobQueue) CompleteJob(ctx context.Context, owner, operationID string) error {
+	return nil
+}
+
+type staticKeyStore struct{}
+
+func (staticKeyStore) GetEncryptionKey(ctx context.Context, transformationFunc func(context.Context, []byte, []byte) ([]byte, error)) ([]byte, error) {
+	return []byte("key"), nil
+}
+
+func (staticKeyStore) SetEncryptionKey(ctx context.Context, key []byte, transformationFunc func(context.Context, []byte, []byte) ([]byte, error)) error {
+	return nil
+}
+
+type noopLocker struct{}
+
+func (noopLocker) Lock(ctx context.Context) error    { return nil }
+func (noopLocker) TryLock(ctx context.Context) error { return nil }
+func (noopLocker) Unlock(ctx context.Context) error  { return nil }
+
+var _ = Describe("Encrypting job queue", func() {
+	var queue *inMemoryJobQueue
+	var encryptingQueue storage.JobQueue
+	var fakeEncrypter *securityfakes.FakeEncrypter
+
+	BeforeEach(func() {
+		fakeEncrypter = &securityfakes.FakeEncrypter{}
+		fakeEncrypter.EncryptCalls(func(ctx context.Context, plainText []byte, key []byte) ([]byte, error) {
+			return append([]byte("encrypt"), plainText...), nil
+		})
+		fakeEncrypter.DecryptCalls(func(ctx context.Context, encryptedText []byte, key []byte) ([]byte, error) {
+			if !strings.HasPrefix(string(encryptedText), "encrypt") {
+				return nil, fmt.Errorf("decryption expects encrypted text")
+			}
+			return []byte(strings.TrimPrefix(string(encryptedText), "encrypt")), nil
+		})
+
+		queue = &inMemoryJobQueue{}
+		var err error
+		encryptingQueue, err = storage.EncryptingJobQueue(context.TODO(), queue, fakeEncrypter, staticKeyStore{}, noopLocker{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("stores encrypted payloads", func() {
+		job := &storage.Job{OperationID: "op1", ResourceType: types.ServiceInstanceType, Payload: []byte("payload")}
+		Expect(encryptingQueue.EnqueueJob(context.TODO(), job)).To(Succeed())
+
+		Expect(queue.jobs).To(HaveLen(1))
+		Expect(string(queue.jobs[0].Payload)).To(Equal("encryptpayload"))
+		Expect(string(job.Payload)).To(Equal("payload"))
+	})
+
+	It("decrypts the payloads of leased jobs", func() {
+		Expect(encryptingQueue.EnqueueJob(context.TODO(), &storage.Job{OperationID: "op1", Payload: []byte("payload")})).To(Succeed())
+
+		jobs, err := encryptingQueue.LeaseJobs(context.TODO(), "owner", types.ServiceInstanceType, 1, 1, time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(string(jobs[0].Payload)).To(Equal("payload"))
+	})
+
+	It("leases jobs without payload if it can not be decrypted", func() {
+		queue.jobs = append(queue.jobs, &storage.Job{OperationID: "op1", Payload: []byte("payload")})
+
+		jobs, err := encryptingQueue.LeaseJobs(context.TODO(), "owner", types.ServiceInstanceType, 1, 1, time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].Payload).To(BeNil())
+	})
+})
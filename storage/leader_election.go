@@ -0,0 +1,312 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/log"
+)
+
+// JobLease is the leadership of a background job together with the outcome of its last run
+type JobLease struct {
+	// Job is the name of the background job
+	Job string
+	// Holder identifies the Service Manager instance which leads the job
+	Holder string
+	// Term is incremented whenever the leadership of the job changes hands
+	Term int64
+	// AcquiredAt is the time when the holder acquired the lease
+	AcquiredAt time.Time
+	// ExpiresAt is the time after which the lease can be acquired by another instance unless the holder renews it
+	ExpiresAt time.Time
+	// LastRunAt is the time when the last run of the job started, if any
+	LastRunAt time.Time
+	// LastRunDuration is the time the last run of the job took
+	LastRunDuration time.Duration
+	// LastError is the error of the last run of the job, if it failed
+	LastError string
+}
+
+// LeaseStore stores the leases of the background jobs shared by all Service Manager instances
+type LeaseStore interface {
+	// AcquireLease acquires the lease of the job for the holder if it is not held by another holder or has expired,
+	// and renews it if the holder already holds it. It returns the term of the lease if the holder holds it and 0 otherwise.
+	AcquireLease(ctx context.Context, job, holder string, leaseDuration time.Duration) (int64, error)
+	// ReleaseLease expires the lease of the job if it is held by the holder
+	ReleaseLease(ctx context.Context, job, holder string) error
+	// RecordJobRun records the last run of the job if its lease is still held by the holder in the given term
+	RecordJobRun(ctx context.Context, job, holder string, term int64, startedAt time.Time, duration time.Duration, runErr error) error
+	// ListLeases returns the leases of all jobs ordered by job name
+	ListLeases(ctx context.Context) ([]*JobLease, error)
+}
+
+// LeaderElectionSettings type to be loaded from the environment
+type LeaderElectionSettings struct {
+	LeaseDuration time.Duration `mapstructure:"lease_duration" description:"the time after which the lease of a background job which is not renewed by its leader can be acquired by another instance"`
+	RenewInterval time.Duration `mapstructure:"renew_interval" description:"the interval in which leaders renew the leases of their background jobs"`
+}
+
+// DefaultLeaderElectionSettings returns default values for the leader election settings
+func DefaultLeaderElectionSettings() *LeaderElectionSettings {
+	return &LeaderElectionSettings{
+		LeaseDuration: 30 * time.Second,
+		RenewInterval: 10 * time.Second,
+	}
+}
+
+// Validate validates the leader election settings
+func (s *LeaderElectionSettings) Validate() error {
+	if s.RenewInterval <= 0 {
+		return fmt.Errorf("leader election renew interval (%s) should be greater than 0", s.RenewInterval)
+	}
+	if s.LeaseDuration <= s.RenewInterval {
+		return fmt.Errorf("leader election lease duration (%s) should be greater than the renew interval (%s)", s.LeaseDuration, s.RenewInterval)
+	}
+	return nil
+}
+
+// LeaderElector elects a single Service Manager instance to run each background job. The leader of a job holds a
+// lease which it renews in the background, so it keeps leading the job between its runs. If the leader crashes or
+// loses its connection to the database, it stops running the job once its lease would have expired and another
+// instance acquires the expired lease on its next attempt to run the job. A run which outlasts the lease of its
+// leader is canceled, so that two instances never run the same job at once.
+type LeaderElector struct {
+	store    LeaseStore
+	settings *LeaderElectionSettings
+	holder   string
+
+	mutex   sync.RWMutex
+	started bool
+	// leases contains the term and the local expiry of the leases of the jobs led by this instance
+	leases map[string]heldLease
+}
+
+type heldLease struct {
+	term      int64
+	expiresAt time.Time
+}
+
+// NewLeaderElector creates a LeaderElector which identifies this instance by its hostname and a generated id
+func NewLeaderElector(store LeaseStore, settings *LeaderElectionSettings) (*LeaderElector, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine leader election holder: %s", err)
+	}
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate GUID for leader election holder: %s", err)
+	}
+
+	return &LeaderElector{
+		store:    store,
+		settings: settings,
+		holder:   fmt.Sprintf("%s-%s", hostname, UUID.String()),
+		leases:   make(map[string]heldLease),
+	}, nil
+}
+
+// Holder returns the identity of this instance in the leases it holds
+func (le *LeaderElector) Holder() string {
+	return le.holder
+}
+
+// Start schedules the renewal of the leases held by this instance. The leases are released when the context is done,
+// so that other instances can take over the jobs without waiting for the leases to expire. It cannot be used concurrently.
+func (le *LeaderElector) Start(ctx context.Context, group *sync.WaitGroup) error {
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+	if le.started {
+		return errors.New("leader elector already started")
+	}
+	le.started = true
+	group.Add(1)
+	go func() {
+		defer func() {
+			le.mutex.Lock()
+			le.started = false
+			le.mutex.Unlock()
+			group.Done()
+		}()
+		ticker := time.NewTicker(le.settings.RenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				le.releaseLeases()
+				return
+			case <-ticker.C:
+				le.renewLeases(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// IsLeader returns whether this instance leads the job
+func (le *LeaderElector) IsLeader(job string) bool {
+	le.mutex.RLock()
+	defer le.mutex.RUnlock()
+	lease, found := le.leases[job]
+	return found && time.Now().Before(lease.expiresAt)
+}
+
+// Run executes the job if this instance acquires or renews its lease, and records the outcome of the run in the
+// lease. The lease is renewed while the job runs and the context passed to the job is canceled once the lease is lost
+// or expires, so the job must use it for its changes. The outcome is recorded only if the lease is still held in the
+// term in which the run started. It returns whether the job was executed.
+func (le *LeaderElector) Run(ctx context.Context, job string, execute func(ctx context.Context) error) bool {
+	// the lease is verified in the storage before each run since the local lease may have been taken over meanwhile
+	term := le.acquireLease(ctx, job)
+	if term == 0 {
+		log.C(ctx).Debugf("Job (%s) is led by another instance", job)
+		return false
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		le.watchLease(runCtx, cancel, job, term)
+	}()
+
+	startedAt := time.Now()
+	err := execute(runCtx)
+	duration := time.Since(startedAt)
+	cancel()
+	<-watched
+	if err != nil {
+		log.C(ctx).Warnf("Run of job (%s) failed after %s: %s", job, duration, err)
+	}
+	if err := le.store.RecordJobRun(ctx, job, le.holder, term, startedAt, duration, err); err != nil {
+		log.C(ctx).Warnf("Could not record run of job (%s): %s", job, err)
+	}
+	return true
+}
+
+// watchLease renews the lease of the running job and cancels the run once the lease is no longer held in the term in
+// which the run started or its local expiry passes without a successful renewal
+func (le *LeaderElector) watchLease(ctx context.Context, cancel context.CancelFunc, job string, term int64) {
+	ticker := time.NewTicker(le.settings.RenewInterval)
+	defer ticker.Stop()
+	for {
+		expiresAt, held := le.leaseExpiry(job, term)
+		if !held {
+			log.C(ctx).Warnf("Lost leadership of job (%s) during its run, canceling the run", job)
+			cancel()
+			return
+		}
+
+		expiry := time.NewTimer(time.Until(expiresAt))
+		select {
+		case <-ctx.Done():
+			expiry.Stop()
+			return
+		case <-ticker.C:
+			le.renewLease(ctx, job)
+		case <-expiry.C:
+		}
+		expiry.Stop()
+	}
+}
+
+func (le *LeaderElector) leaseExpiry(job string, term int64) (time.Time, bool) {
+	le.mutex.RLock()
+	defer le.mutex.RUnlock()
+	lease, found := le.leases[job]
+	return lease.expiresAt, found && lease.term == term && time.Now().Before(lease.expiresAt)
+}
+
+func (le *LeaderElector) acquireLease(ctx context.Context, job string) int64 {
+	// the local expiry is computed from the time before the request so that it never exceeds the expiry in the storage
+	expiresAt := time.Now().Add(le.settings.LeaseDuration)
+	term, err := le.store.AcquireLease(ctx, job, le.holder, le.settings.LeaseDuration)
+	if err != nil {
+		log.C(ctx).Warnf("Could not acquire lease of job (%s): %s", job, err)
+	}
+
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+	if err != nil || term == 0 {
+		if _, found := le.leases[job]; found {
+			log.C(ctx).Infof("Lost leadership of job (%s)", job)
+		}
+		delete(le.leases, job)
+		return 0
+	}
+	if lease, found := le.leases[job]; !found || lease.term != term {
+		log.C(ctx).Infof("Acquired leadership of job (%s) as %s in term %d", job, le.holder, term)
+	}
+	le.leases[job] = heldLease{term: term, expiresAt: expiresAt}
+	return term
+}
+
+func (le *LeaderElector) renewLeases(ctx context.Context) {
+	for _, job := range le.ledJobs() {
+		le.renewLease(ctx, job)
+	}
+}
+
+func (le *LeaderElector) renewLease(ctx context.Context, job string) {
+	// a failed renewal keeps the lease until its local expiry, the job is no longer run after that
+	expiresAt := time.Now().Add(le.settings.LeaseDuration)
+	term, err := le.store.AcquireLease(ctx, job, le.holder, le.settings.LeaseDuration)
+	if err != nil {
+		log.C(ctx).Warnf("Could not renew lease of job (%s): %s", job, err)
+		return
+	}
+
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+	if term != 0 {
+		le.leases[job] = heldLease{term: term, expiresAt: expiresAt}
+	} else {
+		log.C(ctx).Infof("Lost leadership of job (%s)", job)
+		delete(le.leases, job)
+	}
+}
+
+func (le *LeaderElector) releaseLeases() {
+	ctx, cancel := context.WithTimeout(context.Background(), le.settings.RenewInterval)
+	defer cancel()
+	for _, job := range le.ledJobs() {
+		if err := le.store.ReleaseLease(ctx, job, le.holder); err != nil {
+			log.C(ctx).Warnf("Could not release lease of job (%s): %s", job, err)
+		}
+	}
+
+	le.mutex.Lock()
+	defer le.mutex.Unlock()
+	le.leases = make(map[string]heldLease)
+}
+
+func (le *LeaderElector) ledJobs() []string {
+	le.mutex.RLock()
+	defer le.mutex.RUnlock()
+	jobs := make([]string, 0, len(le.leases))
+	for job := range le.leases {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
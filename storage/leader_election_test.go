@@ -0,0 +1,255 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type inMemoryLeaseStore struct {
+	mutex    sync.Mutex
+	leases   map[string]*storage.JobLease
+	failures int
+}
+
+func (s *inMemoryLeaseStore) AcquireLease(ctx context.Context, job, holder string, leaseDuration time.Duration) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return 0, errors.New("connection lost")
+	}
+	lease, found := s.leases[job]
+	if found && lease.Holder != holder && time.Now().Before(lease.ExpiresAt) {
+		return 0, nil
+	}
+	if !found || lease.Holder != holder {
+		lease = &storage.JobLease{Job: job, Holder: holder, AcquiredAt: time.Now()}
+		if found {
+			lease.Term = s.leases[job].Term
+		}
+		lease.Term++
+		s.leases[job] = lease
+	}
+	lease.ExpiresAt = time.Now().Add(leaseDuration)
+	return lease.Term, nil
+}
+
+func (s *inMemoryLeaseStore) ReleaseLease(ctx context.Context, job, holder string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if lease, found := s.leases[job]; found && lease.Holder == holder {
+		lease.ExpiresAt = time.Now()
+	}
+	return nil
+}
+
+func (s *inMemoryLeaseStore) RecordJobRun(ctx context.Context, job, holder string, term int64, startedAt time.Time, duration time.Duration, runErr error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if lease, found := s.leases[job]; found && lease.Holder == holder && lease.Term == term {
+		lease.LastRunAt = startedAt
+		lease.LastRunDuration = duration
+		lease.LastError = ""
+		if runErr != nil {
+			lease.LastError = runErr.Error()
+		}
+	}
+	return nil
+}
+
+func (s *inMemoryLeaseStore) ListLeases(ctx context.Context) ([]*storage.JobLease, error) {
+	return nil, nil
+}
+
+func (s *inMemoryLeaseStore) lease(job string) *storage.JobLease {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	lease := *s.leases[job]
+	return &lease
+}
+
+var _ = Describe("Leader elector", func() {
+	var store *inMemoryLeaseStore
+	var settings *storage.LeaderElectionSettings
+	var first, second *storage.LeaderElector
+
+	BeforeEach(func() {
+		store = &inMemoryLeaseStore{leases: make(map[string]*storage.JobLease)}
+		settings = &storage.LeaderElectionSettings{
+			LeaseDuration: 300 * time.Millisecond,
+			RenewInterval: 100 * time.Millisecond,
+		}
+
+		var err error
+		first, err = storage.NewLeaderElector(store, settings)
+		Expect(err).ToNot(HaveOccurred())
+		second, err = storage.NewLeaderElector(store, settings)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.Holder()).ToNot(Equal(second.Holder()))
+	})
+
+	noop := func(ctx context.Context) error {
+		return nil
+	}
+
+	It("runs the job only on the instance which leads it", func() {
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeTrue())
+		Expect(second.Run(context.TODO(), "job", noop)).To(BeFalse())
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeTrue())
+
+		Expect(first.IsLeader("job")).To(BeTrue())
+		Expect(second.IsLeader("job")).To(BeFalse())
+		Expect(store.lease("job").Holder).To(Equal(first.Holder()))
+	})
+
+	It("records the last run of the job", func() {
+		Expect(first.Run(context.TODO(), "job", func(ctx context.Context) error {
+			return errors.New("run failed")
+		})).To(BeTrue())
+
+		lease := store.lease("job")
+		Expect(lease.LastRunAt).ToNot(BeZero())
+		Expect(lease.LastError).To(Equal("run failed"))
+
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeTrue())
+		Expect(store.lease("job").LastError).To(BeEmpty())
+	})
+
+	It("hands the job over once the lease of the leader expires", func() {
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeTrue())
+
+		Eventually(func() bool {
+			return second.Run(context.TODO(), "job", noop)
+		}, time.Second, 50*time.Millisecond).Should(BeTrue())
+
+		lease := store.lease("job")
+		Expect(lease.Holder).To(Equal(second.Holder()))
+		Expect(lease.Term).To(Equal(int64(2)))
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeFalse())
+	})
+
+	It("verifies the lease before each run", func() {
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeTrue())
+
+		store.mutex.Lock()
+		store.leases["job"].ExpiresAt = time.Now()
+		store.mutex.Unlock()
+		Expect(second.Run(context.TODO(), "job", noop)).To(BeTrue())
+
+		Expect(first.IsLeader("job")).To(BeTrue())
+		Expect(first.Run(context.TODO(), "job", noop)).To(BeFalse())
+		Expect(first.IsLeader("job")).To(BeFalse())
+	})
+
+	It("keeps the lease of a job which runs longer than the lease", func() {
+		Expect(first.Run(context.TODO(), "job", func(ctx context.Context) error {
+			Consistently(func() bool {
+				return second.Run(context.TODO(), "job", noop)
+			}, 2*settings.LeaseDuration, 50*time.Millisecond).Should(BeFalse())
+			return ctx.Err()
+		})).To(BeTrue())
+
+		Expect(store.lease("job").Holder).To(Equal(first.Holder()))
+		Expect(store.lease("job").LastError).To(BeEmpty())
+	})
+
+	It("cancels the run once the lease is lost", func() {
+		Expect(first.Run(context.TODO(), "job", func(ctx context.Context) error {
+			store.mutex.Lock()
+			store.leases["job"].ExpiresAt = time.Now()
+			store.mutex.Unlock()
+			Expect(second.Run(context.TODO(), "job", noop)).To(BeTrue())
+
+			Eventually(ctx.Done(), time.Second).Should(BeClosed())
+			return ctx.Err()
+		})).To(BeTrue())
+
+		lease := store.lease("job")
+		Expect(lease.Holder).To(Equal(second.Holder()))
+		Expect(lease.LastError).To(BeEmpty())
+	})
+
+	It("cancels the run once the lease expires without being renewed", func() {
+		Expect(first.Run(context.TODO(), "job", func(ctx context.Context) error {
+			store.mutex.Lock()
+			store.failures = 100
+			store.mutex.Unlock()
+
+			Eventually(ctx.Done(), time.Second).Should(BeClosed())
+			return ctx.Err()
+		})).To(BeTrue())
+		Expect(first.IsLeader("job")).To(BeFalse())
+	})
+
+	When("started", func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+		var wg *sync.WaitGroup
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			wg = &sync.WaitGroup{}
+			Expect(first.Start(ctx, wg)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			cancel()
+			wg.Wait()
+		})
+
+		It("cannot be started twice", func() {
+			Expect(first.Start(ctx, wg)).To(HaveOccurred())
+		})
+
+		It("keeps leading the job between its runs", func() {
+			Expect(first.Run(ctx, "job", noop)).To(BeTrue())
+
+			Consistently(func() bool {
+				return second.Run(context.TODO(), "job", noop)
+			}, 2*settings.LeaseDuration, 50*time.Millisecond).Should(BeFalse())
+			Expect(first.IsLeader("job")).To(BeTrue())
+		})
+
+		It("stops leading the job once its lease can not be renewed", func() {
+			Expect(first.Run(ctx, "job", noop)).To(BeTrue())
+
+			store.mutex.Lock()
+			store.failures = 100
+			store.mutex.Unlock()
+			Eventually(func() bool {
+				return first.IsLeader("job")
+			}, time.Second, 50*time.Millisecond).Should(BeFalse())
+		})
+
+		It("releases the leases of its jobs when stopped", func() {
+			Expect(first.Run(ctx, "job", noop)).To(BeTrue())
+
+			cancel()
+			wg.Wait()
+			Expect(first.IsLeader("job")).To(BeFalse())
+			Expect(second.Run(context.TODO(), "job", noop)).To(BeTrue())
+		})
+	})
+})
@@ -19,6 +19,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -29,12 +30,25 @@ import (
 	"github.com/Peripli/service-manager/pkg/types"
 )
 
-// NotificationCleaner schedules a go routine which cleans old notifications
+// TombstonePruner deletes the records of the resources deleted before a given time
+type TombstonePruner interface {
+	PruneTombstones(ctx context.Context, before time.Time) error
+}
+
+// NotificationCleanerJob is the name of the notification cleaning in the leases of the leader election
+const NotificationCleanerJob = "cleanupNotifications"
+
+// NotificationCleaner schedules a go routine which cleans old notifications and resource events. The deliveries of the
+// notifications to webhooks and, if tombstones are configured, the records of deleted resources are kept as long as
+// the notifications. If an elector is configured,
+// the notifications are cleaned only by the Service Manager instance which leads the job.
 type NotificationCleaner struct {
 	started bool
 
-	Storage  Repository
-	Settings Settings
+	Storage    Repository
+	Tombstones TombstonePruner
+	Elector    *LeaderElector
+	Settings   Settings
 }
 
 // Start schedules the cleaner. It cannot be used concurrently.
@@ -56,14 +70,24 @@ func (nc *NotificationCleaner) Start(ctx context.Context, group *sync.WaitGroup)
 			case <-ctx.Done():
 				return
 			case <-time.After(cleanInterval):
-				nc.clean(ctx)
+				nc.run(ctx)
 			}
 		}
 	}()
 	return nil
 }
 
-func (nc *NotificationCleaner) clean(ctx context.Context) {
+func (nc *NotificationCleaner) run(ctx context.Context) {
+	if nc.Elector != nil {
+		nc.Elector.Run(ctx, NotificationCleanerJob, nc.clean)
+		return
+	}
+	if err := nc.clean(ctx); err != nil {
+		log.C(ctx).WithError(err).Error("could not clean notifications")
+	}
+}
+
+func (nc *NotificationCleaner) clean(ctx context.Context) error {
 	cleanTimestamp := util.ToRFCNanoFormat(time.Now().Add(-nc.Settings.Notification.KeepFor))
 	log.C(ctx).Infof("Deleting notifications created before %s", cleanTimestamp)
 
@@ -72,10 +96,30 @@ func (nc *NotificationCleaner) clean(ctx context.Context) {
 		if err == util.ErrNotFoundInStorage {
 			log.C(ctx).Debug("no old notifications to delete")
 		} else {
-			log.C(ctx).WithError(err).Error("could not delete old notifications")
+			return fmt.Errorf("could not delete old notifications: %s", err)
 		}
 	} else {
 		log.C(ctx).Infof("successfully deleted notifications created before %v", cleanTimestamp)
 
 	}
+
+	if nc.Tombstones != nil {
+		if err := nc.Tombstones.PruneTombstones(ctx, time.Now().UTC().Add(-nc.Settings.Notification.KeepFor)); err != nil {
+			return fmt.Errorf("could not delete old tombstones: %s", err)
+		}
+	}
+
+	if err := nc.Storage.Delete(ctx, types.WebhookDeliveryType, q); err != nil && err != util.ErrNotFoundInStorage {
+		return fmt.Errorf("could not delete old webhook deliveries: %s", err)
+	}
+
+	if nc.Settings.ResourceEvents != nil {
+		eventsTimestamp := util.ToRFCNanoFormat(time.Now().Add(-nc.Settings.ResourceEvents.KeepFor))
+		q := query.ByField(query.LessThanOperator, "created_at", eventsTimestamp)
+		if err := nc.Storage.Delete(ctx, types.ResourceEventType, q); err != nil && err != util.ErrNotFoundInStorage {
+			return fmt.Errorf("could not delete old resource events: %s", err)
+		}
+	}
+
+	return nil
 }
@@ -79,17 +79,15 @@ var _ = Describe("Notification cleaner", func() {
 		Context("When scheduled", func() {
 			It("Should call storage.DeleteReturning", func() {
 				nc.Settings.Notification.CleanInterval = 0
-				var objType types.ObjectType
-				var criteria []query.Criterion
 				fakeStorage.DeleteStub = func(ctx context.Context, objectType types.ObjectType, criterion ...query.Criterion) error {
-					objType = objectType
-					criteria = criterion
 					cancel() // stop notification cleaner
 					return nil
 				}
 				err := nc.Start(ctx, wg)
 				Expect(err).ToNot(HaveOccurred())
 				wg.Wait()
+				Expect(fakeStorage.DeleteCallCount()).To(BeNumerically(">=", 1))
+				_, objType, criteria := fakeStorage.DeleteArgsForCall(0)
 				Expect(objType).To(Equal(types.NotificationType))
 				Expect(criteria).To(HaveLen(1))
 				Expect(criteria[0].LeftOp).To(Equal("created_at"))
@@ -97,6 +95,45 @@ var _ = Describe("Notification cleaner", func() {
 				timeQueryParameter, err := time.Parse(time.RFC3339, timeString)
 				Expect(timeQueryParameter).To(BeTemporally("<", time.Now()))
 			})
+
+			It("Should delete the webhook deliveries as old as the notifications", func() {
+				nc.Settings.Notification.CleanInterval = 0
+				fakeStorage.DeleteStub = func(ctx context.Context, objectType types.ObjectType, criterion ...query.Criterion) error {
+					if objectType == types.WebhookDeliveryType {
+						cancel() // stop notification cleaner
+					}
+					return nil
+				}
+				err := nc.Start(ctx, wg)
+				Expect(err).ToNot(HaveOccurred())
+				wg.Wait()
+				_, notificationsType, notificationCriteria := fakeStorage.DeleteArgsForCall(0)
+				Expect(notificationsType).To(Equal(types.NotificationType))
+				_, objType, criteria := fakeStorage.DeleteArgsForCall(1)
+				Expect(objType).To(Equal(types.WebhookDeliveryType))
+				Expect(criteria).To(Equal(notificationCriteria))
+			})
+
+			It("Should delete the resource events older than their keep for", func() {
+				nc.Settings.Notification.CleanInterval = 0
+				nc.Settings.ResourceEvents.KeepFor = time.Hour
+				fakeStorage.DeleteStub = func(ctx context.Context, objectType types.ObjectType, criterion ...query.Criterion) error {
+					if objectType == types.ResourceEventType {
+						cancel() // stop notification cleaner
+					}
+					return nil
+				}
+				err := nc.Start(ctx, wg)
+				Expect(err).ToNot(HaveOccurred())
+				wg.Wait()
+				_, objType, criteria := fakeStorage.DeleteArgsForCall(fakeStorage.DeleteCallCount() - 1)
+				Expect(objType).To(Equal(types.ResourceEventType))
+				Expect(criteria).To(HaveLen(1))
+				Expect(criteria[0].LeftOp).To(Equal("created_at"))
+				timeQueryParameter, err := time.Parse(time.RFC3339, criteria[0].RightOp[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(timeQueryParameter).To(BeTemporally("~", time.Now().Add(-time.Hour), time.Minute))
+			})
 		})
 
 		checkCleanerNotStopped := func(storageError error) {
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util/slice"
+)
+
+// notificationPayload holds the labels of the resource in the payload of a notification
+type notificationPayload struct {
+	New *struct {
+		Resource struct {
+			Labels types.Labels `json:"labels"`
+		} `json:"resource"`
+	} `json:"new"`
+	Old *struct {
+		Resource struct {
+			Labels types.Labels `json:"labels"`
+		} `json:"resource"`
+	} `json:"old"`
+}
+
+// NewNotificationFilter returns a filter matching the notifications about one of the resource types, of one of the
+// operation types and about resources with labels matching the label criteria. The resource types may be given either
+// as names or as API paths. It returns nil if neither restricts the notifications.
+func NewNotificationFilter(ctx context.Context, resourceTypes, operationTypes []string, labelCriteria []query.Criterion) NotificationFilter {
+	if len(resourceTypes) == 0 && len(operationTypes) == 0 && len(labelCriteria) == 0 {
+		return nil
+	}
+
+	resourceNames := make([]string, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		resourceNames = append(resourceNames, path.Base(resourceType))
+	}
+
+	return func(notification *types.Notification) bool {
+		if len(resourceNames) != 0 && !slice.StringsAnyEquals(resourceNames, path.Base(string(notification.Resource))) {
+			return false
+		}
+		if len(operationTypes) != 0 && !slice.StringsAnyEquals(operationTypes, string(notification.Type)) {
+			return false
+		}
+		if len(labelCriteria) == 0 {
+			return true
+		}
+
+		payload := &notificationPayload{}
+		if err := json.Unmarshal(notification.Payload, payload); err != nil {
+			log.C(ctx).WithError(err).Errorf("Could not parse the payload of notification %s", notification.ID)
+			return false
+		}
+		// notifications about deleted resources carry only the old resource
+		labels := types.Labels{}
+		if payload.New != nil {
+			labels = payload.New.Resource.Labels
+		} else if payload.Old != nil {
+			labels = payload.Old.Resource.Labels
+		}
+		matches, err := query.MatchesLabels(labels, labelCriteria...)
+		if err != nil {
+			log.C(ctx).WithError(err).Errorf("Could not match the labels of notification %s", notification.ID)
+			return false
+		}
+		return matches
+	}
+}
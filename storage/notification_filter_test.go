@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NotificationFilter", func() {
+	var notification *types.Notification
+
+	BeforeEach(func() {
+		notification = &types.Notification{
+			Resource: types.VisibilityType,
+			Type:     types.CREATED,
+			Payload:  []byte(`{"new":{"resource":{"labels":{"environment":["production"]}}}}`),
+		}
+	})
+
+	labelCriteria := func(labelQuery string) []query.Criterion {
+		criteria, err := query.Parse(query.LabelQuery, labelQuery)
+		Expect(err).ToNot(HaveOccurred())
+		return criteria
+	}
+
+	It("does not restrict the notifications without resource types, operation types and label criteria", func() {
+		Expect(storage.NewNotificationFilter(context.Background(), nil, nil, nil)).To(BeNil())
+	})
+
+	It("matches the resource types by name and by path", func() {
+		Expect(storage.NewNotificationFilter(context.Background(), []string{"visibilities"}, nil, nil)(notification)).To(BeTrue())
+		Expect(storage.NewNotificationFilter(context.Background(), []string{types.VisibilityType.String()}, nil, nil)(notification)).To(BeTrue())
+		Expect(storage.NewNotificationFilter(context.Background(), []string{"platforms"}, nil, nil)(notification)).To(BeFalse())
+	})
+
+	It("matches the operation types", func() {
+		Expect(storage.NewNotificationFilter(context.Background(), nil, []string{"CREATED"}, nil)(notification)).To(BeTrue())
+		Expect(storage.NewNotificationFilter(context.Background(), nil, []string{"DELETED"}, nil)(notification)).To(BeFalse())
+	})
+
+	It("matches the labels of the new resource", func() {
+		Expect(storage.NewNotificationFilter(context.Background(), nil, nil, labelCriteria("environment eq 'production'"))(notification)).To(BeTrue())
+		Expect(storage.NewNotificationFilter(context.Background(), nil, nil, labelCriteria("environment eq 'staging'"))(notification)).To(BeFalse())
+	})
+
+	It("matches the labels of the old resource of deletions", func() {
+		notification.Type = types.DELETED
+		notification.Payload = []byte(`{"old":{"resource":{"labels":{"environment":["production"]}}}}`)
+		Expect(storage.NewNotificationFilter(context.Background(), nil, nil, labelCriteria("environment eq 'production'"))(notification)).To(BeTrue())
+	})
+})
@@ -29,6 +29,12 @@ var _ NotificationQueue = &notificationQueue{}
 
 // NewNotificationQueue returns new NotificationQueue with specific size
 func NewNotificationQueue(size int) (*notificationQueue, error) {
+	return NewFilteredNotificationQueue(size, nil)
+}
+
+// NewFilteredNotificationQueue returns new NotificationQueue with specific size which drops the notifications not
+// matching the filter. If the filter is nil, no notifications are dropped.
+func NewFilteredNotificationQueue(size int, filter NotificationFilter) (*notificationQueue, error) {
 	idBytes, err := uuid.NewV4()
 	if err != nil {
 		return nil, fmt.Errorf("could not generate uuid %v", err)
@@ -39,6 +45,7 @@ func NewNotificationQueue(size int) (*notificationQueue, error) {
 		notificationsChannel: make(chan *types.Notification, size),
 		mutex:                &sync.Mutex{},
 		id:                   idBytes.String(),
+		filter:               filter,
 	}, nil
 }
 
@@ -48,9 +55,11 @@ type notificationQueue struct {
 	notificationsChannel chan *types.Notification
 	mutex                *sync.Mutex
 	id                   string
+	filter               NotificationFilter
 }
 
 // Enqueue adds a new notification for processing. If queue is full ErrQueueFull should be returned.
+// Notifications not matching the filter of the queue are dropped.
 // It should not block or execute heavy operations.
 func (nq *notificationQueue) Enqueue(notification *types.Notification) error {
 	nq.mutex.Lock()
@@ -58,6 +67,9 @@ func (nq *notificationQueue) Enqueue(notification *types.Notification) error {
 	if nq.isClosed {
 		return ErrQueueClosed
 	}
+	if nq.filter != nil && !nq.filter(notification) {
+		return nil
+	}
 	if len(nq.notificationsChannel) >= nq.size {
 		return ErrQueueFull
 	}
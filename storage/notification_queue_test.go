@@ -88,6 +88,21 @@ var _ = Describe("NotificationQueue", func() {
 		})
 	})
 
+	Context("When queue has a filter", func() {
+		It("should drop the notifications not matching it", func() {
+			notificationQueue, err := storage.NewFilteredNotificationQueue(1, func(n *types.Notification) bool {
+				return n.Resource == types.VisibilityType
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(notificationQueue.Enqueue(notification)).To(Succeed())
+			Expect(notificationQueue.Channel()).To(HaveLen(0))
+
+			notification.Resource = types.VisibilityType
+			Expect(notificationQueue.Enqueue(notification)).To(Succeed())
+			Expect(<-notificationQueue.Channel()).To(Equal(notification))
+		})
+	})
+
 	Context("When ID is called", func() {
 		It("should return unique queue ID", func() {
 			notificationQueue1ID := newQueue(1).ID()
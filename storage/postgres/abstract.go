@@ -65,7 +65,7 @@ type pgDB interface {
 }
 
 func create(ctx context.Context, db pgDB, table string, resultDto interface{}, argsDto interface{}) error {
-	setTagType := getDBTags(argsDto, isAutoIncrementable)
+	setTagType := getDBTags(argsDto, isWrittenByDB)
 	dbTags := make([]string, 0, len(setTagType))
 	for _, tagType := range setTagType {
 		dbTags = append(dbTags, tagType.Tag)
@@ -119,6 +119,21 @@ func isAutoIncrementable(tagValue string) bool {
 	return strings.Contains(tagValue, "auto_increment")
 }
 
+func isGenerated(tagValue string) bool {
+	// generated states that the value is derived from the other columns in the DB and is only read
+	for _, option := range strings.Split(tagValue, ",")[1:] {
+		if option == "generated" {
+			return true
+		}
+	}
+	return false
+}
+
+// isWrittenByDB returns true if the column is never written by the storage, as its value is set in the DB
+func isWrittenByDB(tagValue string) bool {
+	return isAutoIncrementable(tagValue) || isGenerated(tagValue)
+}
+
 type tagType struct {
 	Tag  string
 	Type reflect.Type
@@ -167,16 +182,21 @@ func getTags(fields []*structs.Field, set *[]tagType, predicate func(string) boo
 }
 
 func updateQuery(tableName string, structure interface{}) string {
-	dbTags := getDBTags(structure, isAutoIncrementable)
+	set := updateSetSQL(structure)
+	if len(set) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("UPDATE "+tableName+" SET %s WHERE id = :id", set)
+}
+
+// updateSetSQL returns the named assignments of the columns written by the storage
+func updateSetSQL(structure interface{}) string {
+	dbTags := getDBTags(structure, isWrittenByDB)
 	set := make([]string, 0, len(dbTags))
 	for _, dbTag := range dbTags {
 		set = append(set, fmt.Sprintf("%s = :%s", dbTag.Tag, dbTag.Tag))
 	}
-	if len(set) == 0 {
-		return ""
-	}
-	return fmt.Sprintf("UPDATE "+tableName+" SET %s WHERE id = :id",
-		strings.Join(set, ", "))
+	return strings.Join(set, ", ")
 }
 
 func checkUniqueViolation(ctx context.Context, err error) error {
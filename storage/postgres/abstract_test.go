@@ -44,6 +44,17 @@ var _ = Describe("Postgres Storage Abstract", func() {
 			})
 		})
 
+		Context("Called with structure with generated field", func() {
+			It("does not set the generated field", func() {
+				type ts struct {
+					Field     string `db:"generated_at"`
+					Generated string `db:"search_vector,generated"`
+				}
+				query := updateQuery("n/a", ts{Field: "value", Generated: "value"})
+				Expect(query).To(Equal("UPDATE n/a SET generated_at = :generated_at WHERE id = :id"))
+			})
+		})
+
 		Context("Called with structure with empty field", func() {
 			It("allows setting default values for fields", func() {
 				type ts struct {
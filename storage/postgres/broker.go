@@ -39,10 +39,16 @@ type Broker struct {
 	TlsClientKey         string             `db:"tls_client_key"`
 	TlsClientCertificate string             `db:"tls_client_certificate"`
 	Catalog              sqlxtypes.JSONText `db:"catalog"`
+	SearchVector         sql.NullString     `db:"search_vector,generated"`
 
 	Services []*ServiceOffering `db:"-"`
 }
 
+// SecuredColumns returns the columns holding the encrypted credentials and the data protected by the integrity
+func (*Broker) SecuredColumns() []string {
+	return []string{"broker_url", "username", "password", "tls_client_key", "tls_client_certificate", "integrity"}
+}
+
 func (e *Broker) ToObject() (types.Object, error) {
 	var services []*types.ServiceOffering
 	for _, service := range e.Services {
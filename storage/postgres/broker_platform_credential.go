@@ -41,6 +41,11 @@ type BrokerPlatformCredential struct {
 	Active bool `db:"active"`
 }
 
+// SecuredColumns returns the columns holding the encrypted credentials and the data protected by the integrity
+func (*BrokerPlatformCredential) SecuredColumns() []string {
+	return []string{"username", "password_hash", "old_username", "old_password_hash", "platform_id", "broker_id", "integrity"}
+}
+
 func (bpc *BrokerPlatformCredential) ToObject() (types.Object, error) {
 	return &types.BrokerPlatformCredential{
 		Base: types.Base{
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+const cancellationsChannel = "operation_cancellations"
+
+// CancellationNotifier propagates operation cancellations to all Service Manager instances over a postgres
+// notification channel. Cancellations sent while the connection of an instance is being re-established are missed.
+type CancellationNotifier struct {
+	storage           *Storage
+	connectionCreator notificationConnectionCreator
+}
+
+// NewCancellationNotifier returns a CancellationNotifier for the given postgres storage
+func NewCancellationNotifier(st storage.Storage, settings *storage.Settings) (*CancellationNotifier, error) {
+	pgStorage, ok := st.(*Storage)
+	if !ok {
+		return nil, errors.New("expected cancellation notifier storage to be Postgres")
+	}
+	return &CancellationNotifier{
+		storage: pgStorage,
+		connectionCreator: &notificationConnectionCreatorImpl{
+			skipSSLValidation:    settings.SkipSSLValidation,
+			storageURI:           settings.URI,
+			minReconnectInterval: settings.Notification.MinReconnectInterval,
+			maxReconnectInterval: settings.Notification.MaxReconnectInterval,
+		},
+	}, nil
+}
+
+// Start listens for cancellations until the context is done
+func (cn *CancellationNotifier) Start(ctx context.Context, group *sync.WaitGroup, onCancel func(operationID string)) error {
+	connection := cn.connectionCreator.NewConnection(func(isConnected bool, err error) {
+		if isConnected {
+			log.C(ctx).Info("DB connection for operation cancellations established")
+		} else {
+			log.C(ctx).WithError(err).Error("DB connection for operation cancellations closed")
+		}
+	})
+	if err := connection.Listen(cancellationsChannel); err != nil {
+		if closeErr := connection.Close(); closeErr != nil {
+			log.C(ctx).WithError(closeErr).Error("Could not close db connection")
+		}
+		return fmt.Errorf("listen to %s channel failed %v", cancellationsChannel, err)
+	}
+
+	util.StartInWaitGroupWithContext(ctx, func(c context.Context) {
+		defer func() {
+			if err := connection.Close(); err != nil {
+				log.C(c).WithError(err).Error("Could not close db connection")
+			}
+		}()
+		notifications := connection.NotificationChannel()
+		for {
+			select {
+			case <-c.Done():
+				log.C(c).Info("context cancelled, stopping listening for operation cancellations...")
+				return
+			case notification, ok := <-notifications:
+				if !ok {
+					return
+				}
+				// a nil notification is received after the connection is re-established
+				if notification == nil {
+					continue
+				}
+				log.C(c).Debugf("Received cancellation of operation with id %s", notification.Extra)
+				onCancel(notification.Extra)
+			}
+		}
+	}, group)
+	return nil
+}
+
+// NotifyCancellation sends the id of the canceled operation on the postgres notification channel
+func (cn *CancellationNotifier) NotifyCancellation(ctx context.Context, operationID string) error {
+	cn.storage.checkOpen()
+	_, err := cn.storage.pgDB.ExecContext(ctx, "SELECT pg_notify($1, $2)", cancellationsChannel, operationID)
+	return err
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	notificationConnection "github.com/Peripli/service-manager/storage/postgres/notification_connection"
+	notificationConnectionFakes "github.com/Peripli/service-manager/storage/postgres/notification_connection/notification_connectionfakes"
+	"github.com/Peripli/service-manager/storage/postgres/postgresfakes"
+
+	"github.com/lib/pq"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cancellation notifier", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var wg *sync.WaitGroup
+	var notificationChannel chan *pq.Notification
+	var fakeConnection *notificationConnectionFakes.FakeNotificationConnection
+	var notifier *CancellationNotifier
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		wg = &sync.WaitGroup{}
+		notificationChannel = make(chan *pq.Notification, 2)
+		fakeConnection = &notificationConnectionFakes.FakeNotificationConnection{}
+		fakeConnection.NotificationChannelReturns(notificationChannel)
+		fakeConnectionCreator := &postgresfakes.FakeNotificationConnectionCreator{}
+		fakeConnectionCreator.NewConnectionStub = func(f func(isRunning bool, err error)) notificationConnection.NotificationConnection {
+			return fakeConnection
+		}
+		notifier = &CancellationNotifier{connectionCreator: fakeConnectionCreator}
+	})
+
+	AfterEach(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	It("calls back with the ids of the canceled operations", func() {
+		canceled := make(chan string, 1)
+		Expect(notifier.Start(ctx, wg, func(operationID string) {
+			canceled <- operationID
+		})).To(Succeed())
+		Expect(fakeConnection.ListenArgsForCall(0)).To(Equal(cancellationsChannel))
+
+		notificationChannel <- nil
+		notificationChannel <- &pq.Notification{Channel: cancellationsChannel, Extra: "op1"}
+		Eventually(canceled).Should(Receive(Equal("op1")))
+	})
+
+	It("closes the connection once the context is done", func() {
+		Expect(notifier.Start(ctx, wg, func(string) {})).To(Succeed())
+
+		cancel()
+		wg.Wait()
+		Expect(fakeConnection.CloseCallCount()).To(Equal(1))
+	})
+
+	It("fails to start if the channel can not be listened", func() {
+		fakeConnection.ListenReturns(errors.New("listen error"))
+
+		Expect(notifier.Start(ctx, wg, func(string) {})).To(HaveOccurred())
+		Expect(fakeConnection.CloseCallCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// OrderableColumnValues returns the values of the scalar columns of the entity the object is stored as, by column name.
+// Secured columns are not orderable, as their order and the page tokens carrying their values would disclose them.
+// Generated columns are not orderable either, as they are derived from other columns only to be queried.
+func (ps *Storage) OrderableColumnValues(obj types.Object) (map[string]string, error) {
+	entity, err := ps.scheme.convert(obj)
+	if err != nil {
+		return nil, err
+	}
+	secured := make(map[string]bool)
+	for _, column := range securedColumns(entity) {
+		secured[column] = true
+	}
+	values := make(map[string]string)
+	collectOrderableColumnValues(reflect.Indirect(reflect.ValueOf(entity)), secured, values)
+	return values, nil
+}
+
+func collectOrderableColumnValues(value reflect.Value, secured map[string]bool, values map[string]string) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectOrderableColumnValues(value.Field(i), secured, values)
+			continue
+		}
+		tag := field.Tag.Get("db")
+		column := strings.Split(tag, ",")[0]
+		if column == "" || column == "-" || secured[column] || isGenerated(tag) {
+			continue
+		}
+		switch fieldValue := value.Field(i).Interface().(type) {
+		case string:
+			values[column] = fieldValue
+		case sql.NullString:
+			values[column] = fieldValue.String
+		case bool:
+			values[column] = strconv.FormatBool(fieldValue)
+		case int:
+			values[column] = strconv.Itoa(fieldValue)
+		case int64:
+			values[column] = strconv.FormatInt(fieldValue, 10)
+		case time.Time:
+			values[column] = fieldValue.Format(time.RFC3339Nano)
+		}
+	}
+}
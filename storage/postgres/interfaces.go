@@ -33,6 +33,18 @@ type PostgresEntity interface {
 	LabelEntity() PostgresLabel
 }
 
+// JSONQueryableEntity is implemented by entities which allow field queries on the sub-fields of some of their JSON columns.
+// Sub-fields of JSON columns which are not listed can not be queried, as they might contain sensitive data
+type JSONQueryableEntity interface {
+	QueryableJSONColumns() []string
+}
+
+// SecuredEntity is implemented by entities holding encrypted or integrity protected data.
+// The secured columns are selected even if the result is projected to other fields, as the data can not be decrypted and validated without them
+type SecuredEntity interface {
+	SecuredColumns() []string
+}
+
 type PostgresLabel interface {
 	storage.Label
 	LabelsTableName() string
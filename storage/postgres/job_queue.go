@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/lib/pq"
+)
+
+const (
+	enqueueJobQuery = `
+INSERT INTO operation_jobs (operation_id, resource_type, tenant, priority, payload, not_before)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (operation_id) DO UPDATE
+SET tenant = EXCLUDED.tenant, priority = EXCLUDED.priority, payload = EXCLUDED.payload, not_before = EXCLUDED.not_before, enqueued_at = (now() AT TIME ZONE 'UTC'), attempts = 0, lease_owner = NULL, lease_expires_at = NULL`
+
+	// the rank of an available job is its position among the available jobs of its tenant plus the number of jobs of
+	// the tenant which are already leased by any owner, so ordering by rank leases the jobs round robin across tenants
+	// and limits the jobs of a tenant leased by all owners together. Jobs without a tenant are not limited.
+	// The jobs are locked with SKIP LOCKED so that concurrent workers lease different jobs without waiting for each other
+	leaseJobsQuery = `
+UPDATE operation_jobs
+SET lease_owner = $1, lease_expires_at = (now() AT TIME ZONE 'UTC') + $2 * interval '1 millisecond', attempts = attempts + 1
+WHERE operation_id IN (
+	SELECT jobs.operation_id FROM operation_jobs jobs
+	INNER JOIN (
+		SELECT available.operation_id, available.tenant,
+			row_number() OVER (PARTITION BY available.tenant ORDER BY available.priority DESC, available.enqueued_at) + (
+				SELECT count(*) FROM operation_jobs leased
+				WHERE leased.resource_type = $3 AND leased.tenant = available.tenant
+				AND leased.lease_expires_at >= (now() AT TIME ZONE 'UTC')) AS tenant_rank
+		FROM operation_jobs available
+		WHERE available.resource_type = $3 AND (available.lease_expires_at IS NULL OR available.lease_expires_at < (now() AT TIME ZONE 'UTC'))
+		AND (available.not_before IS NULL OR available.not_before <= (now() AT TIME ZONE 'UTC'))
+	) ranked ON ranked.operation_id = jobs.operation_id
+	WHERE ranked.tenant_rank <= $5 OR ranked.tenant = ''
+	ORDER BY ranked.tenant_rank, jobs.priority DESC, jobs.enqueued_at
+	LIMIT $4
+	FOR UPDATE OF jobs SKIP LOCKED)
+RETURNING operation_id, resource_type, tenant, priority, payload, attempts, not_before`
+
+	extendLeaseQuery = `
+UPDATE operation_jobs
+SET lease_expires_at = (now() AT TIME ZONE 'UTC') + $1 * interval '1 millisecond'
+WHERE operation_id = $2 AND lease_owner = $3`
+
+	completeJobQuery = `DELETE FROM operation_jobs WHERE operation_id = $1 AND lease_owner = $2`
+)
+
+type jobRow struct {
+	OperationID  string      `db:"operation_id"`
+	ResourceType string      `db:"resource_type"`
+	Tenant       string      `db:"tenant"`
+	Priority     int         `db:"priority"`
+	Payload      []byte      `db:"payload"`
+	Attempts     int         `db:"attempts"`
+	NotBefore    pq.NullTime `db:"not_before"`
+}
+
+// EnqueueJob adds the job to the operation_jobs table or replaces the job which is already queued for the operation
+func (ps *Storage) EnqueueJob(ctx context.Context, job *storage.Job) error {
+	ps.checkOpen()
+	notBefore := pq.NullTime{Time: job.NotBefore.UTC(), Valid: !job.NotBefore.IsZero()}
+	_, err := ps.pgDB.ExecContext(ctx, enqueueJobQuery, job.OperationID, job.ResourceType.String(), job.Tenant, job.Priority, job.Payload, notBefore)
+	return checkIntegrityViolation(ctx, err)
+}
+
+// LeaseJobs leases the due jobs of the resource type which are not leased or whose lease has expired round robin
+// across their tenants. Jobs of a tenant are not leased while the tenant already holds tenantLimit leases of any owner.
+func (ps *Storage) LeaseJobs(ctx context.Context, owner string, resourceType types.ObjectType, limit, tenantLimit int, leaseDuration time.Duration) ([]*storage.Job, error) {
+	ps.checkOpen()
+	var rows []jobRow
+	if err := ps.pgDB.SelectContext(ctx, &rows, leaseJobsQuery, owner, int64(leaseDuration/time.Millisecond), resourceType.String(), limit, tenantLimit); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*storage.Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, &storage.Job{
+			OperationID:  row.OperationID,
+			ResourceType: types.ObjectType(row.ResourceType),
+			Tenant:       row.Tenant,
+			Priority:     row.Priority,
+			Payload:      row.Payload,
+			Attempts:     row.Attempts,
+			NotBefore:    row.NotBefore.Time,
+		})
+	}
+	return jobs, nil
+}
+
+// ExtendLease renews the lease of the owner on the job of the operation
+func (ps *Storage) ExtendLease(ctx context.Context, owner, operationID string, leaseDuration time.Duration) error {
+	ps.checkOpen()
+	result, err := ps.pgDB.ExecContext(ctx, extendLeaseQuery, int64(leaseDuration/time.Millisecond), operationID, owner)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(ctx, result)
+}
+
+// CompleteJob removes the job of the operation if it is still leased by the owner
+func (ps *Storage) CompleteJob(ctx context.Context, owner, operationID string) error {
+	ps.checkOpen()
+	_, err := ps.pgDB.ExecContext(ctx, completeJobQuery, operationID, owner)
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Job queue", func() {
+	var s *Storage
+	var mockdb *sql.DB
+	var mock sqlmock.Sqlmock
+
+	BeforeEach(func() {
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20210513100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		options := storage.DefaultSettings()
+		options.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+		options.URI = "sqlmock://sqlmock"
+		Expect(s.Open(options)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		s.Close()
+	})
+
+	Describe("EnqueueJob", func() {
+		It("inserts or replaces the job of the operation", func() {
+			mock.ExpectExec(`INSERT INTO operation_jobs .* ON CONFLICT \(operation_id\) DO UPDATE`).
+				WithArgs("op1", "/v1/service_instances", "tenant1", 2, []byte("payload"), nil).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			err := s.EnqueueJob(context.TODO(), &storage.Job{
+				OperationID:  "op1",
+				ResourceType: types.ServiceInstanceType,
+				Tenant:       "tenant1",
+				Priority:     2,
+				Payload:      []byte("payload"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("stores the time before which the job is not leased", func() {
+			notBefore := time.Date(2021, time.March, 27, 22, 0, 0, 0, time.UTC)
+			mock.ExpectExec(`INSERT INTO operation_jobs .* ON CONFLICT \(operation_id\) DO UPDATE`).
+				WithArgs("op1", "/v1/service_instances", "", 0, []byte("payload"), notBefore).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			err := s.EnqueueJob(context.TODO(), &storage.Job{
+				OperationID:  "op1",
+				ResourceType: types.ServiceInstanceType,
+				Payload:      []byte("payload"),
+				NotBefore:    notBefore,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("ExtendLease", func() {
+		Context("when the owner holds the lease", func() {
+			It("renews the lease", func() {
+				mock.ExpectExec(`UPDATE operation_jobs SET lease_expires_at`).
+					WithArgs(int64(30000), "op1", "owner").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				Expect(s.ExtendLease(context.TODO(), "owner", "op1", 30*time.Second)).To(Succeed())
+			})
+		})
+
+		Context("when the lease has been taken over", func() {
+			It("returns not found", func() {
+				mock.ExpectExec(`UPDATE operation_jobs SET lease_expires_at`).
+					WithArgs(int64(30000), "op1", "owner").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := s.ExtendLease(context.TODO(), "owner", "op1", 30*time.Second)
+				Expect(err).To(Equal(util.ErrNotFoundInStorage))
+			})
+		})
+	})
+
+	Describe("CompleteJob", func() {
+		It("deletes the job of the owner", func() {
+			mock.ExpectExec(`DELETE FROM operation_jobs WHERE operation_id = \$1 AND lease_owner = \$2`).
+				WithArgs("op1", "owner").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			Expect(s.CompleteJob(context.TODO(), "owner", "op1")).To(Succeed())
+		})
+	})
+})
@@ -17,6 +17,7 @@
 package postgres
 
 import (
+	"database/sql"
 	sqlxtypes "github.com/jmoiron/sqlx/types"
 	"reflect"
 	"strings"
@@ -35,11 +36,12 @@ func findTagType(tags []tagType, tagName string) reflect.Type {
 }
 
 var (
-	intType       = reflect.TypeOf(int(1))
-	int64Type     = reflect.TypeOf(int64(1))
-	timeType      = reflect.TypeOf(time.Time{})
-	byteSliceType = reflect.TypeOf([]byte{})
-	jsonType      = reflect.TypeOf(sqlxtypes.JSONText{})
+	intType        = reflect.TypeOf(int(1))
+	int64Type      = reflect.TypeOf(int64(1))
+	timeType       = reflect.TypeOf(time.Time{})
+	byteSliceType  = reflect.TypeOf([]byte{})
+	jsonType       = reflect.TypeOf(sqlxtypes.JSONText{})
+	nullStringType = reflect.TypeOf(sql.NullString{})
 )
 
 func determineCastByType(tagType reflect.Type) string {
@@ -61,7 +63,7 @@ func determineCastByType(tagType reflect.Type) string {
 
 func hasMultiVariateOp(criteria []query.Criterion) bool {
 	for _, opt := range criteria {
-		if opt.Operator.Type() == query.MultivariateOperator {
+		if opt.Operator.Type() == query.MultivariateOperator || hasMultiVariateOp(opt.Criteria) {
 			return true
 		}
 	}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Peripli/service-manager/storage"
+	"github.com/lib/pq"
+)
+
+const (
+	// the lease is taken over only if it is held by the same holder or has expired, the term is incremented when
+	// the holder changes so that the runs of different leaders can be told apart
+	acquireLeaseQuery = `
+INSERT INTO leader_leases (job, holder, term, acquired_at, expires_at)
+VALUES ($1, $2, 1, (now() AT TIME ZONE 'UTC'), (now() AT TIME ZONE 'UTC') + $3 * interval '1 millisecond')
+ON CONFLICT (job) DO UPDATE
+SET holder = EXCLUDED.holder,
+	term = CASE WHEN leader_leases.holder = EXCLUDED.holder THEN leader_leases.term ELSE leader_leases.term + 1 END,
+	acquired_at = CASE WHEN leader_leases.holder = EXCLUDED.holder THEN leader_leases.acquired_at ELSE EXCLUDED.acquired_at END,
+	expires_at = EXCLUDED.expires_at
+WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < (now() AT TIME ZONE 'UTC')
+RETURNING term`
+
+	releaseLeaseQuery = `
+UPDATE leader_leases
+SET expires_at = (now() AT TIME ZONE 'UTC')
+WHERE job = $1 AND holder = $2`
+
+	recordJobRunQuery = `
+UPDATE leader_leases
+SET last_run_at = $1, last_run_duration = $2, last_error = $3
+WHERE job = $4 AND holder = $5 AND term = $6`
+
+	listLeasesQuery = `
+SELECT job, holder, term, acquired_at, expires_at, last_run_at, last_run_duration, last_error
+FROM leader_leases
+ORDER BY job`
+)
+
+type leaseRow struct {
+	Job             string         `db:"job"`
+	Holder          string         `db:"holder"`
+	Term            int64          `db:"term"`
+	AcquiredAt      time.Time      `db:"acquired_at"`
+	ExpiresAt       time.Time      `db:"expires_at"`
+	LastRunAt       pq.NullTime    `db:"last_run_at"`
+	LastRunDuration sql.NullInt64  `db:"last_run_duration"`
+	LastError       sql.NullString `db:"last_error"`
+}
+
+// AcquireLease inserts the lease of the job or takes it over if it is held by the holder or has expired, and returns
+// its term. No row is returned if the lease is held by another holder.
+func (ps *Storage) AcquireLease(ctx context.Context, job, holder string, leaseDuration time.Duration) (int64, error) {
+	ps.checkOpen()
+	var term int64
+	if err := ps.pgDB.GetContext(ctx, &term, acquireLeaseQuery, job, holder, toMilliseconds(leaseDuration)); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return term, nil
+}
+
+// ReleaseLease expires the lease of the job if it is held by the holder
+func (ps *Storage) ReleaseLease(ctx context.Context, job, holder string) error {
+	ps.checkOpen()
+	_, err := ps.pgDB.ExecContext(ctx, releaseLeaseQuery, job, holder)
+	return err
+}
+
+// RecordJobRun stores the start, duration and error of the last run of the job in its lease if it is still held by
+// the holder in the term in which the run started
+func (ps *Storage) RecordJobRun(ctx context.Context, job, holder string, term int64, startedAt time.Time, duration time.Duration, runErr error) error {
+	ps.checkOpen()
+	lastError := sql.NullString{}
+	if runErr != nil {
+		lastError = toNullString(runErr.Error())
+	}
+	_, err := ps.pgDB.ExecContext(ctx, recordJobRunQuery, startedAt.UTC(), toMilliseconds(duration), lastError, job, holder, term)
+	return err
+}
+
+// ListLeases returns the leases of all background jobs
+func (ps *Storage) ListLeases(ctx context.Context) ([]*storage.JobLease, error) {
+	ps.checkOpen()
+	var rows []leaseRow
+	if err := ps.pgDB.SelectContext(ctx, &rows, listLeasesQuery); err != nil {
+		return nil, err
+	}
+
+	leases := make([]*storage.JobLease, 0, len(rows))
+	for _, row := range rows {
+		leases = append(leases, &storage.JobLease{
+			Job:             row.Job,
+			Holder:          row.Holder,
+			Term:            row.Term,
+			AcquiredAt:      row.AcquiredAt,
+			ExpiresAt:       row.ExpiresAt,
+			LastRunAt:       row.LastRunAt.Time,
+			LastRunDuration: time.Duration(row.LastRunDuration.Int64) * time.Millisecond,
+			LastError:       row.LastError.String,
+		})
+	}
+	return leases, nil
+}
+
+func toMilliseconds(duration time.Duration) int64 {
+	return int64(duration / time.Millisecond)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Peripli/service-manager/storage"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Leader election", func() {
+	var s *Storage
+	var mockdb *sql.DB
+	var mock sqlmock.Sqlmock
+
+	BeforeEach(func() {
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20210513100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		options := storage.DefaultSettings()
+		options.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+		options.URI = "sqlmock://sqlmock"
+		Expect(s.Open(options)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		s.Close()
+	})
+
+	Describe("AcquireLease", func() {
+		Context("when the lease is free, expired or held by the holder", func() {
+			It("acquires the lease", func() {
+				mock.ExpectQuery(`INSERT INTO leader_leases .* ON CONFLICT \(job\) DO UPDATE .* WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < .* RETURNING term`).
+					WithArgs("job", "holder", int64(30000)).
+					WillReturnRows(sqlmock.NewRows([]string{"term"}).AddRow(2))
+
+				term, err := s.AcquireLease(context.TODO(), "job", "holder", 30*time.Second)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(term).To(Equal(int64(2)))
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
+		})
+
+		Context("when the lease is held by another holder", func() {
+			It("does not acquire the lease", func() {
+				mock.ExpectQuery(`INSERT INTO leader_leases`).
+					WithArgs("job", "holder", int64(30000)).
+					WillReturnRows(sqlmock.NewRows([]string{"term"}))
+
+				term, err := s.AcquireLease(context.TODO(), "job", "holder", 30*time.Second)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(term).To(BeZero())
+			})
+		})
+	})
+
+	Describe("RecordJobRun", func() {
+		It("stores the last run of the job if the holder still leads it in the same term", func() {
+			startedAt := time.Date(2021, time.April, 19, 10, 0, 0, 0, time.UTC)
+			mock.ExpectExec(`UPDATE leader_leases SET last_run_at = \$1, last_run_duration = \$2, last_error = \$3 WHERE job = \$4 AND holder = \$5 AND term = \$6`).
+				WithArgs(startedAt, int64(1500), sql.NullString{String: "run failed", Valid: true}, "job", "holder", int64(2)).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			err := s.RecordJobRun(context.TODO(), "job", "holder", 2, startedAt, 1500*time.Millisecond, errors.New("run failed"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("ListLeases", func() {
+		It("returns the leases of the jobs", func() {
+			acquiredAt := time.Date(2021, time.April, 19, 10, 0, 0, 0, time.UTC)
+			rows := sqlmock.NewRows([]string{"job", "holder", "term", "acquired_at", "expires_at", "last_run_at", "last_run_duration", "last_error"}).
+				AddRow("job", "holder", 2, acquiredAt, acquiredAt.Add(30*time.Second), acquiredAt, 1500, nil).
+				AddRow("other", "holder", 1, acquiredAt, acquiredAt.Add(30*time.Second), nil, nil, nil)
+			mock.ExpectQuery(`SELECT job, holder, term, acquired_at, expires_at, last_run_at, last_run_duration, last_error FROM leader_leases`).
+				WillReturnRows(rows)
+
+			leases, err := s.ListLeases(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(leases).To(Equal([]*storage.JobLease{
+				{
+					Job:             "job",
+					Holder:          "holder",
+					Term:            2,
+					AcquiredAt:      acquiredAt,
+					ExpiresAt:       acquiredAt.Add(30 * time.Second),
+					LastRunAt:       acquiredAt,
+					LastRunDuration: 1500 * time.Millisecond,
+				},
+				{
+					Job:        "other",
+					Holder:     "holder",
+					Term:       1,
+					AcquiredAt: acquiredAt,
+					ExpiresAt:  acquiredAt.Add(30 * time.Second),
+				},
+			}))
+		})
+	})
+})
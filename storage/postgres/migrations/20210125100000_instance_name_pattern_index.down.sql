@@ -0,0 +1 @@
+DROP INDEX IF EXISTS service_instances_lower_name_pattern;
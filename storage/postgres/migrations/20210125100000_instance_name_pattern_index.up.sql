@@ -0,0 +1,2 @@
+-- the startswith operator compares the prefix in lower case
+CREATE INDEX IF NOT EXISTS service_instances_lower_name_pattern ON service_instances (lower(name) text_pattern_ops);
@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS brokers_search_vector ON brokers;
+DROP TRIGGER IF EXISTS service_offerings_search_vector ON service_offerings;
+DROP TRIGGER IF EXISTS service_plans_search_vector ON service_plans;
+DROP TRIGGER IF EXISTS service_instances_search_vector ON service_instances;
+DROP FUNCTION IF EXISTS update_search_vector();
+
+ALTER TABLE brokers DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE service_offerings DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE service_plans DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE service_instances DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
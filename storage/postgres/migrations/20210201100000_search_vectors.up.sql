@@ -0,0 +1,45 @@
+BEGIN;
+
+-- the search vector holds the words of the name with higher weight than the ones of the description, if the table has such column
+CREATE OR REPLACE FUNCTION update_search_vector() RETURNS TRIGGER AS $$
+  DECLARE
+    resource jsonb;
+
+  BEGIN
+    resource = to_jsonb(NEW);
+    NEW.search_vector = setweight(to_tsvector('simple', coalesce(resource->>'name', '')), 'A') ||
+                        setweight(to_tsvector('simple', coalesce(resource->>'description', '')), 'B');
+    RETURN NEW;
+  END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE brokers ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE service_offerings ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE service_plans ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE service_instances ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE TRIGGER brokers_search_vector
+  BEFORE INSERT OR UPDATE ON brokers
+  FOR EACH ROW EXECUTE PROCEDURE update_search_vector();
+CREATE TRIGGER service_offerings_search_vector
+  BEFORE INSERT OR UPDATE ON service_offerings
+  FOR EACH ROW EXECUTE PROCEDURE update_search_vector();
+CREATE TRIGGER service_plans_search_vector
+  BEFORE INSERT OR UPDATE ON service_plans
+  FOR EACH ROW EXECUTE PROCEDURE update_search_vector();
+CREATE TRIGGER service_instances_search_vector
+  BEFORE INSERT OR UPDATE ON service_instances
+  FOR EACH ROW EXECUTE PROCEDURE update_search_vector();
+
+-- the triggers compute the search vectors of the existing resources
+UPDATE brokers SET search_vector = NULL;
+UPDATE service_offerings SET search_vector = NULL;
+UPDATE service_plans SET search_vector = NULL;
+UPDATE service_instances SET search_vector = NULL;
+
+CREATE INDEX IF NOT EXISTS brokers_search_vector ON brokers USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS service_offerings_search_vector ON service_offerings USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS service_plans_search_vector ON service_plans USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS service_instances_search_vector ON service_instances USING GIN (search_vector);
+
+COMMIT;
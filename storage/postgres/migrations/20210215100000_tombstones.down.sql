@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS brokers_tombstone ON brokers;
+DROP TRIGGER IF EXISTS platforms_tombstone ON platforms;
+DROP TRIGGER IF EXISTS service_offerings_tombstone ON service_offerings;
+DROP TRIGGER IF EXISTS service_plans_tombstone ON service_plans;
+DROP TRIGGER IF EXISTS visibilities_tombstone ON visibilities;
+DROP TRIGGER IF EXISTS service_instances_tombstone ON service_instances;
+DROP TRIGGER IF EXISTS service_bindings_tombstone ON service_bindings;
+DROP FUNCTION IF EXISTS record_tombstone();
+
+DROP TABLE IF EXISTS tombstones;
+
+COMMIT;
@@ -0,0 +1,59 @@
+BEGIN;
+
+-- tombstones keep the state of deleted resources so that changes since a point in time can also report deletions
+CREATE TABLE IF NOT EXISTS tombstones (
+  id            bigserial PRIMARY KEY,
+  resource_type varchar(255) NOT NULL,
+  resource_id   varchar(100) NOT NULL,
+  deleted_at    timestamp    NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+  fields        jsonb        NOT NULL,
+  labels        jsonb        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS tombstones_resource_type_deleted_at ON tombstones (resource_type, deleted_at);
+
+-- the trigger arguments are the labels table of the resource and its column referencing the resource.
+-- Credentials and other large or secret columns are not kept.
+CREATE OR REPLACE FUNCTION record_tombstone() RETURNS TRIGGER AS $$
+  DECLARE
+    resource_labels jsonb;
+
+  BEGIN
+    EXECUTE format('SELECT coalesce(jsonb_object_agg(key, vals), ''{}''::jsonb)
+                    FROM (SELECT key, jsonb_agg(val) AS vals FROM %I WHERE %I = $1 GROUP BY key) AS resource_labels',
+                   TG_ARGV[0], TG_ARGV[1])
+      INTO resource_labels
+      USING OLD.id;
+
+    INSERT INTO tombstones (resource_type, resource_id, fields, labels)
+    VALUES (TG_TABLE_NAME, OLD.id,
+            to_jsonb(OLD) - ARRAY['password', 'old_password', 'username', 'old_username', 'credentials', 'integrity',
+                                  'tls_client_key', 'tls_client_certificate', 'catalog', 'search_vector'],
+            resource_labels);
+    RETURN OLD;
+  END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER brokers_tombstone
+  BEFORE DELETE ON brokers
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('broker_labels', 'broker_id');
+CREATE TRIGGER platforms_tombstone
+  BEFORE DELETE ON platforms
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('platform_labels', 'platform_id');
+CREATE TRIGGER service_offerings_tombstone
+  BEFORE DELETE ON service_offerings
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('service_offering_labels', 'service_offering_id');
+CREATE TRIGGER service_plans_tombstone
+  BEFORE DELETE ON service_plans
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('service_plan_labels', 'service_plan_id');
+CREATE TRIGGER visibilities_tombstone
+  BEFORE DELETE ON visibilities
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('visibility_labels', 'visibility_id');
+CREATE TRIGGER service_instances_tombstone
+  BEFORE DELETE ON service_instances
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('service_instance_labels', 'service_instance_id');
+CREATE TRIGGER service_bindings_tombstone
+  BEFORE DELETE ON service_bindings
+  FOR EACH ROW EXECUTE PROCEDURE record_tombstone('service_binding_labels', 'service_binding_id');
+
+COMMIT;
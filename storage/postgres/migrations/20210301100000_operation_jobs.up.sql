@@ -0,0 +1,17 @@
+BEGIN;
+
+-- queued storage actions of asynchronous operations. A job is leased by a single worker at a time and becomes
+-- available again when the lease of the worker expires.
+CREATE TABLE IF NOT EXISTS operation_jobs (
+  operation_id     varchar(100) PRIMARY KEY REFERENCES operations (id) ON DELETE CASCADE,
+  resource_type    varchar(255) NOT NULL,
+  payload          bytea        NOT NULL,
+  enqueued_at      timestamp    NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+  attempts         integer      NOT NULL DEFAULT 0,
+  lease_owner      varchar(255),
+  lease_expires_at timestamp
+);
+
+CREATE INDEX IF NOT EXISTS operation_jobs_resource_type_enqueued_at ON operation_jobs (resource_type, enqueued_at);
+
+COMMIT;
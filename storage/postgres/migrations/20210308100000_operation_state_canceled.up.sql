@@ -0,0 +1 @@
+ALTER TYPE operation_state ADD VALUE IF NOT EXISTS 'canceled';
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE operations DROP COLUMN retries;
+
+COMMIT;
\ No newline at end of file
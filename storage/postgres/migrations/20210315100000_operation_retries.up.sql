@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE operations ADD COLUMN retries integer NOT NULL DEFAULT 0;
+
+COMMIT;
\ No newline at end of file
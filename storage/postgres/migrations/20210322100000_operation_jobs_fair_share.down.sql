@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS operation_jobs_resource_type_tenant;
+
+ALTER TABLE operation_jobs DROP COLUMN IF EXISTS priority;
+ALTER TABLE operation_jobs DROP COLUMN IF EXISTS tenant;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- jobs are leased round robin across the tenants of their operations and by priority within a tenant
+ALTER TABLE operation_jobs ADD COLUMN IF NOT EXISTS tenant varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE operation_jobs ADD COLUMN IF NOT EXISTS priority integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS operation_jobs_resource_type_tenant ON operation_jobs (resource_type, tenant);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE operation_jobs DROP COLUMN IF EXISTS not_before;
+ALTER TABLE operations DROP COLUMN IF EXISTS scheduled_at;
+
+COMMIT;
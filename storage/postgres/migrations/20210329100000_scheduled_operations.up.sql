@@ -0,0 +1,7 @@
+BEGIN;
+
+-- pending operations scheduled for a later time and the jobs executing them are not started before they are due
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+ALTER TABLE operation_jobs ADD COLUMN IF NOT EXISTS not_before timestamp;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS upgrade_campaign_labels;
+DROP TABLE IF EXISTS upgrade_campaigns;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE upgrade_campaigns
+(
+  id              varchar(100) PRIMARY KEY,
+
+  name            varchar(255) NOT NULL,
+  description     text,
+  field_query     text,
+  label_query     text,
+  max_concurrency integer      NOT NULL,
+  state           varchar(100) NOT NULL,
+
+  created_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence BIGSERIAL,
+
+  ready           boolean      NOT NULL
+);
+
+CREATE TABLE upgrade_campaign_labels
+(
+  id                  varchar(100) PRIMARY KEY,
+  key                 varchar(255) NOT NULL CHECK (key <> ''),
+  val                 varchar(255) NOT NULL CHECK (val <> ''),
+  upgrade_campaign_id varchar(100) NOT NULL REFERENCES upgrade_campaigns (id) ON DELETE CASCADE,
+  created_at          timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at          timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, upgrade_campaign_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS upgrade_campaigns_paging_sequence_uindex
+  on upgrade_campaigns (paging_sequence);
+
+COMMIT;
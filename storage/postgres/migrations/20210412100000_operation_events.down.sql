@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS operation_event_labels;
+DROP TABLE IF EXISTS operation_events;
+
+COMMIT;
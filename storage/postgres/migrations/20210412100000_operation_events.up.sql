@@ -0,0 +1,43 @@
+BEGIN;
+
+CREATE TABLE operation_events
+(
+  id              varchar(100) PRIMARY KEY,
+
+  type            varchar(100) NOT NULL,
+  description     text,
+  operation_id    varchar(100) NOT NULL,
+  operation_type  varchar(100) NOT NULL,
+  state           varchar(100) NOT NULL,
+  resource_id     varchar(100) NOT NULL,
+  resource_type   varchar(255) NOT NULL,
+  correlation_id  varchar(255),
+
+  created_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence BIGSERIAL,
+
+  ready           boolean      NOT NULL
+);
+
+CREATE TABLE operation_event_labels
+(
+  id                 varchar(100) PRIMARY KEY,
+  key                varchar(255) NOT NULL CHECK (key <> ''),
+  val                varchar(255) NOT NULL CHECK (val <> ''),
+  operation_event_id varchar(100) NOT NULL REFERENCES operation_events (id) ON DELETE CASCADE,
+  created_at         timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at         timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, operation_event_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS operation_events_paging_sequence_uindex
+  on operation_events (paging_sequence);
+
+CREATE INDEX IF NOT EXISTS operation_events_resource_id_index
+  on operation_events (resource_id);
+
+CREATE INDEX IF NOT EXISTS operation_events_created_at_index
+  on operation_events (created_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS leader_leases;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- leases electing the Service Manager instance which leads each background job. The term is incremented whenever the
+-- leadership of a job changes hands; the last run of the job is recorded by its leader.
+CREATE TABLE IF NOT EXISTS leader_leases (
+  job               varchar(255) PRIMARY KEY,
+  holder            varchar(255) NOT NULL,
+  term              bigint       NOT NULL DEFAULT 1,
+  acquired_at       timestamp    NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+  expires_at        timestamp    NOT NULL,
+  last_run_at       timestamp,
+  last_run_duration bigint,
+  last_error        text
+);
+
+COMMIT;
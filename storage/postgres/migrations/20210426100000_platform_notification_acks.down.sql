@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE platforms DROP COLUMN IF EXISTS last_acked_at;
+ALTER TABLE platforms DROP COLUMN IF EXISTS last_acked_revision;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- the revision of the last notification acknowledged by each platform; notifications after it are redelivered when the
+-- platform reconnects
+ALTER TABLE platforms ADD COLUMN IF NOT EXISTS last_acked_revision bigint NOT NULL DEFAULT 0;
+ALTER TABLE platforms ADD COLUMN IF NOT EXISTS last_acked_at timestamp NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+
+COMMIT;
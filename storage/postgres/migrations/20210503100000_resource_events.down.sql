@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS resource_events_revision ON resource_events;
+DROP FUNCTION IF EXISTS assign_resource_event_revision();
+DROP SEQUENCE IF EXISTS resource_events_revision_seq;
+DROP TABLE IF EXISTS resource_event_labels;
+DROP TABLE IF EXISTS resource_events;
+
+COMMIT;
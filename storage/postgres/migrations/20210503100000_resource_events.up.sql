@@ -0,0 +1,60 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS resource_events
+(
+  id              varchar(100) PRIMARY KEY,
+
+  -- assigned when the transaction recording the event commits
+  revision        bigint       NOT NULL DEFAULT 0,
+  type            varchar(20)  NOT NULL,
+  resource_type   varchar(255) NOT NULL,
+  resource_id     varchar(100) NOT NULL,
+  resource        jsonb        NOT NULL,
+  correlation_id  varchar(255),
+
+  created_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence BIGSERIAL,
+
+  ready           boolean      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS resource_event_labels
+(
+  id                varchar(100) PRIMARY KEY,
+  key               varchar(255) NOT NULL CHECK (key <> ''),
+  val               varchar(255) NOT NULL CHECK (val <> ''),
+  resource_event_id varchar(100) NOT NULL REFERENCES resource_events (id) ON DELETE CASCADE,
+  created_at        timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at        timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, resource_event_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS resource_events_paging_sequence_uindex
+  on resource_events (paging_sequence);
+
+CREATE INDEX IF NOT EXISTS resource_events_revision_index
+  on resource_events (revision);
+
+CREATE INDEX IF NOT EXISTS resource_events_created_at_index
+  on resource_events (created_at);
+
+CREATE SEQUENCE IF NOT EXISTS resource_events_revision_seq;
+
+-- The revisions are assigned at commit time while holding a transaction lock, so the transactions recording events
+-- commit one at a time in the order of their revisions. A consumer which has read the events up to a revision
+-- therefore never misses an event committed later with a lower revision.
+CREATE OR REPLACE FUNCTION assign_resource_event_revision() RETURNS TRIGGER AS $$
+  BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('resource_events'), 0);
+    UPDATE resource_events SET revision = nextval('resource_events_revision_seq') WHERE id = NEW.id;
+    RETURN NULL;
+  END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER resource_events_revision
+  AFTER INSERT ON resource_events
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE PROCEDURE assign_resource_event_revision();
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS webhook_delivery_labels;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_labels;
+DROP TABLE IF EXISTS webhooks;
+
+COMMIT;
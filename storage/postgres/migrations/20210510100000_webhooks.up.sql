@@ -0,0 +1,80 @@
+BEGIN;
+
+CREATE TABLE webhooks
+(
+  id                   varchar(100)  PRIMARY KEY,
+  name                 varchar(255)  NOT NULL,
+  url                  varchar(2048) NOT NULL,
+  secret               text          NOT NULL,
+  resource_types       json          NOT NULL DEFAULT '[]',
+  operation_types      json          NOT NULL DEFAULT '[]',
+  label_query          text,
+
+  disabled             boolean       NOT NULL DEFAULT false,
+  disabled_reason      text,
+
+  -- the revision of the last notification delivered to the webhook or skipped as not matching
+  last_revision        bigint        NOT NULL DEFAULT 0,
+  consecutive_failures integer       NOT NULL DEFAULT 0,
+  next_attempt_at      timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  created_at           timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at           timestamptz   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence      BIGSERIAL,
+
+  ready                boolean       NOT NULL
+);
+
+CREATE TABLE webhook_labels
+(
+  id         varchar(100) PRIMARY KEY,
+  key        varchar(255) NOT NULL CHECK (key <> ''),
+  val        varchar(255) NOT NULL CHECK (val <> ''),
+  webhook_id varchar(100) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  created_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, webhook_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhooks_paging_sequence_uindex
+  on webhooks (paging_sequence);
+
+CREATE TABLE webhook_deliveries
+(
+  id              varchar(100) PRIMARY KEY,
+  webhook_id      varchar(100) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  notification_id varchar(100) NOT NULL,
+  revision        bigint       NOT NULL,
+  attempt         integer      NOT NULL,
+  succeeded       boolean      NOT NULL,
+  status_code     integer      NOT NULL DEFAULT 0,
+  error           text,
+
+  created_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at      timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  paging_sequence BIGSERIAL,
+
+  ready           boolean      NOT NULL
+);
+
+CREATE TABLE webhook_delivery_labels
+(
+  id                  varchar(100) PRIMARY KEY,
+  key                 varchar(255) NOT NULL CHECK (key <> ''),
+  val                 varchar(255) NOT NULL CHECK (val <> ''),
+  webhook_delivery_id varchar(100) NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+  created_at          timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at          timestamptz  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (key, val, webhook_delivery_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_paging_sequence_uindex
+  on webhook_deliveries (paging_sequence);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_index
+  on webhook_deliveries (webhook_id);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_created_at_index
+  on webhook_deliveries (created_at);
+
+COMMIT;
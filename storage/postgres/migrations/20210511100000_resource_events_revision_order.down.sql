@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS resource_events_revision ON resource_events;
+
+CREATE OR REPLACE FUNCTION assign_resource_event_revision() RETURNS TRIGGER AS $$
+  BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('resource_events'), 0);
+    UPDATE resource_events SET revision = nextval('resource_events_revision_seq') WHERE id = NEW.id;
+    RETURN NULL;
+  END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER resource_events_revision
+  AFTER INSERT ON resource_events
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE PROCEDURE assign_resource_event_revision();
+
+COMMIT;
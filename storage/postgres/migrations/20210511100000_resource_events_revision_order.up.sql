@@ -0,0 +1,22 @@
+BEGIN;
+
+-- The revisions were assigned at commit time while holding a transaction lock, which serialized the commits of all
+-- transactions recording events. They are now assigned in the order of insertion, so a transaction may commit an event
+-- with a lower revision than an already committed event. The transaction id is assigned before the revision is drawn,
+-- which lets the readers find the revision up to which all events are committed from the transaction snapshot and
+-- read only up to it.
+DROP TRIGGER IF EXISTS resource_events_revision ON resource_events;
+
+CREATE OR REPLACE FUNCTION assign_resource_event_revision() RETURNS TRIGGER AS $$
+  BEGIN
+    PERFORM txid_current();
+    NEW.revision = nextval('resource_events_revision_seq');
+    RETURN NEW;
+  END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER resource_events_revision
+  BEFORE INSERT ON resource_events
+  FOR EACH ROW EXECUTE PROCEDURE assign_resource_event_revision();
+
+COMMIT;
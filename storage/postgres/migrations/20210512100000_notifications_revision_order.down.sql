@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS notifications_revision ON notifications;
+DROP FUNCTION IF EXISTS assign_notification_revision();
+ALTER TABLE notifications ALTER COLUMN revision SET DEFAULT nextval('notifications_revision_seq');
+
+COMMIT;
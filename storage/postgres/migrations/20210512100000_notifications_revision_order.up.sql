@@ -0,0 +1,20 @@
+BEGIN;
+
+-- The transaction id is assigned before the revision of a notification is drawn, so that the readers following the
+-- notifications in the order of their revisions can find the revision up to which all notifications are committed.
+ALTER TABLE notifications ALTER COLUMN revision SET DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION assign_notification_revision() RETURNS TRIGGER AS $$
+  BEGIN
+    PERFORM txid_current();
+    NEW.revision = nextval('notifications_revision_seq');
+    RETURN NEW;
+  END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS notifications_revision ON notifications;
+CREATE TRIGGER notifications_revision
+  BEFORE INSERT ON notifications
+  FOR EACH ROW EXECUTE PROCEDURE assign_notification_revision();
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE operations DROP COLUMN retry_payload;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE operations ADD COLUMN retry_payload json;
+
+COMMIT;
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// only the acknowledgement columns are updated, so that the updated_at of the platform is kept
+	ackNotificationsQuery = `
+UPDATE platforms
+SET last_acked_revision = $1, last_acked_at = $2
+WHERE id = $3 AND last_acked_revision < $1`
+
+	resetNotificationAcksQuery = `
+UPDATE platforms
+SET last_acked_revision = 0, last_acked_at = $1
+WHERE id = $2`
+)
+
+// AckNotifications stores the revision as the last revision acknowledged by the platform if it is later than the
+// stored one
+func (ps *Storage) AckNotifications(ctx context.Context, platformID string, revision int64, ackedAt time.Time) error {
+	ps.checkOpen()
+	_, err := ps.pgDB.ExecContext(ctx, ackNotificationsQuery, revision, ackedAt.UTC(), platformID)
+	return err
+}
+
+// ResetNotificationAcks removes the acknowledged revision of the platform
+func (ps *Storage) ResetNotificationAcks(ctx context.Context, platformID string) error {
+	ps.checkOpen()
+	_, err := ps.pgDB.ExecContext(ctx, resetNotificationAcksQuery, time.Time{}, platformID)
+	return err
+}
@@ -150,11 +150,11 @@ func (n *Notificator) addConsumer(platform *types.Platform, queue storage.Notifi
 	return atomic.LoadInt64(&n.lastKnownRevision), nil
 }
 
-func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevision int64) (storage.NotificationQueue, int64, error) {
+func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevision int64, filter storage.NotificationFilter) (storage.NotificationQueue, int64, error) {
 	if atomic.LoadInt32(&n.isConnected) == aFalse {
 		return nil, types.InvalidRevision, errors.New("cannot register consumer - Notificator is not running")
 	}
-	queue, err := storage.NewNotificationQueue(n.queueSize)
+	queue, err := storage.NewFilteredNotificationQueue(n.queueSize, filter)
 	if err != nil {
 		return nil, types.InvalidRevision, err
 	}
@@ -180,7 +180,7 @@ func (n *Notificator) RegisterConsumer(consumer *types.Platform, lastKnownRevisi
 		return nil, types.InvalidRevision, err
 	}
 	var queueWithMissedNotifications storage.NotificationQueue
-	queueWithMissedNotifications, err = n.replaceQueueWithMissingNotificationsQueue(queue, lastKnownRevision, lastKnownRevisionToSM, consumer)
+	queueWithMissedNotifications, err = n.replaceQueueWithMissingNotificationsQueue(queue, lastKnownRevision, lastKnownRevisionToSM, consumer, filter)
 	if err != nil {
 		return nil, types.InvalidRevision, err
 	}
@@ -197,7 +197,7 @@ func (n *Notificator) filterRecipients(recipients []*types.Platform, notificatio
 	return recipients
 }
 
-func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.NotificationQueue, lastKnownRevision, lastKnownRevisionToSM int64, platform *types.Platform) (storage.NotificationQueue, error) {
+func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.NotificationQueue, lastKnownRevision, lastKnownRevisionToSM int64, platform *types.Platform, filter storage.NotificationFilter) (storage.NotificationQueue, error) {
 	if _, err := n.storage.GetNotificationByRevision(n.ctx, lastKnownRevision); err != nil {
 		if err == util.ErrNotFoundInStorage {
 			log.C(n.ctx).WithError(err).Debugf("Notification with revision %d not found in storage", lastKnownRevision)
@@ -213,7 +213,7 @@ func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.No
 	filteredMissedNotification := make([]*types.Notification, 0, len(missedNotifications))
 	for _, notification := range missedNotifications {
 		recipients := n.filterRecipients([]*types.Platform{platform}, notification)
-		if len(recipients) != 0 {
+		if len(recipients) != 0 && (filter == nil || filter(notification)) {
 			filteredMissedNotification = append(filteredMissedNotification, notification)
 		}
 	}
@@ -223,7 +223,7 @@ func (n *Notificator) replaceQueueWithMissingNotificationsQueue(queue storage.No
 		return nil, util.ErrInvalidNotificationRevision
 	}
 
-	queueWithMissedNotifications, err := storage.NewNotificationQueue(n.queueSize)
+	queueWithMissedNotifications, err := storage.NewFilteredNotificationQueue(n.queueSize, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +65,7 @@ var _ = Describe("Notificator", func() {
 	expectedError := errors.New("*Expected*")
 
 	expectRegisterConsumerFail := func(errorMessage string, revision int64) {
-		q, smRevision, err := testNotificator.RegisterConsumer(defaultPlatform, revision)
+		q, smRevision, err := testNotificator.RegisterConsumer(defaultPlatform, revision, nil)
 		Expect(q).To(BeNil())
 		Expect(smRevision).To(Equal(types.InvalidRevision))
 		Expect(err).To(HaveOccurred())
@@ -73,7 +73,7 @@ var _ = Describe("Notificator", func() {
 	}
 
 	expectRegisterConsumerSuccess := func(platform *types.Platform, revision int64) storage.NotificationQueue {
-		q, smRevision, err := testNotificator.RegisterConsumer(platform, revision)
+		q, smRevision, err := testNotificator.RegisterConsumer(platform, revision, nil)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(smRevision).To(Equal(defaultLastRevision))
 		Expect(q).ToNot(BeNil())
@@ -438,6 +438,30 @@ var _ = Describe("Notificator", func() {
 			})
 		})
 
+		Context("When registering with a filter", func() {
+			It("Should add only the matching missed and new notifications to the queue", func() {
+				n1 := createNotification("")
+				n2 := createNotification("")
+				n2.Resource = types.VisibilityType
+				n3 := createNotification("")
+				n3.Resource = types.VisibilityType
+				fakeNotificationStorage.GetNotificationByRevisionReturns(n1, nil)
+				fakeNotificationStorage.ListNotificationsReturns([]*types.Notification{n1, n2}, nil)
+				fakeNotificationStorage.GetNotificationReturns(n3, nil)
+				q, _, err := testNotificator.RegisterConsumer(defaultPlatform, defaultLastRevision-1, func(n *types.Notification) bool {
+					return n.Resource == types.VisibilityType
+				})
+				Expect(err).ToNot(HaveOccurred())
+				queueChannel := q.Channel()
+				Expect(queueChannel).To(HaveLen(1))
+				Expect(<-queueChannel).To(Equal(n2))
+				notificationChannel <- &pq.Notification{
+					Extra: createNotificationPayload("", n3.ID),
+				}
+				Expect(<-queueChannel).To(Equal(n3))
+			})
+		})
+
 		Context("When Notificator stops", func() {
 			It("Should return error", func() {
 				registerDefaultPlatform()
@@ -48,6 +48,9 @@ type Operation struct {
 	ParentID            sql.NullString     `db:"parent_id"`
 	RescheduleTimestamp time.Time          `db:"reschedule_timestamp"`
 	DeletionScheduled   time.Time          `db:"deletion_scheduled"`
+	Retries             int                `db:"retries"`
+	ScheduledAt         time.Time          `db:"scheduled_at"`
+	RetryPayload        sqlxtypes.JSONText `db:"retry_payload"`
 	Context             sqlxtypes.JSONText `db:"context"`
 }
 
@@ -90,6 +93,9 @@ func (o *Operation) ToObject() (types.Object, error) {
 		ParentID:            o.ParentID.String,
 		RescheduleTimestamp: o.RescheduleTimestamp,
 		DeletionScheduled:   o.DeletionScheduled,
+		Retries:             o.Retries,
+		ScheduledAt:         o.ScheduledAt,
+		RetryPayload:        getJSONRawMessage(o.RetryPayload),
 	}, nil
 }
 
@@ -137,6 +143,9 @@ func (*Operation) FromObject(object types.Object) (storage.Entity, error) {
 		ParentID:            toNullString(operation.ParentID),
 		RescheduleTimestamp: operation.RescheduleTimestamp,
 		DeletionScheduled:   operation.DeletionScheduled,
+		Retries:             operation.Retries,
+		ScheduledAt:         operation.ScheduledAt,
+		RetryPayload:        getJSONText(operation.RetryPayload),
 	}
 	return o, nil
 }
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Peripli/service-manager/storage"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// OperationEvent entity
+//go:generate smgen storage OperationEvent github.com/Peripli/service-manager/pkg/types
+type OperationEvent struct {
+	BaseEntity
+
+	Type          string         `db:"type"`
+	Description   sql.NullString `db:"description"`
+	OperationID   string         `db:"operation_id"`
+	OperationType string         `db:"operation_type"`
+	State         string         `db:"state"`
+	ResourceID    string         `db:"resource_id"`
+	ResourceType  string         `db:"resource_type"`
+	CorrelationID sql.NullString `db:"correlation_id"`
+}
+
+func (e *OperationEvent) ToObject() (types.Object, error) {
+	return &types.OperationEvent{
+		Base: types.Base{
+			ID:             e.ID,
+			CreatedAt:      e.CreatedAt,
+			UpdatedAt:      e.UpdatedAt,
+			Labels:         map[string][]string{},
+			PagingSequence: e.PagingSequence,
+			Ready:          e.Ready,
+		},
+		Type:          types.OperationEventKind(e.Type),
+		Description:   e.Description.String,
+		OperationID:   e.OperationID,
+		OperationType: types.OperationCategory(e.OperationType),
+		State:         types.OperationState(e.State),
+		ResourceID:    e.ResourceID,
+		ResourceType:  types.ObjectType(e.ResourceType),
+		CorrelationID: e.CorrelationID.String,
+	}, nil
+}
+
+func (*OperationEvent) FromObject(object types.Object) (storage.Entity, error) {
+	event, ok := object.(*types.OperationEvent)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type OperationEvent")
+	}
+
+	return &OperationEvent{
+		BaseEntity: BaseEntity{
+			ID:             event.ID,
+			CreatedAt:      event.CreatedAt,
+			UpdatedAt:      event.UpdatedAt,
+			PagingSequence: event.PagingSequence,
+			Ready:          event.Ready,
+		},
+		Type:          string(event.Type),
+		Description:   toNullString(event.Description),
+		OperationID:   event.OperationID,
+		OperationType: string(event.OperationType),
+		State:         string(event.State),
+		ResourceID:    event.ResourceID,
+		ResourceType:  string(event.ResourceType),
+		CorrelationID: toNullString(event.CorrelationID),
+	}, nil
+}
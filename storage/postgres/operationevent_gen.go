@@ -0,0 +1,72 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"database/sql"
+	"time"
+)
+
+var _ PostgresEntity = &OperationEvent{}
+
+const OperationEventTable = "operation_events"
+
+func (*OperationEvent) LabelEntity() PostgresLabel {
+	return &OperationEventLabel{}
+}
+
+func (*OperationEvent) TableName() string {
+	return OperationEventTable
+}
+
+func (e *OperationEvent) NewLabel(id, entityID, key, value string) storage.Label {
+	now := pq.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	return &OperationEventLabel{
+		BaseLabelEntity: BaseLabelEntity{
+			ID:        sql.NullString{String: id, Valid: id != ""},
+			Key:       sql.NullString{String: key, Valid: key != ""},
+			Val:       sql.NullString{String: value, Valid: value != ""},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		OperationEventID: sql.NullString{String: entityID, Valid: entityID != ""},
+	}
+}
+
+func (e *OperationEvent) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() EntityLabelRow {
+		return &struct {
+			*OperationEvent
+			OperationEventLabel `db:"operation_event_labels"`
+		}{}
+	}
+	result := &types.OperationEvents{
+		OperationEvents: make([]*types.OperationEvent, 0),
+	}
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type OperationEventLabel struct {
+	BaseLabelEntity
+	OperationEventID sql.NullString `db:"operation_event_id"`
+}
+
+func (el OperationEventLabel) LabelsTableName() string {
+	return "operation_event_labels"
+}
+
+func (el OperationEventLabel) ReferenceColumn() string {
+	return "operation_event_id"
+}
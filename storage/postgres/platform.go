@@ -43,6 +43,13 @@ type Platform struct {
 	LastActive        time.Time      `db:"last_active"`
 	Technical         bool           `db:"technical"`
 	Version           sql.NullString `db:"version"`
+	LastAckedRevision int64          `db:"last_acked_revision"`
+	LastAckedAt       time.Time      `db:"last_acked_at"`
+}
+
+// SecuredColumns returns the columns holding the encrypted credentials and the data protected by the integrity
+func (*Platform) SecuredColumns() []string {
+	return []string{"username", "password", "old_username", "old_password", "technical", "integrity"}
 }
 
 func (p *Platform) FromObject(object types.Object) (storage.Entity, error) {
@@ -66,6 +73,8 @@ func (p *Platform) FromObject(object types.Object) (storage.Entity, error) {
 		Technical:         platform.Technical,
 		Version:           toNullString(platform.Version),
 		LastActive:        platform.LastActive,
+		LastAckedRevision: platform.LastAckedRevision,
+		LastAckedAt:       platform.LastAckedAt,
 	}
 
 	if platform.Description != "" {
@@ -106,6 +115,8 @@ func (p *Platform) ToObject() (types.Object, error) {
 		Technical:         p.Technical,
 		Integrity:         p.Integrity,
 		Version:           p.Version.String,
+		LastAckedRevision: p.LastAckedRevision,
+		LastAckedAt:       p.LastAckedAt,
 	}
 	if len(p.Username) > 0 || len(p.Password) > 0 {
 		platform.Credentials = &types.Credentials{
@@ -7,15 +7,23 @@ import (
 	"github.com/Peripli/service-manager/pkg/log"
 	"github.com/Peripli/service-manager/storage"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
 	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/jmoiron/sqlx"
 )
 
 const PrimaryKeyColumn = "id"
 
+// labelKeyParamPrefix prefixes the placeholders of the label keys bound as parameters of a query
+const labelKeyParamPrefix = ":label_key_"
+
+var bindVariablesRegex = regexp.MustCompile(`\?|` + labelKeyParamPrefix + `[0-9]+`)
+
 const CountQueryTemplate = `
 SELECT COUNT(DISTINCT {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}})
 FROM {{.ENTITY_TABLE}}
@@ -36,20 +44,58 @@ FROM {{.ENTITY_TABLE}}
 {{.FOR_UPDATE_OF}}
 {{.LIMIT}};`
 
+// CountGroupedByQueryTemplate counts the distinct matching resources per value of a column or of a label.
+// Resources without value for the column or label are counted in a group with NULL value.
+const CountGroupedByQueryTemplate = `
+WITH matching_resources AS (SELECT DISTINCT {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}}{{if .GROUP_BY_COLUMN}}, {{.ENTITY_TABLE}}.{{.GROUP_BY_COLUMN}}::text AS value{{end}}
+	FROM {{.ENTITY_TABLE}}
+		{{if .hasLabelCriteria}}
+		{{.JOIN}} {{.LABELS_TABLE}}
+			ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
+		{{end}}
+	{{.WHERE}})
+SELECT {{if .GROUP_BY_LABEL}}group_labels.val{{else}}matching_resources.value{{end}} AS value, COUNT(DISTINCT matching_resources.{{.PRIMARY_KEY}}) AS count
+FROM matching_resources
+	{{if .GROUP_BY_LABEL}}
+	LEFT JOIN {{.LABELS_TABLE}} group_labels
+		ON matching_resources.{{.PRIMARY_KEY}} = group_labels.{{.REF_COLUMN}} AND group_labels.key = {{.GROUP_BY_LABEL}}
+	{{end}}
+GROUP BY 1
+ORDER BY 1;`
+
+// ListDeletedQueryTemplate lists the resources deleted after a given time. The entity and labels tables are shadowed by
+// the state of the deleted resources kept in their tombstones, so that the criteria are applied on that state.
+const ListDeletedQueryTemplate = `
+WITH {{.ENTITY_TABLE}} AS (SELECT (jsonb_populate_record(NULL::{{.ENTITY_TABLE}}, tombstones.fields)).*, tombstones.deleted_at AS tombstone_deleted_at
+		FROM tombstones
+		WHERE tombstones.resource_type = '{{.ENTITY_TABLE}}' AND tombstones.deleted_at > ?),
+	{{.LABELS_TABLE}} AS (SELECT tombstones.resource_id AS {{.REF_COLUMN}}, resource_labels.key, label_values.val
+		FROM tombstones, jsonb_each(tombstones.labels) AS resource_labels(key, vals), jsonb_array_elements_text(resource_labels.vals) AS label_values(val)
+		WHERE tombstones.resource_type = '{{.ENTITY_TABLE}}' AND tombstones.deleted_at > ?)
+SELECT {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} AS id, MAX({{.ENTITY_TABLE}}.tombstone_deleted_at) AS deleted_at
+FROM {{.ENTITY_TABLE}}
+	{{if .hasLabelCriteria}}
+	{{.JOIN}} {{.LABELS_TABLE}}
+		ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
+	{{end}}
+{{.WHERE}}
+GROUP BY 1
+ORDER BY 2, 1;`
+
 const SelectQueryTemplate = `
 {{if or .hasFieldCriteria .hasLabelCriteria}}
-WITH matching_resources as (SELECT DISTINCT {{.ENTITY_TABLE}}.paging_sequence
+WITH matching_resources as (SELECT DISTINCT {{.ENTITY_TABLE}}.paging_sequence{{.MATCHING_COLUMNS}}
 							FROM {{.ENTITY_TABLE}}
 							{{if .hasLabelCriteria}}
 							{{.JOIN}} {{.LABELS_TABLE}} 
 								ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
 							{{end}}
 							{{.WHERE}}
-							{{.ORDER_BY_SEQUENCE}}
+							{{.MATCHING_ORDER_BY}}
 							{{.LIMIT}})
 {{end}}
 SELECT 
-{{.ENTITY_TABLE}}.*,
+{{if .ENTITY_COLUMNS}}{{.ENTITY_COLUMNS}}{{else}}{{.ENTITY_TABLE}}.*{{end}},
 {{.LABELS_TABLE}}.id         "{{.LABELS_TABLE}}.id",
 {{.LABELS_TABLE}}.key        "{{.LABELS_TABLE}}.key",
 {{.LABELS_TABLE}}.val        "{{.LABELS_TABLE}}.val",
@@ -57,8 +103,9 @@ SELECT
 {{.LABELS_TABLE}}.updated_at "{{.LABELS_TABLE}}.updated_at",
 {{.LABELS_TABLE}}.{{.REF_COLUMN}} "{{.LABELS_TABLE}}.{{.REF_COLUMN}}" 
 FROM {{.ENTITY_TABLE}}
-	{{.JOIN}} {{.LABELS_TABLE}}
+	{{if .LABEL_KEYS}}LEFT JOIN{{else}}{{.JOIN}}{{end}} {{.LABELS_TABLE}}
 		ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
+		{{if .LABEL_KEYS}}AND {{.LABELS_TABLE}}.key IN ({{.LABEL_KEYS}}){{end}}
 {{if or .hasFieldCriteria .hasLabelCriteria}}
 WHERE {{.ENTITY_TABLE}}.paging_sequence IN 
 	(SELECT matching_resources.paging_sequence FROM matching_resources)
@@ -68,17 +115,17 @@ WHERE {{.ENTITY_TABLE}}.paging_sequence IN
 
 const SelectNoLabelsQueryTemplate = `
 {{if or .hasFieldCriteria .hasLabelCriteria}}
-WITH matching_resources as (SELECT DISTINCT {{.ENTITY_TABLE}}.paging_sequence
+WITH matching_resources as (SELECT DISTINCT {{.ENTITY_TABLE}}.paging_sequence{{.MATCHING_COLUMNS}}
 							FROM {{.ENTITY_TABLE}}
 							{{if .hasLabelCriteria}}
 							{{.JOIN}} {{.LABELS_TABLE}} 
 								ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
 							{{end}}
 							{{.WHERE}}
-							{{.ORDER_BY_SEQUENCE}}
+							{{.MATCHING_ORDER_BY}}
 							{{.LIMIT}})
 {{end}}
-SELECT *
+SELECT {{if .ENTITY_COLUMNS}}{{.ENTITY_COLUMNS}}{{else}}*{{end}}
 FROM {{.ENTITY_TABLE}}
 {{if or .hasFieldCriteria .hasLabelCriteria}}
 WHERE {{.ENTITY_TABLE}}.paging_sequence IN 
@@ -101,6 +148,22 @@ DELETE FROM {{.ENTITY_TABLE}}
 {{end}}
 {{.RETURNING}};`
 
+// UpdateQueryTemplate updates the entity only if it matches the criteria, so that it cannot be modified
+// by another transaction between matching the criteria and updating it.
+const UpdateQueryTemplate = `
+UPDATE {{.ENTITY_TABLE}}
+SET {{.SET}}
+{{if .hasLabelCriteria}}
+	FROM (SELECT {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}}
+			FROM {{.ENTITY_TABLE}}
+				{{.JOIN}} {{.LABELS_TABLE}}
+					ON {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = {{.LABELS_TABLE}}.{{.REF_COLUMN}}
+			{{.WHERE}}) t
+	WHERE {{.ENTITY_TABLE}}.{{.PRIMARY_KEY}} = t.{{.PRIMARY_KEY}}
+{{else if .hasFieldCriteria}}
+	{{.WHERE}}
+{{end}};`
+
 // QueryBuilder is used to construct new queries. It is safe for concurrent usage
 type QueryBuilder struct {
 	db pgDB
@@ -120,6 +183,8 @@ func (qb *QueryBuilder) NewQuery(entity PostgresEntity) *pgQuery {
 		entityTableName:   entity.TableName(),
 		entityTags:        getDBTags(entity, nil),
 		labelEntityTags:   getDBTags(entity.LabelEntity(), nil),
+		jsonColumns:       queryableJSONColumns(entity),
+		securedColumns:    securedColumns(entity),
 		db:                qb.db,
 		fieldsWhereClause: &whereClauseTree{},
 		labelsWhereClause: &whereClauseTree{
@@ -135,6 +200,16 @@ func (qb *QueryBuilder) NewQuery(entity PostgresEntity) *pgQuery {
 type orderRule struct {
 	field     string
 	orderType query.OrderType
+	// expression is used instead of the field when ordering by it requires more than referencing a column
+	expression string
+}
+
+// sortKey returns the SQL expression by which the rule orders the result
+func (r orderRule) sortKey(tableName string) string {
+	if r.expression != "" {
+		return r.expression
+	}
+	return fmt.Sprintf("%s.%s", tableName, r.field)
 }
 
 // pgQuery is used to construct postgres queries. It should be constructed only via the query builder. It is not safe for concurrent use.
@@ -143,14 +218,23 @@ type pgQuery struct {
 	labelEntity     PostgresLabel
 	entityTags      []tagType
 	labelEntityTags []tagType
+	jsonColumns     map[string]bool
+	securedColumns  []string
 
 	queryParams []interface{}
 
 	orderByFields   []orderRule
+	cursor          []string
+	fields          []string
 	hasLock         bool
 	limit           string
 	returningFields []string
 	entityTableName string
+	groupByColumn   string
+	groupByLabel    string
+	setSQL          string
+	// labelKeys are the label keys used in the query outside of the criteria, bound as parameters when the query is resolved
+	labelKeys []string
 
 	fieldsWhereClause *whereClauseTree
 	labelsWhereClause *whereClauseTree
@@ -159,6 +243,9 @@ type pgQuery struct {
 }
 
 func (pq *pgQuery) List(ctx context.Context) (*sqlx.Rows, error) {
+	if !pq.projectsLabels() {
+		return pq.ListNoLabels(ctx)
+	}
 	q, err := pq.resolveQueryTemplate(ctx, SelectQueryTemplate)
 	if err != nil {
 		return nil, err
@@ -216,6 +303,76 @@ func (pq *pgQuery) CountLabelValues(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// CountGroupedBy counts the matching resources grouped by the values of the given field or label (labels.<key>)
+func (pq *pgQuery) CountGroupedBy(ctx context.Context, groupBy string) ([]types.AggregationGroup, error) {
+	if strings.HasPrefix(groupBy, query.LabelFieldPrefix) {
+		key := strings.TrimPrefix(groupBy, query.LabelFieldPrefix)
+		if key == "" {
+			return nil, &util.UnsupportedQueryError{Message: "group by label expects label key"}
+		}
+		pq.groupByLabel = pq.labelKeyParam(key)
+	} else {
+		if err := validateFields(columnsByTags(pq.entityTags), "unsupported entity field for group by: %s", groupBy); err != nil {
+			return nil, err
+		}
+		for _, column := range pq.securedColumns {
+			if column == groupBy {
+				return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported entity field for group by: %s", groupBy)}
+			}
+		}
+		pq.groupByColumn = groupBy
+	}
+
+	q, err := pq.resolveQueryTemplate(ctx, CountGroupedByQueryTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Value sql.NullString `db:"value"`
+		Count int            `db:"count"`
+	}
+	if err := pq.db.SelectContext(ctx, &rows, q, pq.queryParams...); err != nil {
+		return nil, err
+	}
+
+	groups := make([]types.AggregationGroup, 0, len(rows))
+	for _, row := range rows {
+		group := types.AggregationGroup{ItemsCount: row.Count}
+		if row.Value.Valid {
+			value := row.Value.String
+			group.Value = &value
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ListDeleted lists the matching resources deleted after the given time
+func (pq *pgQuery) ListDeleted(ctx context.Context, since time.Time) ([]types.Deletion, error) {
+	if len(pq.orderByFields) != 0 || len(pq.cursor) != 0 || len(pq.fields) != 0 || len(pq.limit) != 0 {
+		return nil, &util.UnsupportedQueryError{Message: "result criteria are not supported when listing deleted resources"}
+	}
+	pq.queryParams = append(pq.queryParams, since, since)
+
+	q, err := pq.resolveQueryTemplate(ctx, ListDeletedQueryTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID        string    `db:"id"`
+		DeletedAt time.Time `db:"deleted_at"`
+	}
+	if err := pq.db.SelectContext(ctx, &rows, q, pq.queryParams...); err != nil {
+		return nil, err
+	}
+
+	deletions := make([]types.Deletion, 0, len(rows))
+	for _, row := range rows {
+		deletions = append(deletions, types.Deletion{ID: row.ID, DeletedAt: row.DeletedAt})
+	}
+	return deletions, nil
+}
+
 func (pq *pgQuery) Delete(ctx context.Context) (sql.Result, error) {
 	q, err := pq.resolveQueryTemplate(ctx, DeleteQueryTemplate)
 	if err != nil {
@@ -247,6 +404,31 @@ func (pq *pgQuery) DeleteReturning(ctx context.Context, fields ...string) (*sqlx
 	return rows, nil
 }
 
+// Update sets the columns of the entity on the rows matching the criteria
+func (pq *pgQuery) Update(ctx context.Context, entity PostgresEntity) (sql.Result, error) {
+	set, setParams, err := sqlx.Named(updateSetSQL(entity), entity)
+	if err != nil {
+		return nil, err
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("entity %s has no columns to update", pq.entityTableName)
+	}
+	pq.setSQL = set
+	// the set clause precedes the where clause, so its parameters are bound first
+	pq.queryParams = append(setParams, pq.queryParams...)
+
+	q, err := pq.resolveQueryTemplate(ctx, UpdateQueryTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := pq.db.ExecContext(ctx, q, pq.queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (pq *pgQuery) resolveQueryTemplate(ctx context.Context, template string) (string, error) {
 	if pq.err != nil {
 		return "", pq.err
@@ -254,12 +436,18 @@ func (pq *pgQuery) resolveQueryTemplate(ctx context.Context, template string) (s
 	if pq.labelEntity == nil {
 		return "", fmt.Errorf("query builder requires the entity to have associated label entity")
 	}
+	if len(pq.cursor) != 0 && len(pq.cursor) != len(pq.orderByFields) {
+		return "", &util.UnsupportedQueryError{Message: fmt.Sprintf("after criterion expects a value for each of the %d order by criteria, but %d values are provided", len(pq.orderByFields), len(pq.cursor))}
+	}
 	data := pq.getTemplateParams()
 
 	q, err := util.Tsprintf(template, data)
 	if err != nil {
 		return "", err
 	}
+	if q, err = pq.bindLabelKeys(q); err != nil {
+		return "", err
+	}
 	if q, err = pq.finalizeSQL(ctx, q); err != nil {
 		return "", err
 	}
@@ -268,7 +456,7 @@ func (pq *pgQuery) resolveQueryTemplate(ctx context.Context, template string) (s
 }
 
 func (pq *pgQuery) getTemplateParams() map[string]interface{} {
-	hasFieldCriteria := len(pq.fieldsWhereClause.children) != 0 || len(pq.limit) != 0
+	hasFieldCriteria := len(pq.fieldsWhereClause.children) != 0 || len(pq.limit) != 0 || len(pq.cursor) != 0
 	hasLabelCriteria := len(pq.labelsWhereClause.children) != 0
 	data := map[string]interface{}{
 		"hasFieldCriteria":  hasFieldCriteria,
@@ -281,9 +469,15 @@ func (pq *pgQuery) getTemplateParams() map[string]interface{} {
 		"WHERE":             pq.whereSQL(),
 		"FOR_UPDATE_OF":     pq.lockSQL(),
 		"ORDER_BY":          pq.orderBySQL(),
-		"ORDER_BY_SEQUENCE": pq.orderBySequenceSQL(),
+		"MATCHING_COLUMNS":  pq.matchingColumnsSQL(),
+		"ENTITY_COLUMNS":    pq.projectedColumnsSQL(),
+		"LABEL_KEYS":        pq.projectedLabelKeysSQL(),
+		"MATCHING_ORDER_BY": pq.matchingOrderBySQL(),
 		"LIMIT":             pq.limitSQL(),
 		"RETURNING":         pq.returningSQL(),
+		"GROUP_BY_COLUMN":   pq.groupByColumn,
+		"GROUP_BY_LABEL":    pq.groupByLabel,
+		"SET":               pq.setSQL,
 	}
 	return data
 }
@@ -305,20 +499,20 @@ func (pq *pgQuery) WithCriteria(criteria ...query.Criterion) *pgQuery {
 		if hasMultiVariateOp(criteria) {
 			pq.shouldRebind = true
 		}
+		if criterion.IsLogical() {
+			tree, err := pq.logicalWhereClause(criterion)
+			if err != nil {
+				pq.err = err
+				return pq
+			}
+			pq.fieldsWhereClause.children = append(pq.fieldsWhereClause.children, tree)
+			continue
+		}
 		switch criterion.Type {
 		case query.FieldQuery:
-			columns := columnsByTags(pq.entityTags)
-			columnName := criterion.LeftOp
-			if strings.Contains(columnName, "/") {
-				columnName = strings.Split(columnName, "/")[0]
-				ttype := findTagType(pq.entityTags, columnName)
-				if ttype != jsonType {
-					pq.err = &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query: json notation on non json column: %s", columnName)}
-					return pq
-				}
-			}
-			if !columns[columnName] {
-				pq.err = &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query key: %s", criterion.LeftOp)}
+			criterion.LeftOp = toJSONPath(criterion.LeftOp)
+			if err := pq.validateFieldQueryKey(criterion.LeftOp); err != nil {
+				pq.err = err
 				return pq
 			}
 			pq.fieldsWhereClause.children = append(pq.fieldsWhereClause.children, &whereClauseTree{
@@ -359,6 +553,103 @@ func (pq *pgQuery) WithCriteria(criteria ...query.Criterion) *pgQuery {
 	return pq
 }
 
+// logicalWhereClause builds the where clause tree of a criterion combining field or label criteria with logical operators.
+// As the operands of OR and NOT cannot be expressed by joining the labels table, each label criterion in the tree
+// is translated to a sub-select of the matching resource ids.
+func (pq *pgQuery) logicalWhereClause(c query.Criterion) (*whereClauseTree, error) {
+	if !c.IsLogical() {
+		switch c.Type {
+		case query.FieldQuery:
+			c.LeftOp = toJSONPath(c.LeftOp)
+			if err := pq.validateFieldQueryKey(c.LeftOp); err != nil {
+				return nil, err
+			}
+			return &whereClauseTree{
+				criterion: c,
+				dbTags:    pq.entityTags,
+				tableName: pq.entityTableName,
+			}, nil
+		case query.LabelQuery:
+			return pq.labelSubQueryWhereClause(c), nil
+		default:
+			return nil, &util.UnsupportedQueryError{Message: fmt.Sprintf("logical operators are not supported for %s", c.Type)}
+		}
+	}
+	tree := &whereClauseTree{
+		sqlBuilder: logicalTreeSqlBuilders[c.Operator],
+	}
+	for _, criterion := range c.Criteria {
+		child, err := pq.logicalWhereClause(criterion)
+		if err != nil {
+			return nil, err
+		}
+		tree.children = append(tree.children, child)
+	}
+	return tree, nil
+}
+
+func (pq *pgQuery) labelSubQueryWhereClause(c query.Criterion) *whereClauseTree {
+	return &whereClauseTree{
+		children: []*whereClauseTree{
+			{
+				criterion: query.ByField(query.EqualsOperator, "key", c.LeftOp),
+				dbTags:    pq.labelEntityTags,
+			},
+			{
+				criterion: query.ByField(c.Operator, "val", c.RightOp...),
+				dbTags:    pq.labelEntityTags,
+			},
+		},
+		sqlBuilder: &treeSqlBuilder{
+			buildSQL: func(childrenSQL []string) string {
+				return fmt.Sprintf("%s.%s IN (SELECT %s FROM %s WHERE %s)", pq.entityTableName, PrimaryKeyColumn,
+					pq.labelEntity.ReferenceColumn(), pq.labelEntity.LabelsTableName(), strings.Join(childrenSQL, fmt.Sprintf(" %s ", AND)))
+			},
+		},
+	}
+}
+
+func (pq *pgQuery) validateFieldQueryKey(key string) error {
+	columns := columnsByTags(pq.entityTags)
+	columnName := key
+	if strings.Contains(columnName, "/") {
+		columnName = strings.Split(columnName, "/")[0]
+		ttype := findTagType(pq.entityTags, columnName)
+		if ttype != jsonType {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query: json notation on non json column: %s", columnName)}
+		}
+		if !pq.jsonColumns[columnName] {
+			return &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query: json notation on non queryable column: %s", columnName)}
+		}
+	}
+	if !columns[columnName] {
+		return &util.UnsupportedQueryError{Message: fmt.Sprintf("unsupported field query key: %s", key)}
+	}
+	return nil
+}
+
+// toJSONPath allows the sub-fields of JSON columns to be referenced both as column/field and column.field
+func toJSONPath(key string) string {
+	return strings.Replace(key, ".", "/", -1)
+}
+
+func securedColumns(entity PostgresEntity) []string {
+	if securedEntity, ok := entity.(SecuredEntity); ok {
+		return securedEntity.SecuredColumns()
+	}
+	return nil
+}
+
+func queryableJSONColumns(entity PostgresEntity) map[string]bool {
+	columns := make(map[string]bool)
+	if jsonEntity, ok := entity.(JSONQueryableEntity); ok {
+		for _, column := range jsonEntity.QueryableJSONColumns() {
+			columns[column] = true
+		}
+	}
+	return columns
+}
+
 func (pq *pgQuery) WithLock() *pgQuery {
 	if pq.err != nil {
 		return pq
@@ -395,6 +686,14 @@ func (pq *pgQuery) whereSQL() string {
 		},
 	}
 	whereSQL, queryParams := whereClause.compileSQL()
+	if cursorSQL, cursorParams := pq.cursorSQL(); len(cursorSQL) != 0 {
+		if len(whereSQL) != 0 {
+			whereSQL = fmt.Sprintf("%s %s %s", whereSQL, AND, cursorSQL)
+		} else {
+			whereSQL = cursorSQL
+		}
+		queryParams = append(queryParams, cursorParams...)
+	}
 	if len(whereSQL) == 0 {
 		return ""
 	}
@@ -454,11 +753,38 @@ func (pq *pgQuery) processResultCriteria(c query.Criterion) *pgQuery {
 			field:     c.RightOp[0],
 			orderType: query.OrderType(c.RightOp[1]),
 		}
-		if err := validateOrderFields(columnsByTags(pq.entityTags), rule); err != nil {
+		if err := validateOrderFields(pq.orderableColumns(), rule); err != nil {
+			pq.err = err
+			return pq
+		}
+		if findTagType(pq.entityTags, rule.field) == nullStringType {
+			// missing values are ordered as empty strings so that they can be compared with the cursor values
+			rule.expression = fmt.Sprintf("COALESCE(%s.%s, '')", pq.entityTableName, rule.field)
+		}
+		pq.orderByFields = append(pq.orderByFields, rule)
+	case query.OrderByLabel:
+		rule := orderRule{
+			field:      c.RightOp[0],
+			orderType:  query.OrderType(c.RightOp[1]),
+			expression: pq.labelSortKeySQL(c.RightOp[0]),
+		}
+		if err := validateOrderFields(map[string]bool{rule.field: true}, rule); err != nil {
 			pq.err = err
 			return pq
 		}
 		pq.orderByFields = append(pq.orderByFields, rule)
+	case query.After:
+		if pq.cursor != nil {
+			pq.err = fmt.Errorf("zero/one after criterion expected but multiple provided")
+			return pq
+		}
+		pq.cursor = c.RightOp
+	case query.Fields:
+		if pq.fields != nil {
+			pq.err = fmt.Errorf("zero/one fields criterion expected but multiple provided")
+			return pq
+		}
+		pq.fields = c.RightOp
 	case query.Limit:
 		if pq.limit != "" {
 			pq.err = fmt.Errorf("zero/one limit expected but multiple provided")
@@ -469,13 +795,32 @@ func (pq *pgQuery) processResultCriteria(c query.Criterion) *pgQuery {
 	return pq
 }
 
+// orderableColumns returns the columns of the entity except the secured ones, which the order would disclose, and the
+// generated ones
+func (pq *pgQuery) orderableColumns() map[string]bool {
+	columns := columnsByTags(pq.entityTags)
+	for _, column := range pq.securedColumns {
+		delete(columns, column)
+	}
+	for _, tag := range pq.entityTags {
+		if isGenerated(tag.Tag) {
+			delete(columns, strings.Split(tag.Tag, ",")[0])
+		}
+	}
+	return columns
+}
+
 func (pq *pgQuery) orderBySQL() string {
 	sql := ""
 	rules := pq.orderByFields
 	if len(rules) > 0 {
 		sql += "ORDER BY"
 		for _, orderRule := range rules {
-			sql += fmt.Sprintf(" %s %s,", orderRule.field, orderRule.orderType)
+			field := orderRule.field
+			if orderRule.expression != "" {
+				field = orderRule.expression
+			}
+			sql += fmt.Sprintf(" %s %s,", field, orderRule.orderType)
 		}
 		sql = sql[:len(sql)-1]
 	}
@@ -486,11 +831,167 @@ func (pq *pgQuery) orderBySQL() string {
 	return sql
 }
 
-func (pq *pgQuery) orderBySequenceSQL() string {
-	if len(pq.limit) > 0 {
+// matchingOrderBySQL orders the matching resources before the limit is applied so that the limit selects
+// the first resources in the requested order
+func (pq *pgQuery) matchingOrderBySQL() string {
+	if len(pq.limit) == 0 {
+		return ""
+	}
+	if len(pq.orderByFields) == 0 {
 		return fmt.Sprintf("ORDER BY %s.paging_sequence ASC", pq.entityTableName)
 	}
-	return ""
+	sortKeys := make([]string, 0, len(pq.orderByFields))
+	for _, rule := range pq.orderByFields {
+		sortKeys = append(sortKeys, fmt.Sprintf("%s %s", rule.sortKey(pq.entityTableName), rule.orderType))
+	}
+	return "ORDER BY " + strings.Join(sortKeys, ", ")
+}
+
+// matchingColumnsSQL returns the sort keys by which the matching resources are ordered, as they need to be selected
+// together with the distinct paging sequences
+func (pq *pgQuery) matchingColumnsSQL() string {
+	if len(pq.limit) == 0 {
+		return ""
+	}
+	sql := ""
+	pagingSequence := fmt.Sprintf("%s.paging_sequence", pq.entityTableName)
+	for _, rule := range pq.orderByFields {
+		if sortKey := rule.sortKey(pq.entityTableName); sortKey != pagingSequence {
+			sql += ", " + sortKey
+		}
+	}
+	return sql
+}
+
+// cursorSQL builds the keyset condition which selects the resources placed after the cursor in the requested order
+func (pq *pgQuery) cursorSQL() (string, []interface{}) {
+	if len(pq.cursor) == 0 || len(pq.cursor) != len(pq.orderByFields) {
+		return "", nil
+	}
+	queryParams := make([]interface{}, 0)
+	disjunctions := make([]string, 0, len(pq.orderByFields))
+	for i, rule := range pq.orderByFields {
+		conjunctions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conjunctions = append(conjunctions, fmt.Sprintf("%s = ?", pq.orderByFields[j].sortKey(pq.entityTableName)))
+			queryParams = append(queryParams, pq.cursor[j])
+		}
+		operator := ">"
+		if rule.orderType == query.DescOrder {
+			operator = "<"
+		}
+		conjunctions = append(conjunctions, fmt.Sprintf("%s %s ?", rule.sortKey(pq.entityTableName), operator))
+		queryParams = append(queryParams, pq.cursor[i])
+		disjunctions = append(disjunctions, fmt.Sprintf("(%s)", strings.Join(conjunctions, fmt.Sprintf(" %s ", AND))))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(disjunctions, fmt.Sprintf(" %s ", OR))), queryParams
+}
+
+// projectedColumnsSQL returns the columns selected when the result is projected to some of the fields.
+// The primary key, the paging sequence, the order by and the secured columns are always selected.
+// All columns are selected, denoted by empty string, when there is no projection or some of the fields are not backed by a single column.
+func (pq *pgQuery) projectedColumnsSQL() string {
+	if len(pq.fields) == 0 {
+		return ""
+	}
+	columns := columnsByTags(pq.entityTags)
+	selectedColumns := []string{PrimaryKeyColumn, "paging_sequence"}
+	for _, field := range pq.fields {
+		if field == query.LabelsField || strings.HasPrefix(field, query.LabelFieldPrefix) {
+			continue
+		}
+		if !columns[field] {
+			return ""
+		}
+		selectedColumns = append(selectedColumns, field)
+	}
+	for _, rule := range pq.orderByFields {
+		if columns[rule.field] {
+			selectedColumns = append(selectedColumns, rule.field)
+		}
+	}
+	selectedColumns = append(selectedColumns, pq.securedColumns...)
+
+	selected := make(map[string]bool)
+	qualifiedColumns := make([]string, 0, len(selectedColumns))
+	for _, column := range selectedColumns {
+		if !selected[column] {
+			selected[column] = true
+			qualifiedColumns = append(qualifiedColumns, fmt.Sprintf("%s.%s", pq.entityTableName, column))
+		}
+	}
+	return strings.Join(qualifiedColumns, ", ")
+}
+
+// projectsLabels returns true if the labels are part of the result
+func (pq *pgQuery) projectsLabels() bool {
+	if len(pq.fields) == 0 {
+		return true
+	}
+	for _, field := range pq.fields {
+		if field == query.LabelsField || strings.HasPrefix(field, query.LabelFieldPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectedLabelKeysSQL returns the keys of the labels which are part of the result or empty string if all labels are
+func (pq *pgQuery) projectedLabelKeysSQL() string {
+	keys := make([]string, 0)
+	for _, field := range pq.fields {
+		if field == query.LabelsField {
+			return ""
+		}
+		if strings.HasPrefix(field, query.LabelFieldPrefix) {
+			keys = append(keys, pq.labelKeyParam(strings.TrimPrefix(field, query.LabelFieldPrefix)))
+		}
+	}
+	return strings.Join(keys, ", ")
+}
+
+// labelSortKeySQL returns the value of the label with the given key by which resources are ordered.
+// Resources with multiple values for the label are ordered by the lowest one and resources without the label as if its value is empty.
+func (pq *pgQuery) labelSortKeySQL(key string) string {
+	return fmt.Sprintf(`COALESCE((SELECT MIN(order_labels.val COLLATE "C") FROM %s order_labels WHERE order_labels.%s = %s.%s AND order_labels.key = %s), '')`,
+		pq.labelEntity.LabelsTableName(), pq.labelEntity.ReferenceColumn(), pq.entityTableName, PrimaryKeyColumn, pq.labelKeyParam(key))
+}
+
+// labelKeyParam returns a placeholder for the label key. The placeholders are replaced by bind variables once the
+// query template is resolved, because the fragments containing them are not rendered in the order in which they are built.
+func (pq *pgQuery) labelKeyParam(key string) string {
+	pq.labelKeys = append(pq.labelKeys, key)
+	return fmt.Sprintf("%s%d", labelKeyParamPrefix, len(pq.labelKeys)-1)
+}
+
+// bindLabelKeys replaces the label key placeholders of the query by bind variables and inserts the label keys among
+// the query parameters in the order in which the bind variables appear in the query
+func (pq *pgQuery) bindLabelKeys(sql string) (string, error) {
+	if len(pq.labelKeys) == 0 {
+		return sql, nil
+	}
+	params := make([]interface{}, 0, len(pq.queryParams)+len(pq.labelKeys))
+	next := 0
+	var err error
+	sql = bindVariablesRegex.ReplaceAllStringFunc(sql, func(match string) string {
+		if match == "?" {
+			if next < len(pq.queryParams) {
+				params = append(params, pq.queryParams[next])
+				next++
+			} else {
+				err = fmt.Errorf("query has more bind variables than parameters")
+			}
+			return match
+		}
+		index, _ := strconv.Atoi(strings.TrimPrefix(match, labelKeyParamPrefix))
+		params = append(params, pq.labelKeys[index])
+		return "?"
+	})
+	if err != nil {
+		return "", err
+	}
+	pq.queryParams = append(params, pq.queryParams[next:]...)
+	return sql, nil
 }
 
 func validateOrderFields(columns map[string]bool, orderRules ...orderRule) error {
@@ -8,6 +8,7 @@ import (
 
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Peripli/service-manager/pkg/query"
 
@@ -284,7 +285,7 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					List(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
+WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence, visibilities.id
                             FROM visibilities
 								JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
                             WHERE ((visibilities.id::text != ? AND
@@ -295,7 +296,7 @@ WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
 										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text IN (?, ?)))
 										INTERSECT
 										(SELECT visibility_id FROM visibility_labels WHERE (key::text = ? AND val::text != ?)))))
-                            ORDER BY visibilities.paging_sequence ASC
+                            ORDER BY visibilities.id ASC
                             LIMIT ?)
 SELECT visibilities.*,
        visibility_labels.id            "visibility_labels.id",
@@ -324,6 +325,83 @@ ORDER BY id ASC ;`)))
 				Expect(queryArgs[12]).Should(Equal("10"))
 			})
 		})
+		Context("when fields criteria is used", func() {
+			Context("without labels", func() {
+				It("selects only the requested columns and skips the labels", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.SelectResultFields("service_plan_id"), query.OrderResultBy("created_at", query.DescOrder)).
+						List(ctx)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(executedQuery).Should(Equal(trim(`
+SELECT visibilities.id, visibilities.paging_sequence, visibilities.service_plan_id, visibilities.created_at
+FROM visibilities
+ORDER BY created_at DESC ;`)))
+				})
+			})
+
+			Context("with single labels", func() {
+				It("joins only the requested labels", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.SelectResultFields("id", "labels.tenant", "labels.it's"), query.ByLabel(query.EqualsOperator, "env", "dev")).
+						List(ctx)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(executedQuery).Should(Equal(trim(`
+WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
+                            FROM visibilities
+								JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
+                            WHERE (key::text = ? AND val::text = ?) )
+SELECT visibilities.id, visibilities.paging_sequence,
+       visibility_labels.id            "visibility_labels.id",
+       visibility_labels.key           "visibility_labels.key",
+       visibility_labels.val           "visibility_labels.val",
+       visibility_labels.created_at    "visibility_labels.created_at",
+       visibility_labels.updated_at    "visibility_labels.updated_at",
+       visibility_labels.visibility_id "visibility_labels.visibility_id"
+FROM visibilities
+	LEFT JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
+		AND visibility_labels.key IN (?, ?)
+WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
+ORDER BY visibilities.paging_sequence ASC ;`)))
+					Expect(queryArgs).To(Equal([]interface{}{"env", "dev", "tenant", "it's"}))
+				})
+			})
+
+			Context("with all labels", func() {
+				It("joins all labels", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.SelectResultFields("labels", "labels.tenant")).
+						List(ctx)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(executedQuery).Should(ContainSubstring("LEFT JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id ORDER BY"))
+				})
+			})
+
+			Context("when a field is not backed by a single column", func() {
+				It("selects all columns", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.SelectResultFields("id", "credentials")).
+						ListNoLabels(ctx)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(executedQuery).Should(Equal(trim(`
+SELECT *
+FROM visibilities
+ORDER BY visibilities.paging_sequence ASC ;`)))
+				})
+			})
+
+			Context("when the entity has secured columns", func() {
+				It("selects them", func() {
+					_, err := qb.NewQuery(&postgres.Broker{}).
+						WithCriteria(query.SelectResultFields("name")).
+						ListNoLabels(ctx)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(executedQuery).Should(HavePrefix(trim(`
+SELECT brokers.id, brokers.paging_sequence, brokers.name, brokers.broker_url, brokers.username, brokers.password,
+       brokers.tls_client_key, brokers.tls_client_certificate, brokers.integrity
+FROM brokers`)))
+				})
+			})
+		})
 	})
 
 	Describe("ListNoLabels", func() {
@@ -451,6 +529,74 @@ ORDER BY id DESC, created_at ASC ;`)))
 			})
 		})
 
+		Context("when order by label criteria is used", func() {
+			It("orders by the lowest value of the label", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.OrderResultByLabel("env", query.AscOrder), query.OrderResultBy("platform_id", query.DescOrder), query.LimitResultBy(5)).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence,
+                                COALESCE((SELECT MIN(order_labels.val COLLATE "C") FROM visibility_labels order_labels WHERE order_labels.visibility_id = visibilities.id AND order_labels.key = ?), ''),
+                                COALESCE(visibilities.platform_id, '')
+                            FROM visibilities
+                            ORDER BY COALESCE((SELECT MIN(order_labels.val COLLATE "C") FROM visibility_labels order_labels WHERE order_labels.visibility_id = visibilities.id AND order_labels.key = ?), '') ASC,
+                                COALESCE(visibilities.platform_id, '') DESC
+                            LIMIT ?)
+SELECT *
+FROM visibilities
+WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
+ORDER BY COALESCE((SELECT MIN(order_labels.val COLLATE "C") FROM visibility_labels order_labels WHERE order_labels.visibility_id = visibilities.id AND order_labels.key = ?), '') ASC,
+    COALESCE(visibilities.platform_id, '') DESC ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"env", "env", "5", "env"}))
+			})
+
+			It("binds the label key as parameter", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.OrderResultByLabel("it's", query.AscOrder)).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(ContainSubstring("order_labels.key = ?"))
+				Expect(executedQuery).ShouldNot(ContainSubstring("it's"))
+				Expect(queryArgs).To(ContainElement("it's"))
+			})
+		})
+
+		Context("when after criteria is used", func() {
+			It("builds keyset condition from the order by criteria", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.OrderResultBy("service_plan_id", query.DescOrder),
+						query.OrderResultBy("paging_sequence", query.AscOrder),
+						query.LimitResultBy(10),
+						query.ResultAfter("plan", "42"),
+						query.ByField(query.EqualsOperator, "id", "1")).
+					ListNoLabels(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence, visibilities.service_plan_id
+                            FROM visibilities
+                            WHERE visibilities.id::text = ? AND
+                                ((visibilities.service_plan_id < ?) OR (visibilities.service_plan_id = ? AND visibilities.paging_sequence > ?))
+                            ORDER BY visibilities.service_plan_id DESC, visibilities.paging_sequence ASC
+                            LIMIT ?)
+SELECT *
+FROM visibilities
+WHERE visibilities.paging_sequence IN (SELECT matching_resources.paging_sequence FROM matching_resources)
+ORDER BY service_plan_id DESC, paging_sequence ASC ;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"1", "plan", "plan", "42", "10"}))
+			})
+
+			Context("when the values do not match the order by criteria", func() {
+				It("returns error", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.OrderResultBy("id", query.AscOrder), query.ResultAfter("1", "2")).
+						ListNoLabels(ctx)
+					Expect(err).Should(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("after criterion expects a value for each of the 1 order by criteria, but 2 values are provided"))
+				})
+			})
+		})
+
 		Context("when limit criteria is used", func() {
 			It("builds query with limit clause", func() {
 				_, err := qb.NewQuery(entity).
@@ -501,12 +647,12 @@ ORDER BY visibilities.paging_sequence ASC ;`)))
 					ListNoLabels(ctx)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(executedQuery).Should(Equal(trim(`
-WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence
+WITH matching_resources as (SELECT DISTINCT visibilities.paging_sequence, visibilities.id
                             FROM visibilities
 							WHERE (visibilities.id::text != ? AND
                                     visibilities.service_plan_id::text NOT IN (?, ?, ?) AND
                                     (visibilities.platform_id::text = ? OR platform_id IS NULL))
-                            ORDER BY visibilities.paging_sequence ASC
+                            ORDER BY visibilities.id ASC
                             LIMIT ?)
 SELECT *
 FROM visibilities
@@ -628,6 +774,101 @@ LIMIT ?;`)))
 			})
 		})
 
+		Context("when pattern operators are used", func() {
+			It("builds query with escaped like patterns and regular expressions", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(
+						query.ByField(query.ContainsOperator, "id", "50%_off"),
+						query.ByField(query.StartsWithOperator, "platform_id", `prod\`),
+						query.ByField(query.MatchesOperator, "service_plan_id", "^plan-[0-9]+$"),
+					).
+					Count(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT visibilities.id)
+FROM visibilities
+WHERE (visibilities.id::text ILIKE ? AND
+	lower(visibilities.platform_id::text) LIKE lower(?) AND
+	visibilities.service_plan_id::text ~* ?) ;`)))
+				Expect(queryArgs).To(HaveLen(3))
+				Expect(queryArgs[0]).Should(Equal(`%50\%\_off%`))
+				Expect(queryArgs[1]).Should(Equal(`prod\\%`))
+				Expect(queryArgs[2]).Should(Equal("^plan-[0-9]+$"))
+			})
+
+			It("matches the patterns against the text of columns which are not strings", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(
+						query.ByField(query.ContainsOperator, "paging_sequence", "42"),
+						query.ByField(query.StartsWithOperator, "created_at", "2021-05"),
+					).
+					Count(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT visibilities.id)
+FROM visibilities
+WHERE (visibilities.paging_sequence::text ILIKE ? AND
+	lower(visibilities.created_at::text) LIKE lower(?)) ;`)))
+			})
+		})
+
+		Context("when logical operators are used", func() {
+			It("builds query with field criteria combined by or and not", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.ByAny(
+						query.ByField(query.EqualsOperator, "platform_id", "1"),
+						query.ByAll(
+							query.ByField(query.EqualsOperator, "platform_id", "2"),
+							query.ByNot(query.ByField(query.InOperator, "service_plan_id", "3", "4")),
+						),
+					)).
+					Count(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT visibilities.id)
+FROM visibilities
+WHERE (visibilities.platform_id::text = ? OR
+	(visibilities.platform_id::text = ? AND (NOT visibilities.service_plan_id::text IN (?, ?)))) ;`)))
+				Expect(queryArgs).To(HaveLen(4))
+				Expect(queryArgs[0]).Should(Equal("1"))
+				Expect(queryArgs[1]).Should(Equal("2"))
+				Expect(queryArgs[2]).Should(Equal("3"))
+				Expect(queryArgs[3]).Should(Equal("4"))
+			})
+
+			It("builds query with label criteria as sub-selects", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.ByAny(
+						query.ByLabel(query.EqualsOperator, "left1", "right1"),
+						query.ByNot(query.ByLabel(query.EqualsOperator, "left2", "right2")),
+					)).
+					Count(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT visibilities.id)
+FROM visibilities
+WHERE (visibilities.id IN (SELECT visibility_id FROM visibility_labels WHERE key::text = ? AND val::text = ?) OR
+	(NOT visibilities.id IN (SELECT visibility_id FROM visibility_labels WHERE key::text = ? AND val::text = ?))) ;`)))
+				Expect(queryArgs).To(HaveLen(4))
+				Expect(queryArgs[0]).Should(Equal("left1"))
+				Expect(queryArgs[1]).Should(Equal("right1"))
+				Expect(queryArgs[2]).Should(Equal("left2"))
+				Expect(queryArgs[3]).Should(Equal("right2"))
+			})
+
+			Context("when field is missing", func() {
+				It("returns error", func() {
+					_, err := qb.NewQuery(entity).
+						WithCriteria(query.ByAny(
+							query.ByField(query.EqualsOperator, "platform_id", "1"),
+							query.ByField(query.EqualsOperator, "non-existing-field", "2"),
+						)).
+						Count(ctx)
+					Expect(err).Should(HaveOccurred())
+				})
+			})
+		})
+
 		Context("when multiple criteria are used", func() {
 			It("builds a valid query", func() {
 				criteria1 := query.ByField(query.NotEqualsOperator, "id", "1")
@@ -676,6 +917,29 @@ LIMIT ?;`)))
 		})
 	})
 
+	Describe("Text search", func() {
+		It("builds query matching the words starting with the words of the text", func() {
+			_, err := qb.NewQuery(&postgres.ServicePlan{}).
+				WithCriteria(query.ByField(query.TextSearchOperator, "search_vector", "Small  db-plan!")).
+				Count(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT service_plans.id)
+FROM service_plans
+WHERE service_plans.search_vector @@ to_tsquery('simple', ?) ;`)))
+			Expect(queryArgs).To(HaveLen(1))
+			Expect(queryArgs[0]).Should(Equal("Small:* & db:* & plan:*"))
+		})
+
+		It("drops text search operators from the text", func() {
+			_, err := qb.NewQuery(&postgres.ServicePlan{}).
+				WithCriteria(query.ByField(query.TextSearchOperator, "search_vector", "a|b & !c:*")).
+				Count(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(queryArgs[0]).Should(Equal("a:* & b:* & c:*"))
+		})
+	})
+
 	Describe("CountLabelValues", func() {
 		Context("when entity does not have an associated label entity", func() {
 			It("returns error", func() {
@@ -833,6 +1097,139 @@ LIMIT ?;`)))
 		})
 	})
 
+	Describe("CountGroupedBy", func() {
+		Context("when grouped by field", func() {
+			It("builds query counting the resources per value of the field", func() {
+				_, err := qb.NewQuery(entity).CountGroupedBy(ctx, "service_plan_id")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+WITH matching_resources AS (SELECT DISTINCT visibilities.id, visibilities.service_plan_id::text AS value
+	FROM visibilities )
+SELECT matching_resources.value AS value, COUNT(DISTINCT matching_resources.id) AS count
+FROM matching_resources
+GROUP BY 1
+ORDER BY 1;`)))
+				Expect(queryArgs).To(HaveLen(0))
+			})
+
+			Context("when field is missing", func() {
+				It("returns error", func() {
+					_, err := qb.NewQuery(entity).CountGroupedBy(ctx, "non-existing-field")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("unsupported entity field for group by: non-existing-field"))
+				})
+			})
+
+			Context("when field is secured", func() {
+				It("returns error", func() {
+					_, err := qb.NewQuery(&postgres.Platform{}).CountGroupedBy(ctx, "password")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("unsupported entity field for group by: password"))
+				})
+			})
+		})
+
+		Context("when grouped by label", func() {
+			It("builds query counting the resources per value of the label", func() {
+				_, err := qb.NewQuery(entity).CountGroupedBy(ctx, "labels.tenant")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+WITH matching_resources AS (SELECT DISTINCT visibilities.id
+	FROM visibilities )
+SELECT group_labels.val AS value, COUNT(DISTINCT matching_resources.id) AS count
+FROM matching_resources
+	LEFT JOIN visibility_labels group_labels
+		ON matching_resources.id = group_labels.visibility_id AND group_labels.key = ?
+GROUP BY 1
+ORDER BY 1;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"tenant"}))
+			})
+
+			Context("when label key is missing", func() {
+				It("returns error", func() {
+					_, err := qb.NewQuery(entity).CountGroupedBy(ctx, "labels.")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("group by label expects label key"))
+				})
+			})
+		})
+
+		Context("when field and label criteria are used", func() {
+			It("counts only the matching resources", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.ByField(query.EqualsOperator, "platform_id", "1")).
+					WithCriteria(query.ByLabel(query.EqualsOperator, "labelKey", "labelValue")).
+					CountGroupedBy(ctx, "labels.tenant")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+WITH matching_resources AS (SELECT DISTINCT visibilities.id
+	FROM visibilities
+		JOIN visibility_labels
+			ON visibilities.id = visibility_labels.visibility_id
+	WHERE (visibilities.platform_id::text = ? AND (key::text = ? AND val::text = ?)))
+SELECT group_labels.val AS value, COUNT(DISTINCT matching_resources.id) AS count
+FROM matching_resources
+	LEFT JOIN visibility_labels group_labels
+		ON matching_resources.id = group_labels.visibility_id AND group_labels.key = ?
+GROUP BY 1
+ORDER BY 1;`)))
+				Expect(queryArgs).To(Equal([]interface{}{"1", "labelKey", "labelValue", "tenant"}))
+			})
+		})
+	})
+
+	Describe("ListDeleted", func() {
+		since := time.Date(2021, 2, 15, 10, 0, 0, 0, time.UTC)
+
+		Context("when no criteria is used", func() {
+			It("builds query listing the tombstones of the entity", func() {
+				_, err := qb.NewQuery(entity).ListDeleted(ctx, since)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+WITH visibilities AS (SELECT (jsonb_populate_record(NULL::visibilities, tombstones.fields)).*, tombstones.deleted_at AS tombstone_deleted_at
+		FROM tombstones
+		WHERE tombstones.resource_type = 'visibilities' AND tombstones.deleted_at > ?),
+	visibility_labels AS (SELECT tombstones.resource_id AS visibility_id, resource_labels.key, label_values.val
+		FROM tombstones, jsonb_each(tombstones.labels) AS resource_labels(key, vals), jsonb_array_elements_text(resource_labels.vals) AS label_values(val)
+		WHERE tombstones.resource_type = 'visibilities' AND tombstones.deleted_at > ?)
+SELECT visibilities.id AS id, MAX(visibilities.tombstone_deleted_at) AS deleted_at
+FROM visibilities
+GROUP BY 1
+ORDER BY 2, 1;`)))
+				Expect(queryArgs).To(Equal([]interface{}{since, since}))
+			})
+		})
+
+		Context("when field and label criteria are used", func() {
+			It("applies them on the deleted resources", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.ByField(query.EqualsOperator, "platform_id", "1")).
+					WithCriteria(query.ByLabel(query.EqualsOperator, "labelKey", "labelValue")).
+					ListDeleted(ctx, since)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(executedQuery).Should(ContainSubstring(trim(`
+SELECT visibilities.id AS id, MAX(visibilities.tombstone_deleted_at) AS deleted_at
+FROM visibilities
+	JOIN visibility_labels
+		ON visibilities.id = visibility_labels.visibility_id
+WHERE (visibilities.platform_id::text = ? AND (key::text = ? AND val::text = ?))
+GROUP BY 1
+ORDER BY 2, 1;`)))
+				Expect(queryArgs).To(Equal([]interface{}{since, since, "1", "labelKey", "labelValue"}))
+			})
+		})
+
+		Context("when result criteria are used", func() {
+			It("returns error", func() {
+				_, err := qb.NewQuery(entity).
+					WithCriteria(query.LimitResultBy(10)).
+					ListDeleted(ctx, since)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("result criteria are not supported when listing deleted resources"))
+			})
+		})
+	})
+
 	Describe("Delete", func() {
 		Context("when entity does not have an associated label entity", func() {
 			It("returns error", func() {
@@ -980,6 +1377,50 @@ WHERE visibilities.id = t.id RETURNING *;`)))
 		})
 	})
 
+	Describe("Update", func() {
+		BeforeEach(func() {
+			entity.ID = "1"
+			entity.ServicePlanID = "plan"
+		})
+
+		Context("when field criteria is used", func() {
+			It("updates only the entities matching the criteria", func() {
+				criteria := query.ByField(query.EqualsOperator, "id", "1")
+				_, err := qb.NewQuery(entity).WithCriteria(criteria).Update(ctx, entity)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(executedQuery).Should(Equal(trim(`
+UPDATE visibilities
+SET id = ?, created_at = ?, updated_at = ?, ready = ?, platform_id = ?, service_plan_id = ?
+WHERE visibilities.id::text = ? ;`)))
+				Expect(queryArgs).To(HaveLen(7))
+				Expect(queryArgs[0]).Should(Equal("1"))
+				Expect(queryArgs[5]).Should(Equal("plan"))
+				Expect(queryArgs[6]).Should(Equal("1"))
+			})
+		})
+
+		Context("when label criteria is used", func() {
+			It("updates only the entities with matching labels", func() {
+				criteria := query.ByLabel(query.EqualsOperator, "left", "right")
+				_, err := qb.NewQuery(entity).WithCriteria(criteria).Update(ctx, entity)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(executedQuery).Should(Equal(trim(`
+UPDATE visibilities
+SET id = ?, created_at = ?, updated_at = ?, ready = ?, platform_id = ?, service_plan_id = ?
+FROM (SELECT visibilities.id
+		FROM visibilities
+			JOIN visibility_labels ON visibilities.id = visibility_labels.visibility_id
+		WHERE (key::text = ? AND val::text = ?)) t
+WHERE visibilities.id = t.id ;`)))
+				Expect(queryArgs).To(HaveLen(8))
+				Expect(queryArgs[6]).Should(Equal("left"))
+				Expect(queryArgs[7]).Should(Equal("right"))
+			})
+		})
+	})
+
 	Describe("Query", func() {
 		Context("when query by missing label with params ", func() {
 			It("builds a valid query", func() {
@@ -1076,6 +1517,56 @@ ORDER BY service_instances.paging_sequence ASC ;`)))
 			})
 		})
 
+		Context("when query for json field with dot notation", func() {
+			It("builds a valid query", func() {
+				criteria := query.ByField(query.EqualsOperator, "context.organization_guid", "org")
+				_, err := qb.NewQuery(entity).WithCriteria(criteria).Count(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT service_instances.id)
+FROM service_instances
+WHERE service_instances.context->>'organization_guid' = ? ;`)))
+				Expect(queryArgs).To(HaveLen(1))
+				Expect(queryArgs[0]).Should(Equal("org"))
+			})
+		})
+
+		Context("when query for json field of non queryable column", func() {
+			It("returns error", func() {
+				criteria := query.ByField(query.EqualsOperator, "previous_values/name", "value")
+				_, err := qb.NewQuery(entity).WithCriteria(criteria).Count(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("json notation on non queryable column: previous_values"))
+			})
+		})
+
+		Context("when query for json field of entity without queryable columns", func() {
+			It("returns error", func() {
+				criteria := query.ByField(query.EqualsOperator, "context/platform", "value")
+				_, err := qb.NewQuery(&postgres.Operation{}).WithCriteria(criteria).Count(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("json notation on non queryable column: context"))
+			})
+		})
+
+		Context("when multivariate query for json field", func() {
+			It("builds a query matching both single values and arrays", func() {
+				criteria := query.ByField(query.InOperator, "metadata.supportedPlatforms", "cloudfoundry", "kubernetes")
+				_, err := qb.NewQuery(&postgres.ServicePlan{}).WithCriteria(criteria).Count(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(executedQuery).Should(Equal(trim(`
+SELECT COUNT(DISTINCT service_plans.id)
+FROM service_plans
+WHERE EXISTS (SELECT 1 FROM jsonb_array_elements_text(CASE jsonb_typeof((service_plans.metadata->'supportedPlatforms')::jsonb)
+	WHEN 'array' THEN (service_plans.metadata->'supportedPlatforms')::jsonb
+	ELSE jsonb_build_array((service_plans.metadata->'supportedPlatforms')::jsonb) END) AS element
+	WHERE element IN (?, ?)) ;`)))
+				Expect(queryArgs).To(HaveLen(2))
+				Expect(queryArgs[0]).Should(Equal("cloudfoundry"))
+				Expect(queryArgs[1]).Should(Equal("kubernetes"))
+			})
+		})
+
 		Context("when query for compound json field", func() {
 			It("builds a valid query", func() {
 				criteria := query.ByField(query.EqualsOperator, "context/a/b", "kubernetes")
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Peripli/service-manager/storage"
+	sqlxtypes "github.com/jmoiron/sqlx/types"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// ResourceEvent entity
+//go:generate smgen storage ResourceEvent github.com/Peripli/service-manager/pkg/types
+type ResourceEvent struct {
+	BaseEntity
+
+	Revision      int64              `db:"revision,auto_increment"`
+	Type          string             `db:"type"`
+	ResourceType  string             `db:"resource_type"`
+	ResourceID    string             `db:"resource_id"`
+	Resource      sqlxtypes.JSONText `db:"resource"`
+	CorrelationID sql.NullString     `db:"correlation_id"`
+}
+
+func (e *ResourceEvent) ToObject() (types.Object, error) {
+	return &types.ResourceEvent{
+		Base: types.Base{
+			ID:             e.ID,
+			CreatedAt:      e.CreatedAt,
+			UpdatedAt:      e.UpdatedAt,
+			Labels:         map[string][]string{},
+			PagingSequence: e.PagingSequence,
+			Ready:          e.Ready,
+		},
+		Revision:      e.Revision,
+		Type:          types.NotificationOperation(e.Type),
+		ResourceType:  types.ObjectType(e.ResourceType),
+		ResourceID:    e.ResourceID,
+		Resource:      getJSONRawMessage(e.Resource),
+		CorrelationID: e.CorrelationID.String,
+	}, nil
+}
+
+func (*ResourceEvent) FromObject(object types.Object) (storage.Entity, error) {
+	event, ok := object.(*types.ResourceEvent)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type ResourceEvent")
+	}
+
+	return &ResourceEvent{
+		BaseEntity: BaseEntity{
+			ID:             event.ID,
+			CreatedAt:      event.CreatedAt,
+			UpdatedAt:      event.UpdatedAt,
+			PagingSequence: event.PagingSequence,
+			Ready:          event.Ready,
+		},
+		Revision:      event.Revision, // the revision is assigned by the DB when the event is inserted
+		Type:          string(event.Type),
+		ResourceType:  string(event.ResourceType),
+		ResourceID:    event.ResourceID,
+		Resource:      getJSONText(event.Resource),
+		CorrelationID: toNullString(event.CorrelationID),
+	}, nil
+}
@@ -0,0 +1,72 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"database/sql"
+	"time"
+)
+
+var _ PostgresEntity = &ResourceEvent{}
+
+const ResourceEventTable = "resource_events"
+
+func (*ResourceEvent) LabelEntity() PostgresLabel {
+	return &ResourceEventLabel{}
+}
+
+func (*ResourceEvent) TableName() string {
+	return ResourceEventTable
+}
+
+func (e *ResourceEvent) NewLabel(id, entityID, key, value string) storage.Label {
+	now := pq.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	return &ResourceEventLabel{
+		BaseLabelEntity: BaseLabelEntity{
+			ID:        sql.NullString{String: id, Valid: id != ""},
+			Key:       sql.NullString{String: key, Valid: key != ""},
+			Val:       sql.NullString{String: value, Valid: value != ""},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		ResourceEventID: sql.NullString{String: entityID, Valid: entityID != ""},
+	}
+}
+
+func (e *ResourceEvent) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() EntityLabelRow {
+		return &struct {
+			*ResourceEvent
+			ResourceEventLabel `db:"resource_event_labels"`
+		}{}
+	}
+	result := &types.ResourceEvents{
+		ResourceEvents: make([]*types.ResourceEvent, 0),
+	}
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type ResourceEventLabel struct {
+	BaseLabelEntity
+	ResourceEventID sql.NullString `db:"resource_event_id"`
+}
+
+func (el ResourceEventLabel) LabelsTableName() string {
+	return "resource_event_labels"
+}
+
+func (el ResourceEventLabel) ReferenceColumn() string {
+	return "resource_event_id"
+}
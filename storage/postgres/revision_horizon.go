@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+const transactionHorizonQuery = `
+SELECT txid_snapshot_xmin(snapshot) AS xmin, txid_snapshot_xmax(snapshot) AS xmax
+FROM txid_current_snapshot() snapshot`
+
+// revisionSequences are the sequences assigning the revisions of the types. The revisions are assigned by triggers
+// which assign the transaction id of the inserting transaction before drawing the revision.
+var revisionSequences = map[types.ObjectType]string{
+	types.ResourceEventType: "resource_events_revision_seq",
+	types.NotificationType:  "notifications_revision_seq",
+}
+
+// revisionCheckpoint is a revision drawn before the transaction with id xmax started
+type revisionCheckpoint struct {
+	revision int64
+	xmax     int64
+}
+
+// revisionHorizon tracks the committed revision of a sequence
+type revisionHorizon struct {
+	committed int64
+	pending   []revisionCheckpoint
+}
+
+// CommittedRevision returns the revision up to which all the records of the given type are committed.
+//
+// Each call reads the last drawn revision and then the transaction ids of the current snapshot. The transaction of a
+// record with a revision up to the read one has been assigned an id lower than the xmax of the snapshot, so once all
+// the transactions before that xmax have finished, the revision is committed. Checkpoints which are not committed yet
+// are kept and become the horizon of a later call.
+//
+// The checkpoints are kept in the memory of each instance. After a restart, and on an instance which has not been
+// asked for the revision before, the horizon stays behind the committed records for as long as transactions which
+// started before the first call are running. Readers therefore have to treat records after the horizon as pending
+// rather than as missing.
+func (ps *Storage) CommittedRevision(ctx context.Context, objectType types.ObjectType) (int64, error) {
+	ps.checkOpen()
+	sequence, ok := revisionSequences[objectType]
+	if !ok {
+		return 0, fmt.Errorf("revisions of type %s are not supported", objectType)
+	}
+
+	var revision int64
+	if err := ps.pgDB.GetContext(ctx, &revision, fmt.Sprintf("SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM %s", sequence)); err != nil {
+		return 0, err
+	}
+	var snapshot struct {
+		Xmin int64 `db:"xmin"`
+		Xmax int64 `db:"xmax"`
+	}
+	if err := ps.pgDB.GetContext(ctx, &snapshot, transactionHorizonQuery); err != nil {
+		return 0, err
+	}
+
+	ps.horizonsMutex.Lock()
+	defer ps.horizonsMutex.Unlock()
+
+	if ps.horizons == nil {
+		ps.horizons = make(map[types.ObjectType]*revisionHorizon)
+	}
+	horizon, ok := ps.horizons[objectType]
+	if !ok {
+		horizon = &revisionHorizon{}
+		ps.horizons[objectType] = horizon
+	}
+	if count := len(horizon.pending); count == 0 || horizon.pending[count-1].revision < revision {
+		horizon.pending = append(horizon.pending, revisionCheckpoint{revision: revision, xmax: snapshot.Xmax})
+	}
+
+	pending := horizon.pending[:0]
+	for _, checkpoint := range horizon.pending {
+		if checkpoint.xmax > snapshot.Xmin {
+			pending = append(pending, checkpoint)
+		} else if checkpoint.revision > horizon.committed {
+			horizon.committed = checkpoint.revision
+		}
+	}
+	horizon.pending = pending
+
+	return horizon.committed, nil
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Revision horizon", func() {
+	var s *Storage
+	var mockdb *sql.DB
+	var mock sqlmock.Sqlmock
+
+	BeforeEach(func() {
+		var err error
+		mockdb, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		s = &Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return mockdb, nil
+			},
+		}
+
+		mock.ExpectQuery(`SELECT CURRENT_DATABASE()`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("mock"))
+		mock.ExpectQuery(`SELECT COUNT(1)*`).WillReturnRows(sqlmock.NewRows([]string{"mock"}).FromCSVString("1"))
+		mock.ExpectExec("SELECT pg_advisory_lock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).FromCSVString("20210513100000,false"))
+		mock.ExpectExec("SELECT pg_advisory_unlock*").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		options := storage.DefaultSettings()
+		options.EncryptionKey = "ejHjRNHbS0NaqARSRvnweVV9zcmhQEa8"
+		options.URI = "sqlmock://sqlmock"
+		err = s.Open(options)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).ToNot(HaveOccurred())
+		s.Close()
+	})
+
+	expectSequenceSnapshot := func(sequence string, lastRevision, xmin, xmax int64) {
+		mock.ExpectQuery("SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM " + sequence).
+			WillReturnRows(sqlmock.NewRows([]string{"last_value"}).FromCSVString(fmt.Sprint(lastRevision)))
+		mock.ExpectQuery("SELECT txid_snapshot_xmin").
+			WillReturnRows(sqlmock.NewRows([]string{"xmin", "xmax"}).FromCSVString(fmt.Sprintf("%d,%d", xmin, xmax)))
+	}
+
+	expectSnapshot := func(lastRevision, xmin, xmax int64) {
+		expectSequenceSnapshot("resource_events_revision_seq", lastRevision, xmin, xmax)
+	}
+
+	committedRevision := func() int64 {
+		revision, err := s.CommittedRevision(context.Background(), types.ResourceEventType)
+		Expect(err).ToNot(HaveOccurred())
+		return revision
+	}
+
+	Context("when no transactions are running", func() {
+		It("returns the last revision", func() {
+			expectSnapshot(5, 100, 100)
+			Expect(committedRevision()).To(Equal(int64(5)))
+		})
+	})
+
+	Context("when transactions are running", func() {
+		It("returns the last revision once the transactions started before reading it have finished", func() {
+			expectSnapshot(5, 90, 100)
+			Expect(committedRevision()).To(Equal(int64(0)))
+
+			expectSnapshot(7, 95, 105)
+			Expect(committedRevision()).To(Equal(int64(0)))
+
+			expectSnapshot(9, 100, 110)
+			Expect(committedRevision()).To(Equal(int64(5)))
+
+			expectSnapshot(9, 110, 110)
+			Expect(committedRevision()).To(Equal(int64(9)))
+		})
+	})
+
+	Context("when the revisions of several types are requested", func() {
+		It("tracks the committed revision of each type separately", func() {
+			expectSnapshot(5, 90, 100)
+			Expect(committedRevision()).To(Equal(int64(0)))
+
+			expectSequenceSnapshot("notifications_revision_seq", 42, 100, 100)
+			revision, err := s.CommittedRevision(context.Background(), types.NotificationType)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision).To(Equal(int64(42)))
+
+			expectSnapshot(5, 100, 100)
+			Expect(committedRevision()).To(Equal(int64(5)))
+		})
+	})
+
+	Context("when the revisions of the type are not assigned by a sequence", func() {
+		It("returns an error", func() {
+			_, err := s.CommittedRevision(context.Background(), types.PlatformType)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -42,6 +42,16 @@ type ServiceBinding struct {
 	Integrity         []byte                 `db:"integrity"`
 }
 
+// QueryableJSONColumns returns the JSON columns whose sub-fields can be used in field queries
+func (*ServiceBinding) QueryableJSONColumns() []string {
+	return []string{"context", "bind_resource"}
+}
+
+// SecuredColumns returns the integrity column, as the credentials are validated against it whenever they are selected
+func (*ServiceBinding) SecuredColumns() []string {
+	return []string{"credentials", "integrity"}
+}
+
 func (sb *ServiceBinding) ToObject() (types.Object, error) {
 	return &types.ServiceBinding{
 		Base: types.Base{
@@ -32,6 +32,7 @@ import (
 type ServiceInstance struct {
 	BaseEntity
 	Name            string             `db:"name"`
+	SearchVector    sql.NullString     `db:"search_vector,generated"`
 	ServicePlanID   string             `db:"service_plan_id"`
 	PlatformID      string             `db:"platform_id"`
 	DashboardURL    sql.NullString     `db:"dashboard_url"`
@@ -42,6 +43,11 @@ type ServiceInstance struct {
 	Usable          bool               `db:"usable"`
 }
 
+// QueryableJSONColumns returns the JSON columns whose sub-fields can be used in field queries
+func (*ServiceInstance) QueryableJSONColumns() []string {
+	return []string{"context", "maintenance_info"}
+}
+
 func (si *ServiceInstance) ToObject() (types.Object, error) {
 	var updateValues types.InstanceUpdateValues
 	if si.UpdateValues != nil {
@@ -17,6 +17,7 @@
 package postgres
 
 import (
+	"database/sql"
 	"fmt"
 
 	"github.com/Peripli/service-manager/storage"
@@ -28,8 +29,9 @@ import (
 //go:generate smgen storage ServiceOffering github.com/Peripli/service-manager/pkg/types
 type ServiceOffering struct {
 	BaseEntity
-	Name        string `db:"name"`
-	Description string `db:"description"`
+	Name         string         `db:"name"`
+	Description  string         `db:"description"`
+	SearchVector sql.NullString `db:"search_vector,generated"`
 
 	Bindable             bool   `db:"bindable"`
 	InstancesRetrievable bool   `db:"instances_retrievable"`
@@ -28,8 +28,9 @@ import (
 //go:generate smgen storage ServicePlan github.com/Peripli/service-manager/pkg/types
 type ServicePlan struct {
 	BaseEntity
-	Name        string `db:"name"`
-	Description string `db:"description"`
+	Name         string         `db:"name"`
+	Description  string         `db:"description"`
+	SearchVector sql.NullString `db:"search_vector,generated"`
 
 	Free          bool         `db:"free"`
 	Bindable      sql.NullBool `db:"bindable"`
@@ -45,6 +46,11 @@ type ServicePlan struct {
 	ServiceOfferingID string `db:"service_offering_id"`
 }
 
+// QueryableJSONColumns returns the JSON columns whose sub-fields can be used in field queries
+func (*ServicePlan) QueryableJSONColumns() []string {
+	return []string{"metadata", "maintenance_info"}
+}
+
 func (sp *ServicePlan) ToObject() (types.Object, error) {
 	return &types.ServicePlan{
 		Base: types.Base{
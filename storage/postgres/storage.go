@@ -55,6 +55,9 @@ type Storage struct {
 	layerOneEncryptionKey []byte
 	scheme                *scheme
 	mutex                 sync.Mutex
+
+	horizons      map[types.ObjectType]*revisionHorizon
+	horizonsMutex sync.Mutex
 }
 
 func (ps *Storage) Introduce(entity storage.Entity) {
@@ -124,6 +127,11 @@ func (ps *Storage) Open(settings *storage.Settings) error {
 		ps.scheme.introduce(&ServiceInstance{})
 		ps.scheme.introduce(&ServiceBinding{})
 		ps.scheme.introduce(&BrokerPlatformCredential{})
+		ps.scheme.introduce(&UpgradeCampaign{})
+		ps.scheme.introduce(&OperationEvent{})
+		ps.scheme.introduce(&ResourceEvent{})
+		ps.scheme.introduce(&Webhook{})
+		ps.scheme.introduce(&WebhookDelivery{})
 	}
 
 	return nil
@@ -169,6 +177,9 @@ func (ps *Storage) PingContext(ctx context.Context) error {
 }
 
 func (ps *Storage) Create(ctx context.Context, obj types.Object) (types.Object, error) {
+	// updated_at is the time of the write, so that it is close to the commit of the transaction
+	obj.SetUpdatedAt(time.Now().UTC().Truncate(time.Microsecond))
+
 	pgEntity, err := ps.scheme.convert(obj)
 	if err != nil {
 		return nil, err
@@ -408,6 +419,29 @@ func (ps *Storage) CountLabelValues(ctx context.Context, objType types.ObjectTyp
 	return ps.queryBuilder.NewQuery(entity).WithCriteria(criteria...).CountLabelValues(ctx)
 }
 
+func (ps *Storage) CountGroupedBy(ctx context.Context, objType types.ObjectType, groupBy string, criteria ...query.Criterion) ([]types.AggregationGroup, error) {
+	entity, err := ps.scheme.provide(objType)
+	if err != nil {
+		return nil, err
+	}
+	return ps.queryBuilder.NewQuery(entity).WithCriteria(criteria...).CountGroupedBy(ctx, groupBy)
+}
+
+func (ps *Storage) ListDeleted(ctx context.Context, objType types.ObjectType, since time.Time, criteria ...query.Criterion) ([]types.Deletion, error) {
+	entity, err := ps.scheme.provide(objType)
+	if err != nil {
+		return nil, err
+	}
+	return ps.queryBuilder.NewQuery(entity).WithCriteria(criteria...).ListDeleted(ctx, since)
+}
+
+// PruneTombstones deletes the records of the resources deleted before the given time
+func (ps *Storage) PruneTombstones(ctx context.Context, before time.Time) error {
+	ps.checkOpen()
+	_, err := ps.pgDB.ExecContext(ctx, "DELETE FROM tombstones WHERE deleted_at < $1", before)
+	return err
+}
+
 func (ps *Storage) DeleteReturning(ctx context.Context, objType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
 	entity, err := ps.scheme.provide(objType)
 	if err != nil {
@@ -464,14 +498,23 @@ func (ps *Storage) Delete(ctx context.Context, objType types.ObjectType, criteri
 	return checkRowsAffected(ctx, result)
 }
 
-func (ps *Storage) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, _ ...query.Criterion) (types.Object, error) {
-	obj.SetUpdatedAt(time.Now().UTC())
+func (ps *Storage) Update(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, criteria ...query.Criterion) (types.Object, error) {
+	if !storage.KeepsUpdatedAt(ctx) || obj.GetUpdatedAt().IsZero() {
+		// updated_at is stored with microsecond precision
+		obj.SetUpdatedAt(time.Now().UTC().Truncate(time.Microsecond))
+	}
 
 	entity, err := ps.scheme.convert(obj)
 	if err != nil {
 		return nil, err
 	}
-	if err = update(ctx, ps.pgDB, entity.TableName(), entity); err != nil {
+	if len(criteria) != 0 {
+		// the object may have been modified since it was fetched, so it is only updated if it still matches the criteria
+		err = ps.updateMatching(ctx, entity, criteria...)
+	} else {
+		err = update(ctx, ps.pgDB, entity.TableName(), entity)
+	}
+	if err != nil {
 		return nil, err
 	}
 	if err = ps.updateLabels(ctx, obj.GetType(), entity.GetID(), labelChanges); err != nil {
@@ -485,6 +528,24 @@ func (ps *Storage) Update(ctx context.Context, obj types.Object, labelChanges ty
 	return result, nil
 }
 
+// updateMatching updates the entity only if it still matches the criteria. The criteria are checked by the update
+// statement itself, so that concurrent modifications cannot happen between checking and updating the entity.
+func (ps *Storage) updateMatching(ctx context.Context, entity PostgresEntity, criteria ...query.Criterion) error {
+	byID := query.ByField(query.EqualsOperator, "id", entity.GetID())
+	result, err := ps.queryBuilder.NewQuery(entity).WithCriteria(append(criteria, byID)...).Update(ctx, entity)
+	if err = checkIntegrityViolation(ctx, checkUniqueViolation(ctx, err)); err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrConcurrentResourceModification
+	}
+	return nil
+}
+
 func (ps *Storage) UpdateLabels(ctx context.Context, objectType types.ObjectType, objectID string, labelChanges types.LabelChanges, _ ...query.Criterion) error {
 	return ps.updateLabels(ctx, objectType, objectID, labelChanges)
 }
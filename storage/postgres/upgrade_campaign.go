@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Peripli/service-manager/storage"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// UpgradeCampaign entity
+//go:generate smgen storage UpgradeCampaign github.com/Peripli/service-manager/pkg/types
+type UpgradeCampaign struct {
+	BaseEntity
+
+	Name        string         `db:"name"`
+	Description sql.NullString `db:"description"`
+
+	FieldQuery sql.NullString `db:"field_query"`
+	LabelQuery sql.NullString `db:"label_query"`
+
+	MaxConcurrency int    `db:"max_concurrency"`
+	State          string `db:"state"`
+}
+
+func (uc *UpgradeCampaign) ToObject() (types.Object, error) {
+	return &types.UpgradeCampaign{
+		Base: types.Base{
+			ID:             uc.ID,
+			CreatedAt:      uc.CreatedAt,
+			UpdatedAt:      uc.UpdatedAt,
+			Labels:         map[string][]string{},
+			PagingSequence: uc.PagingSequence,
+			Ready:          uc.Ready,
+		},
+		Name:           uc.Name,
+		Description:    uc.Description.String,
+		FieldQuery:     uc.FieldQuery.String,
+		LabelQuery:     uc.LabelQuery.String,
+		MaxConcurrency: uc.MaxConcurrency,
+		State:          types.UpgradeCampaignState(uc.State),
+	}, nil
+}
+
+func (*UpgradeCampaign) FromObject(object types.Object) (storage.Entity, error) {
+	campaign, ok := object.(*types.UpgradeCampaign)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type UpgradeCampaign")
+	}
+
+	return &UpgradeCampaign{
+		BaseEntity: BaseEntity{
+			ID:             campaign.ID,
+			CreatedAt:      campaign.CreatedAt,
+			UpdatedAt:      campaign.UpdatedAt,
+			PagingSequence: campaign.PagingSequence,
+			Ready:          campaign.Ready,
+		},
+		Name:           campaign.Name,
+		Description:    toNullString(campaign.Description),
+		FieldQuery:     toNullString(campaign.FieldQuery),
+		LabelQuery:     toNullString(campaign.LabelQuery),
+		MaxConcurrency: campaign.MaxConcurrency,
+		State:          string(campaign.State),
+	}, nil
+}
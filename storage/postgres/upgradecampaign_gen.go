@@ -0,0 +1,72 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"database/sql"
+	"time"
+)
+
+var _ PostgresEntity = &UpgradeCampaign{}
+
+const UpgradeCampaignTable = "upgrade_campaigns"
+
+func (*UpgradeCampaign) LabelEntity() PostgresLabel {
+	return &UpgradeCampaignLabel{}
+}
+
+func (*UpgradeCampaign) TableName() string {
+	return UpgradeCampaignTable
+}
+
+func (e *UpgradeCampaign) NewLabel(id, entityID, key, value string) storage.Label {
+	now := pq.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	return &UpgradeCampaignLabel{
+		BaseLabelEntity: BaseLabelEntity{
+			ID:        sql.NullString{String: id, Valid: id != ""},
+			Key:       sql.NullString{String: key, Valid: key != ""},
+			Val:       sql.NullString{String: value, Valid: value != ""},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		UpgradeCampaignID: sql.NullString{String: entityID, Valid: entityID != ""},
+	}
+}
+
+func (e *UpgradeCampaign) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() EntityLabelRow {
+		return &struct {
+			*UpgradeCampaign
+			UpgradeCampaignLabel `db:"upgrade_campaign_labels"`
+		}{}
+	}
+	result := &types.UpgradeCampaigns{
+		UpgradeCampaigns: make([]*types.UpgradeCampaign, 0),
+	}
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type UpgradeCampaignLabel struct {
+	BaseLabelEntity
+	UpgradeCampaignID sql.NullString `db:"upgrade_campaign_id"`
+}
+
+func (el UpgradeCampaignLabel) LabelsTableName() string {
+	return "upgrade_campaign_labels"
+}
+
+func (el UpgradeCampaignLabel) ReferenceColumn() string {
+	return "upgrade_campaign_id"
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Peripli/service-manager/storage"
+	sqlxtypes "github.com/jmoiron/sqlx/types"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// Webhook entity
+//go:generate smgen storage Webhook github.com/Peripli/service-manager/pkg/types
+type Webhook struct {
+	BaseEntity
+
+	Name           string             `db:"name"`
+	URL            string             `db:"url"`
+	Secret         string             `db:"secret"`
+	ResourceTypes  sqlxtypes.JSONText `db:"resource_types"`
+	OperationTypes sqlxtypes.JSONText `db:"operation_types"`
+	LabelQuery     sql.NullString     `db:"label_query"`
+
+	Disabled       bool           `db:"disabled"`
+	DisabledReason sql.NullString `db:"disabled_reason"`
+
+	LastRevision        int64     `db:"last_revision"`
+	ConsecutiveFailures int       `db:"consecutive_failures"`
+	NextAttemptAt       time.Time `db:"next_attempt_at"`
+}
+
+func (w *Webhook) ToObject() (types.Object, error) {
+	resourceTypes, err := toStrings(w.ResourceTypes)
+	if err != nil {
+		return nil, err
+	}
+	operationTypes, err := toStrings(w.OperationTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Webhook{
+		Base: types.Base{
+			ID:             w.ID,
+			CreatedAt:      w.CreatedAt,
+			UpdatedAt:      w.UpdatedAt,
+			Labels:         map[string][]string{},
+			PagingSequence: w.PagingSequence,
+			Ready:          w.Ready,
+		},
+		Name:                w.Name,
+		URL:                 w.URL,
+		Secret:              w.Secret,
+		ResourceTypes:       resourceTypes,
+		OperationTypes:      operationTypes,
+		LabelQuery:          w.LabelQuery.String,
+		Disabled:            w.Disabled,
+		DisabledReason:      w.DisabledReason.String,
+		LastRevision:        w.LastRevision,
+		ConsecutiveFailures: w.ConsecutiveFailures,
+		NextAttemptAt:       w.NextAttemptAt,
+	}, nil
+}
+
+func (*Webhook) FromObject(object types.Object) (storage.Entity, error) {
+	webhook, ok := object.(*types.Webhook)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type Webhook")
+	}
+
+	resourceTypes, err := fromStrings(webhook.ResourceTypes)
+	if err != nil {
+		return nil, err
+	}
+	operationTypes, err := fromStrings(webhook.OperationTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		BaseEntity: BaseEntity{
+			ID:             webhook.ID,
+			CreatedAt:      webhook.CreatedAt,
+			UpdatedAt:      webhook.UpdatedAt,
+			PagingSequence: webhook.PagingSequence,
+			Ready:          webhook.Ready,
+		},
+		Name:                webhook.Name,
+		URL:                 webhook.URL,
+		Secret:              webhook.Secret,
+		ResourceTypes:       resourceTypes,
+		OperationTypes:      operationTypes,
+		LabelQuery:          toNullString(webhook.LabelQuery),
+		Disabled:            webhook.Disabled,
+		DisabledReason:      toNullString(webhook.DisabledReason),
+		LastRevision:        webhook.LastRevision,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		NextAttemptAt:       webhook.NextAttemptAt,
+	}, nil
+}
+
+func toStrings(values sqlxtypes.JSONText) ([]string, error) {
+	var result []string
+	if len(values) != 0 {
+		if err := json.Unmarshal(values, &result); err != nil {
+			return nil, err
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func fromStrings(values []string) (sqlxtypes.JSONText, error) {
+	if values == nil {
+		values = make([]string, 0)
+	}
+	bytes, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return sqlxtypes.JSONText(bytes), nil
+}
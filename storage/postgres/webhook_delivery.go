@@ -0,0 +1,84 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Peripli/service-manager/storage"
+
+	"github.com/Peripli/service-manager/pkg/types"
+)
+
+// WebhookDelivery entity
+//go:generate smgen storage WebhookDelivery github.com/Peripli/service-manager/pkg/types
+type WebhookDelivery struct {
+	BaseEntity
+
+	WebhookID      string         `db:"webhook_id"`
+	NotificationID string         `db:"notification_id"`
+	Revision       int64          `db:"revision"`
+	Attempt        int            `db:"attempt"`
+	Succeeded      bool           `db:"succeeded"`
+	StatusCode     int            `db:"status_code"`
+	Error          sql.NullString `db:"error"`
+}
+
+func (d *WebhookDelivery) ToObject() (types.Object, error) {
+	return &types.WebhookDelivery{
+		Base: types.Base{
+			ID:             d.ID,
+			CreatedAt:      d.CreatedAt,
+			UpdatedAt:      d.UpdatedAt,
+			Labels:         map[string][]string{},
+			PagingSequence: d.PagingSequence,
+			Ready:          d.Ready,
+		},
+		WebhookID:      d.WebhookID,
+		NotificationID: d.NotificationID,
+		Revision:       d.Revision,
+		Attempt:        d.Attempt,
+		Succeeded:      d.Succeeded,
+		StatusCode:     d.StatusCode,
+		Error:          d.Error.String,
+	}, nil
+}
+
+func (*WebhookDelivery) FromObject(object types.Object) (storage.Entity, error) {
+	delivery, ok := object.(*types.WebhookDelivery)
+	if !ok {
+		return nil, fmt.Errorf("object is not of type WebhookDelivery")
+	}
+
+	return &WebhookDelivery{
+		BaseEntity: BaseEntity{
+			ID:             delivery.ID,
+			CreatedAt:      delivery.CreatedAt,
+			UpdatedAt:      delivery.UpdatedAt,
+			PagingSequence: delivery.PagingSequence,
+			Ready:          delivery.Ready,
+		},
+		WebhookID:      delivery.WebhookID,
+		NotificationID: delivery.NotificationID,
+		Revision:       delivery.Revision,
+		Attempt:        delivery.Attempt,
+		Succeeded:      delivery.Succeeded,
+		StatusCode:     delivery.StatusCode,
+		Error:          toNullString(delivery.Error),
+	}, nil
+}
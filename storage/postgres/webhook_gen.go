@@ -0,0 +1,72 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"database/sql"
+	"time"
+)
+
+var _ PostgresEntity = &Webhook{}
+
+const WebhookTable = "webhooks"
+
+func (*Webhook) LabelEntity() PostgresLabel {
+	return &WebhookLabel{}
+}
+
+func (*Webhook) TableName() string {
+	return WebhookTable
+}
+
+func (e *Webhook) NewLabel(id, entityID, key, value string) storage.Label {
+	now := pq.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	return &WebhookLabel{
+		BaseLabelEntity: BaseLabelEntity{
+			ID:        sql.NullString{String: id, Valid: id != ""},
+			Key:       sql.NullString{String: key, Valid: key != ""},
+			Val:       sql.NullString{String: value, Valid: value != ""},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		WebhookID: sql.NullString{String: entityID, Valid: entityID != ""},
+	}
+}
+
+func (e *Webhook) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() EntityLabelRow {
+		return &struct {
+			*Webhook
+			WebhookLabel `db:"webhook_labels"`
+		}{}
+	}
+	result := &types.Webhooks{
+		Webhooks: make([]*types.Webhook, 0),
+	}
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type WebhookLabel struct {
+	BaseLabelEntity
+	WebhookID sql.NullString `db:"webhook_id"`
+}
+
+func (el WebhookLabel) LabelsTableName() string {
+	return "webhook_labels"
+}
+
+func (el WebhookLabel) ReferenceColumn() string {
+	return "webhook_id"
+}
@@ -0,0 +1,72 @@
+// GENERATED. DO NOT MODIFY!
+
+package postgres
+
+import (
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"database/sql"
+	"time"
+)
+
+var _ PostgresEntity = &WebhookDelivery{}
+
+const WebhookDeliveryTable = "webhook_deliveries"
+
+func (*WebhookDelivery) LabelEntity() PostgresLabel {
+	return &WebhookDeliveryLabel{}
+}
+
+func (*WebhookDelivery) TableName() string {
+	return WebhookDeliveryTable
+}
+
+func (e *WebhookDelivery) NewLabel(id, entityID, key, value string) storage.Label {
+	now := pq.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+	return &WebhookDeliveryLabel{
+		BaseLabelEntity: BaseLabelEntity{
+			ID:        sql.NullString{String: id, Valid: id != ""},
+			Key:       sql.NullString{String: key, Valid: key != ""},
+			Val:       sql.NullString{String: value, Valid: value != ""},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		WebhookDeliveryID: sql.NullString{String: entityID, Valid: entityID != ""},
+	}
+}
+
+func (e *WebhookDelivery) RowsToList(rows *sqlx.Rows) (types.ObjectList, error) {
+	rowCreator := func() EntityLabelRow {
+		return &struct {
+			*WebhookDelivery
+			WebhookDeliveryLabel `db:"webhook_delivery_labels"`
+		}{}
+	}
+	result := &types.WebhookDeliveries{
+		WebhookDeliveries: make([]*types.WebhookDelivery, 0),
+	}
+	err := rowsToList(rows, rowCreator, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type WebhookDeliveryLabel struct {
+	BaseLabelEntity
+	WebhookDeliveryID sql.NullString `db:"webhook_delivery_id"`
+}
+
+func (el WebhookDeliveryLabel) LabelsTableName() string {
+	return "webhook_delivery_labels"
+}
+
+func (el WebhookDeliveryLabel) ReferenceColumn() string {
+	return "webhook_delivery_id"
+}
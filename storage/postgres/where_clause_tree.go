@@ -19,6 +19,7 @@ package postgres
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/Peripli/service-manager/pkg/query"
 )
@@ -27,12 +28,16 @@ type logicalOperator string
 
 const (
 	AND       logicalOperator = "AND"
+	OR        logicalOperator = "OR"
+	NOT       logicalOperator = "NOT"
 	INTERSECT logicalOperator = "INTERSECT"
 )
 
 // treeSqlBuilder is a helper struct to allow for dynamic changing of the function that builds the sql template statements
 type treeSqlBuilder struct {
 	buildSQL func(childrenSQL []string) string
+	// unary builders are applied even when the node has a single child
+	unary bool
 }
 
 var defaultTreeSqlBuilder = &treeSqlBuilder{
@@ -41,6 +46,26 @@ var defaultTreeSqlBuilder = &treeSqlBuilder{
 	},
 }
 
+var orTreeSqlBuilder = &treeSqlBuilder{
+	buildSQL: func(childrenSQL []string) string {
+		return fmt.Sprintf("(%s)", strings.Join(childrenSQL, fmt.Sprintf(" %s ", OR)))
+	},
+}
+
+var notTreeSqlBuilder = &treeSqlBuilder{
+	buildSQL: func(childrenSQL []string) string {
+		return fmt.Sprintf("(%s %s)", NOT, childrenSQL[0])
+	},
+	unary: true,
+}
+
+// logicalTreeSqlBuilders maps the logical query operators to the builders joining their operands
+var logicalTreeSqlBuilders = map[query.Operator]*treeSqlBuilder{
+	query.AndOperator: defaultTreeSqlBuilder,
+	query.OrOperator:  orTreeSqlBuilder,
+	query.NotOperator: notTreeSqlBuilder,
+}
+
 // whereClauseTree represents an sql where clause as tree structure with AND/OR on the nodes
 type whereClauseTree struct {
 	criterion query.Criterion
@@ -84,7 +109,11 @@ func (t *whereClauseTree) compileSQL() (string, []interface{}) {
 	case 0:
 		sql = ""
 	case 1:
-		sql = childrenSQL[0]
+		if t.sqlBuilder != nil && t.sqlBuilder.unary {
+			sql = t.sqlBuilder.buildSQL(childrenSQL)
+		} else {
+			sql = childrenSQL[0]
+		}
 	default:
 		if t.sqlBuilder == nil {
 			t.sqlBuilder = defaultTreeSqlBuilder
@@ -101,8 +130,18 @@ func criterionSQL(c query.Criterion, dbTags []tagType, tableAlias string) (strin
 	column := strings.Split(c.LeftOp, "/")[0]
 	ttype := findTagType(dbTags, column)
 	dbCast := determineCastByType(ttype)
+	switch c.Operator {
+	case query.TextSearchOperator:
+		dbCast = ""
+	case query.ContainsOperator, query.StartsWithOperator, query.MatchesOperator:
+		// the patterns are matched against the text of the column, including numbers and timestamps
+		dbCast = "::text"
+	}
 	if ttype == jsonType {
 		var isCompound bool
+		if c.Operator.Type() == query.MultivariateOperator && strings.Contains(c.LeftOp, "/") {
+			return jsonElementsSQL(c, tableAlias, sqlOperation, rightOpBindVar), rightOpQueryValue
+		}
 		c.LeftOp, isCompound = convertToJsonKey(c.LeftOp)
 		if isCompound {
 			dbCast = ""
@@ -111,10 +150,16 @@ func criterionSQL(c query.Criterion, dbTags []tagType, tableAlias string) (strin
 	var clause string
 	if c.Type == query.ExistQuery {
 		clause = fmt.Sprintf("%s (%s)", sqlOperation, rightOpQueryValue.(string))
-	} else if tableAlias != "" {
-		clause = fmt.Sprintf("%s.%s%s %s %s", tableAlias, c.LeftOp, dbCast, sqlOperation, rightOpBindVar)
 	} else {
-		clause = fmt.Sprintf("%s%s %s %s", c.LeftOp, dbCast, sqlOperation, rightOpBindVar)
+		leftOp := c.LeftOp + dbCast
+		if tableAlias != "" {
+			leftOp = fmt.Sprintf("%s.%s", tableAlias, leftOp)
+		}
+		if c.Operator == query.StartsWithOperator {
+			// the prefix is compared in lower case, so that indices on the lower case values with text_pattern_ops can be used
+			leftOp = fmt.Sprintf("lower(%s)", leftOp)
+		}
+		clause = fmt.Sprintf("%s %s %s", leftOp, sqlOperation, rightOpBindVar)
 	}
 	if c.Operator.IsNullable() {
 		clause = fmt.Sprintf("(%s OR %s IS NULL)", clause, c.LeftOp)
@@ -129,25 +174,74 @@ func convertToJsonKey(key string) (string, bool) {
 	} else {
 		result := columnParts[0]
 		for i := 1; i < len(columnParts)-1; i++ {
-			result += fmt.Sprintf("%s'%s'", "->", columnParts[i])
+			result += fmt.Sprintf("%s'%s'", "->", escapeJsonKey(columnParts[i]))
 		}
-		result += fmt.Sprintf("%s'%s'", "->>", columnParts[len(columnParts)-1])
+		result += fmt.Sprintf("%s'%s'", "->>", escapeJsonKey(columnParts[len(columnParts)-1]))
 		return result, true
 	}
 }
 
+// jsonElementsSQL builds a multivariate clause on a JSON sub-field which matches both single values and arrays of values
+func jsonElementsSQL(c query.Criterion, tableAlias, sqlOperation, rightOpBindVar string) string {
+	columnParts := strings.Split(c.LeftOp, "/")
+	field := columnParts[0]
+	if tableAlias != "" {
+		field = fmt.Sprintf("%s.%s", tableAlias, field)
+	}
+	for _, part := range columnParts[1:] {
+		field += fmt.Sprintf("->'%s'", escapeJsonKey(part))
+	}
+	field = fmt.Sprintf("(%s)::jsonb", field)
+	existence := "EXISTS"
+	if c.Operator == query.NotInOperator {
+		existence = "NOT EXISTS"
+		sqlOperation = "IN"
+	}
+	return fmt.Sprintf("%s (SELECT 1 FROM jsonb_array_elements_text(CASE jsonb_typeof(%s) WHEN 'array' THEN %s ELSE jsonb_build_array(%s) END) AS element WHERE element %s %s)",
+		existence, field, field, field, sqlOperation, rightOpBindVar)
+}
+
+func escapeJsonKey(key string) string {
+	return strings.Replace(key, "'", "''", -1)
+}
+
+// likeEscaper escapes the LIKE wildcards so that the pattern operators match the provided value literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func buildRightOp(operator query.Operator, rightOp []string) (string, interface{}) {
 	rightOpBindVar := "?"
 	var rhs interface{}
-	if operator.Type() == query.MultivariateOperator {
+	switch {
+	case operator.Type() == query.MultivariateOperator:
 		rightOpBindVar = "(?)"
 		rhs = rightOp
-	} else {
+	case operator == query.ContainsOperator:
+		rhs = "%" + likeEscaper.Replace(rightOp[0]) + "%"
+	case operator == query.StartsWithOperator:
+		// the pattern is anchored at the beginning, so that indices with text_pattern_ops can be used
+		rightOpBindVar = "lower(?)"
+		rhs = likeEscaper.Replace(rightOp[0]) + "%"
+	case operator == query.TextSearchOperator:
+		rightOpBindVar = "to_tsquery('simple', ?)"
+		rhs = prefixTextSearchQuery(rightOp[0])
+	default:
 		rhs = rightOp[0]
 	}
 	return rightOpBindVar, rhs
 }
 
+// prefixTextSearchQuery builds a text search query matching the words starting with each of the words in the given text.
+// Only letters and digits are kept, so that the text cannot contain text search query operators.
+func prefixTextSearchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i := range words {
+		words[i] += ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
 func translateOperationToSQLEquivalent(operator query.Operator) string {
 	switch operator {
 	case query.LessThanOperator:
@@ -170,6 +264,14 @@ func translateOperationToSQLEquivalent(operator query.Operator) string {
 		return "="
 	case query.NotEqualsOperator:
 		return "!="
+	case query.ContainsOperator:
+		return "ILIKE"
+	case query.StartsWithOperator:
+		return "LIKE"
+	case query.MatchesOperator:
+		return "~*"
+	case query.TextSearchOperator:
+		return "@@"
 	default:
 		return strings.ToUpper(operator.String())
 	}
@@ -10,11 +10,12 @@ import (
 )
 
 type FakeNotificator struct {
-	RegisterConsumerStub        func(*types.Platform, int64) (storage.NotificationQueue, int64, error)
+	RegisterConsumerStub        func(*types.Platform, int64, storage.NotificationFilter) (storage.NotificationQueue, int64, error)
 	registerConsumerMutex       sync.RWMutex
 	registerConsumerArgsForCall []struct {
 		arg1 *types.Platform
 		arg2 int64
+		arg3 storage.NotificationFilter
 	}
 	registerConsumerReturns struct {
 		result1 storage.NotificationQueue
@@ -58,17 +59,18 @@ type FakeNotificator struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeNotificator) RegisterConsumer(arg1 *types.Platform, arg2 int64) (storage.NotificationQueue, int64, error) {
+func (fake *FakeNotificator) RegisterConsumer(arg1 *types.Platform, arg2 int64, arg3 storage.NotificationFilter) (storage.NotificationQueue, int64, error) {
 	fake.registerConsumerMutex.Lock()
 	ret, specificReturn := fake.registerConsumerReturnsOnCall[len(fake.registerConsumerArgsForCall)]
 	fake.registerConsumerArgsForCall = append(fake.registerConsumerArgsForCall, struct {
 		arg1 *types.Platform
 		arg2 int64
-	}{arg1, arg2})
-	fake.recordInvocation("RegisterConsumer", []interface{}{arg1, arg2})
+		arg3 storage.NotificationFilter
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("RegisterConsumer", []interface{}{arg1, arg2, arg3})
 	fake.registerConsumerMutex.Unlock()
 	if fake.RegisterConsumerStub != nil {
-		return fake.RegisterConsumerStub(arg1, arg2)
+		return fake.RegisterConsumerStub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -83,17 +85,17 @@ func (fake *FakeNotificator) RegisterConsumerCallCount() int {
 	return len(fake.registerConsumerArgsForCall)
 }
 
-func (fake *FakeNotificator) RegisterConsumerCalls(stub func(*types.Platform, int64) (storage.NotificationQueue, int64, error)) {
+func (fake *FakeNotificator) RegisterConsumerCalls(stub func(*types.Platform, int64, storage.NotificationFilter) (storage.NotificationQueue, int64, error)) {
 	fake.registerConsumerMutex.Lock()
 	defer fake.registerConsumerMutex.Unlock()
 	fake.RegisterConsumerStub = stub
 }
 
-func (fake *FakeNotificator) RegisterConsumerArgsForCall(i int) (*types.Platform, int64) {
+func (fake *FakeNotificator) RegisterConsumerArgsForCall(i int) (*types.Platform, int64, storage.NotificationFilter) {
 	fake.registerConsumerMutex.RLock()
 	defer fake.registerConsumerMutex.RUnlock()
 	argsForCall := fake.registerConsumerArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeNotificator) RegisterConsumerReturns(result1 storage.NotificationQueue, result2 int64, result3 error) {
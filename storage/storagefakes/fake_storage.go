@@ -4,6 +4,7 @@ package storagefakes
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/types"
@@ -36,6 +37,22 @@ type FakeStorage struct {
 		result1 int
 		result2 error
 	}
+	CountGroupedByStub        func(context.Context, types.ObjectType, string, ...query.Criterion) ([]types.AggregationGroup, error)
+	countGroupedByMutex       sync.RWMutex
+	countGroupedByArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 string
+		arg4 []query.Criterion
+	}
+	countGroupedByReturns struct {
+		result1 []types.AggregationGroup
+		result2 error
+	}
+	countGroupedByReturnsOnCall map[int]struct {
+		result1 []types.AggregationGroup
+		result2 error
+	}
 	CountLabelValuesStub        func(context.Context, types.ObjectType, ...query.Criterion) (int, error)
 	countLabelValuesMutex       sync.RWMutex
 	countLabelValuesArgsForCall []struct {
@@ -165,6 +182,22 @@ type FakeStorage struct {
 		result1 types.ObjectList
 		result2 error
 	}
+	ListDeletedStub        func(context.Context, types.ObjectType, time.Time, ...query.Criterion) ([]types.Deletion, error)
+	listDeletedMutex       sync.RWMutex
+	listDeletedArgsForCall []struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 time.Time
+		arg4 []query.Criterion
+	}
+	listDeletedReturns struct {
+		result1 []types.Deletion
+		result2 error
+	}
+	listDeletedReturnsOnCall map[int]struct {
+		result1 []types.Deletion
+		result2 error
+	}
 	ListNoLabelsStub        func(context.Context, types.ObjectType, ...query.Criterion) (types.ObjectList, error)
 	listNoLabelsMutex       sync.RWMutex
 	listNoLabelsArgsForCall []struct {
@@ -370,6 +403,72 @@ func (fake *FakeStorage) CountReturnsOnCall(i int, result1 int, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) CountGroupedBy(arg1 context.Context, arg2 types.ObjectType, arg3 string, arg4 ...query.Criterion) ([]types.AggregationGroup, error) {
+	fake.countGroupedByMutex.Lock()
+	ret, specificReturn := fake.countGroupedByReturnsOnCall[len(fake.countGroupedByArgsForCall)]
+	fake.countGroupedByArgsForCall = append(fake.countGroupedByArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 string
+		arg4 []query.Criterion
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("CountGroupedBy", []interface{}{arg1, arg2, arg3, arg4})
+	fake.countGroupedByMutex.Unlock()
+	if fake.CountGroupedByStub != nil {
+		return fake.CountGroupedByStub(arg1, arg2, arg3, arg4...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.countGroupedByReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) CountGroupedByCallCount() int {
+	fake.countGroupedByMutex.RLock()
+	defer fake.countGroupedByMutex.RUnlock()
+	return len(fake.countGroupedByArgsForCall)
+}
+
+func (fake *FakeStorage) CountGroupedByCalls(stub func(context.Context, types.ObjectType, string, ...query.Criterion) ([]types.AggregationGroup, error)) {
+	fake.countGroupedByMutex.Lock()
+	defer fake.countGroupedByMutex.Unlock()
+	fake.CountGroupedByStub = stub
+}
+
+func (fake *FakeStorage) CountGroupedByArgsForCall(i int) (context.Context, types.ObjectType, string, []query.Criterion) {
+	fake.countGroupedByMutex.RLock()
+	defer fake.countGroupedByMutex.RUnlock()
+	argsForCall := fake.countGroupedByArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeStorage) CountGroupedByReturns(result1 []types.AggregationGroup, result2 error) {
+	fake.countGroupedByMutex.Lock()
+	defer fake.countGroupedByMutex.Unlock()
+	fake.CountGroupedByStub = nil
+	fake.countGroupedByReturns = struct {
+		result1 []types.AggregationGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) CountGroupedByReturnsOnCall(i int, result1 []types.AggregationGroup, result2 error) {
+	fake.countGroupedByMutex.Lock()
+	defer fake.countGroupedByMutex.Unlock()
+	fake.CountGroupedByStub = nil
+	if fake.countGroupedByReturnsOnCall == nil {
+		fake.countGroupedByReturnsOnCall = make(map[int]struct {
+			result1 []types.AggregationGroup
+			result2 error
+		})
+	}
+	fake.countGroupedByReturnsOnCall[i] = struct {
+		result1 []types.AggregationGroup
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) CountLabelValues(arg1 context.Context, arg2 types.ObjectType, arg3 ...query.Criterion) (int, error) {
 	fake.countLabelValuesMutex.Lock()
 	ret, specificReturn := fake.countLabelValuesReturnsOnCall[len(fake.countLabelValuesArgsForCall)]
@@ -965,6 +1064,72 @@ func (fake *FakeStorage) ListReturnsOnCall(i int, result1 types.ObjectList, resu
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) ListDeleted(arg1 context.Context, arg2 types.ObjectType, arg3 time.Time, arg4 ...query.Criterion) ([]types.Deletion, error) {
+	fake.listDeletedMutex.Lock()
+	ret, specificReturn := fake.listDeletedReturnsOnCall[len(fake.listDeletedArgsForCall)]
+	fake.listDeletedArgsForCall = append(fake.listDeletedArgsForCall, struct {
+		arg1 context.Context
+		arg2 types.ObjectType
+		arg3 time.Time
+		arg4 []query.Criterion
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("ListDeleted", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listDeletedMutex.Unlock()
+	if fake.ListDeletedStub != nil {
+		return fake.ListDeletedStub(arg1, arg2, arg3, arg4...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listDeletedReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) ListDeletedCallCount() int {
+	fake.listDeletedMutex.RLock()
+	defer fake.listDeletedMutex.RUnlock()
+	return len(fake.listDeletedArgsForCall)
+}
+
+func (fake *FakeStorage) ListDeletedCalls(stub func(context.Context, types.ObjectType, time.Time, ...query.Criterion) ([]types.Deletion, error)) {
+	fake.listDeletedMutex.Lock()
+	defer fake.listDeletedMutex.Unlock()
+	fake.ListDeletedStub = stub
+}
+
+func (fake *FakeStorage) ListDeletedArgsForCall(i int) (context.Context, types.ObjectType, time.Time, []query.Criterion) {
+	fake.listDeletedMutex.RLock()
+	defer fake.listDeletedMutex.RUnlock()
+	argsForCall := fake.listDeletedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeStorage) ListDeletedReturns(result1 []types.Deletion, result2 error) {
+	fake.listDeletedMutex.Lock()
+	defer fake.listDeletedMutex.Unlock()
+	fake.ListDeletedStub = nil
+	fake.listDeletedReturns = struct {
+		result1 []types.Deletion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) ListDeletedReturnsOnCall(i int, result1 []types.Deletion, result2 error) {
+	fake.listDeletedMutex.Lock()
+	defer fake.listDeletedMutex.Unlock()
+	fake.ListDeletedStub = nil
+	if fake.listDeletedReturnsOnCall == nil {
+		fake.listDeletedReturnsOnCall = make(map[int]struct {
+			result1 []types.Deletion
+			result2 error
+		})
+	}
+	fake.listDeletedReturnsOnCall[i] = struct {
+		result1 []types.Deletion
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) ListNoLabels(arg1 context.Context, arg2 types.ObjectType, arg3 ...query.Criterion) (types.ObjectList, error) {
 	fake.listNoLabelsMutex.Lock()
 	ret, specificReturn := fake.listNoLabelsReturnsOnCall[len(fake.listNoLabelsArgsForCall)]
@@ -1353,6 +1518,8 @@ func (fake *FakeStorage) Invocations() map[string][][]interface{} {
 	defer fake.closeMutex.RUnlock()
 	fake.countMutex.RLock()
 	defer fake.countMutex.RUnlock()
+	fake.countGroupedByMutex.RLock()
+	defer fake.countGroupedByMutex.RUnlock()
 	fake.countLabelValuesMutex.RLock()
 	defer fake.countLabelValuesMutex.RUnlock()
 	fake.createMutex.RLock()
@@ -1373,6 +1540,8 @@ func (fake *FakeStorage) Invocations() map[string][][]interface{} {
 	defer fake.introduceMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listDeletedMutex.RLock()
+	defer fake.listDeletedMutex.RUnlock()
 	fake.listNoLabelsMutex.RLock()
 	defer fake.listNoLabelsMutex.RUnlock()
 	fake.openMutex.RLock()
@@ -9,6 +9,8 @@ const (
 	QueryForOperationsWithResource
 	QueryForTenantScopedServiceOfferings
 	QueryForInstanceChildrenByLabel
+	QueryForOperationsWithUnleasedJob
+	QueryForOperationsWithJob
 )
 
 // The sub-queries are dedicated to be used with ByExists/ByNotExists Criterion to allow additional querying/filtering
@@ -51,6 +53,13 @@ var subQueries = map[SubQuery]string{
 		SELECT 1 FROM service_instances i
         INNER JOIN service_instance_labels l ON i.id = l.service_instance_id
 		WHERE  l.key IN ({{.PARENT_KEYS}}) AND l.val = '{{.PARENT_ID}}' AND i.id = service_instances.id`,
+	QueryForOperationsWithUnleasedJob: `
+	SELECT 1 FROM operation_jobs
+	WHERE operation_jobs.operation_id = operations.id
+	AND (operation_jobs.lease_expires_at IS NULL OR operation_jobs.lease_expires_at < (now() AT TIME ZONE 'UTC'))`,
+	QueryForOperationsWithJob: `
+	SELECT 1 FROM operation_jobs
+	WHERE operation_jobs.operation_id = operations.id`,
 }
 
 func GetSubQuery(query SubQuery) string {
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bulk_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type object = common.Object
+type array = common.Array
+
+func TestBulk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bulk Tests Suite")
+}
+
+var _ = Describe("Bulk", func() {
+	var ctx *common.TestContext
+	bulkURL := web.PlatformsURL + web.ResourceBulkURL
+
+	BeforeSuite(func() {
+		ctx = common.NewTestContextBuilder().Build()
+	})
+
+	AfterSuite(func() {
+		if ctx != nil {
+			ctx.Cleanup()
+		}
+	})
+
+	AfterEach(func() {
+		ctx.CleanupAdditionalResources()
+	})
+
+	createPlatform := func(id string) {
+		ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(common.MakePlatform(id, id, "kubernetes", "bulk platform")).
+			Expect().Status(http.StatusCreated)
+	}
+
+	It("returns 400 when there are no items", func() {
+		ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{"items": array{}}).
+			Expect().Status(http.StatusBadRequest)
+	})
+
+	It("returns 400 for unsupported operations in atomic mode", func() {
+		ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+			"atomic": true,
+			"items":  array{object{"op": "upsert", "id": "bulk-platform-1"}},
+		}).Expect().Status(http.StatusBadRequest)
+	})
+
+	Context("best effort", func() {
+		It("executes each item on its own and returns the result of each item", func() {
+			createPlatform("bulk-platform-1")
+			createPlatform("bulk-platform-2")
+
+			resp := ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+				"items": array{
+					object{"op": "create", "resource": common.MakePlatform("bulk-platform-3", "bulk-platform-3", "kubernetes", "")},
+					object{"op": "patch", "id": "bulk-platform-1", "resource": object{
+						"description": "patched",
+						"labels":      array{object{"op": "add", "key": "tenant", "values": array{"t1"}}},
+					}},
+					object{"op": "delete", "id": "bulk-platform-2"},
+					object{"op": "delete", "id": "missing-platform"},
+				},
+			}).Expect().Status(http.StatusOK).JSON().Object()
+
+			items := resp.Value("items").Array()
+			items.Length().Equal(4)
+			items.Element(0).Object().ValueEqual("status", http.StatusCreated)
+			items.Element(0).Object().Value("resource").Object().ValueEqual("id", "bulk-platform-3")
+			items.Element(1).Object().ValueEqual("status", http.StatusOK)
+			items.Element(1).Object().Value("resource").Object().ValueEqual("description", "patched")
+			items.Element(2).Object().ValueEqual("status", http.StatusOK)
+			items.Element(3).Object().ValueEqual("status", http.StatusNotFound)
+			items.Element(3).Object().Value("error").Object().ValueEqual("error", "NotFound")
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-3").Expect().Status(http.StatusOK)
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-2").Expect().Status(http.StatusNotFound)
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-1").Expect().Status(http.StatusOK).
+				JSON().Object().Value("labels").Object().Value("tenant").Array().Contains("t1")
+		})
+
+		It("applies the filters of the single resource endpoints to each item", func() {
+			resp := ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+				"items": array{object{"op": "delete", "id": types.SMPlatform}},
+			}).Expect().Status(http.StatusOK).JSON().Object()
+
+			resp.Path("$.items[0].status").Equal(http.StatusNotFound)
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + types.SMPlatform).Expect().Status(http.StatusOK)
+		})
+	})
+
+	Context("atomic", func() {
+		It("stores all items", func() {
+			createPlatform("bulk-platform-1")
+
+			ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+				"atomic": true,
+				"items": array{
+					object{"op": "create", "resource": common.MakePlatform("bulk-platform-2", "bulk-platform-2", "kubernetes", "")},
+					object{"op": "delete", "id": "bulk-platform-1"},
+				},
+			}).Expect().Status(http.StatusOK).JSON().Path("$.items[*].status").Array().Equal(array{http.StatusCreated, http.StatusOK})
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-2").Expect().Status(http.StatusOK)
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-1").Expect().Status(http.StatusNotFound)
+		})
+
+		It("executes items referring to the items before them", func() {
+			ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+				"atomic": true,
+				"items": array{
+					object{"op": "create", "resource": common.MakePlatform("bulk-platform-1", "bulk-platform-1", "kubernetes", "")},
+					object{"op": "patch", "id": "bulk-platform-1", "resource": object{"description": "patched"}},
+				},
+			}).Expect().Status(http.StatusOK).JSON().Path("$.items[*].status").Array().Equal(array{http.StatusCreated, http.StatusOK})
+
+			platform := ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-1").Expect().Status(http.StatusOK).JSON().Object()
+			platform.ValueEqual("description", "patched")
+			platform.ValueEqual("ready", true)
+		})
+
+		It("does not take the readiness of created items from the request", func() {
+			platform := common.MakePlatform("bulk-platform-1", "bulk-platform-1", "kubernetes", "")
+			platform["ready"] = false
+			ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+				"atomic": true,
+				"items":  array{object{"op": "create", "resource": platform}},
+			}).Expect().Status(http.StatusOK).JSON().Path("$.items[0].resource.ready").Equal(true)
+		})
+
+		It("stores none of the items when one of them fails", func() {
+			createPlatform("bulk-platform-1")
+
+			ctx.SMWithOAuth.POST(bulkURL).WithJSON(object{
+				"atomic": true,
+				"items": array{
+					object{"op": "create", "resource": common.MakePlatform("bulk-platform-2", "bulk-platform-2", "kubernetes", "")},
+					object{"op": "delete", "id": "bulk-platform-1"},
+					object{"op": "create", "resource": common.MakePlatform("bulk-platform-2", "bulk-platform-3", "kubernetes", "")},
+				},
+			}).Expect().Status(http.StatusConflict).JSON().Object().Value("description").String().Contains("bulk item 2")
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-2").Expect().Status(http.StatusNotFound)
+			ctx.SMWithOAuth.GET(web.PlatformsURL + "/bulk-platform-1").Expect().Status(http.StatusOK)
+		})
+	})
+})
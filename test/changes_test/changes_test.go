@@ -0,0 +1,274 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test"
+	"github.com/Peripli/service-manager/test/common"
+	"github.com/spf13/pflag"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type object = common.Object
+
+const lastModifiedSafetyWindow = time.Second
+
+func TestChanges(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Changes Tests Suite")
+}
+
+var _ = Describe("Changes", func() {
+	var ctx *common.TestContext
+
+	BeforeSuite(func() {
+		ctx = common.NewTestContextBuilder().WithEnvPreExtensions(func(set *pflag.FlagSet) {
+			Expect(set.Set("api.last_modified_safety_window", lastModifiedSafetyWindow.String())).ToNot(HaveOccurred())
+		}).Build()
+	})
+
+	AfterSuite(func() {
+		if ctx != nil {
+			ctx.Cleanup()
+		}
+	})
+
+	AfterEach(func() {
+		ctx.CleanupAdditionalResources()
+	})
+
+	createPlatform := func(id string) {
+		ctx.SMWithOAuth.POST(web.PlatformsURL).WithJSON(common.MakePlatform(id, id, "kubernetes", "polled platform")).
+			Expect().Status(http.StatusCreated)
+	}
+
+	patchPlatform := func(id string) {
+		ctx.SMWithOAuth.PATCH(web.PlatformsURL + "/" + id).WithJSON(object{"description": "changed"}).
+			Expect().Status(http.StatusOK)
+	}
+
+	Describe("conditional get", func() {
+		BeforeEach(func() {
+			createPlatform("polled-platform")
+		})
+
+		It("returns 304 while the ETag is current", func() {
+			etag := ctx.SMWithOAuth.GET(web.PlatformsURL + "/polled-platform").
+				Expect().Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL+"/polled-platform").WithHeader("If-None-Match", etag).
+				Expect().Status(http.StatusNotModified).Header("ETag").Equal(etag)
+
+			patchPlatform("polled-platform")
+			ctx.SMWithOAuth.GET(web.PlatformsURL+"/polled-platform").WithHeader("If-None-Match", etag).
+				Expect().Status(http.StatusOK)
+		})
+
+		It("returns 304 when not modified since the given time", func() {
+			lastModified := ctx.SMWithOAuth.GET(web.PlatformsURL + "/polled-platform").
+				Expect().Status(http.StatusOK).Header("Last-Modified").NotEmpty().Raw()
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL+"/polled-platform").WithHeader("If-Modified-Since", lastModified).
+				Expect().Status(http.StatusNotModified)
+
+			time.Sleep(time.Second)
+			patchPlatform("polled-platform")
+			ctx.SMWithOAuth.GET(web.PlatformsURL+"/polled-platform").WithHeader("If-Modified-Since", lastModified).
+				Expect().Status(http.StatusOK)
+		})
+	})
+
+	Describe("conditional get of resources with operations", func() {
+		var (
+			brokerServer *common.BrokerServer
+			instanceID   string
+		)
+
+		BeforeEach(func() {
+			brokerUtils := ctx.RegisterBroker()
+			brokerServer = brokerUtils.Broker.BrokerServer
+			ctx.Servers[common.BrokerServerPrefix+brokerUtils.Broker.ID] = brokerServer
+			offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, "fieldQuery=broker_id eq '"+brokerUtils.Broker.ID+"'").
+				First().Object().Value("id").String().Raw()
+			planID := ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, "fieldQuery=service_offering_id eq '"+offeringID+"'").
+				First().Object().Value("id").String().Raw()
+			test.EnsurePublicPlanVisibility(ctx.SMRepository, planID)
+
+			instanceID = ctx.SMWithOAuth.POST(web.ServiceInstancesURL).
+				WithQuery("async", false).
+				WithJSON(object{"name": "polled-instance", "service_plan_id": planID}).
+				Expect().Status(http.StatusCreated).
+				JSON().Object().Value("id").String().Raw()
+		})
+
+		It("returns 200 once the last operation of the resource changed", func() {
+			instanceURL := web.ServiceInstancesURL + "/" + instanceID
+			etag := ctx.SMWithOAuth.GET(instanceURL).
+				Expect().Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+
+			brokerServer.ServiceInstanceHandlerFunc(http.MethodPatch, http.MethodPatch+"1", common.ParameterizedHandler(http.StatusBadRequest, object{"error": "error"}))
+			ctx.SMWithOAuth.PATCH(instanceURL).WithQuery("async", false).WithJSON(object{}).
+				Expect().Status(http.StatusBadRequest)
+
+			resp := ctx.SMWithOAuth.GET(instanceURL).WithHeader("If-None-Match", etag).
+				Expect().Status(http.StatusOK)
+			resp.Header("ETag").NotEqual(etag)
+			resp.JSON().Path("$.last_operation.state").Equal("failed")
+		})
+
+		It("checks the If-Match header of instance patches before the broker is called", func() {
+			instanceURL := web.ServiceInstancesURL + "/" + instanceID
+			brokerCalled := false
+			brokerServer.ServiceInstanceHandlerFunc(http.MethodPatch, http.MethodPatch+"1", func(req *http.Request) (int, map[string]interface{}) {
+				brokerCalled = true
+				return http.StatusOK, object{}
+			})
+
+			ctx.SMWithOAuth.PATCH(instanceURL).WithQuery("async", false).WithHeader("If-Match", `"2000-01-01T00:00:00Z"`).
+				WithJSON(object{"name": "renamed-instance"}).
+				Expect().Status(http.StatusPreconditionFailed)
+			Expect(brokerCalled).To(BeFalse())
+
+			etag := ctx.SMWithOAuth.GET(instanceURL).
+				Expect().Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+			ctx.SMWithOAuth.PATCH(instanceURL).WithQuery("async", false).WithHeader("If-Match", etag).
+				WithJSON(object{"name": "renamed-instance"}).
+				Expect().Status(http.StatusOK)
+			Expect(brokerCalled).To(BeTrue())
+		})
+
+		It("returns different ETags for different projections", func() {
+			instanceURL := web.ServiceInstancesURL + "/" + instanceID
+			etag := ctx.SMWithOAuth.GET(instanceURL).
+				Expect().Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+			projectedETag := ctx.SMWithOAuth.GET(instanceURL).WithQuery("fields", "name,updated_at").
+				Expect().Status(http.StatusOK).Header("ETag").NotEqual(etag).Raw()
+
+			ctx.SMWithOAuth.GET(instanceURL).WithQuery("fields", "updated_at,name,name").WithHeader("If-None-Match", projectedETag).
+				Expect().Status(http.StatusNotModified)
+			ctx.SMWithOAuth.GET(instanceURL).WithHeader("If-None-Match", projectedETag).
+				Expect().Status(http.StatusOK)
+		})
+	})
+
+	Describe("conditional list", func() {
+		BeforeEach(func() {
+			createPlatform("polled-platform")
+		})
+
+		It("returns 304 while the ETag of the page is current", func() {
+			etag := ctx.SMWithOAuth.GET(web.PlatformsURL).
+				Expect().Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithHeader("If-None-Match", etag).
+				Expect().Status(http.StatusNotModified)
+
+			createPlatform("new-platform")
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithHeader("If-None-Match", etag).
+				Expect().Status(http.StatusOK)
+		})
+
+		It("returns a Last-Modified time the safety window before the request", func() {
+			lastModified, err := time.Parse(http.TimeFormat, ctx.SMWithOAuth.GET(web.PlatformsURL).
+				Expect().Status(http.StatusOK).Header("Last-Modified").NotEmpty().Raw())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastModified).To(BeTemporally("<=", time.Now().Add(-lastModifiedSafetyWindow)))
+		})
+
+		It("reports changes committed after the Last-Modified time", func() {
+			lastModified := ctx.SMWithOAuth.GET(web.PlatformsURL).
+				Expect().Status(http.StatusOK).Header("Last-Modified").NotEmpty().Raw()
+
+			patchPlatform("polled-platform")
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithHeader("If-Modified-Since", lastModified).
+				Expect().Status(http.StatusOK)
+		})
+
+		It("returns 304 when nothing was changed or deleted since the given time", func() {
+			time.Sleep(lastModifiedSafetyWindow + 2*time.Second)
+			lastModified := ctx.SMWithOAuth.GET(web.PlatformsURL).
+				Expect().Status(http.StatusOK).Header("Last-Modified").NotEmpty().Raw()
+			time.Sleep(time.Second)
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithHeader("If-Modified-Since", lastModified).
+				Expect().Status(http.StatusNotModified)
+
+			ctx.SMWithOAuth.DELETE(web.PlatformsURL + "/polled-platform").Expect().Status(http.StatusOK)
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithHeader("If-Modified-Since", lastModified).
+				Expect().Status(http.StatusOK)
+		})
+	})
+
+	Describe("updated_since", func() {
+		It("returns 400 for invalid times", func() {
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("updated_since", "yesterday").
+				Expect().Status(http.StatusBadRequest)
+		})
+
+		It("lists the changed and the deleted platforms", func() {
+			createPlatform("unchanged-platform")
+			createPlatform("changed-platform")
+			createPlatform("deleted-platform")
+			time.Sleep(lastModifiedSafetyWindow)
+			since := time.Now().UTC().Format(time.RFC3339Nano)
+
+			createPlatform("created-platform")
+			patchPlatform("changed-platform")
+			ctx.SMWithOAuth.DELETE(web.PlatformsURL + "/deleted-platform").Expect().Status(http.StatusOK)
+
+			page := ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("updated_since", since).
+				Expect().Status(http.StatusOK).JSON().Object()
+			page.Value("num_items").Equal(2)
+			page.Path("$.items[*].id").Array().ContainsOnly("created-platform", "changed-platform")
+			page.Path("$.deleted[*].id").Array().ContainsOnly("deleted-platform")
+		})
+
+		It("lists the changes made in the safety window before the given time", func() {
+			createPlatform("unchanged-platform")
+			time.Sleep(lastModifiedSafetyWindow)
+			createPlatform("recent-platform")
+			since := time.Now().UTC().Format(time.RFC3339Nano)
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("updated_since", since).
+				Expect().Status(http.StatusOK).
+				JSON().Path("$.items[*].id").Array().ContainsOnly("recent-platform")
+		})
+
+		It("reports only the deletions matching the query", func() {
+			createPlatform("deleted-platform")
+			createPlatform("other-deleted-platform")
+			time.Sleep(lastModifiedSafetyWindow)
+			since := time.Now().UTC().Format(time.RFC3339Nano)
+
+			ctx.SMWithOAuth.DELETE(web.PlatformsURL + "/deleted-platform").Expect().Status(http.StatusOK)
+			ctx.SMWithOAuth.DELETE(web.PlatformsURL + "/other-deleted-platform").Expect().Status(http.StatusOK)
+
+			ctx.SMWithOAuth.GET(web.PlatformsURL).
+				WithQuery("updated_since", since).
+				WithQuery("fieldQuery", "name eq 'deleted-platform'").
+				Expect().Status(http.StatusOK).
+				JSON().Path("$.deleted[*].id").Array().ContainsOnly("deleted-platform")
+		})
+	})
+})
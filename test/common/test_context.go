@@ -621,6 +621,16 @@ func newSMServer(smEnv env.Environment, wg *sync.WaitGroup, fs []func(ctx contex
 	if err != nil {
 		panic(err)
 	}
+	err = smb.WebhookDispatcher.Start(ctx, wg)
+	if err != nil {
+		panic(err)
+	}
+	err = smb.CancellationNotifier.Start(ctx, wg, func(operationID string) {
+		operations.CancelAction(operationID)
+	})
+	if err != nil {
+		panic(err)
+	}
 
 	testServer := httptest.NewUnstartedServer(serviceManager.Server.Router)
 	if listener != nil {
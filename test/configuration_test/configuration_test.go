@@ -90,12 +90,15 @@ var _ = Describe("Service Manager Config API", func() {
 					"action_timeout": "15m0s",
             		"cleanup_interval": "1h0m0s",
 					"default_pool_size": 20,
+					"default_retry_policy": {
+						"initial_backoff": "1ms",
+						"max_backoff": "1ms"
+					},
 					"lifespan": "168h0m0s",
 					"maintainer_retry_interval": "10m0s",
 					"polling_interval": "1ms",
 					"pools": "",
 					"reconciliation_operation_timeout": "168h0m0s",
-					"rescheduling_interval": "1ms",
 					"sm_supported_platform_type": "service-manager"
 				  },
 				"server": {
@@ -0,0 +1,294 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/test"
+	. "github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resource Events Tests Suite")
+}
+
+var _ = Describe("Resource events", func() {
+	var (
+		ctx          *TestContext
+		brokerID     string
+		brokerServer *BrokerServer
+		planID       string
+		lastRevision int64
+	)
+
+	listEvents := func(query map[string]interface{}) *httpexpect.Object {
+		req := ctx.SMWithOAuth.GET(web.EventsURL)
+		for key, value := range query {
+			req = req.WithQuery(key, value)
+		}
+		return req.Expect().Status(http.StatusOK).JSON().Object()
+	}
+
+	resourceEventsQuery := func(resourceType types.ObjectType, resourceID string) map[string]interface{} {
+		return map[string]interface{}{
+			web.QueryParamLastRevision: lastRevision,
+			"fieldQuery":               fmt.Sprintf("resource_type eq '%s' and resource_id eq '%s'", resourceType, resourceID),
+			"max_items":                100,
+		}
+	}
+
+	// awaitCommittedEvents waits until the events api returns the latest recorded event, as the events are returned only
+	// once all the events with lower revisions are committed
+	awaitCommittedEvents := func() {
+		latest, err := ctx.SMRepository.List(context.Background(), types.ResourceEventType,
+			query.OrderResultBy("revision", query.DescOrder), query.LimitResultBy(1))
+		Expect(err).ToNot(HaveOccurred())
+		if latest.Len() == 0 {
+			return
+		}
+		revision := latest.ItemAt(0).(*types.ResourceEvent).Revision
+		if revision <= lastRevision {
+			return
+		}
+		Eventually(func() int {
+			return len(listEvents(map[string]interface{}{
+				web.QueryParamLastRevision: lastRevision,
+				"fieldQuery":               fmt.Sprintf("revision eq %d", revision),
+			}).Value("events").Array().Iter())
+		}).Should(Equal(1))
+	}
+
+	// eventsSince returns the events of the resource after the last revision read by the test
+	eventsSince := func(resourceType types.ObjectType, resourceID string) []map[string]interface{} {
+		awaitCommittedEvents()
+		result := make([]map[string]interface{}, 0)
+		page := listEvents(resourceEventsQuery(resourceType, resourceID))
+		for _, event := range page.Value("events").Array().Iter() {
+			result = append(result, event.Object().Raw())
+		}
+		return result
+	}
+
+	eventTypes := func(events []map[string]interface{}) []string {
+		result := make([]string, 0, len(events))
+		for _, event := range events {
+			result = append(result, event["type"].(string))
+		}
+		return result
+	}
+
+	createInstance := func() string {
+		UUID, err := uuid.NewV4()
+		Expect(err).ToNot(HaveOccurred())
+		return ctx.SMWithOAuth.POST(web.ServiceInstancesURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"name":            "test-instance-" + UUID.String(),
+				"service_plan_id": planID,
+				"parameters":      Object{"secret_parameter": "secret-value"},
+				"labels":          Object{"cost_center": Array{"cc-42"}},
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+	}
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().Build()
+
+		catalog := NewEmptySBCatalog()
+		catalog.AddService(GenerateTestServiceWithPlans(GenerateTestPlan()))
+		brokerUtils := ctx.RegisterBrokerWithCatalog(catalog)
+		brokerID = brokerUtils.Broker.ID
+		brokerServer = brokerUtils.Broker.BrokerServer
+		ctx.Servers[BrokerServerPrefix+brokerID] = brokerServer
+
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, fmt.Sprintf("fieldQuery=broker_id eq '%s'", brokerID)).
+			First().Object().Value("id").String().Raw()
+		planID = ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, fmt.Sprintf("fieldQuery=service_offering_id eq '%s'", offeringID)).
+			First().Object().Value("id").String().Raw()
+		test.EnsurePlanVisibility(ctx.SMRepository, "", types.SMPlatform, planID, "")
+
+		lastRevision = 0
+		for {
+			page := listEvents(map[string]interface{}{web.QueryParamLastRevision: lastRevision, "max_items": 200})
+			lastRevision = int64(page.Value("last_revision").Number().Raw())
+			if !page.Value("has_more").Boolean().Raw() {
+				break
+			}
+		}
+	})
+
+	AfterEach(func() {
+		err := ctx.SMRepository.Delete(context.Background(), types.ResourceEventType)
+		if err != nil && err != util.ErrNotFoundInStorage {
+			Fail(err.Error())
+		}
+		ctx.Cleanup()
+	})
+
+	It("records the changes of an instance and its operation without its parameters", func() {
+		instanceID := createInstance()
+
+		events := eventsSince(types.ServiceInstanceType, instanceID)
+		Expect(len(events)).To(BeNumerically(">=", 2))
+		created, last := events[0], events[len(events)-1]
+		Expect(created["type"]).To(Equal(string(types.CREATED)))
+		Expect(created["resource"]).To(HaveKeyWithValue("ready", false))
+		Expect(last["type"]).To(Equal(string(types.MODIFIED)))
+		Expect(last["resource"]).To(HaveKeyWithValue("ready", true))
+		Expect(last["revision"]).To(BeNumerically(">", created["revision"]))
+		for _, event := range events {
+			Expect(event["resource"]).ToNot(HaveKey("parameters"))
+		}
+
+		operationEvents := listEvents(map[string]interface{}{
+			web.QueryParamLastRevision: lastRevision,
+			"fieldQuery":               fmt.Sprintf("resource_type eq '%s'", types.OperationType),
+		}).Value("events").Array()
+		operationEvents.NotEmpty()
+		operationEvents.Last().Object().Value("resource").Object().Value("resource_id").Equal(instanceID)
+		operationEvents.Last().Object().Value("resource").Object().Value("state").Equal(string(types.SUCCEEDED))
+	})
+
+	It("records the deletion of an instance with its state and labels", func() {
+		instanceID := createInstance()
+		ctx.SMWithOAuth.DELETE(web.ServiceInstancesURL+"/"+instanceID).
+			WithQuery("async", false).
+			Expect().Status(http.StatusOK)
+
+		events := eventsSince(types.ServiceInstanceType, instanceID)
+		deleted := events[len(events)-1]
+		Expect(deleted["type"]).To(Equal(string(types.DELETED)))
+		Expect(deleted["resource"]).To(HaveKeyWithValue("service_plan_id", planID))
+		Expect(deleted["resource"]).To(HaveKeyWithValue("labels", HaveKeyWithValue("cost_center", ConsistOf("cc-42"))))
+	})
+
+	It("does not record the credentials of bindings", func() {
+		instanceID := createInstance()
+		brokerServer.BindingHandlerFunc(http.MethodPut, http.MethodPut+"1", ParameterizedHandler(http.StatusCreated, Object{
+			"credentials": Object{"password": "secret-password"},
+		}))
+		bindingID := ctx.SMWithOAuth.POST(web.ServiceBindingsURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"name":                "test-binding",
+				"service_instance_id": instanceID,
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+
+		events := eventsSince(types.ServiceBindingType, bindingID)
+		Expect(events).ToNot(BeEmpty())
+		for _, event := range events {
+			Expect(event["resource"]).ToNot(HaveKey("credentials"))
+		}
+	})
+
+	It("records the deletion of the plans of a deleted broker", func() {
+		ctx.SMWithOAuth.DELETE(web.ServiceBrokersURL + "/" + brokerID).
+			Expect().Status(http.StatusOK)
+
+		events := eventsSince(types.ServicePlanType, planID)
+		Expect(eventTypes(events)).To(Equal([]string{string(types.DELETED)}))
+	})
+
+	It("does not return events with revisions after events which are not committed yet", func() {
+		UUID, err := uuid.NewV4()
+		Expect(err).ToNot(HaveOccurred())
+		recorded := make(chan struct{})
+		release := make(chan struct{})
+		committed := make(chan error, 1)
+		go func() {
+			committed <- ctx.SMRepository.InTransaction(context.Background(), func(txCtx context.Context, repository storage.Repository) error {
+				_, err := repository.Create(txCtx, &types.ResourceEvent{
+					Base:         types.Base{ID: UUID.String(), Ready: true},
+					Type:         types.CREATED,
+					ResourceType: types.PlatformType,
+					ResourceID:   UUID.String(),
+					Resource:     json.RawMessage(`{}`),
+				})
+				close(recorded)
+				<-release
+				return err
+			})
+		}()
+		<-recorded
+
+		instanceID := createInstance()
+		heldBackPage := listEvents(resourceEventsQuery(types.ServiceInstanceType, instanceID))
+		heldBackPage.Value("events").Array().Empty()
+		heldBackPage.Value("has_more").Equal(false)
+
+		close(release)
+		Expect(<-committed).ToNot(HaveOccurred())
+		Expect(eventsSince(types.ServiceInstanceType, instanceID)).ToNot(BeEmpty())
+		Expect(eventsSince(types.PlatformType, UUID.String())).To(HaveLen(1))
+	})
+
+	It("pages the events after the last revision", func() {
+		createInstance()
+		awaitCommittedEvents()
+
+		firstPage := listEvents(map[string]interface{}{web.QueryParamLastRevision: lastRevision, "max_items": 1})
+		firstPage.Value("events").Array().Length().Equal(1)
+		firstPage.Value("has_more").Equal(true)
+		firstRevision := firstPage.Value("events").Array().First().Object().Value("revision").Number().Raw()
+		firstPage.Value("last_revision").Equal(firstRevision)
+
+		secondPage := listEvents(map[string]interface{}{web.QueryParamLastRevision: firstRevision, "max_items": 1})
+		secondPage.Value("events").Array().First().Object().Value("revision").Number().Gt(firstRevision)
+	})
+
+	It("returns the last revision known to the client if there are no new events", func() {
+		page := listEvents(map[string]interface{}{web.QueryParamLastRevision: lastRevision})
+		page.Value("events").Array().Empty()
+		page.Value("last_revision").Equal(lastRevision)
+		page.Value("has_more").Equal(false)
+	})
+
+	It("rejects revisions which are not available", func() {
+		ctx.SMWithOAuth.GET(web.EventsURL).
+			WithQuery(web.QueryParamLastRevision, lastRevision+1000).
+			Expect().Status(http.StatusGone)
+	})
+
+	It("rejects invalid revisions", func() {
+		ctx.SMWithOAuth.GET(web.EventsURL).
+			WithQuery(web.QueryParamLastRevision, "invalid").
+			Expect().Status(http.StatusBadRequest)
+	})
+
+	It("requires a token", func() {
+		ctx.SM.GET(web.EventsURL).
+			Expect().Status(http.StatusUnauthorized)
+	})
+})
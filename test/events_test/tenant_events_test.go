@@ -0,0 +1,129 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+package events_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/sm"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test"
+	. "github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resource events of tenants", func() {
+	var (
+		ctx    *TestContext
+		planID string
+	)
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+			_, err := smb.EnableMultitenancy("tenant", ExtractTenantFunc)
+			return err
+		}).Build()
+
+		catalog := NewEmptySBCatalog()
+		catalog.AddService(GenerateTestServiceWithPlans(GenerateTestPlan()))
+		brokerUtils := ctx.RegisterBrokerWithCatalog(catalog)
+		ctx.Servers[BrokerServerPrefix+brokerUtils.Broker.ID] = brokerUtils.Broker.BrokerServer
+
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, fmt.Sprintf("fieldQuery=broker_id eq '%s'", brokerUtils.Broker.ID)).
+			First().Object().Value("id").String().Raw()
+		planID = ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, fmt.Sprintf("fieldQuery=service_offering_id eq '%s'", offeringID)).
+			First().Object().Value("id").String().Raw()
+		test.EnsurePublicPlanVisibility(ctx.SMRepository, planID)
+	})
+
+	AfterEach(func() {
+		err := ctx.SMRepository.Delete(context.Background(), types.ResourceEventType)
+		if err != nil && err != util.ErrNotFoundInStorage {
+			Fail(err.Error())
+		}
+		ctx.Cleanup()
+	})
+
+	createInstance := func(smExpect *SMExpect) string {
+		UUID, err := uuid.NewV4()
+		Expect(err).ToNot(HaveOccurred())
+		return smExpect.POST(web.ServiceInstancesURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"name":            "test-instance-" + UUID.String(),
+				"service_plan_id": planID,
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+	}
+
+	instanceEventResourceIDs := func(smExpect *SMExpect) []string {
+		events := smExpect.GET(web.EventsURL).
+			WithQuery("fieldQuery", fmt.Sprintf("resource_type eq '%s'", types.ServiceInstanceType)).
+			WithQuery("max_items", 100).
+			Expect().Status(http.StatusOK).JSON().Object().Value("events").Array()
+		ids := make([]string, 0)
+		for _, event := range events.Iter() {
+			ids = append(ids, event.Object().Value("resource_id").String().Raw())
+		}
+		return ids
+	}
+
+	It("returns only the events of the resources of the tenant to tenant tokens", func() {
+		tenantExpect := ctx.NewTenantExpect("tenancyClient", "tenant-1")
+		tenantInstanceID := createInstance(tenantExpect)
+		otherInstanceID := createInstance(ctx.NewTenantExpect("tenancyClient", "tenant-2"))
+
+		Eventually(func() []string {
+			return instanceEventResourceIDs(ctx.SMWithOAuth)
+		}).Should(ContainElement(otherInstanceID))
+		Expect(instanceEventResourceIDs(ctx.SMWithOAuth)).To(ContainElement(tenantInstanceID))
+
+		tenantEvents := instanceEventResourceIDs(tenantExpect)
+		Expect(tenantEvents).To(ContainElement(tenantInstanceID))
+		Expect(tenantEvents).ToNot(ContainElement(otherInstanceID))
+	})
+
+	It("rejects revisions of events of other tenants", func() {
+		otherExpect := ctx.NewTenantExpect("tenancyClient", "tenant-2")
+		otherInstanceID := createInstance(otherExpect)
+
+		var otherRevision int64
+		Eventually(func() int {
+			events := otherExpect.GET(web.EventsURL).
+				WithQuery("fieldQuery", fmt.Sprintf("resource_id eq '%s'", otherInstanceID)).
+				Expect().Status(http.StatusOK).JSON().Object().Value("events").Array()
+			if length := len(events.Iter()); length > 0 {
+				otherRevision = int64(events.First().Object().Value("revision").Number().Raw())
+				return length
+			}
+			return 0
+		}).Should(BeNumerically(">", 0))
+
+		otherExpect.GET(web.EventsURL).WithQuery(web.QueryParamLastRevision, otherRevision).
+			Expect().Status(http.StatusOK)
+		ctx.NewTenantExpect("tenancyClient", "tenant-1").GET(web.EventsURL).WithQuery(web.QueryParamLastRevision, otherRevision).
+			Expect().Status(http.StatusGone)
+	})
+})
@@ -71,6 +71,23 @@ func DescribeGetTestsfor(ctx *common.TestContext, t TestCase, responseMode Respo
 									Status(http.StatusOK).JSON().Object().ContainsMap(testResource)
 							})
 
+							Context("with fields query", func() {
+								It("returns only the requested fields", func() {
+									ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", t.API, testResourceID)).
+										WithQuery("fields", "id,created_at").
+										Expect().
+										Status(http.StatusOK).JSON().Object().
+										Keys().ContainsOnly("id", "created_at")
+								})
+
+								It("returns 400 for unknown field", func() {
+									ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", t.API, testResourceID)).
+										WithQuery("fields", "id,unknown").
+										Expect().
+										Status(http.StatusBadRequest)
+								})
+							})
+
 							if t.SupportsAsyncOperations && responseMode == Async {
 								Context("when resource is created async", func() {
 									It("returns last operation with the resource", func() {
@@ -82,6 +99,25 @@ func DescribeGetTestsfor(ctx *common.TestContext, t TestCase, responseMode Respo
 											response.Value("last_operation").Object().ValueEqual("state", "succeeded")
 										}
 									})
+
+									It("returns the last operation only when it is a requested field", func() {
+										ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", t.API, testResourceID)).
+											WithQuery("fields", "id").
+											Expect().
+											Status(http.StatusOK).JSON().Object().
+											Keys().ContainsOnly("id")
+
+										response := ctx.SMWithOAuth.GET(fmt.Sprintf("%s/%s", t.API, testResourceID)).
+											WithQuery("fields", "id,last_operation").
+											Expect().
+											Status(http.StatusOK).JSON().Object()
+										response.Keys().Contains("id")
+										result := response.Raw()
+										Expect(len(result)).To(BeNumerically("<=", 2))
+										if _, found := result["last_operation"]; found {
+											response.Value("last_operation").Object().ValueEqual("state", "succeeded")
+										}
+									})
 								})
 							}
 						})
@@ -0,0 +1,186 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+package history_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect"
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test"
+	. "github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHistory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resource History Tests Suite")
+}
+
+var _ = Describe("Resource history", func() {
+	var (
+		ctx          *TestContext
+		brokerServer *BrokerServer
+		planID       string
+	)
+
+	eventTypes := func(history *httpexpect.Array) []string {
+		result := make([]string, 0)
+		for _, event := range history.Iter() {
+			result = append(result, event.Object().Value("type").String().Raw())
+		}
+		return result
+	}
+
+	getHistory := func(resourceURL, resourceID string) *httpexpect.Array {
+		return ctx.SMWithOAuth.GET(resourceURL + "/" + resourceID + web.ResourceHistoryURL).
+			Expect().Status(http.StatusOK).
+			JSON().Object().Value("items").Array()
+	}
+
+	createInstance := func() string {
+		UUID, err := uuid.NewV4()
+		Expect(err).ToNot(HaveOccurred())
+		return ctx.SMWithOAuth.POST(web.ServiceInstancesURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"name":            "test-instance-" + UUID.String(),
+				"service_plan_id": planID,
+				"parameters":      Object{"secret_parameter": "secret-value"},
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+	}
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().Build()
+
+		catalog := NewEmptySBCatalog()
+		catalog.AddService(GenerateTestServiceWithPlans(GenerateTestPlan()))
+		brokerUtils := ctx.RegisterBrokerWithCatalog(catalog)
+		brokerServer = brokerUtils.Broker.BrokerServer
+		ctx.Servers[BrokerServerPrefix+brokerUtils.Broker.ID] = brokerServer
+
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, fmt.Sprintf("fieldQuery=broker_id eq '%s'", brokerUtils.Broker.ID)).
+			First().Object().Value("id").String().Raw()
+		planID = ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, fmt.Sprintf("fieldQuery=service_offering_id eq '%s'", offeringID)).
+			First().Object().Value("id").String().Raw()
+		test.EnsurePlanVisibility(ctx.SMRepository, "", types.SMPlatform, planID, "")
+	})
+
+	AfterEach(func() {
+		err := ctx.SMRepository.Delete(context.Background(), types.OperationEventType)
+		if err != nil && err != util.ErrNotFoundInStorage {
+			Fail(err.Error())
+		}
+		ctx.Cleanup()
+	})
+
+	It("returns the events of the operations of an instance in the order in which they were recorded", func() {
+		instanceID := createInstance()
+
+		history := getHistory(web.ServiceInstancesURL, instanceID)
+		Expect(eventTypes(history)).To(Equal([]string{
+			string(types.OperationCreatedEvent),
+			string(types.BrokerRequestEvent),
+			string(types.BrokerResponseEvent),
+			string(types.OperationStateChangedEvent),
+		}))
+		for _, event := range history.Iter() {
+			event.Object().Value("resource_id").Equal(instanceID)
+			event.Object().Value("resource_type").Equal(string(types.ServiceInstanceType))
+			event.Object().Value("operation_type").Equal(string(types.CREATE))
+			event.Object().Value("description").String().NotContains("secret-value")
+		}
+		history.Last().Object().Value("state").Equal(string(types.SUCCEEDED))
+	})
+
+	It("returns the events in pages", func() {
+		instanceID := createInstance()
+		historyURL := web.ServiceInstancesURL + "/" + instanceID + web.ResourceHistoryURL
+
+		firstPage := ctx.SMWithOAuth.GET(historyURL).WithQuery("max_items", 3).
+			Expect().Status(http.StatusOK).JSON().Object()
+		firstPage.Value("num_items").Equal(4)
+		firstPage.Value("items").Array().Length().Equal(3)
+		token := firstPage.Value("token").String().NotEmpty().Raw()
+
+		secondPage := ctx.SMWithOAuth.GET(historyURL).WithQuery("max_items", 3).WithQuery("token", token).
+			Expect().Status(http.StatusOK).JSON().Object()
+		secondPage.NotContainsKey("token")
+		secondPage.Value("items").Array().Length().Equal(1)
+		secondPage.Value("items").Array().First().Object().Value("type").Equal(string(types.OperationStateChangedEvent))
+	})
+
+	It("records broker errors and the failure of the operation", func() {
+		brokerServer.ServiceInstanceHandlerFunc(http.MethodPut, http.MethodPut+"1", ParameterizedHandler(http.StatusBadRequest, Object{"error": "error"}))
+		UUID, err := uuid.NewV4()
+		Expect(err).ToNot(HaveOccurred())
+		ctx.SMWithOAuth.POST(web.ServiceInstancesURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"id":              UUID.String(),
+				"name":            "test-instance",
+				"service_plan_id": planID,
+			}).
+			Expect().Status(http.StatusBadRequest)
+
+		history := getHistory(web.ServiceInstancesURL, UUID.String())
+		Expect(eventTypes(history)).To(ContainElement(string(types.BrokerErrorEvent)))
+		history.Last().Object().Value("type").Equal(string(types.OperationStateChangedEvent))
+		history.Last().Object().Value("state").Equal(string(types.FAILED))
+	})
+
+	It("keeps the history of an instance after it is deleted", func() {
+		instanceID := createInstance()
+		ctx.SMWithOAuth.DELETE(web.ServiceInstancesURL+"/"+instanceID).
+			WithQuery("async", false).
+			Expect().Status(http.StatusOK)
+
+		history := getHistory(web.ServiceInstancesURL, instanceID)
+		history.Last().Object().Value("operation_type").Equal(string(types.DELETE))
+		history.Last().Object().Value("state").Equal(string(types.SUCCEEDED))
+	})
+
+	It("does not record the credentials of bindings", func() {
+		instanceID := createInstance()
+		brokerServer.BindingHandlerFunc(http.MethodPut, http.MethodPut+"1", ParameterizedHandler(http.StatusCreated, Object{
+			"credentials": Object{"password": "secret-password"},
+		}))
+		bindingID := ctx.SMWithOAuth.POST(web.ServiceBindingsURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"name":                "test-binding",
+				"service_instance_id": instanceID,
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+
+		history := getHistory(web.ServiceBindingsURL, bindingID)
+		Expect(eventTypes(history)).To(ContainElement(string(types.BrokerResponseEvent)))
+		for _, event := range history.Iter() {
+			event.Object().Value("description").String().NotContains("secret-password")
+		}
+	})
+})
@@ -0,0 +1,77 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package jobs_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestJobs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Background Jobs Monitoring Suite")
+}
+
+var _ = Describe("Background jobs monitoring API", func() {
+	var ctx *common.TestContext
+
+	BeforeEach(func() {
+		ctx = common.NewTestContextBuilder().Build()
+	})
+
+	AfterEach(func() {
+		ctx.Cleanup()
+	})
+
+	findJob := func(name string) map[string]interface{} {
+		jobs := ctx.SMWithOAuth.GET(web.MonitorJobsURL).Expect().
+			Status(http.StatusOK).JSON().Object().Value("jobs").Array()
+		for _, job := range jobs.Iter() {
+			if job.Object().Value("name").String().Raw() == name {
+				return job.Object().Raw()
+			}
+		}
+		return nil
+	}
+
+	It("requires authentication", func() {
+		ctx.SM.GET(web.MonitorJobsURL).Expect().Status(http.StatusUnauthorized)
+	})
+
+	It("lists the leaders and the last runs of the maintainer jobs", func() {
+		Eventually(func() map[string]interface{} {
+			return findJob("cleanupExternalOperations")
+		}, 10*time.Second, 500*time.Millisecond).Should(And(
+			HaveKey("last_run_at"),
+			HaveKeyWithValue("lease_expired", false),
+		))
+
+		job := findJob("cleanupExternalOperations")
+		Expect(job["leader"]).ToNot(BeEmpty())
+		Expect(job["term"]).To(BeNumerically(">=", 1))
+		Expect(job["leader_since"]).ToNot(BeEmpty())
+		Expect(job["lease_expires_at"]).ToNot(BeEmpty())
+		Expect(job["last_run_duration"]).ToNot(BeEmpty())
+		Expect(job).ToNot(HaveKey("last_error"))
+	})
+})
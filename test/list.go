@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
 
 	"github.com/gavv/httpexpect"
 
@@ -688,6 +689,131 @@ func DescribeListTestsFor(ctx *common.TestContext, t TestCase, responseMode Resp
 						resp.JSON().Path("$.items[*]").Array().Length().Gt(0).Le(pageSize)
 					})
 				})
+				Context("with order by query", func() {
+					listIDs := func(orderBy string, pageSize int) []string {
+						ids := make([]string, 0)
+						token := ""
+						for {
+							req := ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", orderBy).WithQuery("max_items", pageSize)
+							if token != "" {
+								req = req.WithQuery("token", token)
+							}
+							page := req.Expect().Status(http.StatusOK).JSON().Object()
+							for _, item := range page.Value("items").Array().Iter() {
+								ids = append(ids, item.Object().Value("id").String().Raw())
+							}
+							nextToken, found := page.Raw()["token"]
+							if !found {
+								return ids
+							}
+							token = nextToken.(string)
+						}
+					}
+
+					It("returns all items in the requested order across pages", func() {
+						allIDs := listIDs("ready desc,created_at desc,id", 1000)
+						Expect(allIDs).ToNot(BeEmpty())
+						Expect(listIDs("ready desc,created_at desc,id", 1)).To(Equal(allIDs))
+					})
+
+					It("returns 400 when ordering by unknown field", func() {
+						ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "unknown asc").Expect().Status(http.StatusBadRequest)
+					})
+
+					It("returns 400 when the token is generated for another order", func() {
+						token := ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "id desc").WithQuery("max_items", 1).
+							Expect().Status(http.StatusOK).JSON().Path("$.token").String().Raw()
+						ctx.SMWithOAuth.GET(t.API).WithQuery("orderBy", "id asc").WithQuery("token", token).
+							Expect().Status(http.StatusBadRequest)
+					})
+				})
+				Context("with fields query", func() {
+					It("returns only the requested fields", func() {
+						items := ctx.SMWithOAuth.GET(t.API).WithQuery("fields", "id,labels").
+							Expect().Status(http.StatusOK).JSON().Path("$.items").Array()
+						items.Length().Gt(0)
+						for _, item := range items.Iter() {
+							keys := make([]string, 0)
+							for key := range item.Object().Raw() {
+								keys = append(keys, key)
+							}
+							Expect([]string{"id", "labels"}).To(ContainElements(keys))
+						}
+					})
+
+					It("returns only the requested labels", func() {
+						ctx.SMWithOAuth.GET(t.API).WithQuery("fieldQuery", fmt.Sprintf("id eq '%s'", r[0]["id"])).
+							WithQuery("fields", "id,labels.labelKey2").
+							Expect().Status(http.StatusOK).JSON().Path("$.items[0]").Object().
+							Equal(common.Object{"id": r[0]["id"], "labels": common.Object{"labelKey2": common.Array{"str"}}})
+					})
+
+					if t.SupportsAsyncOperations {
+						It("returns the last operations only when they are requested", func() {
+							items := ctx.SMWithOAuth.GET(t.API).WithQuery("attach_last_operations", "true").
+								WithQuery("fields", "id,last_operation").
+								Expect().Status(http.StatusOK).JSON().Path("$.items").Array()
+							items.Length().Gt(0)
+							for _, item := range items.Iter() {
+								keys := make([]string, 0)
+								for key := range item.Object().Raw() {
+									keys = append(keys, key)
+								}
+								Expect([]string{"id", "last_operation"}).To(ContainElements(keys))
+								if _, found := item.Object().Raw()["last_operation"]; found {
+									item.Object().Path("$.last_operation.resource_id").Equal(item.Object().Value("id").Raw())
+								}
+							}
+
+							items = ctx.SMWithOAuth.GET(t.API).WithQuery("attach_last_operations", "true").WithQuery("fields", "id").
+								Expect().Status(http.StatusOK).JSON().Path("$.items").Array()
+							for _, item := range items.Iter() {
+								item.Object().NotContainsKey("last_operation")
+							}
+						})
+					}
+
+					It("returns the same items across pages with projection", func() {
+						listIDs := func(fields string) []string {
+							ids := make([]string, 0)
+							token := ""
+							for {
+								req := ctx.SMWithOAuth.GET(t.API).WithQuery("max_items", 2)
+								if fields != "" {
+									req = req.WithQuery("fields", fields)
+								}
+								if token != "" {
+									req = req.WithQuery("token", token)
+								}
+								page := req.Expect().Status(http.StatusOK).JSON().Object()
+								for _, item := range page.Value("items").Array().Iter() {
+									ids = append(ids, item.Object().Value("id").String().Raw())
+								}
+								nextToken, found := page.Raw()["token"]
+								if !found {
+									return ids
+								}
+								token = nextToken.(string)
+							}
+						}
+
+						allIDs := listIDs("")
+						Expect(len(allIDs)).To(BeNumerically(">", 2))
+						Expect(listIDs("id")).To(Equal(allIDs))
+						Expect(listIDs("id,labels.labelKey2")).To(Equal(allIDs))
+					})
+
+					It("returns all items when paging with projection", func() {
+						count := ctx.SMWithOAuth.GET(t.API).WithQuery("max_items", 0).Expect().Status(http.StatusOK).
+							JSON().Path("$.num_items").Number().Raw()
+						ctx.SMWithOAuth.GET(t.API).WithQuery("fields", "id").WithQuery("max_items", count).
+							Expect().Status(http.StatusOK).JSON().Path("$.items[*].id").Array().Length().Equal(count)
+					})
+
+					It("returns 400 for unknown field", func() {
+						ctx.SMWithOAuth.GET(t.API).WithQuery("fields", "unknown").Expect().Status(http.StatusBadRequest)
+					})
+				})
 				Context("with invalid token", func() {
 					executeWithInvalidToken := func(token string) {
 						ctx.SMWithOAuth.GET(t.API).WithQuery("token", token).Expect().Status(http.StatusBadRequest)
@@ -712,6 +838,60 @@ func DescribeListTestsFor(ctx *common.TestContext, t TestCase, responseMode Resp
 				})
 			})
 
+			Context("Aggregations", func() {
+				It("returns the counts of the resources grouped by field", func() {
+					count := ctx.SMWithOAuth.GET(t.API).WithQuery("max_items", 0).Expect().Status(http.StatusOK).
+						JSON().Path("$.num_items").Number().Raw()
+					aggregation := ctx.SMWithOAuth.GET(t.API+web.ResourceAggregationsURL).WithQuery("group_by", "ready").
+						Expect().Status(http.StatusOK).JSON().Object()
+					aggregation.Value("group_by").String().Equal("ready")
+					aggregation.Value("num_items").Number().Equal(count)
+					var groupsCount float64
+					for _, group := range aggregation.Value("groups").Array().Iter() {
+						groupsCount += group.Object().Value("num_items").Number().Raw()
+					}
+					Expect(groupsCount).To(Equal(count))
+				})
+
+				It("returns the counts of the resources grouped by label", func() {
+					ctx.SMWithOAuth.GET(t.API+web.ResourceAggregationsURL).WithQuery("group_by", "labels.unknown").
+						Expect().Status(http.StatusOK).JSON().Path("$.groups[*].value").Array().Equal([]interface{}{nil})
+				})
+
+				It("returns the count of each value of the grouped field", func() {
+					aggregation := ctx.SMWithOAuth.GET(t.API+web.ResourceAggregationsURL).WithQuery("group_by", "id").
+						WithQuery("fieldQuery", fmt.Sprintf("id in ('%s','%s')", r[0]["id"], r[1]["id"])).
+						Expect().Status(http.StatusOK).JSON().Object()
+					aggregation.Value("num_items").Number().Equal(2)
+					Expect(aggregation.Value("groups").Raw()).To(ConsistOf(
+						common.Object{"value": r[0]["id"], "num_items": float64(1)},
+						common.Object{"value": r[1]["id"], "num_items": float64(1)},
+					))
+				})
+
+				It("returns the count of each value of the grouped label", func() {
+					aggregation := ctx.SMWithOAuth.GET(t.API+web.ResourceAggregationsURL).WithQuery("group_by", "labels.labelKey2").
+						WithQuery("fieldQuery", fmt.Sprintf("id in ('%s','%s','%s')", r[0]["id"], r[1]["id"], r[2]["id"])).
+						Expect().Status(http.StatusOK).JSON().Object()
+					aggregation.Value("num_items").Number().Equal(3)
+					aggregation.Value("groups").Array().Equal(common.Array{common.Object{"value": "str", "num_items": 3}})
+				})
+
+				It("returns 400 when group by is missing", func() {
+					ctx.SMWithOAuth.GET(t.API + web.ResourceAggregationsURL).Expect().Status(http.StatusBadRequest)
+				})
+
+				It("returns 400 when grouping by unknown field", func() {
+					ctx.SMWithOAuth.GET(t.API+web.ResourceAggregationsURL).WithQuery("group_by", "unknown").
+						Expect().Status(http.StatusBadRequest)
+				})
+
+				It("returns 400 when grouping by label without key", func() {
+					ctx.SMWithOAuth.GET(t.API+web.ResourceAggregationsURL).WithQuery("group_by", "labels.").
+						Expect().Status(http.StatusBadRequest)
+				})
+			})
+
 			Context("with no field query", func() {
 				It("it returns all resources", func() {
 					verifyListOpWithAuth(listOpEntry{
@@ -26,7 +26,7 @@ var _ = Describe("cascade operations", func() {
 				Reschedulable:     false,
 				DeletionScheduled: false,
 			})
-			ctx.Maintainer.CleanupFinishedCascadeOperations()
+			ctx.Maintainer.CleanupFinishedCascadeOperations(context.Background())
 			count, err := ctx.SMRepository.Count(
 				context.Background(),
 				types.OperationType,
@@ -51,7 +51,7 @@ var _ = Describe("cascade operations", func() {
 				return count
 			}, actionTimeout*20+pollCascade*20).Should(Equal(1))
 
-			ctx.Maintainer.CleanupFinishedCascadeOperations()
+			ctx.Maintainer.CleanupFinishedCascadeOperations(context.Background())
 			count, err := ctx.SMRepository.Count(
 				context.Background(),
 				types.OperationType,
@@ -71,7 +71,7 @@ var _ = Describe("cascade operations", func() {
 				Reschedulable:     false,
 				DeletionScheduled: false,
 			})
-			ctx.Maintainer.CleanupFinishedCascadeOperations()
+			ctx.Maintainer.CleanupFinishedCascadeOperations(context.Background())
 			count, err := ctx.SMRepository.Count(
 				context.Background(),
 				types.OperationType,
@@ -103,7 +103,7 @@ var _ = Describe("cascade operations", func() {
 				Reschedulable:     false,
 				DeletionScheduled: false,
 			})
-			ctx.Maintainer.CleanupFinishedCascadeOperations()
+			ctx.Maintainer.CleanupFinishedCascadeOperations(context.Background())
 			count, err := ctx.SMRepository.Count(
 				context.Background(),
 				types.OperationType, []query.Criterion{
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/storage/postgres"
+	"github.com/Peripli/service-manager/test"
+	. "github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Job worker", func() {
+	const heartbeatInterval = 500 * time.Millisecond
+
+	var (
+		ctx          *TestContext
+		db           *sql.DB
+		queue        *postgres.Storage
+		brokerServer *BrokerServer
+		planID       string
+	)
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+			e.Set("operations.job_heartbeat_interval", heartbeatInterval)
+			e.Set("operations.job_lease_duration", 10*heartbeatInterval)
+		}).Build()
+
+		queue = &postgres.Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				var err error
+				db, err = sql.Open(driver, url)
+				return db, err
+			},
+		}
+		Expect(queue.Open(ctx.Config.Storage)).To(Succeed())
+
+		brokerUtils := ctx.RegisterBroker()
+		brokerServer = brokerUtils.Broker.BrokerServer
+		ctx.Servers[BrokerServerPrefix+brokerUtils.Broker.ID] = brokerServer
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, fmt.Sprintf("fieldQuery=broker_id eq '%s'", brokerUtils.Broker.ID)).
+			First().Object().Value("id").String().Raw()
+		planID = ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, fmt.Sprintf("fieldQuery=service_offering_id eq '%s'", offeringID)).
+			First().Object().Value("id").String().Raw()
+		test.EnsurePublicPlanVisibility(ctx.SMRepository, planID)
+	})
+
+	AfterEach(func() {
+		Expect(queue.Close()).To(Succeed())
+		ctx.Cleanup()
+	})
+
+	It("abandons the action of a job once its lease is taken over", func() {
+		started := make(chan struct{}, 1)
+		canceled := make(chan struct{}, 1)
+		brokerServer.ServiceInstanceHandlerFunc(http.MethodPut, http.MethodPut+"1", func(req *http.Request) (int, map[string]interface{}) {
+			started <- struct{}{}
+			select {
+			case <-req.Context().Done():
+				canceled <- struct{}{}
+			case <-time.After(20 * heartbeatInterval):
+			}
+			return http.StatusCreated, Object{}
+		})
+
+		ctx.SMWithOAuth.POST(web.ServiceInstancesURL).
+			WithQuery("async", true).
+			WithJSON(Object{"name": "leased-instance", "service_plan_id": planID}).
+			Expect().Status(http.StatusAccepted)
+		Eventually(started, 10*heartbeatInterval).Should(Receive())
+
+		var operationID string
+		Expect(db.QueryRow("SELECT operation_id FROM operation_jobs WHERE lease_owner IS NOT NULL").Scan(&operationID)).To(Succeed())
+		_, err := db.Exec("UPDATE operation_jobs SET lease_owner = $1 WHERE operation_id = $2", "another-owner", operationID)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(canceled, 4*heartbeatInterval).Should(Receive())
+		byID := query.ByField(query.EqualsOperator, "id", operationID)
+		Consistently(func() types.OperationState {
+			operation, err := ctx.SMRepository.Get(context.Background(), types.OperationType, byID)
+			Expect(err).ToNot(HaveOccurred())
+			return operation.(*types.Operation).State
+		}, 4*heartbeatInterval).Should(Equal(types.IN_PROGRESS))
+	})
+})
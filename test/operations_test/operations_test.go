@@ -17,8 +17,11 @@ package operations_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -31,6 +34,7 @@ import (
 	"github.com/Peripli/service-manager/pkg/query"
 	"github.com/Peripli/service-manager/pkg/sm"
 	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
 	"github.com/Peripli/service-manager/pkg/web"
 	"github.com/Peripli/service-manager/storage"
 	. "github.com/Peripli/service-manager/test/common"
@@ -129,9 +133,12 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					})
 				}
 
-				When("when there are no available workers", func() {
+				When("when there are no available workers and operations are not queued", func() {
 					BeforeEach(func() {
-						ctx = NewTestContextBuilder().Build()
+						postHook := func(e env.Environment, servers map[string]FakeServer) {
+							e.Set("operations.job_queue_enabled", false)
+						}
+						ctx = NewTestContextBuilder().WithEnvPostExtensions(postHook).Build()
 					})
 
 					It("returns 503", func() {
@@ -171,6 +178,466 @@ var _ = test.DescribeTestsFor(test.TestCase{
 						}
 					})
 				})
+
+				When("when there are no available workers and operations are queued", func() {
+					BeforeEach(func() {
+						postHook := func(e env.Environment, servers map[string]FakeServer) {
+							e.Set("operations.default_pool_size", 1)
+						}
+						ctx = NewTestContextBuilder().WithEnvPostExtensions(postHook).Build()
+					})
+
+					It("accepts all requests and executes them once workers are available", func() {
+						brokerServer := NewBrokerServer()
+
+						requestCount := 10
+						locations := make(chan string, requestCount)
+						executeReq := func(i int) {
+							defer GinkgoRecover()
+							resp := ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(Object{
+								"name":       fmt.Sprintf("queued-broker-%d", i),
+								"broker_url": brokerServer.URL(),
+								"credentials": Object{
+									"basic": Object{
+										"username": brokerServer.Username,
+										"password": brokerServer.Password,
+									},
+								},
+							}).WithQuery("async", "true").Expect().Status(http.StatusAccepted)
+							locations <- resp.Header("Location").Raw()
+						}
+
+						for i := 0; i < requestCount; i++ {
+							go executeReq(i)
+						}
+
+						for i := 0; i < requestCount; i++ {
+							var location string
+							Eventually(locations, 10*time.Second).Should(Receive(&location))
+							Eventually(func() string {
+								return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+							}, 20*time.Second).Should(Equal(string(types.SUCCEEDED)))
+						}
+					})
+				})
+
+				When("an operation is canceled", func() {
+					var brokerServer *BrokerServer
+
+					BeforeEach(func() {
+						ctx = NewTestContextBuilder().Build()
+						brokerServer = NewBrokerServer()
+						ctx.Servers[BrokerServerPrefix+"123"] = brokerServer
+						brokerServer.CatalogHandler = func(rw http.ResponseWriter, req *http.Request) {
+							select {
+							case <-req.Context().Done():
+							case <-time.After(10 * time.Second):
+							}
+							SetResponse(rw, http.StatusOK, Object{})
+						}
+					})
+
+					It("aborts the action and marks the operation as canceled", func() {
+						resp := ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerBody()).
+							WithQuery("async", "true").
+							Expect().Status(http.StatusAccepted)
+						location := resp.Header("Location").Raw()
+
+						operation := ctx.SMWithOAuth.POST(location + web.OperationCancelURL).Expect().
+							Status(http.StatusOK).
+							JSON().Object()
+						operation.Value("state").Equal(string(types.CANCELED))
+						// the broker may have been created before the action was canceled
+						operation.Value("deletion_scheduled").String().NotEqual(time.Time{}.Format(time.RFC3339))
+
+						Consistently(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 3*time.Second).Should(Equal(string(types.CANCELED)))
+						brokerID := strings.Split(strings.TrimPrefix(location, web.ServiceBrokersURL+"/"), "/")[0]
+						ctx.SMWithOAuth.GET(web.ServiceBrokersURL + "/" + brokerID).Expect().Status(http.StatusNotFound)
+					})
+
+					It("does not cancel finished operations", func() {
+						brokerServer.ResetHandlers()
+						resp := ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerBody()).
+							WithQuery("async", "true").
+							Expect().Status(http.StatusAccepted)
+						location := resp.Header("Location").Raw()
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.SUCCEEDED)))
+
+						ctx.SMWithOAuth.POST(location + web.OperationCancelURL).Expect().Status(http.StatusConflict)
+					})
+
+					It("does not cancel operations which finish while they are canceled", func() {
+						operation := &types.Operation{
+							Base: types.Base{
+								ID:        "finishing-operation",
+								CreatedAt: time.Now(),
+								UpdatedAt: time.Now(),
+								Ready:     true,
+							},
+							Type:          types.UPDATE,
+							State:         types.IN_PROGRESS,
+							ResourceID:    "test-resource-id",
+							ResourceType:  types.ServiceBrokerType,
+							PlatformID:    types.SMPlatform,
+							CorrelationID: "test-correlation-id",
+						}
+						_, err := ctx.SMRepository.Create(context.Background(), operation)
+						Expect(err).ToNot(HaveOccurred())
+
+						finishing := make(chan struct{})
+						finished := make(chan error, 1)
+						commit := make(chan struct{})
+						go func() {
+							finished <- ctx.SMRepository.InTransaction(context.Background(), func(ctx context.Context, repository storage.Repository) error {
+								byID := query.ByField(query.EqualsOperator, "id", operation.ID)
+								object, err := repository.Get(ctx, types.OperationType, byID)
+								if err != nil {
+									return err
+								}
+								finishedOperation := object.(*types.Operation)
+								finishedOperation.State = types.SUCCEEDED
+								if _, err := repository.Update(ctx, finishedOperation, types.LabelChanges{}); err != nil {
+									return err
+								}
+								close(finishing)
+								<-commit
+								return nil
+							})
+						}()
+						Eventually(finishing).Should(BeClosed())
+
+						canceled := make(chan error, 1)
+						go func() {
+							_, err := operations.Cancel(context.Background(), ctx.SMRepository, nil, operation)
+							canceled <- err
+						}()
+						Consistently(canceled, 500*time.Millisecond).ShouldNot(Receive())
+						close(commit)
+						Eventually(finished).Should(Receive(BeNil()))
+
+						var cancelErr error
+						Eventually(canceled).Should(Receive(&cancelErr))
+						Expect(cancelErr).To(HaveOccurred())
+						Expect(cancelErr.(*util.HTTPError).StatusCode).To(Equal(http.StatusConflict))
+
+						ctx.SMWithOAuth.GET(web.OperationsURL + "/" + operation.ID).Expect().Status(http.StatusOK).
+							JSON().Object().Value("state").Equal(string(types.SUCCEEDED))
+					})
+				})
+
+				When("an operation with post hooks is canceled", func() {
+					var hookServer *httptest.Server
+					var notifiedStates chan types.OperationState
+
+					BeforeEach(func() {
+						notifiedStates = make(chan types.OperationState, 10)
+						hookServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							request := &operations.HookRequest{}
+							if err := json.NewDecoder(r.Body).Decode(request); err == nil && request.Operation != nil {
+								notifiedStates <- request.Operation.State
+							}
+							w.WriteHeader(http.StatusOK)
+						}))
+						ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+							e.Set("operations.hooks", []interface{}{
+								map[string]interface{}{
+									"name":      "notification",
+									"url":       hookServer.URL,
+									"secret":    "hook-secret",
+									"phase":     "post",
+									"resources": []string{types.ServiceBrokerType.String()},
+								},
+							})
+						}).Build()
+						brokerServer := NewBrokerServer()
+						ctx.Servers[BrokerServerPrefix+"123"] = brokerServer
+						brokerServer.CatalogHandler = func(rw http.ResponseWriter, req *http.Request) {
+							select {
+							case <-req.Context().Done():
+							case <-time.After(10 * time.Second):
+							}
+							SetResponse(rw, http.StatusOK, Object{})
+						}
+					})
+
+					AfterEach(func() {
+						hookServer.Close()
+					})
+
+					It("notifies the post hooks of the cancellation", func() {
+						resp := ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(postBrokerBody()).
+							WithQuery("async", "true").
+							Expect().Status(http.StatusAccepted)
+						location := resp.Header("Location").Raw()
+
+						ctx.SMWithOAuth.POST(location + web.OperationCancelURL).Expect().Status(http.StatusOK)
+						Eventually(notifiedStates, 10*time.Second).Should(Receive(Equal(types.CANCELED)))
+					})
+				})
+
+				When("an operation is retried", func() {
+					var brokerServer *BrokerServer
+
+					BeforeEach(func() {
+						ctx = NewTestContextBuilder().Build()
+						brokerServer = NewBrokerServer()
+						ctx.Servers[BrokerServerPrefix+"123"] = brokerServer
+						ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(Object{
+							"id":         "123",
+							"name":       "test-broker",
+							"broker_url": brokerServer.URL(),
+							"credentials": Object{
+								"basic": Object{
+									"username": brokerServer.Username,
+									"password": brokerServer.Password,
+								},
+							},
+						}).Expect().Status(http.StatusCreated)
+					})
+
+					updateBroker := func() (string, string) {
+						resp := ctx.SMWithOAuth.PATCH(web.ServiceBrokersURL+"/123").WithJSON(Object{"description": "updated"}).
+							WithQuery("async", "true").
+							Expect().Status(http.StatusAccepted)
+						location := resp.Header("Location").Raw()
+						operationID := ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("id").String().Raw()
+						return location, operationID
+					}
+
+					It("executes the action of a failed operation again", func() {
+						UUID, err := uuid.NewV4()
+						Expect(err).ToNot(HaveOccurred())
+						operationID := UUID.String()
+						_, err = ctx.SMRepository.Create(context.Background(), &types.Operation{
+							Base: types.Base{
+								ID:        operationID,
+								CreatedAt: time.Now(),
+								UpdatedAt: time.Now(),
+								Labels:    make(map[string][]string),
+								Ready:     true,
+							},
+							Type:          types.DELETE,
+							State:         types.FAILED,
+							ResourceID:    "123",
+							ResourceType:  types.ServiceBrokerType,
+							PlatformID:    types.SMPlatform,
+							CorrelationID: operationID,
+						})
+						Expect(err).ToNot(HaveOccurred())
+
+						location := ctx.SMWithOAuth.POST(web.OperationsURL + "/" + operationID + web.OperationRetryURL).Expect().
+							Status(http.StatusAccepted).
+							Header("Location").Raw()
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.SUCCEEDED)))
+						ctx.SMWithOAuth.GET(web.ServiceBrokersURL + "/123").Expect().Status(http.StatusNotFound)
+					})
+
+					It("applies the changes of a failed update operation again", func() {
+						brokerServer.CatalogHandler = func(rw http.ResponseWriter, req *http.Request) {
+							SetResponse(rw, http.StatusInternalServerError, Object{})
+						}
+						location, operationID := updateBroker()
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.FAILED)))
+						ctx.SMWithOAuth.GET(web.ServiceBrokersURL+"/123").Expect().Status(http.StatusOK).
+							JSON().Object().ValueEqual("description", "")
+
+						brokerServer.ResetHandlers()
+						ctx.SMWithOAuth.POST(web.OperationsURL + "/" + operationID + web.OperationRetryURL).Expect().
+							Status(http.StatusAccepted)
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.SUCCEEDED)))
+						ctx.SMWithOAuth.GET(web.ServiceBrokersURL+"/123").Expect().Status(http.StatusOK).
+							JSON().Object().ValueEqual("description", "updated")
+					})
+
+					It("does not retry operations whose resource no longer exists", func() {
+						UUID, err := uuid.NewV4()
+						Expect(err).ToNot(HaveOccurred())
+						operationID := UUID.String()
+						_, err = ctx.SMRepository.Create(context.Background(), &types.Operation{
+							Base: types.Base{
+								ID:        operationID,
+								CreatedAt: time.Now(),
+								UpdatedAt: time.Now(),
+								Labels:    make(map[string][]string),
+								Ready:     true,
+							},
+							Type:          types.CREATE,
+							State:         types.FAILED,
+							ResourceID:    "missing",
+							ResourceType:  types.ServiceBrokerType,
+							PlatformID:    types.SMPlatform,
+							CorrelationID: operationID,
+						})
+						Expect(err).ToNot(HaveOccurred())
+
+						ctx.SMWithOAuth.POST(web.OperationsURL + "/" + operationID + web.OperationRetryURL).Expect().
+							Status(http.StatusConflict)
+					})
+
+					It("does not retry operations which did not fail", func() {
+						location, operationID := updateBroker()
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.SUCCEEDED)))
+
+						ctx.SMWithOAuth.POST(web.OperationsURL + "/" + operationID + web.OperationRetryURL).Expect().Status(http.StatusConflict)
+					})
+				})
+
+				When("an operation is scheduled while the job queue is disabled", func() {
+					BeforeEach(func() {
+						ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+							e.Set("operations.job_queue_enabled", false)
+							e.Set("operations.maintainer_retry_interval", 1*time.Second)
+						}).Build()
+					})
+
+					It("starts the operation from the Maintainer once it is due", func() {
+						_, instance := CreateInstanceInPlatform(ctx, types.SMPlatform)
+
+						scheduledAt := time.Now().Add(2 * time.Second).UTC().Format(time.RFC3339)
+						location := ctx.SMWithOAuth.PATCH(web.ServiceInstancesURL+"/"+instance.ID).
+							WithQuery(web.QueryParamScheduledAt, scheduledAt).
+							WithJSON(Object{"name": "scheduled-name"}).
+							Expect().Status(http.StatusAccepted).
+							Header("Location").Raw()
+						ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).
+							JSON().Object().ValueEqual("state", string(types.PENDING))
+
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 20*time.Second).Should(Equal(string(types.SUCCEEDED)))
+						ctx.SMWithOAuth.GET(web.ServiceInstancesURL+"/"+instance.ID).Expect().Status(http.StatusOK).
+							JSON().Object().ValueEqual("name", "scheduled-name")
+					})
+				})
+
+				When("an action fails with a transient error", func() {
+					var brokerServer *BrokerServer
+
+					BeforeEach(func() {
+						ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+							e.Set("operations.default_retry_policy.max_action_retries", 1)
+						}).Build()
+						brokerServer = NewBrokerServer()
+						ctx.Servers[BrokerServerPrefix+"123"] = brokerServer
+						ctx.SMWithOAuth.POST(web.ServiceBrokersURL).WithJSON(Object{
+							"id":         "123",
+							"name":       "test-broker",
+							"broker_url": brokerServer.URL(),
+							"credentials": Object{
+								"basic": Object{
+									"username": brokerServer.Username,
+									"password": brokerServer.Password,
+								},
+							},
+						}).Expect().Status(http.StatusCreated)
+					})
+
+					// unreachableCatalogHandler drops the connections of the given number of catalog requests before it responds
+					unreachableCatalogHandler := func(failures int) http.HandlerFunc {
+						var mutex sync.Mutex
+						return func(rw http.ResponseWriter, req *http.Request) {
+							mutex.Lock()
+							defer mutex.Unlock()
+							if failures == 0 {
+								SetResponse(rw, http.StatusOK, JSONToMap(string(brokerServer.Catalog)))
+								return
+							}
+							failures--
+							conn, _, err := rw.(http.Hijacker).Hijack()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(conn.Close()).To(Succeed())
+						}
+					}
+
+					updateBroker := func() string {
+						return ctx.SMWithOAuth.PATCH(web.ServiceBrokersURL+"/123").WithJSON(Object{"description": "updated"}).
+							WithQuery("async", "true").
+							Expect().Status(http.StatusAccepted).
+							Header("Location").Raw()
+					}
+
+					It("retries the action automatically", func() {
+						brokerServer.CatalogHandler = unreachableCatalogHandler(1)
+						location := updateBroker()
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.SUCCEEDED)))
+						ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().ValueEqual("retries", 1)
+						ctx.SMWithOAuth.GET(web.ServiceBrokersURL+"/123").Expect().Status(http.StatusOK).
+							JSON().Object().ValueEqual("description", "updated")
+					})
+
+					It("fails the operation once the retries of the retry policy are exhausted", func() {
+						brokerServer.CatalogHandler = unreachableCatalogHandler(2)
+						location := updateBroker()
+						Eventually(func() string {
+							return ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+						}, 10*time.Second).Should(Equal(string(types.FAILED)))
+						ctx.SMWithOAuth.GET(location).Expect().Status(http.StatusOK).JSON().Object().ValueEqual("retries", 1)
+					})
+				})
+			})
+
+			Context("Listing", func() {
+				var failedCreateID string
+
+				createOperation := func(opType types.OperationCategory, state types.OperationState, resourceType types.ObjectType) string {
+					UUID, err := uuid.NewV4()
+					Expect(err).ToNot(HaveOccurred())
+					_, err = ctx.SMRepository.Create(context.Background(), &types.Operation{
+						Base: types.Base{
+							ID:        UUID.String(),
+							CreatedAt: time.Now(),
+							UpdatedAt: time.Now(),
+							Labels:    make(map[string][]string),
+							Ready:     true,
+						},
+						Type:          opType,
+						State:         state,
+						ResourceID:    UUID.String(),
+						ResourceType:  resourceType,
+						PlatformID:    types.SMPlatform,
+						CorrelationID: UUID.String(),
+					})
+					Expect(err).ToNot(HaveOccurred())
+					return UUID.String()
+				}
+
+				BeforeEach(func() {
+					ctx = NewTestContextBuilder().Build()
+					failedCreateID = createOperation(types.CREATE, types.FAILED, types.ServiceInstanceType)
+					createOperation(types.CREATE, types.SUCCEEDED, types.ServiceInstanceType)
+					createOperation(types.DELETE, types.FAILED, types.ServiceInstanceType)
+					createOperation(types.CREATE, types.FAILED, types.ServiceBindingType)
+				})
+
+				It("lists the operations matching the field query", func() {
+					fieldQuery := fmt.Sprintf("state eq '%s' and type eq '%s' and resource_type eq '%s' and created_at gt '%s'",
+						types.FAILED, types.CREATE, types.ServiceInstanceType, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+					items := ctx.SMWithOAuth.GET(web.OperationsURL).WithQuery("fieldQuery", fieldQuery).
+						Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+					items.Length().Equal(1)
+					items.First().Object().Value("id").Equal(failedCreateID)
+				})
+
+				It("returns a single operation by id", func() {
+					ctx.SMWithOAuth.GET(web.OperationsURL+"/"+failedCreateID).
+						Expect().Status(http.StatusOK).JSON().Object().
+						ValueEqual("id", failedCreateID).
+						ValueEqual("state", string(types.FAILED))
+				})
 			})
 
 			Context("Jobs", func() {
@@ -197,9 +664,11 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					}
 
 					ctx = NewTestContextBuilder().WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+						settings := operations.DefaultSettings()
+						settings.ApplyReschedulingInterval()
 						testController := panicController{
 							operation: operation,
-							scheduler: operations.NewScheduler(ctx, smb.Storage, operations.DefaultSettings(), 10, &sync.WaitGroup{}),
+							scheduler: operations.NewScheduler(ctx, smb.Storage, settings, 10, &sync.WaitGroup{}),
 						}
 
 						smb.RegisterControllers(testController)
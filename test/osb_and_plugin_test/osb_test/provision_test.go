@@ -286,6 +286,30 @@ var _ = Describe("Provision", func() {
 		})
 	})
 
+	Context("when instance is provisioned for a tenant", func() {
+		It("lists the operations of the instance for the tenant", func() {
+			brokerServer.ServiceInstanceHandler = parameterizedHandler(http.StatusCreated, `{}`)
+			ctx.SMWithBasic.PUT(smBrokerURL+"/v2/service_instances/"+SID).
+				WithHeader(brokerAPIVersionHeaderKey, brokerAPIVersionHeaderValue).
+				WithJSON(provisionRequestBodyMap()()).Expect().Status(http.StatusCreated)
+
+			brokerServer.ServiceInstanceHandler = parameterizedHandler(http.StatusOK, `{}`)
+			ctx.SMWithBasic.DELETE(smBrokerURL+"/v2/service_instances/"+SID).
+				WithHeader(brokerAPIVersionHeaderKey, brokerAPIVersionHeaderValue).
+				Expect().Status(http.StatusOK)
+
+			operations := ctx.SMWithOAuthForTenant.GET(web.OperationsURL).
+				WithQuery("fieldQuery", fmt.Sprintf("resource_id eq '%s'", SID)).
+				Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+			operations.Length().Equal(2)
+			operations.Path("$[*].type").Array().ContainsOnly(string(types.CREATE), string(types.DELETE))
+
+			ctx.NewTenantExpect("tenancyClient", "other-tenant").GET(web.OperationsURL).
+				WithQuery("fieldQuery", fmt.Sprintf("resource_id eq '%s'", SID)).
+				Expect().Status(http.StatusOK).JSON().Object().Value("items").Array().Empty()
+		})
+	})
+
 	Context("when call contains query params", func() {
 		It("propagates them to the service broker", func() {
 			headerKey, headerValue := generateRandomQueryParam()
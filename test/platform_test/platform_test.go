@@ -294,6 +294,53 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					})
 				})
 
+				Context("With If-Match header", func() {
+					var etag string
+
+					BeforeEach(func() {
+						etag = ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + id).
+							Expect().
+							Status(http.StatusOK).Header("ETag").NotEmpty().Raw()
+					})
+
+					It("returns 200 and the new ETag when the ETag is current", func() {
+						newETag := ctx.SMWithOAuth.PATCH(web.PlatformsURL+"/"+id).
+							WithHeader("If-Match", etag).
+							WithJSON(common.Object{"description": "updated"}).
+							Expect().
+							Status(http.StatusOK).Header("ETag").NotEqual(etag).Raw()
+
+						ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + id).
+							Expect().
+							Status(http.StatusOK).Header("ETag").Equal(newETag)
+					})
+
+					It("returns 412 when the platform was modified in the meantime", func() {
+						ctx.SMWithOAuth.PATCH(web.PlatformsURL + "/" + id).
+							WithJSON(common.Object{"description": "updated"}).
+							Expect().
+							Status(http.StatusOK)
+
+						ctx.SMWithOAuth.PATCH(web.PlatformsURL+"/"+id).
+							WithHeader("If-Match", etag).
+							WithJSON(common.Object{"description": "stale"}).
+							Expect().
+							Status(http.StatusPreconditionFailed)
+
+						ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + id).
+							Expect().
+							Status(http.StatusOK).JSON().Object().Value("description").Equal("updated")
+					})
+
+					It("returns 412 for weak ETags", func() {
+						ctx.SMWithOAuth.PATCH(web.PlatformsURL+"/"+id).
+							WithHeader("If-Match", "W/"+etag).
+							WithJSON(common.Object{"description": "updated"}).
+							Expect().
+							Status(http.StatusPreconditionFailed)
+					})
+				})
+
 				Context("With created_at in body", func() {
 					It("should not update created_at", func() {
 						By("Update platform")
@@ -574,6 +621,41 @@ var _ = test.DescribeTestsFor(test.TestCase{
 					})
 				})
 
+				Context("with If-Match header", func() {
+					const unusedPlatformID = "p2"
+					var etag string
+
+					BeforeEach(func() {
+						etag = ctx.SMWithOAuth.POST(web.PlatformsURL).
+							WithJSON(common.MakePlatform(unusedPlatformID, "cf-20", "cf", "descr")).
+							Expect().
+							Status(http.StatusCreated).Header("ETag").NotEmpty().Raw()
+					})
+
+					It("should return 412 when the platform was modified in the meantime", func() {
+						ctx.SMWithOAuth.PATCH(web.PlatformsURL + "/" + unusedPlatformID).
+							WithJSON(common.Object{"description": "updated"}).
+							Expect().
+							Status(http.StatusOK)
+
+						ctx.SMWithOAuth.DELETE(web.PlatformsURL+"/"+unusedPlatformID).
+							WithHeader("If-Match", etag).
+							Expect().
+							Status(http.StatusPreconditionFailed)
+					})
+
+					It("should delete the platform when the ETag is current", func() {
+						ctx.SMWithOAuth.DELETE(web.PlatformsURL+"/"+unusedPlatformID).
+							WithHeader("If-Match", etag).
+							Expect().
+							Status(http.StatusOK)
+
+						ctx.SMWithOAuth.GET(web.PlatformsURL + "/" + unusedPlatformID).
+							Expect().
+							Status(http.StatusNotFound)
+					})
+				})
+
 				Context("with active platform", func() {
 					var makePlatformActive = func(platformID string) {
 						err := ctx.SMRepository.InTransaction(context.TODO(), func(ctx context.Context, storage storage.Repository) error {
@@ -624,6 +706,30 @@ var _ = test.DescribeTestsFor(test.TestCase{
 			})
 
 			Describe("GET", func() {
+				Context("With order by query", func() {
+					BeforeEach(func() {
+						common.RegisterPlatformInSM(common.GenerateRandomPlatform(), ctx.SMWithOAuth, nil)
+						common.RegisterPlatformInSM(common.GenerateRandomPlatform(), ctx.SMWithOAuth, nil)
+					})
+
+					It("should page by columns which are not fields of the platforms", func() {
+						token := ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("orderBy", "active desc,id").WithQuery("max_items", 1).
+							Expect().Status(http.StatusOK).JSON().Path("$.token").String().Raw()
+						ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("orderBy", "active desc,id").WithQuery("token", token).
+							Expect().Status(http.StatusOK).JSON().Path("$.items").Array().NotEmpty()
+					})
+
+					It("should return 400 when ordering by fields which are not columns", func() {
+						ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("orderBy", "credentials asc").
+							Expect().Status(http.StatusBadRequest)
+					})
+
+					It("should return 400 when ordering by secured columns", func() {
+						ctx.SMWithOAuth.GET(web.PlatformsURL).WithQuery("orderBy", "username asc").
+							Expect().Status(http.StatusBadRequest)
+					})
+				})
+
 				Context("Technical Platform", func() {
 					var opID string
 					BeforeEach(func() {
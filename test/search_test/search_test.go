@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package search_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	"github.com/gavv/httpexpect"
+	"github.com/tidwall/sjson"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSearch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Search Tests Suite")
+}
+
+var _ = Describe("Search", func() {
+	var ctx *common.TestContext
+	var planID string
+
+	BeforeSuite(func() {
+		ctx = common.NewTestContextBuilderWithSecurity().Build()
+
+		plan, err := sjson.Set(common.GeneratePaidTestPlan(), "name", "marmalade-plan")
+		Expect(err).ToNot(HaveOccurred())
+		plan, err = sjson.Set(plan, "description", "orange jam storage")
+		Expect(err).ToNot(HaveOccurred())
+		catalog := common.NewEmptySBCatalog()
+		catalog.AddService(common.GenerateTestServiceWithPlans(plan))
+		ctx.RegisterBrokerWithCatalog(catalog)
+
+		planID = ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, "fieldQuery=name eq 'marmalade-plan'").
+			First().Object().Value("id").String().Raw()
+	})
+
+	AfterSuite(func() {
+		if ctx != nil {
+			ctx.Cleanup()
+		}
+	})
+
+	It("returns 400 when the search text is missing", func() {
+		ctx.SMWithOAuth.GET(web.SearchURL).
+			Expect().Status(http.StatusBadRequest)
+	})
+
+	It("returns 400 when the search text contains no words", func() {
+		ctx.SMWithOAuth.GET(web.SearchURL).WithQuery(web.QueryParamSearchText, "&|!").
+			Expect().Status(http.StatusBadRequest)
+	})
+
+	It("matches prefixes of words in the name", func() {
+		resp := ctx.SMWithOAuth.GET(web.SearchURL).WithQuery(web.QueryParamSearchText, "marma").
+			Expect().Status(http.StatusOK).JSON().Object()
+
+		resp.Value("service_plans").Object().Value("num_items").Number().Equal(1)
+		resp.Value("service_plans").Object().Value("items").Array().First().Object().Value("id").Equal(planID)
+		resp.Value("service_offerings").Object().Value("num_items").Number().Equal(0)
+	})
+
+	It("matches words in the description", func() {
+		ctx.SMWithOAuth.GET(web.SearchURL).WithQuery(web.QueryParamSearchText, "orange jam").
+			Expect().Status(http.StatusOK).JSON().
+			Path("$.service_plans.items[*].id").Array().Contains(planID)
+	})
+
+	It("requires all words to match", func() {
+		ctx.SMWithOAuth.GET(web.SearchURL).WithQuery(web.QueryParamSearchText, "marmalade apple").
+			Expect().Status(http.StatusOK).JSON().
+			Path("$.service_plans.num_items").Number().Equal(0)
+	})
+
+	It("returns only the number of matches when max_items is 0", func() {
+		resp := ctx.SMWithOAuth.GET(web.SearchURL).
+			WithQuery(web.QueryParamSearchText, "marmalade").
+			WithQuery("max_items", 0).
+			Expect().Status(http.StatusOK).JSON().Object()
+
+		resp.Path("$.service_plans.num_items").Number().Equal(1)
+		resp.Path("$.service_plans.items").Array().Empty()
+	})
+
+	Context("with platform credentials", func() {
+		var k8sAgent *common.SMExpect
+
+		BeforeEach(func() {
+			platformJSON := common.MakePlatform("search-k8s-platform", "search-k8s-platform", "kubernetes", "test-platform-k8s")
+			platform := common.RegisterPlatformInSM(platformJSON, ctx.SMWithOAuth, map[string]string{})
+			username, password := platform.Credentials.Basic.Username, platform.Credentials.Basic.Password
+			k8sAgent = &common.SMExpect{Expect: ctx.SM.Builder(func(req *httpexpect.Request) {
+				req.WithBasicAuth(username, password)
+			})}
+		})
+
+		AfterEach(func() {
+			ctx.CleanupAdditionalResources()
+		})
+
+		It("returns only the plans visible to the platform", func() {
+			k8sAgent.GET(web.SearchURL).WithQuery(web.QueryParamSearchText, "marmalade").
+				Expect().Status(http.StatusOK).JSON().
+				Path("$.service_plans.num_items").Number().Equal(0)
+
+			common.RegisterVisibilityForPlanAndPlatform(ctx.SMWithOAuth, planID, "")
+
+			k8sAgent.GET(web.SearchURL).WithQuery(web.QueryParamSearchText, "marmalade").
+				Expect().Status(http.StatusOK).JSON().
+				Path("$.service_plans.items[*].id").Array().Contains(planID)
+		})
+	})
+})
@@ -1661,6 +1661,39 @@ var _ = DescribeTestsFor(TestCase{
 										Type: types.ServiceBindingType,
 									})
 								})
+
+								It("deletes the binding once the scheduled operation is due", func() {
+									resp := createBinding(ctx.SMWithOAuthForTenant, testCase.async, testCase.expectedCreateSuccessStatusCode)
+									bindingID, _ = VerifyOperationExists(ctx, resp.Header("Location").Raw(), OperationExpectations{
+										Category:          types.CREATE,
+										State:             types.SUCCEEDED,
+										ResourceType:      types.ServiceBindingType,
+										Reschedulable:     false,
+										DeletionScheduled: false,
+									})
+
+									resp = ctx.SMWithOAuthForTenant.DELETE(web.ServiceBindingsURL+"/"+bindingID).
+										WithQuery(web.QueryParamScheduledAt, time.Now().Add(2*time.Second).UTC().Format(time.RFC3339)).
+										Expect().
+										Status(http.StatusAccepted)
+									location := resp.Header("Location").Raw()
+									ctx.SMWithOAuthForTenant.GET(location).Expect().
+										Status(http.StatusOK).
+										JSON().Object().Value("state").Equal(string(types.PENDING))
+									VerifyResourceExists(ctx.SMWithOAuthForTenant, ResourceExpectations{
+										ID:    bindingID,
+										Type:  types.ServiceBindingType,
+										Ready: true,
+									})
+
+									Eventually(func() string {
+										return ctx.SMWithOAuthForTenant.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+									}, 20*time.Second).Should(Equal(string(types.SUCCEEDED)))
+									VerifyResourceDoesNotExist(ctx.SMWithOAuthForTenant, ResourceExpectations{
+										ID:   bindingID,
+										Type: types.ServiceBindingType,
+									})
+								})
 							})
 						})
 
@@ -39,7 +39,9 @@ import (
 
 	"strconv"
 
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/Peripli/service-manager/pkg/types"
@@ -393,6 +395,56 @@ var _ = DescribeTestsFor(TestCase{
 				})
 			})
 
+			Describe("operations", func() {
+				var tenantInstanceID string
+				var otherTenantInstanceID string
+
+				BeforeEach(func() {
+					EnsurePublicPlanVisibility(ctx.SMRepository, servicePlanID)
+					createInstance(ctx.SMWithOAuthForTenant, "false", http.StatusCreated)
+					tenantInstanceID = instanceID
+
+					postInstanceRequest["name"] = "other-tenant-instance"
+					createInstance(ctx.NewTenantExpect("tenancyClient", "other-tenant"), "false", http.StatusCreated)
+					otherTenantInstanceID = instanceID
+				})
+
+				It("lists only the operations of the instances of the tenant", func() {
+					operations := ctx.SMWithOAuthForTenant.GET(web.OperationsURL).
+						Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+					operations.Length().Equal(1)
+					operations.First().Object().
+						ValueEqual("resource_id", tenantInstanceID).
+						ValueEqual("type", string(types.CREATE))
+
+					otherTenantOperationID := ctx.SMWithOAuth.GET(web.OperationsURL).
+						WithQuery("fieldQuery", fmt.Sprintf("resource_id eq '%s'", otherTenantInstanceID)).
+						Expect().Status(http.StatusOK).JSON().Object().Value("items").Array().
+						First().Object().Value("id").String().Raw()
+					ctx.SMWithOAuthForTenant.GET(web.OperationsURL + "/" + otherTenantOperationID).
+						Expect().Status(http.StatusNotFound)
+				})
+
+				When("the instance is updated by a request without a tenant", func() {
+					It("lists the operation for the tenant of the instance", func() {
+						patchInstanceRequest["name"] = "renamed-instance"
+						patchInstance(ctx.SMWithOAuth, "false", tenantInstanceID, http.StatusOK)
+
+						operations := ctx.SMWithOAuthForTenant.GET(web.OperationsURL).
+							WithQuery("fieldQuery", fmt.Sprintf("type eq '%s'", types.UPDATE)).
+							Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+						operations.Length().Equal(1)
+						operations.First().Object().
+							ValueEqual("resource_id", tenantInstanceID).
+							Path(fmt.Sprintf("$.labels[%s][*]", TenantIdentifier)).Array().Contains(TenantIDValue)
+
+						ctx.NewTenantExpect("tenancyClient", "other-tenant").GET(web.OperationsURL).
+							WithQuery("fieldQuery", fmt.Sprintf("type eq '%s'", types.UPDATE)).
+							Expect().Status(http.StatusOK).JSON().Object().Value("items").Array().Empty()
+					})
+				})
+			})
+
 			Describe("POST", func() {
 				for _, testCase := range testCases {
 					testCase := testCase
@@ -1297,6 +1349,45 @@ var _ = DescribeTestsFor(TestCase{
 										Type: types.ServiceInstanceType,
 									}, testCase.async, false)
 								})
+
+								if testCase.async == "true" {
+									It("provisions the stored instance again once the operation is retried without deprovisioning it", func() {
+										resp := createInstance(ctx.SMWithOAuthForTenant, testCase.async, testCase.expectedBrokerFailureStatusCode)
+
+										var operationID string
+										instanceID, operationID = VerifyOperationExists(ctx, resp.Header("Location").Raw(), OperationExpectations{
+											Category:          types.CREATE,
+											State:             types.FAILED,
+											ResourceType:      types.ServiceInstanceType,
+											Reschedulable:     false,
+											DeletionScheduled: false,
+										})
+
+										brokerServer.ServiceInstanceHandlerFunc(http.MethodPut, http.MethodPut+"3", ParameterizedHandler(http.StatusCreated, Object{"async": false}))
+										brokerServer.ResetCallHistory()
+
+										location := ctx.SMWithOAuth.POST(web.OperationsURL + "/" + operationID + web.OperationRetryURL).Expect().
+											Status(http.StatusAccepted).
+											Header("Location").Raw()
+										VerifyOperationExists(ctx, location, OperationExpectations{
+											Category:          types.CREATE,
+											State:             types.SUCCEEDED,
+											ResourceType:      types.ServiceInstanceType,
+											Reschedulable:     false,
+											DeletionScheduled: false,
+										})
+
+										VerifyResourceExists(ctx.SMWithOAuthForTenant, ResourceExpectations{
+											ID:    instanceID,
+											Type:  types.ServiceInstanceType,
+											Ready: true,
+										})
+										for _, req := range brokerServer.ServiceInstanceEndpointRequests {
+											Expect(req.Method).To(Equal(http.MethodPut))
+										}
+										Expect(brokerServer.ServiceInstanceEndpointRequests).To(HaveLen(1))
+									})
+								}
 							})
 
 							When("provision responds with error that requires orphan mitigation", func() {
@@ -1801,6 +1892,70 @@ var _ = DescribeTestsFor(TestCase{
 													Type: types.ServiceInstanceType,
 												})
 											})
+
+											It("keeps the previous values of the instance stored by the old platform", func() {
+												By("update the instance in the old platform")
+												testCtx.SMWithBasic.PATCH("/v1/osb/"+brokerID+"/v2/service_instances/"+SID).
+													WithHeader(brokerAPIVersionHeaderKey, brokerAPIVersionHeaderValue).
+													WithJSON(Object{
+														"service_id": service1CatalogID,
+														"plan_id":    plan1CatalogID,
+														"context": Object{
+															TenantIdentifier: TenantIDValue,
+														},
+														"previous_values": Object{
+															"service_id": service1CatalogID,
+															"plan_id":    plan1CatalogID,
+														},
+													}).
+													Expect().Status(http.StatusOK)
+
+												storedInstance := func() *types.ServiceInstance {
+													object, err := testCtx.SMRepository.Get(context.TODO(), types.ServiceInstanceType, query.ByField(query.EqualsOperator, "id", SID))
+													Expect(err).ToNot(HaveOccurred())
+													return object.(*types.ServiceInstance)
+												}
+
+												By("fail the transfer of the instance to SMaaP")
+												brokerServer.ServiceInstanceHandlerFunc(http.MethodPatch, http.MethodPatch+"1", ParameterizedHandler(http.StatusAccepted, Object{"async": true}))
+												brokerServer.ServiceInstanceLastOpHandlerFunc(http.MethodPatch+"1", ParameterizedHandler(http.StatusOK, Object{"state": "failed"}))
+												resp := testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+SID).
+													WithQuery("async", testCase.async == "true").
+													WithJSON(Object{"platform_id": types.SMPlatform}).
+													Expect().Status(testCase.responseByBrokerOrClientMode(testCase.expectedBrokerFailureStatusCode, http.StatusAccepted))
+
+												VerifyOperationExists(testCtx, resp.Header("Location").Raw(), OperationExpectations{
+													Category:          types.UPDATE,
+													State:             types.FAILED,
+													ResourceType:      types.ServiceInstanceType,
+													Reschedulable:     false,
+													DeletionScheduled: false,
+												})
+
+												instance := storedInstance()
+												Expect(instance.PlatformID).To(Equal(testCtx.TestPlatform.ID))
+												Expect(instance.UpdateValues.ServiceInstance).To(BeNil())
+												Expect(gjson.GetBytes(instance.PreviousValues, "plan_id").String()).To(Equal(plan1CatalogID))
+
+												By("transfer the instance to SMaaP")
+												brokerServer.ServiceInstanceHandlerFunc(http.MethodPatch, http.MethodPatch, ParameterizedHandler(http.StatusOK, Object{}))
+												resp = testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+SID).
+													WithQuery("async", testCase.async == "true").
+													WithJSON(Object{"platform_id": types.SMPlatform}).
+													Expect().Status(testCase.expectedUpdateSuccessStatusCode)
+
+												VerifyOperationExists(testCtx, resp.Header("Location").Raw(), OperationExpectations{
+													Category:          types.UPDATE,
+													State:             types.SUCCEEDED,
+													ResourceType:      types.ServiceInstanceType,
+													Reschedulable:     false,
+													DeletionScheduled: false,
+												})
+
+												instance = storedInstance()
+												Expect(instance.PlatformID).To(Equal(types.SMPlatform))
+												Expect(gjson.GetBytes(instance.PreviousValues, "plan_id").String()).To(Equal(plan1CatalogID))
+											})
 										})
 									})
 								})
@@ -1901,6 +2056,192 @@ var _ = DescribeTestsFor(TestCase{
 								})
 							})
 
+							When("scheduled_at is provided", func() {
+								operationState := func(location string) string {
+									return testCtx.SMWithOAuthForTenant.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+								}
+
+								It("returns 400 if it is not in the future", func() {
+									testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery(web.QueryParamScheduledAt, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)).
+										WithJSON(Object{"name": "scheduled-name"}).
+										Expect().Status(http.StatusBadRequest)
+								})
+
+								It("stores a pending operation which is executed once it is due", func() {
+									scheduledAt := time.Now().Add(2 * time.Second).UTC().Format(time.RFC3339)
+									resp := testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery(web.QueryParamScheduledAt, scheduledAt).
+										WithJSON(Object{"name": "scheduled-name"}).
+										Expect().Status(http.StatusAccepted)
+									location := resp.Header("Location").Raw()
+
+									operation := testCtx.SMWithOAuthForTenant.GET(location).Expect().Status(http.StatusOK).JSON().Object()
+									operation.Value("state").Equal(string(types.PENDING))
+									operation.Value("scheduled_at").Equal(scheduledAt)
+
+									Eventually(func() string {
+										return operationState(location)
+									}, 20*time.Second).Should(Equal(string(types.SUCCEEDED)))
+									testCtx.SMWithOAuthForTenant.GET(web.ServiceInstancesURL + "/" + instanceID).Expect().
+										Status(http.StatusOK).
+										JSON().Object().Value("name").Equal("scheduled-name")
+								})
+
+								It("does not execute scheduled operations which are canceled", func() {
+									resp := testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery(web.QueryParamScheduledAt, time.Now().Add(2*time.Second).UTC().Format(time.RFC3339)).
+										WithJSON(Object{"name": "scheduled-name"}).
+										Expect().Status(http.StatusAccepted)
+									location := resp.Header("Location").Raw()
+
+									testCtx.SMWithOAuthForTenant.POST(location + web.OperationCancelURL).Expect().
+										Status(http.StatusOK).
+										JSON().Object().Value("state").Equal(string(types.CANCELED))
+									Consistently(func() string {
+										return operationState(location)
+									}, 5*time.Second).Should(Equal(string(types.CANCELED)))
+									testCtx.SMWithOAuthForTenant.GET(web.ServiceInstancesURL + "/" + instanceID).Expect().
+										Status(http.StatusOK).
+										JSON().Object().Value("name").NotEqual("scheduled-name")
+								})
+							})
+
+							When("the tenant has maintenance windows", func() {
+								var windowStart time.Time
+
+								BeforeEach(func() {
+									windowDay := time.Now().UTC().AddDate(0, 0, 2)
+									windowStart = time.Date(windowDay.Year(), windowDay.Month(), windowDay.Day(), 0, 0, 0, 0, time.UTC)
+									testCtx = t.ContextBuilder.WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+										e.Set("operations.maintenance_windows", []interface{}{
+											map[string]interface{}{
+												"tenant":   TenantIDValue,
+												"days":     []string{strings.ToLower(windowDay.Weekday().String())},
+												"start":    "00:00",
+												"duration": "1h",
+											},
+										})
+									}).BuildWithoutCleanup()
+								})
+
+								AfterEach(func() {
+									testCtx.CleanupAll(false)
+								})
+
+								It("defers plan upgrades to the next maintenance window", func() {
+									resp := testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery("async", testCase.async == "true").
+										WithJSON(Object{"maintenance_info": Object{"version": "2.0.0"}}).
+										Expect().Status(http.StatusAccepted)
+
+									operation := testCtx.SMWithOAuthForTenant.GET(resp.Header("Location").Raw()).Expect().
+										Status(http.StatusOK).
+										JSON().Object()
+									operation.Value("state").Equal(string(types.PENDING))
+									operation.Value("scheduled_at").Equal(windowStart.Format(time.RFC3339))
+								})
+
+								It("does not defer other updates", func() {
+									testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery("async", testCase.async == "true").
+										WithJSON(Object{"name": "updated-name"}).
+										Expect().Status(testCase.expectedUpdateSuccessStatusCode)
+								})
+							})
+
+							When("a pre hook approves updates", func() {
+								var hookServer *httptest.Server
+								var hookStatus int32
+								var invalidSignatures int32
+
+								BeforeEach(func() {
+									atomic.StoreInt32(&hookStatus, http.StatusOK)
+									atomic.StoreInt32(&invalidSignatures, 0)
+									hookServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+										body, _ := ioutil.ReadAll(r.Body)
+										timestamp := r.Header.Get(operations.HookTimestampHeader)
+										if r.Header.Get(operations.HookSignatureHeader) != operations.SignHookRequest("hook-secret", timestamp, body) {
+											atomic.AddInt32(&invalidSignatures, 1)
+										}
+										w.WriteHeader(int(atomic.LoadInt32(&hookStatus)))
+										fmt.Fprint(w, `{"description":"ticket approval"}`)
+									}))
+									testCtx = t.ContextBuilder.WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+										e.Set("operations.hook_retry_interval", "1s")
+										e.Set("operations.hooks", []interface{}{
+											map[string]interface{}{
+												"name":            "ticket-approval",
+												"url":             hookServer.URL,
+												"secret":          "hook-secret",
+												"phase":           "pre",
+												"resources":       []string{types.ServiceInstanceType.String()},
+												"operation_types": []string{string(types.UPDATE)},
+											},
+										})
+									}).BuildWithoutCleanup()
+								})
+
+								AfterEach(func() {
+									testCtx.CleanupAll(false)
+									hookServer.Close()
+									Expect(atomic.LoadInt32(&invalidSignatures)).To(BeZero())
+								})
+
+								It("executes approved updates", func() {
+									testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery("async", testCase.async == "true").
+										WithJSON(Object{"name": "approved-name"}).
+										Expect().Status(testCase.expectedUpdateSuccessStatusCode)
+								})
+
+								It("rejects updates rejected by the hook", func() {
+									atomic.StoreInt32(&hookStatus, http.StatusForbidden)
+									testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery("async", testCase.async == "true").
+										WithJSON(Object{"name": "rejected-name"}).
+										Expect().Status(http.StatusForbidden).
+										JSON().Object().Value("description").String().Contains("ticket approval")
+								})
+
+								It("keeps deferred updates pending until they are approved", func() {
+									atomic.StoreInt32(&hookStatus, http.StatusAccepted)
+									resp := testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery("async", testCase.async == "true").
+										WithJSON(Object{"name": "deferred-name"}).
+										Expect().Status(http.StatusAccepted)
+									location := resp.Header("Location").Raw()
+
+									operationState := func() string {
+										return testCtx.SMWithOAuthForTenant.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+									}
+									Consistently(operationState, 3*time.Second).Should(Equal(string(types.PENDING)))
+
+									atomic.StoreInt32(&hookStatus, http.StatusOK)
+									Eventually(operationState, 20*time.Second).Should(Equal(string(types.SUCCEEDED)))
+									testCtx.SMWithOAuthForTenant.GET(web.ServiceInstancesURL + "/" + instanceID).Expect().
+										Status(http.StatusOK).
+										JSON().Object().Value("name").Equal("deferred-name")
+								})
+
+								It("defers updates while the hook is rate limited", func() {
+									atomic.StoreInt32(&hookStatus, http.StatusTooManyRequests)
+									resp := testCtx.SMWithOAuthForTenant.PATCH(web.ServiceInstancesURL+"/"+instanceID).
+										WithQuery("async", testCase.async == "true").
+										WithJSON(Object{"name": "rate-limited-name"}).
+										Expect().Status(http.StatusAccepted)
+									location := resp.Header("Location").Raw()
+
+									operationState := func() string {
+										return testCtx.SMWithOAuthForTenant.GET(location).Expect().Status(http.StatusOK).JSON().Object().Value("state").String().Raw()
+									}
+									Consistently(operationState, 3*time.Second).Should(Equal(string(types.PENDING)))
+
+									atomic.StoreInt32(&hookStatus, http.StatusOK)
+									Eventually(operationState, 20*time.Second).Should(Equal(string(types.SUCCEEDED)))
+								})
+							})
+
 							When("platform_id provided in body", func() {
 								AfterEach(func() {
 									objAfterUpdate := VerifyResourceExists(ctx.SMWithOAuthForTenant, ResourceExpectations{
@@ -168,6 +168,14 @@ var _ = test.DescribeTestsFor(test.TestCase{
 									"fieldQuery": fmt.Sprintf("catalog_name eq '%s'", planCatalogName),
 								}, nil...)
 						})
+
+						It("should return an empty aggregation", func() {
+							aggregation := k8sAgent.GET(web.ServicePlansURL+web.ResourceAggregationsURL).WithQuery("group_by", "ready").
+								Expect().Status(http.StatusOK).JSON().Object()
+							aggregation.Value("group_by").String().Equal("ready")
+							aggregation.Value("num_items").Number().Equal(0)
+							aggregation.Value("groups").Array().Empty()
+						})
 					})
 
 					Context("with public visibility for plan", func() {
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/postgres"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Peripli/service-manager/test/common"
+)
+
+var _ = Describe("Job queue", func() {
+	const resourceType = types.ServiceInstanceType
+
+	var ctx *common.TestContext
+	var queue *postgres.Storage
+
+	BeforeEach(func() {
+		// the job queue of the Service Manager is disabled, so that it does not lease the jobs of the test
+		ctx = common.NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]common.FakeServer) {
+			e.Set("operations.job_queue_enabled", false)
+		}).Build()
+
+		queue = &postgres.Storage{
+			ConnectFunc: func(driver string, url string) (*sql.DB, error) {
+				return sql.Open(driver, url)
+			},
+		}
+		Expect(queue.Open(ctx.Config.Storage)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(queue.Close()).To(Succeed())
+		// the jobs are removed along with their operations
+		ctx.Cleanup()
+	})
+
+	enqueueJobs := func(tenant string, count int) {
+		for i := 0; i < count; i++ {
+			UUID, err := uuid.NewV4()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ctx.SMRepository.Create(context.Background(), &types.Operation{
+				Base: types.Base{
+					ID:        UUID.String(),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+					Labels:    make(map[string][]string),
+					Ready:     true,
+				},
+				Type:          types.CREATE,
+				State:         types.IN_PROGRESS,
+				ResourceID:    UUID.String(),
+				ResourceType:  resourceType,
+				PlatformID:    types.SMPlatform,
+				CorrelationID: UUID.String(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(queue.EnqueueJob(context.Background(), &storage.Job{
+				OperationID:  UUID.String(),
+				ResourceType: resourceType,
+				Tenant:       tenant,
+				Payload:      []byte("payload"),
+			})).To(Succeed())
+		}
+	}
+
+	leaseJobs := func(owner string, limit, tenantLimit int) map[string]int {
+		jobs, err := queue.LeaseJobs(context.Background(), owner, resourceType, limit, tenantLimit, time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		jobsPerTenant := make(map[string]int)
+		for _, job := range jobs {
+			jobsPerTenant[job.Tenant]++
+		}
+		return jobsPerTenant
+	}
+
+	It("leases the jobs round robin across tenants", func() {
+		enqueueJobs("tenant1", 3)
+		enqueueJobs("tenant2", 3)
+
+		Expect(leaseJobs("owner1", 2, 10)).To(Equal(map[string]int{"tenant1": 1, "tenant2": 1}))
+		Expect(leaseJobs("owner2", 2, 10)).To(Equal(map[string]int{"tenant1": 1, "tenant2": 1}))
+	})
+
+	It("limits the jobs of a tenant leased by all owners together", func() {
+		enqueueJobs("tenant1", 3)
+		enqueueJobs("tenant2", 1)
+
+		Expect(leaseJobs("owner1", 10, 2)).To(Equal(map[string]int{"tenant1": 2, "tenant2": 1}))
+		Expect(leaseJobs("owner2", 10, 2)).To(BeEmpty())
+	})
+
+	It("does not limit the jobs without a tenant", func() {
+		enqueueJobs("", 3)
+
+		Expect(leaseJobs("owner1", 2, 1)).To(Equal(map[string]int{"": 2}))
+		Expect(leaseJobs("owner2", 2, 1)).To(Equal(map[string]int{"": 1}))
+	})
+})
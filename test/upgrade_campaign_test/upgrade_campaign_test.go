@@ -0,0 +1,341 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+package upgrade_campaign_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/sm"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test"
+	. "github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestUpgradeCampaigns(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Upgrade Campaigns Tests Suite")
+}
+
+var _ = Describe("Upgrade campaigns", func() {
+	var (
+		ctx          *TestContext
+		brokerServer *BrokerServer
+		planID       string
+	)
+
+	createInstanceWith := func(smClient *SMExpect, version string) string {
+		UUID, err := uuid.NewV4()
+		Expect(err).ToNot(HaveOccurred())
+		return smClient.POST(web.ServiceInstancesURL).
+			WithQuery("async", false).
+			WithJSON(Object{
+				"name":             "test-instance-" + UUID.String(),
+				"service_plan_id":  planID,
+				"maintenance_info": Object{"version": version},
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+	}
+
+	createInstance := func(version string) string {
+		return createInstanceWith(ctx.SMWithOAuth, version)
+	}
+
+	createCampaign := func(campaign Object) string {
+		return ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL).
+			WithJSON(campaign).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+	}
+
+	campaignState := func(campaignID string) string {
+		return ctx.SMWithOAuth.GET(web.UpgradeCampaignsURL + "/" + campaignID).
+			Expect().Status(http.StatusOK).
+			JSON().Object().Value("state").String().Raw()
+	}
+
+	instanceVersion := func(instanceID string) string {
+		return ctx.SMWithOAuth.GET(web.ServiceInstancesURL + "/" + instanceID).
+			Expect().Status(http.StatusOK).
+			JSON().Path("$.maintenance_info.version").String().Raw()
+	}
+
+	registerBroker := func() {
+		plan, err := sjson.Set(GenerateTestPlan(), "maintenance_info.version", "2.0.0")
+		Expect(err).ToNot(HaveOccurred())
+		catalog := NewEmptySBCatalog()
+		catalog.AddService(GenerateTestServiceWithPlans(plan))
+		brokerUtils := ctx.RegisterBrokerWithCatalog(catalog)
+		brokerServer = brokerUtils.Broker.BrokerServer
+		ctx.Servers[BrokerServerPrefix+brokerUtils.Broker.ID] = brokerServer
+
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, fmt.Sprintf("fieldQuery=broker_id eq '%s'", brokerUtils.Broker.ID)).
+			First().Object().Value("id").String().Raw()
+		planID = ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, fmt.Sprintf("fieldQuery=service_offering_id eq '%s'", offeringID)).
+			First().Object().Value("id").String().Raw()
+		test.EnsurePlanVisibility(ctx.SMRepository, "", types.SMPlatform, planID, "")
+	}
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+			e.Set("operations.upgrade_campaign_interval", "1s")
+		}).Build()
+		registerBroker()
+	})
+
+	AfterEach(func() {
+		err := ctx.SMRepository.Delete(context.Background(), types.UpgradeCampaignType)
+		if err != nil && err != util.ErrNotFoundInStorage {
+			Fail(err.Error())
+		}
+		ctx.Cleanup()
+	})
+
+	Context("create", func() {
+		It("returns 400 if the field query is invalid", func() {
+			ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL).
+				WithJSON(Object{"name": "campaign", "field_query": "service_plan_id"}).
+				Expect().Status(http.StatusBadRequest)
+		})
+
+		It("returns 400 if max_concurrency is not positive", func() {
+			ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL).
+				WithJSON(Object{"name": "campaign", "max_concurrency": 0}).
+				Expect().Status(http.StatusBadRequest)
+		})
+
+		It("returns 400 if the campaign is created in a final state", func() {
+			ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL).
+				WithJSON(Object{"name": "campaign", "state": string(types.UpgradeCampaignSucceeded)}).
+				Expect().Status(http.StatusBadRequest)
+		})
+	})
+
+	Context("in progress", func() {
+		It("upgrades the instances lagging behind their plans", func() {
+			laggingInstanceIDs := []string{createInstance("1.0.0"), createInstance("1.9.0")}
+			upToDateInstanceID := createInstance("2.0.0")
+			brokerServer.ResetCallHistory()
+
+			campaignID := createCampaign(Object{
+				"name":            "campaign",
+				"field_query":     fmt.Sprintf("service_plan_id eq '%s'", planID),
+				"max_concurrency": 1,
+			})
+
+			Eventually(func() string {
+				return campaignState(campaignID)
+			}, 30*time.Second).Should(Equal(string(types.UpgradeCampaignSucceeded)))
+
+			progress := ctx.SMWithOAuth.GET(web.UpgradeCampaignsURL + "/" + campaignID).
+				Expect().Status(http.StatusOK).
+				JSON().Object().Value("progress").Object()
+			progress.Value("total").Equal(2)
+			progress.Value("succeeded").Equal(2)
+			progress.Value("pending").Equal(0)
+			progress.Value("failed").Equal(0)
+
+			for _, instanceID := range laggingInstanceIDs {
+				Expect(instanceVersion(instanceID)).To(Equal("2.0.0"))
+			}
+			Expect(instanceVersion(upToDateInstanceID)).To(Equal("2.0.0"))
+			Expect(brokerServer.ServiceInstanceEndpointRequests).To(HaveLen(2))
+			for _, request := range brokerServer.ServiceInstanceEndpointRequests {
+				Expect(request.Method).To(Equal(http.MethodPatch))
+			}
+			Expect(brokerServer.LastRequest.Header.Get("X-Broker-API-Version")).To(Equal("2.15"))
+			Expect(gjson.GetBytes(brokerServer.LastRequestBody, "maintenance_info.version").String()).To(Equal("2.0.0"))
+			Expect(gjson.GetBytes(brokerServer.LastRequestBody, "previous_values.maintenance_info.version").String()).To(Equal("1.9.0"))
+		})
+	})
+
+	Context("with instances of a tenant", func() {
+		const tenantLabelKey = "tenant"
+
+		BeforeEach(func() {
+			ctx.Cleanup()
+			ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+				e.Set("operations.upgrade_campaign_interval", "1s")
+			}).WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+				_, err := smb.EnableMultitenancy(tenantLabelKey, ExtractTenantFunc)
+				return err
+			}).Build()
+			registerBroker()
+		})
+
+		It("labels the upgrade operations with the tenant of the instance", func() {
+			tenantExpect := ctx.NewTenantExpect("tenancyClient", "tenant-1")
+			instanceID := createInstanceWith(tenantExpect, "1.0.0")
+			campaignID := createCampaign(Object{
+				"name":        "campaign",
+				"field_query": fmt.Sprintf("service_plan_id eq '%s'", planID),
+			})
+			Eventually(func() string {
+				return campaignState(campaignID)
+			}, 30*time.Second).Should(Equal(string(types.UpgradeCampaignSucceeded)))
+
+			operations := tenantExpect.GET(web.OperationsURL).
+				WithQuery("fieldQuery", fmt.Sprintf("type eq '%s'", types.UPDATE)).
+				Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+			operations.Length().Equal(1)
+			operation := operations.First().Object()
+			operation.ValueEqual("resource_id", instanceID)
+			operation.Path(fmt.Sprintf("$.labels[%s][*]", tenantLabelKey)).Array().Contains("tenant-1")
+			operation.Path(fmt.Sprintf("$.labels[%s][*]", types.UpgradeCampaignLabelKey)).Array().Contains(campaignID)
+
+			ctx.NewTenantExpect("tenancyClient", "tenant-2").GET(web.OperationsURL).
+				WithQuery("fieldQuery", fmt.Sprintf("type eq '%s'", types.UPDATE)).
+				Expect().Status(http.StatusOK).JSON().Object().Value("items").Array().Empty()
+		})
+	})
+
+	Context("with canceled upgrades", func() {
+		It("counts them as failed and finishes", func() {
+			instanceID := createInstance("1.0.0")
+			campaignID := createCampaign(Object{
+				"name":        "campaign",
+				"field_query": fmt.Sprintf("service_plan_id eq '%s'", planID),
+				"state":       string(types.UpgradeCampaignPaused),
+			})
+
+			UUID, err := uuid.NewV4()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ctx.SMRepository.Create(context.Background(), &types.Operation{
+				Base: types.Base{
+					ID:        UUID.String(),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+					Labels:    types.Labels{types.UpgradeCampaignLabelKey: {campaignID}},
+					Ready:     true,
+				},
+				Type:          types.UPDATE,
+				State:         types.CANCELED,
+				ResourceID:    instanceID,
+				ResourceType:  types.ServiceInstanceType,
+				PlatformID:    types.SMPlatform,
+				CorrelationID: UUID.String(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL + "/" + campaignID + web.UpgradeCampaignResumeURL).
+				Expect().Status(http.StatusOK)
+			Eventually(func() string {
+				return campaignState(campaignID)
+			}, 30*time.Second).Should(Equal(string(types.UpgradeCampaignFailed)))
+
+			progress := ctx.SMWithOAuth.GET(web.UpgradeCampaignsURL + "/" + campaignID).
+				Expect().Status(http.StatusOK).
+				JSON().Object().Value("progress").Object()
+			progress.Value("failed").Equal(1)
+			progress.Value("in_progress").Equal(0)
+			Expect(instanceVersion(instanceID)).To(Equal("1.0.0"))
+		})
+	})
+
+	Context("with maintenance windows", func() {
+		var windowStart time.Time
+
+		BeforeEach(func() {
+			ctx.Cleanup()
+			windowDay := time.Now().UTC().AddDate(0, 0, 2)
+			windowStart = time.Date(windowDay.Year(), windowDay.Month(), windowDay.Day(), 0, 0, 0, 0, time.UTC)
+			ctx = NewTestContextBuilder().WithEnvPostExtensions(func(e env.Environment, servers map[string]FakeServer) {
+				e.Set("operations.upgrade_campaign_interval", "1s")
+				e.Set("operations.maintenance_windows", []interface{}{
+					map[string]interface{}{
+						"platform_id": types.SMPlatform,
+						"days":        []string{strings.ToLower(windowDay.Weekday().String())},
+						"start":       "00:00",
+						"duration":    "1h",
+					},
+				})
+			}).Build()
+			registerBroker()
+		})
+
+		It("defers the upgrades to the next maintenance window of the instances", func() {
+			instanceID := createInstance("1.0.0")
+			campaignID := createCampaign(Object{
+				"name":        "campaign",
+				"field_query": fmt.Sprintf("service_plan_id eq '%s'", planID),
+			})
+
+			var operation *types.Operation
+			Eventually(func() int {
+				operations, err := ctx.SMRepository.List(context.Background(), types.OperationType,
+					query.ByLabel(query.EqualsOperator, types.UpgradeCampaignLabelKey, campaignID))
+				Expect(err).ToNot(HaveOccurred())
+				if operations.Len() != 0 {
+					operation = operations.ItemAt(0).(*types.Operation)
+				}
+				return operations.Len()
+			}, 10*time.Second).Should(Equal(1))
+			Expect(operation.State).To(Equal(types.PENDING))
+			Expect(operation.ScheduledAt).To(BeTemporally("==", windowStart))
+			Expect(campaignState(campaignID)).To(Equal(string(types.UpgradeCampaignInProgress)))
+			Expect(instanceVersion(instanceID)).To(Equal("1.0.0"))
+		})
+	})
+
+	Context("paused", func() {
+		var campaignID string
+
+		BeforeEach(func() {
+			createInstance("1.0.0")
+			campaignID = createCampaign(Object{
+				"name":        "campaign",
+				"field_query": fmt.Sprintf("service_plan_id eq '%s'", planID),
+				"state":       string(types.UpgradeCampaignPaused),
+			})
+		})
+
+		It("does not start upgrades until it is resumed", func() {
+			Consistently(func() int {
+				return int(ctx.SMWithOAuth.GET(web.UpgradeCampaignsURL + "/" + campaignID).
+					Expect().Status(http.StatusOK).
+					JSON().Path("$.progress.pending").Number().Raw())
+			}, 3*time.Second).Should(Equal(1))
+
+			ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL + "/" + campaignID + web.UpgradeCampaignResumeURL).
+				Expect().Status(http.StatusOK).
+				JSON().Object().Value("state").Equal(string(types.UpgradeCampaignInProgress))
+
+			Eventually(func() string {
+				return campaignState(campaignID)
+			}, 30*time.Second).Should(Equal(string(types.UpgradeCampaignSucceeded)))
+		})
+
+		It("returns 409 when it is paused again", func() {
+			ctx.SMWithOAuth.POST(web.UpgradeCampaignsURL + "/" + campaignID + web.UpgradeCampaignPauseURL).
+				Expect().Status(http.StatusConflict)
+		})
+	})
+})
@@ -0,0 +1,152 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/sm"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	. "github.com/Peripli/service-manager/test/common"
+	"github.com/Peripli/service-manager/webhooks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks of tenants", func() {
+	var (
+		ctx          *TestContext
+		server       *httptest.Server
+		tenantExpect *SMExpect
+		otherExpect  *SMExpect
+
+		mutex  sync.Mutex
+		events []*webhooks.CloudEvent
+	)
+
+	received := func() []*webhooks.CloudEvent {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return events
+	}
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().WithEnvPreExtensions(func(set *pflag.FlagSet) {
+			Expect(set.Set("webhooks.delivery_interval", "100ms")).ToNot(HaveOccurred())
+			Expect(set.Set("webhooks.allow_private_networks", "true")).ToNot(HaveOccurred())
+		}).WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+			_, err := smb.EnableMultitenancy("tenant", ExtractTenantFunc)
+			return err
+		}).Build()
+		tenantExpect = ctx.NewTenantExpect("tenancyClient", "tenant-1")
+		otherExpect = ctx.NewTenantExpect("tenancyClient", "tenant-2")
+
+		events = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			event := &webhooks.CloudEvent{}
+			_ = json.Unmarshal(body, event)
+			mutex.Lock()
+			events = append(events, event)
+			mutex.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		err := ctx.SMRepository.Delete(context.Background(), types.WebhookType)
+		if err != nil && err != util.ErrNotFoundInStorage {
+			Fail(err.Error())
+		}
+		server.Close()
+		ctx.Cleanup()
+	})
+
+	createTenantWebhook := func() string {
+		return tenantExpect.POST(web.WebhooksURL).
+			WithJSON(Object{
+				"name":           "tenant-webhook",
+				"url":            server.URL,
+				"resource_types": Array{"visibilities"},
+			}).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Value("id").String().Raw()
+	}
+
+	It("hides the webhooks of a tenant from other tenants", func() {
+		webhookURL := web.WebhooksURL + "/" + createTenantWebhook()
+
+		tenantExpect.GET(webhookURL).Expect().Status(http.StatusOK)
+		ctx.SMWithOAuth.GET(webhookURL).Expect().Status(http.StatusOK)
+
+		otherExpect.GET(webhookURL).Expect().Status(http.StatusNotFound)
+		otherExpect.GET(webhookURL + web.WebhookDeliveryHistoryURL).Expect().Status(http.StatusNotFound)
+		otherExpect.GET(web.WebhooksURL).Expect().Status(http.StatusOK).
+			JSON().Object().Value("items").Array().Empty()
+		otherExpect.PATCH(webhookURL).WithJSON(Object{"disabled": true}).Expect().Status(http.StatusNotFound)
+		otherExpect.DELETE(webhookURL).Expect().Status(http.StatusNotFound)
+	})
+
+	It("delivers only the notifications of the platforms of the tenant", func() {
+		createTenantWebhook()
+
+		brokerID := ctx.RegisterBroker().Broker.ID
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, "fieldQuery=broker_id eq '"+brokerID+"'").
+			First().Object().Value("id").String().Raw()
+		planID := ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, "fieldQuery=service_offering_id eq '"+offeringID+"'").
+			First().Object().Value("id").String().Raw()
+		tenantPlatform := RegisterPlatformInSM(GenerateRandomPlatform(), tenantExpect, map[string]string{})
+		otherPlatform := RegisterPlatformInSM(GenerateRandomPlatform(), otherExpect, map[string]string{})
+
+		RegisterVisibilityForPlanAndPlatform(ctx.SMWithOAuth, planID, otherPlatform.ID)
+		tenantVisibilityID := RegisterVisibilityForPlanAndPlatform(ctx.SMWithOAuth, planID, tenantPlatform.ID)
+
+		Eventually(received, "5s").Should(HaveLen(1))
+		Consistently(received, "1s").Should(HaveLen(1))
+		Expect(received()[0].Subject).To(Equal(tenantVisibilityID))
+	})
+})
+
+var _ = Describe("Webhook targets", func() {
+	var ctx *TestContext
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().Build()
+	})
+
+	AfterEach(func() {
+		ctx.Cleanup()
+	})
+
+	It("rejects webhooks targeting the local host or private addresses", func() {
+		for _, url := range []string{"http://localhost:8080/webhook", "http://127.0.0.1:8080/webhook", "http://169.254.169.254/latest/meta-data"} {
+			ctx.SMWithOAuth.POST(web.WebhooksURL).
+				WithJSON(Object{"name": "private-webhook", "url": url}).
+				Expect().Status(http.StatusBadRequest)
+		}
+	})
+})
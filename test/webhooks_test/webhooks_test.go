@@ -0,0 +1,191 @@
+/*
+ *    Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/pkg/web"
+	. "github.com/Peripli/service-manager/test/common"
+	"github.com/Peripli/service-manager/webhooks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhooks Tests Suite")
+}
+
+var _ = Describe("Webhooks", func() {
+	var (
+		ctx        *TestContext
+		server     *httptest.Server
+		statusCode int
+
+		mutex   sync.Mutex
+		events  []*webhooks.CloudEvent
+		headers []http.Header
+		bodies  [][]byte
+	)
+
+	received := func() []*webhooks.CloudEvent {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return events
+	}
+
+	createWebhook := func(webhook Object) map[string]interface{} {
+		return ctx.SMWithOAuth.POST(web.WebhooksURL).
+			WithJSON(webhook).
+			Expect().Status(http.StatusCreated).
+			JSON().Object().Raw()
+	}
+
+	createVisibility := func() string {
+		platform := ctx.RegisterPlatform()
+		brokerID := ctx.RegisterBroker().Broker.ID
+		offeringID := ctx.SMWithOAuth.ListWithQuery(web.ServiceOfferingsURL, "fieldQuery=broker_id eq '"+brokerID+"'").
+			First().Object().Value("id").String().Raw()
+		planID := ctx.SMWithOAuth.ListWithQuery(web.ServicePlansURL, "fieldQuery=service_offering_id eq '"+offeringID+"'").
+			First().Object().Value("id").String().Raw()
+		return RegisterVisibilityForPlanAndPlatform(ctx.SMWithOAuth, planID, platform.ID)
+	}
+
+	BeforeEach(func() {
+		ctx = NewTestContextBuilder().WithEnvPreExtensions(func(set *pflag.FlagSet) {
+			Expect(set.Set("webhooks.delivery_interval", "100ms")).ToNot(HaveOccurred())
+			Expect(set.Set("webhooks.retry_interval", "100ms")).ToNot(HaveOccurred())
+			Expect(set.Set("webhooks.max_retry_interval", "100ms")).ToNot(HaveOccurred())
+			Expect(set.Set("webhooks.max_failures", "2")).ToNot(HaveOccurred())
+			Expect(set.Set("webhooks.allow_private_networks", "true")).ToNot(HaveOccurred())
+		}).Build()
+
+		statusCode = http.StatusOK
+		events, headers, bodies = nil, nil, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			event := &webhooks.CloudEvent{}
+			_ = json.Unmarshal(body, event)
+			mutex.Lock()
+			events = append(events, event)
+			headers = append(headers, r.Header)
+			bodies = append(bodies, body)
+			status := statusCode
+			mutex.Unlock()
+			w.WriteHeader(status)
+		}))
+	})
+
+	AfterEach(func() {
+		err := ctx.SMRepository.Delete(context.Background(), types.WebhookType)
+		if err != nil && err != util.ErrNotFoundInStorage {
+			Fail(err.Error())
+		}
+		server.Close()
+		ctx.Cleanup()
+	})
+
+	It("returns the secret only when the webhook is created", func() {
+		webhook := createWebhook(Object{"name": "test-webhook", "url": server.URL})
+		Expect(webhook["secret"]).ToNot(BeEmpty())
+
+		ctx.SMWithOAuth.GET(web.WebhooksURL + "/" + webhook["id"].(string)).
+			Expect().Status(http.StatusOK).
+			JSON().Object().NotContainsKey("secret")
+	})
+
+	It("delivers the matching notifications as signed CloudEvents and records the deliveries", func() {
+		webhook := createWebhook(Object{
+			"name":           "test-webhook",
+			"url":            server.URL,
+			"resource_types": Array{"visibilities"},
+		})
+		visibilityID := createVisibility()
+
+		Eventually(received, "5s").Should(HaveLen(1))
+		mutex.Lock()
+		event, header, body := events[0], headers[0], bodies[0]
+		mutex.Unlock()
+		Expect(event.Type).To(Equal("io.peripli.servicemanager.visibilities.created"))
+		Expect(event.Subject).To(Equal(visibilityID))
+		Expect(header.Get("Content-Type")).To(Equal(webhooks.CloudEventsContentType))
+		timestamp := header.Get(operations.HookTimestampHeader)
+		Expect(header.Get(operations.HookSignatureHeader)).To(Equal(operations.SignHookRequest(webhook["secret"].(string), timestamp, body)))
+
+		deliveries := ctx.SMWithOAuth.GET(web.WebhooksURL + "/" + webhook["id"].(string) + web.WebhookDeliveryHistoryURL).
+			Expect().Status(http.StatusOK).
+			JSON().Object().Value("items").Array()
+		deliveries.Length().Equal(1)
+		deliveries.First().Object().Value("succeeded").Equal(true)
+		deliveries.First().Object().Value("revision").Equal(event.Revision)
+	})
+
+	It("disables the webhook after repeated failures until it is enabled again", func() {
+		mutex.Lock()
+		statusCode = http.StatusInternalServerError
+		mutex.Unlock()
+		webhook := createWebhook(Object{
+			"name":           "test-webhook",
+			"url":            server.URL,
+			"resource_types": Array{"visibilities"},
+		})
+		createVisibility()
+
+		webhookURL := web.WebhooksURL + "/" + webhook["id"].(string)
+		Eventually(func() bool {
+			return ctx.SMWithOAuth.GET(webhookURL).Expect().Status(http.StatusOK).JSON().Object().Value("disabled").Boolean().Raw()
+		}, "5s").Should(BeTrue())
+		Expect(received()).To(HaveLen(2))
+
+		mutex.Lock()
+		statusCode = http.StatusOK
+		mutex.Unlock()
+		ctx.SMWithOAuth.PATCH(webhookURL).
+			WithJSON(Object{"disabled": false}).
+			Expect().Status(http.StatusOK).
+			JSON().Object().Value("consecutive_failures").Equal(0)
+		Eventually(received, "5s").Should(HaveLen(3))
+	})
+
+	It("rejects invalid label queries", func() {
+		ctx.SMWithOAuth.POST(web.WebhooksURL).
+			WithJSON(Object{"name": "test-webhook", "url": server.URL, "label_query": "invalid"}).
+			Expect().Status(http.StatusBadRequest)
+	})
+
+	It("rejects unknown resource types", func() {
+		ctx.SMWithOAuth.POST(web.WebhooksURL).
+			WithJSON(Object{"name": "test-webhook", "url": server.URL, "resource_types": Array{"visibility"}}).
+			Expect().Status(http.StatusBadRequest)
+	})
+
+	It("requires a token", func() {
+		ctx.SM.GET(web.WebhooksURL).
+			Expect().Status(http.StatusUnauthorized)
+	})
+})
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package ws_notification_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Peripli/service-manager/pkg/env"
+	"github.com/Peripli/service-manager/pkg/sm"
+	"github.com/Peripli/service-manager/pkg/web"
+	"github.com/Peripli/service-manager/test/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notifications monitoring", func() {
+	var ctx *common.TestContext
+
+	BeforeEach(func() {
+		ctx = common.NewTestContextBuilder().WithSMExtensions(func(ctx context.Context, smb *sm.ServiceManagerBuilder, e env.Environment) error {
+			_, err := smb.EnableMultitenancy("tenant", common.ExtractTenantFunc)
+			return err
+		}).Build()
+	})
+
+	AfterEach(func() {
+		ctx.Cleanup()
+	})
+
+	monitoredPlatformIDs := func(smExpect *common.SMExpect) []string {
+		platforms := smExpect.GET(web.MonitorNotificationsURL).Expect().
+			Status(http.StatusOK).JSON().Object().Value("platforms").Array()
+		ids := make([]string, 0)
+		for _, platform := range platforms.Iter() {
+			ids = append(ids, platform.Object().Value("id").String().Raw())
+		}
+		return ids
+	}
+
+	It("lists only the platforms of the tenant to tenant tokens", func() {
+		tenantExpect := ctx.NewTenantExpect("tenancyClient", "tenant-1")
+		tenantPlatform := common.RegisterPlatformInSM(common.GenerateRandomPlatform(), tenantExpect, map[string]string{})
+		otherPlatform := common.RegisterPlatformInSM(common.GenerateRandomPlatform(), ctx.NewTenantExpect("tenancyClient", "tenant-2"), map[string]string{})
+		globalPlatform := common.RegisterPlatformInSM(common.GenerateRandomPlatform(), ctx.SMWithOAuth, map[string]string{})
+
+		Expect(monitoredPlatformIDs(tenantExpect)).To(ConsistOf(tenantPlatform.ID))
+		Expect(monitoredPlatformIDs(ctx.SMWithOAuth)).To(ContainElement(tenantPlatform.ID))
+		Expect(monitoredPlatformIDs(ctx.SMWithOAuth)).To(ContainElement(otherPlatform.ID))
+		Expect(monitoredPlatformIDs(ctx.SMWithOAuth)).To(ContainElement(globalPlatform.ID))
+	})
+})
@@ -17,10 +17,13 @@
 package ws_notification_test
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,6 +54,7 @@ func TestWsConn(t *testing.T) {
 }
 
 var pingTimeout = 1 * time.Second
+var longPollTimeout = 1 * time.Second
 
 var _ = Describe("WS", func() {
 	var ctx *common.TestContext
@@ -72,6 +76,7 @@ var _ = Describe("WS", func() {
 		ctx = common.NewTestContextBuilderWithSecurity().
 			WithEnvPreExtensions(func(set *pflag.FlagSet) {
 				Expect(set.Set("websocket.ping_timeout", pingTimeout.String())).ShouldNot(HaveOccurred())
+				Expect(set.Set("websocket.long_poll_timeout", longPollTimeout.String())).ShouldNot(HaveOccurred())
 			}).Build()
 		repository = ctx.SMRepository
 		Expect(repository).ToNot(BeNil())
@@ -322,6 +327,259 @@ var _ = Describe("WS", func() {
 		})
 	})
 
+	Context("when proxy acknowledges notifications", func() {
+		var notification, notification2 *types.Notification
+
+		lastAckedRevision := func() int64 {
+			obj, err := repository.Get(context.TODO(), types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			return obj.(*types.Platform).LastAckedRevision
+		}
+
+		ack := func(revision int64) {
+			err := wsconn.WriteJSON(&notifications.Message{Type: notifications.AckMessageType, Revision: revision})
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		JustBeforeEach(func() {
+			notification = createNotification(repository, platform.ID)
+			notification2 = createNotification(repository, platform.ID)
+			expectNotification(wsconn, notification.ID, platform.ID)
+			expectNotification(wsconn, notification2.ID, platform.ID)
+		})
+
+		It("should persist the last acknowledged revision of the platform", func() {
+			ack(notification.Revision)
+			Eventually(lastAckedRevision).Should(Equal(notification.Revision))
+
+			ack(notification2.Revision)
+			Eventually(lastAckedRevision).Should(Equal(notification2.Revision))
+		})
+
+		It("should not change the updated_at of the platform", func() {
+			obj, err := repository.Get(context.TODO(), types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			updatedAt := obj.GetUpdatedAt()
+
+			ack(notification2.Revision)
+			Eventually(lastAckedRevision).Should(Equal(notification2.Revision))
+
+			obj, err = repository.Get(context.TODO(), types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(obj.GetUpdatedAt()).To(BeTemporally("==", updatedAt))
+		})
+
+		It("should ignore acknowledgements of revisions which have not been sent", func() {
+			ack(notification2.Revision + 100)
+			Consistently(lastAckedRevision).Should(BeZero())
+		})
+
+		It("should redeliver the unacknowledged notifications on reconnect", func() {
+			ack(notification.Revision)
+			Eventually(lastAckedRevision).Should(Equal(notification.Revision))
+			Expect(wsconn.Close()).ShouldNot(HaveOccurred())
+
+			queryParams[notifications.LastKnownRevisionQueryParam] = strconv.FormatInt(notification2.Revision, 10)
+			conn, _, err := ctx.ConnectWebSocket(platform, queryParams, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			expectNotification(conn, notification2.ID, platform.ID)
+		})
+
+		It("should report the unacknowledged notifications of the platform", func() {
+			ack(notification.Revision)
+			Eventually(lastAckedRevision).Should(Equal(notification.Revision))
+
+			platforms := ctx.SMWithOAuth.GET(web.MonitorNotificationsURL).Expect().
+				Status(http.StatusOK).JSON().Object().Value("platforms").Array()
+			for _, p := range platforms.Iter() {
+				if p.Object().Value("id").String().Raw() == platform.ID {
+					p.Object().ContainsMap(map[string]interface{}{
+						"acknowledging":         true,
+						"last_acked_revision":   notification.Revision,
+						"unacked_notifications": 1,
+					})
+					return
+				}
+			}
+			Fail("platform not found in notifications monitoring response")
+		})
+	})
+
+	Context("when notifications are requested as server-sent events", func() {
+		var notification, notification2 *types.Notification
+
+		BeforeEach(func() {
+			notification = createNotification(repository, platform.ID)
+			notification2 = createNotification(repository, platform.ID)
+		})
+
+		It("should stream the notifications after the last event id", func() {
+			req, err := http.NewRequest(http.MethodGet, ctx.Servers[common.SMServer].URL()+web.NotificationsURL, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			req.SetBasicAuth(platform.Credentials.Basic.Username, platform.Credentials.Basic.Password)
+			req.Header.Set("Accept", notifications.EventStreamContentType)
+			req.Header.Set(notifications.LastEventIDHeader, strconv.FormatInt(notification.Revision, 10))
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal(notifications.EventStreamContentType))
+
+			reader := bufio.NewReader(resp.Body)
+			readLine := func() string {
+				line, err := reader.ReadString('\n')
+				Expect(err).ShouldNot(HaveOccurred())
+				return strings.TrimSuffix(line, "\n")
+			}
+			Expect(readLine()).To(Equal(fmt.Sprintf("id: %d", notification2.Revision)))
+			Expect(readLine()).To(Equal("event: " + notifications.NotificationEventType))
+			Expect(readLine()).To(And(HavePrefix("data: "), ContainSubstring(notification2.ID)))
+		})
+
+		It("should acknowledge the last event id on reconnect", func() {
+			req, err := http.NewRequest(http.MethodGet, ctx.Servers[common.SMServer].URL()+web.NotificationsURL, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			req.SetBasicAuth(platform.Credentials.Basic.Username, platform.Credentials.Basic.Password)
+			req.Header.Set("Accept", notifications.EventStreamContentType)
+			req.Header.Set(notifications.LastEventIDHeader, strconv.FormatInt(notification.Revision, 10))
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			obj, err := repository.Get(context.TODO(), types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(obj.(*types.Platform).LastAckedRevision).To(Equal(notification.Revision))
+		})
+
+		It("should return 410 Gone for unknown event ids", func() {
+			ctx.SMWithBasic.GET(web.NotificationsURL).
+				WithHeader("Accept", notifications.EventStreamContentType).
+				WithHeader(notifications.LastEventIDHeader, strconv.FormatInt(notification2.Revision+1, 10)).
+				Expect().Status(http.StatusGone)
+		})
+	})
+
+	Context("when notifications are long-polled", func() {
+		var notification, notification2 *types.Notification
+		var platformBasic *common.SMExpect
+
+		BeforeEach(func() {
+			notification = createNotification(repository, platform.ID)
+			notification2 = createNotification(repository, platform.ID)
+		})
+
+		JustBeforeEach(func() {
+			platformBasic = &common.SMExpect{}
+			platformBasic.SetBasicCredentials(ctx, platform.Credentials.Basic.Username, platform.Credentials.Basic.Password)
+		})
+
+		It("should return the notifications after the known revision and acknowledge it", func() {
+			resp := platformBasic.GET(web.NotificationsURL).
+				WithHeader("Accept", "application/json").
+				WithQuery(notifications.LastKnownRevisionQueryParam, notification.Revision).
+				Expect().Status(http.StatusOK).JSON().Object()
+			resp.Value("last_notification_revision").Equal(notification2.Revision)
+			resp.Value("notifications").Array().Length().Equal(1)
+			resp.Value("notifications").Array().First().Object().Value("id").Equal(notification2.ID)
+
+			obj, err := repository.Get(context.TODO(), types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(obj.(*types.Platform).LastAckedRevision).To(Equal(notification.Revision))
+		})
+
+		It("should return no notifications when none are created until the timeout", func() {
+			resp := platformBasic.GET(web.NotificationsURL).
+				WithHeader("Accept", "application/json").
+				WithQuery(notifications.LastKnownRevisionQueryParam, notification2.Revision).
+				Expect().Status(http.StatusOK).JSON().Object()
+			resp.Value("last_notification_revision").Equal(notification2.Revision)
+			resp.Value("notifications").Array().Empty()
+		})
+
+		It("should reset the acknowledgements of the platform when the revision is no longer available", func() {
+			platformBasic.GET(web.NotificationsURL).
+				WithHeader("Accept", "application/json").
+				WithQuery(notifications.LastKnownRevisionQueryParam, notification2.Revision).
+				Expect().Status(http.StatusOK)
+
+			platformBasic.GET(web.NotificationsURL).
+				WithHeader("Accept", "application/json").
+				WithQuery(notifications.LastKnownRevisionQueryParam, notification2.Revision+100).
+				Expect().Status(http.StatusGone)
+
+			obj, err := repository.Get(context.TODO(), types.PlatformType, query.ByField(query.EqualsOperator, "id", platform.ID))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(obj.(*types.Platform).LastAckedRevision).To(BeZero())
+		})
+
+		It("should mark the platform as active while it long-polls", func() {
+			longPollingPlatform := common.RegisterPlatformInSM(common.GenerateRandomPlatform(), ctx.SMWithOAuth, map[string]string{})
+			longPollingPlatformBasic := &common.SMExpect{}
+			longPollingPlatformBasic.SetBasicCredentials(ctx, longPollingPlatform.Credentials.Basic.Username, longPollingPlatform.Credentials.Basic.Password)
+			byID := query.ByField(query.EqualsOperator, "id", longPollingPlatform.ID)
+
+			longPollingPlatformBasic.GET(web.NotificationsURL).
+				WithHeader("Accept", "application/json").
+				Expect().Status(http.StatusOK)
+
+			obj, err := repository.Get(context.TODO(), types.PlatformType, byID)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(obj.(*types.Platform).Active).To(BeTrue())
+
+			By("not long-polling until the inactivity timeout passes")
+			Eventually(func() bool {
+				obj, err := repository.Get(context.TODO(), types.PlatformType, byID)
+				Expect(err).ShouldNot(HaveOccurred())
+				return obj.(*types.Platform).Active
+			}, 5*longPollTimeout, longPollTimeout/4).Should(BeFalse())
+		})
+	})
+
+	Context("when subscription filters are given", func() {
+		createVisibilityNotification := func(env string) *types.Notification {
+			notification := common.GenerateRandomNotification()
+			notification.PlatformID = platform.ID
+			notification.Resource = types.VisibilityType
+			notification.Payload = json.RawMessage(fmt.Sprintf(`{"new":{"resource":{"labels":{"env":["%s"]}}}}`, env))
+			_, err := repository.Create(context.Background(), notification)
+			Expect(err).ShouldNot(HaveOccurred())
+			return notification
+		}
+
+		BeforeEach(func() {
+			queryParams[notifications.ResourceTypesQueryParam] = "visibilities"
+			queryParams[string(query.LabelQuery)] = "env eq 'dev'"
+		})
+
+		It("should send only the matching notifications", func() {
+			createNotification(repository, platform.ID)
+			createVisibilityNotification("test")
+			notification := createVisibilityNotification("dev")
+			expectNotification(wsconn, notification.ID, platform.ID)
+		})
+
+		Context("and the resource types are invalid", func() {
+			It("should return status 400", func() {
+				queryParams[notifications.ResourceTypesQueryParam] = "visibility"
+				_, resp, err := ctx.ConnectWebSocket(platform, queryParams, nil)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+
+		Context("and the operation types are invalid", func() {
+			It("should return status 400", func() {
+				queryParams[notifications.OperationTypesQueryParam] = "UPSERTED"
+				_, resp, err := ctx.ConnectWebSocket(platform, queryParams, nil)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("when same platform is connected twice", func() {
 		It("should send same notifications to both", func() {
 			conn, _, err := ctx.ConnectWebSocket(platform, queryParams, nil)
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification of the delivered events
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventsContentType is the content type of the structured CloudEvents delivered to the webhooks
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// CloudEventTypePrefix prefixes the types of the delivered events, e.g. io.peripli.servicemanager.visibilities.created
+	CloudEventTypePrefix = "io.peripli.servicemanager."
+)
+
+// CloudEvent is a notification in the structured JSON format of the CloudEvents specification. The revision, the
+// platform and the correlation id of the notification are sent as extension attributes.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+
+	Revision      int64  `json:"revision"`
+	PlatformID    string `json:"platformid,omitempty"`
+	CorrelationID string `json:"correlationid,omitempty"`
+}
+
+// NewCloudEvent converts the notification into a CloudEvent. The subject of the event is the id of the resource.
+func NewCloudEvent(notification *types.Notification) *CloudEvent {
+	subject := gjson.GetBytes(notification.Payload, "new.resource.id").String()
+	if subject == "" {
+		subject = gjson.GetBytes(notification.Payload, "old.resource.id").String()
+	}
+
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              notification.ID,
+		Source:          notification.Resource.String(),
+		Type:            CloudEventTypePrefix + path.Base(notification.Resource.String()) + "." + strings.ToLower(string(notification.Type)),
+		Subject:         subject,
+		Time:            util.ToRFCNanoFormat(notification.CreatedAt),
+		DataContentType: "application/json",
+		Data:            notification.Payload,
+		Revision:        notification.Revision,
+		PlatformID:      notification.PlatformID,
+		CorrelationID:   notification.CorrelationID,
+	}
+}
@@ -0,0 +1,476 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/log"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/pkg/util"
+	"github.com/Peripli/service-manager/storage"
+)
+
+// WebhookDispatcherJob is the name of the webhook deliveries in the leases of the leader election
+const WebhookDispatcherJob = "deliverWebhooks"
+
+// Dispatcher schedules a go routine which delivers the notifications to the webhooks in the order of their revisions.
+// A failed delivery is retried with an exponential backoff before any later notification is delivered to the webhook,
+// and the webhook is disabled after too many consecutive failures. Only the notifications up to the committed revision
+// are delivered, so that a notification committed later with a lower revision is not skipped. If an elector is
+// configured, the notifications are delivered only by the Service Manager instance which leads the job.
+// The webhooks are delivered to concurrently, so that a slow endpoint does not delay the deliveries to the others.
+// Webhooks of a tenant only receive the notifications which the platforms of the tenant receive.
+type Dispatcher struct {
+	started bool
+
+	repository storage.TransactionalRepository
+	horizon    storage.RevisionHorizon
+	elector    *storage.LeaderElector
+	settings   *Settings
+	tenantKey  string
+	client     *http.Client
+}
+
+// NewDispatcher returns a dispatcher delivering the notifications from the repository up to the committed revision
+// provided by the horizon
+func NewDispatcher(repository storage.TransactionalRepository, horizon storage.RevisionHorizon, elector *storage.LeaderElector, settings *Settings, tenantKey string) *Dispatcher {
+	dialer := &net.Dialer{Timeout: settings.Timeout}
+	if !settings.AllowPrivateNetworks {
+		// the addresses are checked after the resolution of the host names, so that the webhooks cannot reach
+		// internal endpoints through host names resolving to internal addresses
+		dialer.Control = denyPrivateAddresses
+	}
+	return &Dispatcher{
+		repository: repository,
+		horizon:    horizon,
+		elector:    elector,
+		settings:   settings,
+		tenantKey:  tenantKey,
+		client: &http.Client{
+			Timeout: settings.Timeout,
+			// the deliveries are not sent through proxies, as the addresses of the webhooks could not be checked then
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+			// redirects are not followed, as they could lead the signed deliveries to other endpoints
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Filter returns the filter of the notifications delivered to the webhook or nil if it receives all notifications
+func Filter(ctx context.Context, webhook *types.Webhook) (storage.NotificationFilter, error) {
+	var labelCriteria []query.Criterion
+	if webhook.LabelQuery != "" {
+		var err error
+		if labelCriteria, err = query.Parse(query.LabelQuery, webhook.LabelQuery); err != nil {
+			return nil, err
+		}
+	}
+	return storage.NewNotificationFilter(ctx, webhook.ResourceTypes, webhook.OperationTypes, labelCriteria), nil
+}
+
+// Start schedules the dispatcher. It cannot be used concurrently.
+func (d *Dispatcher) Start(ctx context.Context, group *sync.WaitGroup) error {
+	if d.started {
+		return errors.New("webhook dispatcher already started")
+	}
+	d.started = true
+	group.Add(1)
+	go func() {
+		defer func() {
+			d.started = false
+			group.Done()
+		}()
+		log.C(ctx).Infof("Scheduling webhook deliveries every %s", d.settings.DeliveryInterval.String())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d.settings.DeliveryInterval):
+				d.run(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	if d.elector != nil {
+		d.elector.Run(ctx, WebhookDispatcherJob, d.dispatch)
+		return
+	}
+	if err := d.dispatch(ctx); err != nil {
+		log.C(ctx).WithError(err).Error("could not deliver notifications to webhooks")
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) error {
+	committedRevision, err := d.horizon.CommittedRevision(ctx, types.NotificationType)
+	if err != nil {
+		return fmt.Errorf("could not get committed revision of notifications: %s", err)
+	}
+
+	webhooks, err := d.repository.List(ctx, types.WebhookType, query.ByField(query.EqualsOperator, "disabled", "false"))
+	if err != nil {
+		return fmt.Errorf("could not list webhooks: %s", err)
+	}
+
+	now := time.Now()
+	workers := make(chan struct{}, d.settings.Workers)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < webhooks.Len(); i++ {
+		webhook := webhooks.ItemAt(i).(*types.Webhook)
+		if webhook.NextAttemptAt.After(now) || webhook.LastRevision >= committedRevision {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case workers <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			if err := d.deliverTo(ctx, webhook, committedRevision); err != nil {
+				log.C(ctx).WithError(err).Errorf("could not deliver notifications to webhook %s", webhook.ID)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// tenantFilter returns the filter of the notifications of the platforms of the tenant of the webhook and the
+// notifications for all platforms, or nil if the webhook has no tenant
+func (d *Dispatcher) tenantFilter(ctx context.Context, webhook *types.Webhook) (storage.NotificationFilter, error) {
+	if d.tenantKey == "" || len(webhook.Labels[d.tenantKey]) == 0 {
+		return nil, nil
+	}
+	platforms, err := d.repository.ListNoLabels(ctx, types.PlatformType,
+		query.ByLabel(query.InOperator, d.tenantKey, webhook.Labels[d.tenantKey]...))
+	if err != nil {
+		return nil, fmt.Errorf("could not list platforms of tenant: %s", err)
+	}
+	platformIDs := make(map[string]bool, platforms.Len())
+	for i := 0; i < platforms.Len(); i++ {
+		platformIDs[platforms.ItemAt(i).GetID()] = true
+	}
+	return func(notification *types.Notification) bool {
+		return notification.PlatformID == "" || platformIDs[notification.PlatformID]
+	}, nil
+}
+
+// deliverTo delivers the notifications after the last revision of the webhook up to the committed revision until a
+// delivery fails. The notifications not matching the filter of the webhook are skipped.
+func (d *Dispatcher) deliverTo(ctx context.Context, webhook *types.Webhook, committedRevision int64) error {
+	filter, err := Filter(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("invalid label_query of webhook: %s", err)
+	}
+	tenantFilter, err := d.tenantFilter(ctx, webhook)
+	if err != nil {
+		return err
+	}
+	stored := newDeliveryState(webhook)
+
+	notifications, err := d.repository.ListNoLabels(ctx, types.NotificationType,
+		query.ByField(query.GreaterThanOperator, "revision", strconv.FormatInt(webhook.LastRevision, 10)),
+		query.ByField(query.LessThanOrEqualOperator, "revision", strconv.FormatInt(committedRevision, 10)),
+		query.OrderResultBy("revision", query.AscOrder),
+		query.LimitResultBy(d.settings.BatchSize))
+	if err != nil {
+		return fmt.Errorf("could not list notifications: %s", err)
+	}
+
+	skipped := false
+	for i := 0; i < notifications.Len(); i++ {
+		notification := notifications.ItemAt(i).(*types.Notification)
+		if (filter != nil && !filter(notification)) || (tenantFilter != nil && !tenantFilter(notification)) {
+			webhook.LastRevision = notification.Revision
+			skipped = true
+			continue
+		}
+		if ctx.Err() != nil || (d.elector != nil && !d.elector.IsLeader(WebhookDispatcherJob)) {
+			break
+		}
+
+		delivery, err := d.deliver(ctx, webhook, notification)
+		if err != nil {
+			return err
+		}
+		if delivery.Succeeded {
+			webhook.LastRevision = notification.Revision
+			webhook.ConsecutiveFailures = 0
+		} else {
+			d.recordFailure(ctx, webhook, delivery)
+		}
+		skipped = false
+		if err := d.save(ctx, webhook, stored, delivery); err != nil {
+			return err
+		}
+		if !delivery.Succeeded {
+			// the later notifications are delivered only after the failed one to preserve their order
+			return nil
+		}
+	}
+
+	if skipped {
+		return d.save(ctx, webhook, stored, nil)
+	}
+	return nil
+}
+
+// deliver posts the notification to the webhook as a signed CloudEvent and returns the record of the attempt
+func (d *Dispatcher) deliver(ctx context.Context, webhook *types.Webhook, notification *types.Notification) (*types.WebhookDelivery, error) {
+	UUID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate GUID for webhook delivery: %s", err)
+	}
+
+	now := time.Now()
+	delivery := &types.WebhookDelivery{
+		Base: types.Base{
+			ID:        UUID.String(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Labels:    map[string][]string{},
+			Ready:     true,
+		},
+		WebhookID:      webhook.ID,
+		NotificationID: notification.ID,
+		Revision:       notification.Revision,
+		Attempt:        webhook.ConsecutiveFailures + 1,
+	}
+
+	statusCode, err := d.post(ctx, webhook, NewCloudEvent(notification))
+	delivery.StatusCode = statusCode
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		delivery.Succeeded = true
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) post(ctx context.Context, webhook *types.Webhook, event *CloudEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", CloudEventsContentType)
+	request.Header.Set(operations.HookTimestampHeader, timestamp)
+	request.Header.Set(operations.HookSignatureHeader, operations.SignHookRequest(webhook.Secret, timestamp, body))
+
+	response, err := d.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// the response is drained so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// recordFailure schedules the retry of the failed delivery with an exponential backoff and disables the webhook
+// after too many consecutive failures
+func (d *Dispatcher) recordFailure(ctx context.Context, webhook *types.Webhook, delivery *types.WebhookDelivery) {
+	webhook.ConsecutiveFailures++
+	backoff := d.settings.RetryInterval
+	for i := 1; i < webhook.ConsecutiveFailures && backoff < d.settings.MaxRetryInterval; i++ {
+		backoff *= 2
+	}
+	if backoff > d.settings.MaxRetryInterval {
+		backoff = d.settings.MaxRetryInterval
+	}
+	webhook.NextAttemptAt = time.Now().Add(backoff)
+	log.C(ctx).Warnf("Delivery of notification with revision %d to webhook %s failed (attempt %d): %s",
+		delivery.Revision, webhook.ID, delivery.Attempt, delivery.Error)
+
+	if webhook.ConsecutiveFailures >= d.settings.MaxFailures {
+		webhook.Disabled = true
+		webhook.DisabledReason = fmt.Sprintf("disabled after %d consecutive failed deliveries, last error: %s", webhook.ConsecutiveFailures, delivery.Error)
+		log.C(ctx).Warnf("Disabled webhook %s after %d consecutive failed deliveries", webhook.ID, webhook.ConsecutiveFailures)
+	}
+}
+
+// deliveryState is the delivery state of a webhook as last read or saved by the dispatcher
+type deliveryState struct {
+	consecutiveFailures int
+	nextAttemptAt       time.Time
+	disabled            bool
+}
+
+func newDeliveryState(webhook *types.Webhook) *deliveryState {
+	return &deliveryState{
+		consecutiveFailures: webhook.ConsecutiveFailures,
+		nextAttemptAt:       webhook.NextAttemptAt,
+		disabled:            webhook.Disabled,
+	}
+}
+
+func (s *deliveryState) matches(webhook *types.Webhook) bool {
+	return s.consecutiveFailures == webhook.ConsecutiveFailures &&
+		s.nextAttemptAt.Equal(webhook.NextAttemptAt) &&
+		s.disabled == webhook.Disabled
+}
+
+// save records the delivery and the delivery state of the webhook. The webhook is locked so that the concurrent
+// changes of its other fields are kept. A failure is only recorded if the delivery state of the webhook has not
+// changed since it was read, so that enabling the webhook during the delivery resets its failures nonetheless.
+// Webhooks deleted in the meantime are ignored.
+func (d *Dispatcher) save(ctx context.Context, webhook *types.Webhook, stored *deliveryState, delivery *types.WebhookDelivery) error {
+	err := d.repository.InTransaction(ctx, func(ctx context.Context, txStorage storage.Repository) error {
+		obj, err := txStorage.GetForUpdate(ctx, types.WebhookType, query.ByField(query.EqualsOperator, "id", webhook.ID))
+		if err != nil {
+			return err
+		}
+		current := obj.(*types.Webhook)
+
+		if delivery != nil {
+			if _, err := txStorage.Create(ctx, delivery); err != nil {
+				return err
+			}
+		}
+
+		current.LastRevision = webhook.LastRevision
+		switch {
+		case delivery == nil:
+		case delivery.Succeeded:
+			current.ConsecutiveFailures = 0
+		case stored.matches(current):
+			current.ConsecutiveFailures = webhook.ConsecutiveFailures
+			current.NextAttemptAt = webhook.NextAttemptAt
+			if webhook.Disabled {
+				current.Disabled = true
+				current.DisabledReason = webhook.DisabledReason
+			}
+		default:
+			log.C(ctx).Infof("Delivery state of webhook %s changed during the delivery. Not recording the failure.", webhook.ID)
+		}
+		current.UpdatedAt = time.Now()
+		if _, err = txStorage.Update(ctx, current, types.LabelChanges{}); err != nil {
+			return err
+		}
+
+		*stored = *newDeliveryState(current)
+		webhook.ConsecutiveFailures = current.ConsecutiveFailures
+		webhook.NextAttemptAt = current.NextAttemptAt
+		webhook.Disabled = current.Disabled
+		return nil
+	})
+	if err == util.ErrNotFoundInStorage {
+		log.C(ctx).Debugf("Webhook %s was deleted during the delivery", webhook.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not save delivery state of webhook %s: %s", webhook.ID, err)
+	}
+	return nil
+}
+
+// privateNetworks are the loopback, private, link-local and unspecified networks which the webhooks must not target
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12", "192.168.0.0/16",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPrivateAddress reports whether the ip is a loopback, private, link-local or unspecified address
+func IsPrivateAddress(ip net.IP) bool {
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTarget returns an error if the host of the url of a webhook is a private address or the local host. The
+// host names resolving to private addresses are refused by the dispatcher when it connects to the webhook.
+func CheckTarget(webhookURL string) error {
+	endpoint, err := url.Parse(webhookURL)
+	if err != nil {
+		return err
+	}
+	host := endpoint.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("webhook url %s targets the local host", webhookURL)
+	}
+	if ip := net.ParseIP(host); ip != nil && IsPrivateAddress(ip) {
+		return fmt.Errorf("webhook url %s targets the private address %s", webhookURL, ip)
+	}
+	return nil
+}
+
+func denyPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || IsPrivateAddress(ip) {
+		return fmt.Errorf("connections to the private address %s are not allowed", host)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Peripli/service-manager/operations"
+	"github.com/Peripli/service-manager/pkg/query"
+	"github.com/Peripli/service-manager/pkg/types"
+	"github.com/Peripli/service-manager/storage"
+	"github.com/Peripli/service-manager/storage/storagefakes"
+	"github.com/Peripli/service-manager/webhooks"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeHorizon returns the committed revision from a function
+type fakeHorizon func() int64
+
+func (f fakeHorizon) CommittedRevision(ctx context.Context, objectType types.ObjectType) (int64, error) {
+	return f(), nil
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		ctx         context.Context
+		cancel      context.CancelFunc
+		wg          *sync.WaitGroup
+		fakeStorage *storagefakes.FakeStorage
+		settings    *webhooks.Settings
+		server      *httptest.Server
+		statusCode  int
+
+		mutex         sync.Mutex
+		webhook       *types.Webhook
+		notifications []*types.Notification
+		deliveries    []*types.WebhookDelivery
+		events        []*webhooks.CloudEvent
+		headers       []http.Header
+		bodies        [][]byte
+		stopWhen      func(webhook *types.Webhook) bool
+		onDelivery    func()
+		horizon       fakeHorizon
+	)
+
+	notification := func(revision int64, resource types.ObjectType) *types.Notification {
+		return &types.Notification{
+			Base:     types.Base{ID: fmt.Sprintf("notification-%d", revision), CreatedAt: time.Now()},
+			Resource: resource,
+			Type:     types.CREATED,
+			Revision: revision,
+			Payload:  json.RawMessage(`{"new":{"resource":{"id":"resource-id","labels":{}}}}`),
+		}
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		wg = &sync.WaitGroup{}
+		statusCode = http.StatusOK
+		onDelivery = func() {}
+		deliveries, events, headers, bodies = nil, nil, nil, nil
+		notifications = []*types.Notification{
+			notification(1, types.VisibilityType),
+			notification(2, types.PlatformType),
+			notification(3, types.VisibilityType),
+		}
+		webhook = &types.Webhook{
+			Base:          types.Base{ID: "webhook-id"},
+			Secret:        "secret",
+			ResourceTypes: []string{"visibilities"},
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			event := &webhooks.CloudEvent{}
+			_ = json.Unmarshal(body, event)
+			mutex.Lock()
+			events = append(events, event)
+			headers = append(headers, r.Header)
+			bodies = append(bodies, body)
+			onDelivery()
+			mutex.Unlock()
+			w.WriteHeader(statusCode)
+		}))
+		webhook.URL = server.URL
+		horizon = func() int64 {
+			return 3
+		}
+
+		fakeStorage = &storagefakes.FakeStorage{}
+		fakeStorage.InTransactionStub = func(ctx context.Context, f func(ctx context.Context, storage storage.Repository) error) error {
+			return f(ctx, fakeStorage)
+		}
+		fakeStorage.ListNoLabelsStub = func(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.ObjectList, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if objectType == types.WebhookType {
+				current := *webhook
+				return &types.Webhooks{Webhooks: []*types.Webhook{&current}}, nil
+			}
+			committedRevision := int64(-1)
+			for _, criterion := range criteria {
+				if criterion.LeftOp == "revision" && criterion.Operator == query.LessThanOrEqualOperator {
+					committedRevision, _ = strconv.ParseInt(criterion.RightOp[0], 10, 64)
+				}
+			}
+			result := &types.Notifications{}
+			for _, n := range notifications {
+				if n.Revision > webhook.LastRevision && (committedRevision < 0 || n.Revision <= committedRevision) {
+					result.Add(n)
+				}
+			}
+			return result, nil
+		}
+		fakeStorage.ListStub = fakeStorage.ListNoLabelsStub
+		fakeStorage.GetForUpdateStub = func(ctx context.Context, objectType types.ObjectType, criteria ...query.Criterion) (types.Object, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			current := *webhook
+			return &current, nil
+		}
+		fakeStorage.CreateStub = func(ctx context.Context, obj types.Object) (types.Object, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			deliveries = append(deliveries, obj.(*types.WebhookDelivery))
+			return obj, nil
+		}
+		fakeStorage.UpdateStub = func(ctx context.Context, obj types.Object, labelChanges types.LabelChanges, criteria ...query.Criterion) (types.Object, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			webhook = obj.(*types.Webhook)
+			if stopWhen(webhook) {
+				cancel()
+			}
+			return obj, nil
+		}
+
+		settings = webhooks.DefaultSettings()
+		settings.DeliveryInterval = time.Millisecond
+		settings.RetryInterval = time.Millisecond
+		settings.MaxRetryInterval = time.Millisecond
+		settings.AllowPrivateNetworks = true
+	})
+
+	AfterEach(func() {
+		cancel()
+		wg.Wait()
+		server.Close()
+	})
+
+	start := func() {
+		dispatcher := webhooks.NewDispatcher(fakeStorage, horizon, nil, settings, "")
+		Expect(dispatcher.Start(ctx, wg)).To(Succeed())
+		Eventually(ctx.Done()).Should(BeClosed())
+		wg.Wait()
+	}
+
+	It("delivers the matching notifications in the order of their revisions as signed CloudEvents", func() {
+		stopWhen = func(webhook *types.Webhook) bool {
+			return webhook.LastRevision == 3
+		}
+		start()
+
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Revision).To(Equal(int64(1)))
+		Expect(events[1].Revision).To(Equal(int64(3)))
+		Expect(events[0].SpecVersion).To(Equal(webhooks.CloudEventsSpecVersion))
+		Expect(events[0].Type).To(Equal("io.peripli.servicemanager.visibilities.created"))
+		Expect(events[0].Subject).To(Equal("resource-id"))
+		Expect(headers[0].Get("Content-Type")).To(Equal(webhooks.CloudEventsContentType))
+		timestamp := headers[0].Get(operations.HookTimestampHeader)
+		Expect(headers[0].Get(operations.HookSignatureHeader)).To(Equal(operations.SignHookRequest("secret", timestamp, bodies[0])))
+
+		Expect(deliveries).To(HaveLen(2))
+		Expect(deliveries[0].Succeeded).To(BeTrue())
+		Expect(deliveries[0].Attempt).To(Equal(1))
+		Expect(webhook.ConsecutiveFailures).To(Equal(0))
+	})
+
+	It("delivers only the notifications up to the committed revision", func() {
+		calls := 0
+		horizon = func() int64 {
+			calls++
+			if calls == 3 {
+				cancel()
+			}
+			return 2
+		}
+		stopWhen = func(webhook *types.Webhook) bool {
+			return false
+		}
+		start()
+
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Revision).To(Equal(int64(1)))
+		Expect(webhook.LastRevision).To(Equal(int64(2)))
+	})
+
+	It("retries a failed delivery before delivering later notifications and disables the webhook after too many failures", func() {
+		statusCode = http.StatusInternalServerError
+		settings.MaxFailures = 3
+		stopWhen = func(webhook *types.Webhook) bool {
+			return webhook.Disabled
+		}
+		start()
+
+		Expect(events).To(HaveLen(3))
+		for _, event := range events {
+			Expect(event.Revision).To(Equal(int64(1)))
+		}
+		Expect(deliveries).To(HaveLen(3))
+		for i, delivery := range deliveries {
+			Expect(delivery.Succeeded).To(BeFalse())
+			Expect(delivery.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(delivery.Attempt).To(Equal(i + 1))
+		}
+		Expect(webhook.LastRevision).To(Equal(int64(0)))
+		Expect(webhook.ConsecutiveFailures).To(Equal(3))
+		Expect(webhook.DisabledReason).To(ContainSubstring("status 500"))
+	})
+
+	It("backs off exponentially after consecutive failures", func() {
+		statusCode = http.StatusServiceUnavailable
+		settings.RetryInterval = time.Minute
+		settings.MaxRetryInterval = time.Hour
+		webhook.ConsecutiveFailures = 2
+		stopWhen = func(webhook *types.Webhook) bool {
+			return true
+		}
+		start()
+
+		Expect(events).To(HaveLen(1))
+		Expect(webhook.ConsecutiveFailures).To(Equal(3))
+		Expect(webhook.NextAttemptAt).To(BeTemporally("~", time.Now().Add(4*time.Minute), 10*time.Second))
+		Expect(webhook.Disabled).To(BeFalse())
+	})
+
+	It("keeps the delivery state reset during a failed delivery", func() {
+		statusCode = http.StatusServiceUnavailable
+		settings.MaxFailures = 3
+		webhook.ConsecutiveFailures = 2
+		onDelivery = func() {
+			// the webhook is enabled again while the delivery is in progress
+			webhook.ConsecutiveFailures = 0
+			webhook.NextAttemptAt = time.Now()
+		}
+		stopWhen = func(webhook *types.Webhook) bool {
+			return true
+		}
+		start()
+
+		Expect(deliveries).To(HaveLen(1))
+		Expect(deliveries[0].Succeeded).To(BeFalse())
+		Expect(webhook.ConsecutiveFailures).To(Equal(0))
+		Expect(webhook.Disabled).To(BeFalse())
+	})
+
+	It("does not deliver to private addresses unless they are allowed", func() {
+		settings.AllowPrivateNetworks = false
+		stopWhen = func(webhook *types.Webhook) bool {
+			return true
+		}
+		start()
+
+		Expect(events).To(BeEmpty())
+		Expect(deliveries).To(HaveLen(1))
+		Expect(deliveries[0].Succeeded).To(BeFalse())
+		Expect(deliveries[0].Error).To(ContainSubstring("private address"))
+	})
+
+	It("does not follow redirects", func() {
+		redirectServer := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusTemporaryRedirect))
+		defer redirectServer.Close()
+		webhook.URL = redirectServer.URL
+		stopWhen = func(webhook *types.Webhook) bool {
+			return true
+		}
+		start()
+
+		Expect(events).To(BeEmpty())
+		Expect(deliveries).To(HaveLen(1))
+		Expect(deliveries[0].Succeeded).To(BeFalse())
+		Expect(deliveries[0].StatusCode).To(Equal(http.StatusTemporaryRedirect))
+	})
+
+	It("rejects webhook urls targeting private addresses", func() {
+		Expect(webhooks.CheckTarget("https://example.com/webhook")).To(Succeed())
+		Expect(webhooks.CheckTarget("http://localhost:8080/webhook")).ToNot(Succeed())
+		Expect(webhooks.CheckTarget("http://127.0.0.1/webhook")).ToNot(Succeed())
+		Expect(webhooks.CheckTarget("http://10.1.2.3/webhook")).ToNot(Succeed())
+		Expect(webhooks.CheckTarget("http://169.254.169.254/latest/meta-data")).ToNot(Succeed())
+		Expect(webhooks.CheckTarget("http://[::1]/webhook")).ToNot(Succeed())
+	})
+})
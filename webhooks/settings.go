@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018 The Service Manager Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks
+
+import (
+	"fmt"
+	"time"
+)
+
+// Settings type to be loaded from the environment
+type Settings struct {
+	DeliveryInterval     time.Duration `mapstructure:"delivery_interval" description:"interval at which new notifications are delivered to the webhooks"`
+	Timeout              time.Duration `mapstructure:"timeout" description:"timeout of the delivery requests to the webhooks"`
+	BatchSize            int           `mapstructure:"batch_size" description:"maximum number of notifications delivered to a webhook in a single run"`
+	RetryInterval        time.Duration `mapstructure:"retry_interval" description:"interval after which a failed delivery is retried. It is doubled after each consecutive failure"`
+	MaxRetryInterval     time.Duration `mapstructure:"max_retry_interval" description:"maximum interval after which a failed delivery is retried"`
+	MaxFailures          int           `mapstructure:"max_failures" description:"number of consecutive failed deliveries after which a webhook is disabled"`
+	Workers              int           `mapstructure:"workers" description:"maximum number of webhooks to which notifications are delivered concurrently"`
+	AllowPrivateNetworks bool          `mapstructure:"allow_private_networks" description:"allow webhooks targeting loopback, private and link-local addresses"`
+}
+
+// DefaultSettings returns the default values for the webhook deliveries
+func DefaultSettings() *Settings {
+	return &Settings{
+		DeliveryInterval: 5 * time.Second,
+		Timeout:          30 * time.Second,
+		BatchSize:        100,
+		RetryInterval:    10 * time.Second,
+		MaxRetryInterval: time.Hour,
+		MaxFailures:      10,
+		Workers:          10,
+	}
+}
+
+// Validate validates the webhooks settings
+func (s *Settings) Validate() error {
+	if s.DeliveryInterval <= 0 {
+		return fmt.Errorf("validate webhooks settings: DeliveryInterval should be > 0")
+	}
+
+	if s.Timeout <= 0 {
+		return fmt.Errorf("validate webhooks settings: Timeout should be > 0")
+	}
+
+	if s.BatchSize <= 0 {
+		return fmt.Errorf("validate webhooks settings: BatchSize should be > 0")
+	}
+
+	if s.RetryInterval <= 0 {
+		return fmt.Errorf("validate webhooks settings: RetryInterval should be > 0")
+	}
+
+	if s.MaxRetryInterval < s.RetryInterval {
+		return fmt.Errorf("validate webhooks settings: MaxRetryInterval should be >= RetryInterval")
+	}
+
+	if s.MaxFailures <= 0 {
+		return fmt.Errorf("validate webhooks settings: MaxFailures should be > 0")
+	}
+
+	if s.Workers <= 0 {
+		return fmt.Errorf("validate webhooks settings: Workers should be > 0")
+	}
+
+	return nil
+}